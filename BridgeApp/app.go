@@ -12,14 +12,31 @@ import (
 	"sync"
 	"time"
 
+	"BridgeApp/internal/alerts"
+	"BridgeApp/internal/closereq"
+	"BridgeApp/internal/config"
+	"BridgeApp/internal/deadletter"
+	"BridgeApp/internal/execution"
+	"BridgeApp/internal/feed"
 	grpcserver "BridgeApp/internal/grpc"
+	"BridgeApp/internal/hedgepnl"
+	"BridgeApp/internal/idempotency"
 	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/notify"
+	"BridgeApp/internal/pnl"
+	"BridgeApp/internal/protocol"
+	"BridgeApp/internal/queue"
+	"BridgeApp/internal/schedule"
+	"BridgeApp/internal/selection"
+	"BridgeApp/internal/session"
 )
 
 // App struct
 type App struct {
 	ctx                  context.Context
-	tradeQueue           chan Trade
+	tradeQueues          map[string]*queue.Scheduler[Trade] // destination MT5 terminal -> priority queue
+	tradeQueueCfg        queue.Config
+	tradeQueuesMux       sync.RWMutex
 	queueMux             sync.Mutex
 	netPosition          int
 	hedgeLot             float64
@@ -33,9 +50,9 @@ type App struct {
 	grpcServer *grpcserver.Server
 	grpcPort   string
 
-	// Addon connection tracking
-	lastAddonRequestTime time.Time
-	addonStatusMux       sync.Mutex
+	// Addon connection tracking (the client session registry marks it stale, see app_clients.go)
+	addonStatusMux sync.Mutex
+	clientMonitor  sync.Once
 	// HedgeBot connection tracking
 	eaStatusMux sync.Mutex // Protects eaActive and eaLastPing
 	eaLastPing  time.Time  // Timestamp of the last successful ping from Hedgebot
@@ -43,13 +60,18 @@ type App struct {
 	// MT5 ticket to BaseID mapping
 	// CRITICAL: BaseID is always Quantower Position.Id - the authoritative correlation key
 	mt5TicketMux       sync.RWMutex
-	mt5TicketToBaseId  map[uint64]string          // MT5 ticket -> BaseID (Quantower Position.Id)
+	mt5TicketToBaseId  map[ticketKey]string       // MT5 ticket (per terminal) -> BaseID (Quantower Position.Id)
 	baseIdToTickets    map[string][]uint64        // BaseID (Quantower Position.Id) -> all MT5 hedge tickets
 	pendingCloseByBase map[string][]pendingTicket // BaseID (Quantower Position.Id) -> tickets actively being closed
+	ticketStates       map[ticketKey]*ticketState // MT5 ticket -> volume, open time and PnL from MT5 results
+	orphanTickets      map[ticketKey]orphanTicket // open MT5 tickets no BaseID tracks (see app_manual_hedge.go)
+	closeSelection     selection.Config           // which pooled ticket a close takes first, per account
 
 	// Metadata to aid resolution when BaseID mismatches occur
 	baseIdToInstrument map[string]string // BaseID (Quantower Position.Id) -> instrument symbol
 	baseIdToAccount    map[string]string // BaseID (Quantower Position.Id) -> account name
+	baseIdToTerminal   map[string]string // BaseID (Quantower Position.Id) -> MT5 terminal hedging it
+	cancelledEntries   map[string]int    // BaseID -> queued entries cancelled by a close before delivery
 	// Track client-initiated close requests by MT5 ticket to tag subsequent MT5 close results as acks
 	clientCloseMux         sync.Mutex
 	clientInitiatedTickets map[uint64]time.Time // ticket -> time marked
@@ -65,12 +87,78 @@ type App struct {
 	// Sequence counter for unique elastic event IDs
 	elasticSeqMux   sync.Mutex
 	elasticSeqCount uint64
+
+	// Undeliverable trades, and delivered trades awaiting their MT5 result (BaseID -> oldest first)
+	deadLetterMux sync.RWMutex
+	deadLetters   *deadletter.Store
+	deliveredMux  sync.Mutex
+	delivered     map[string][]deliveredTrade
+
+	// Client idempotency keys and their responses, shared with the gRPC server
+	idempotencyMux sync.Mutex
+	idempotency    *idempotency.Store
+
+	// MT5 result classification: retry policy, retries per trade id, retries waiting for their
+	// backoff per BaseID, outcomes per BaseID
+	execMux        sync.Mutex
+	retryCfg       execution.RetryConfig
+	retryAttempts  map[string]int
+	pendingRetries map[string][]*pendingRetry
+	execHistory    *execution.History
+
+	// SubmitCloseHedge calls tracked until MT5 confirms (or rejects) every ticket they closed
+	closeRequests *closereq.Tracker
+	closeTimeout  time.Duration
+
+	// MT5 prices, costs and PnL of every hedge deal, per BaseID and ticket
+	hedgeLedger *hedgepnl.Ledger
+
+	// Quantower balance, daily PnL and position profit combined with the hedge results
+	combinedPnL *pnl.Aggregator
+
+	// Trading-session calendar behind daily counters, loss-limit windows, log files and end-of-day summaries
+	calendar     *session.Calendar
+	lossLimit    float64
+	sessionDay   string // trading day the session clock last saw
+	sessionMux   sync.Mutex
+	sessionClock sync.Once
+
+	// Scheduled flattens and close_only windows; bridgeMode is "normal" or "close_only"
+	schedule        *schedule.Schedule
+	scheduleChecked time.Time // flattens up to this instant have fired
+	bridgeMode      string
+	modeWindows     []schedule.Occurrence
+	scheduleMux     sync.Mutex
+	scheduler       sync.Once
+
+	// Last error reported on StatusStream (dead-lettered trades, MT5 rejections)
+	lastError   string
+	lastErrorAt time.Time
+	statusMux   sync.Mutex
+
+	// Rule-based alerts to webhook/Telegram/email (see app_alerts.go)
+	alerter      *alerts.Alerter
+	alertMonitor sync.Once
+	mt5Seen      bool      // an EA connected at least once since startup
+	mt5DownSince time.Time // zero while the EA is connected
+	alertMux     sync.Mutex
+
+	// Desktop notification history (see app_notifications.go)
+	notifications *notify.Center
+
+	// Live event feed for the blotter and BaseID timelines (see app_feed.go)
+	feed *feed.Ring
+
+	// Bridge configuration file (symbol map, ...)
+	configMux sync.RWMutex
+	config    *config.Config
 }
 
 type elasticInfo struct {
 	PointsPer1kLoss float64
 	Instrument      string
 	Account         string
+	MT5Symbol       string
 }
 
 // elasticMark captures an elastic close signal context for short-lived correlation
@@ -184,6 +272,15 @@ type Trade struct {
 	QTPositionID string `json:"qt_position_id,omitempty"`
 	StrategyTag  string `json:"strategy_tag,omitempty"`
 	Origin       string `json:"origin_platform,omitempty"`
+
+	// MT5 symbol resolved by the bridge instrument map (empty = EA falls back to Instrument)
+	MT5Symbol string `json:"mt5_symbol,omitempty"`
+	// Destination MT5 terminal chosen by bridge routing (entries) or inherited from the BaseID
+	Terminal string `json:"terminal,omitempty"`
+	// MT5 lot computed by the bridge sizing module (0 = EA sizes the hedge itself)
+	HedgeLot float64 `json:"hedge_lot,omitempty"`
+	// CLOSE_HEDGE only: lots to close on MT5Ticket (0 = close the whole position)
+	CloseVolume float64 `json:"close_volume,omitempty"`
 }
 
 func normalizeTrade(t *Trade) {
@@ -212,16 +309,14 @@ func (a *App) popTicket(baseID string) (uint64, bool) {
 	a.mt5TicketMux.Lock()
 	defer a.mt5TicketMux.Unlock()
 
-	list := a.baseIdToTickets[baseID]
+	list := a.orderedPoolLocked(baseID)
 	if len(list) == 0 {
 		return 0, false
 	}
-	var ticket uint64
+	ticket := list[0]
 	if len(list) == 1 {
-		ticket = list[0]
 		delete(a.baseIdToTickets, baseID)
 	} else {
-		ticket = list[0]
 		a.baseIdToTickets[baseID] = list[1:]
 	}
 	return ticket, true
@@ -247,7 +342,11 @@ func (a *App) pushTicket(baseID string, ticket uint64) {
 	}
 	a.mt5TicketMux.Lock()
 	defer a.mt5TicketMux.Unlock()
+	a.pushTicketLocked(baseID, ticket)
+}
 
+// pushTicketLocked puts ticket at the front of baseID's pool. The caller holds mt5TicketMux.
+func (a *App) pushTicketLocked(baseID string, ticket uint64) {
 	current := a.baseIdToTickets[baseID]
 	updated := append([]uint64{ticket}, current...)
 	a.baseIdToTickets[baseID] = updated
@@ -262,7 +361,7 @@ func (a *App) removeTicketFromPool(baseID string, ticket uint64) {
 
 	trimmedBase := strings.TrimSpace(baseID)
 	if trimmedBase == "" {
-		if mapped, ok := a.mt5TicketToBaseId[ticket]; ok {
+		if _, mapped, ok := a.lookupTicketLocked("", ticket); ok {
 			trimmedBase = mapped
 		}
 	}
@@ -294,7 +393,7 @@ func (a *App) removeTicketFromPool(baseID string, ticket uint64) {
 			}
 		}
 	}
-	delete(a.mt5TicketToBaseId, ticket)
+	delete(a.mt5TicketToBaseId, a.ticketKeyLocked(trimmedBase, ticket))
 }
 
 const pendingCloseTTL = 15 * time.Second
@@ -354,8 +453,8 @@ func (a *App) trackPendingTicket(baseID string, ticket uint64) {
 		entries = append(entries, pendingTicket{ticket: ticket, marked: now})
 	}
 	a.pendingCloseByBase[baseID] = entries
-	if _, exists := a.mt5TicketToBaseId[ticket]; !exists {
-		a.mt5TicketToBaseId[ticket] = baseID
+	if key := a.ticketKeyLocked(baseID, ticket); a.mt5TicketToBaseId[key] == "" {
+		a.mt5TicketToBaseId[key] = baseID
 	}
 }
 
@@ -420,8 +519,10 @@ func (a *App) rehydrateTickets(baseID string) []uint64 {
 	defer a.mt5TicketMux.Unlock()
 
 	var restored []uint64
-	for ticket, mappedBase := range a.mt5TicketToBaseId {
-		if ticket == 0 || mappedBase != baseID {
+	terminal := a.terminalOfLocked(baseID)
+	for key, mappedBase := range a.mt5TicketToBaseId {
+		ticket := key.ticket
+		if ticket == 0 || mappedBase != baseID || key.terminal != terminal {
 			continue
 		}
 		if containsUint64(a.baseIdToTickets[baseID], ticket) {
@@ -477,7 +578,7 @@ func (a *App) recordInstrumentAccount(baseID, instrument, account string) {
 	a.mt5TicketMux.Unlock()
 }
 
-func (a *App) enqueueCloseTrade(baseID string, ticket uint64, instrument, account string, request interface{}) error {
+func (a *App) enqueueCloseTrade(baseID string, ticket uint64, volume float64, instrument, account string, request interface{}) error {
 	trade := Trade{
 		ID:              fmt.Sprintf("close_%d", time.Now().UnixNano()),
 		BaseID:          baseID,
@@ -497,6 +598,7 @@ func (a *App) enqueueCloseTrade(baseID string, ticket uint64, instrument, accoun
 		NTTradeResult:   "closed",
 		NTSessionTrades: 0,
 		MT5Ticket:       ticket,
+		CloseVolume:     volume,
 	}
 	if trade.Instrument == "" {
 		trade.Instrument = getInstrumentFromRequest(request)
@@ -504,6 +606,9 @@ func (a *App) enqueueCloseTrade(baseID string, ticket uint64, instrument, accoun
 	if trade.AccountName == "" {
 		trade.AccountName = getAccountFromRequest(request)
 	}
+	a.mt5TicketMux.RLock()
+	trade.MT5Symbol = a.baseIdToElastic[baseID].MT5Symbol
+	a.mt5TicketMux.RUnlock()
 	if err := a.AddToTradeQueue(trade); err != nil {
 		return err
 	}
@@ -512,6 +617,10 @@ func (a *App) enqueueCloseTrade(baseID string, ticket uint64, instrument, accoun
 		a.clientInitiatedTickets[ticket] = time.Now()
 	}
 	a.clientCloseMux.Unlock()
+	if volume > 0 {
+		log.Printf("gRPC: Enqueued CLOSE_HEDGE ticket %d (%.2f lots) for BaseID %s", ticket, volume, baseID)
+		return nil
+	}
 	log.Printf("gRPC: Enqueued CLOSE_HEDGE ticket %d for BaseID %s", ticket, baseID)
 	return nil
 }
@@ -525,24 +634,44 @@ func NewApp() *App {
 	log.Printf("Configuration: gRPC=true, gRPCPort=%s", grpcPort)
 
 	app := &App{
-		tradeQueue:           make(chan Trade, 100),
+		tradeQueues:          make(map[string]*queue.Scheduler[Trade]),
 		eaActive:             false, // Initialize HedgeBot as inactive
 		tradeLogSenderActive: false,
 		// gRPC configuration from environment
 		grpcPort: grpcPort,
 		// Initialize MT5 ticket mappings
-		mt5TicketToBaseId:      make(map[uint64]string),
+		mt5TicketToBaseId:      make(map[ticketKey]string),
 		baseIdToTickets:        make(map[string][]uint64),
 		pendingCloseByBase:     make(map[string][]pendingTicket),
+		ticketStates:           make(map[ticketKey]*ticketState),
+		orphanTickets:          make(map[ticketKey]orphanTicket),
 		baseIdToInstrument:     make(map[string]string),
 		baseIdToAccount:        make(map[string]string),
+		baseIdToTerminal:       make(map[string]string),
+		cancelledEntries:       make(map[string]int),
+		delivered:              make(map[string][]deliveredTrade),
+		retryAttempts:          make(map[string]int),
+		pendingRetries:         make(map[string][]*pendingRetry),
+		execHistory:            execution.NewHistory(),
+		closeRequests:          closereq.NewTracker(),
+		closeTimeout:           defaultCloseTimeout,
+		hedgeLedger:            hedgepnl.NewLedger(),
+		combinedPnL:            pnl.NewAggregator(),
 		clientInitiatedTickets: make(map[uint64]time.Time),
 		baseIdToElastic:        make(map[string]elasticInfo),
 	}
+	app.alerter = alerts.New(logAlert)
+	app.notifications = notify.NewCenter()
+	app.feed = feed.NewRing(feed.DefaultSize)
 
 	// Initialize gRPC server
 	app.grpcServer = grpcserver.NewGRPCServer(app)
 
+	// Load optional configuration file (symbol map, ...)
+	if err := app.applyConfig(loadConfig(config.DefaultPath())); err != nil {
+		log.Printf("ERROR: Failed to apply configuration: %v", err)
+	}
+
 	// Initialize elastic correlation maps
 	app.initElasticMaps()
 
@@ -562,22 +691,6 @@ func getEnvString(key, defaultValue string) string {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
-	// Start background goroutine to monitor addon connection status
-	go func() {
-		ticker := time.NewTicker(10 * time.Second)
-		defer ticker.Stop()
-		for {
-			<-ticker.C
-
-			a.addonStatusMux.Lock()
-			// Do not forcibly mark disconnected on idle; only warn if stale.
-			if a.platformConnected && time.Since(a.lastAddonRequestTime) > 2*time.Minute {
-				log.Printf("Addon connection appears stale (last request: %s ago) — keeping connected=true until explicit disconnect", time.Since(a.lastAddonRequestTime))
-			}
-			a.addonStatusMux.Unlock()
-		}
-	}()
-
 	// Start server initialization
 	a.startServer()
 
@@ -590,7 +703,7 @@ func (a *App) startServer() {
 	log.Printf("Initial state:")
 	log.Printf("Net position: %d", a.netPosition)
 	log.Printf("Hedge size: %.2f", a.hedgeLot)
-	log.Printf("Queue size: %d", len(a.tradeQueue))
+	log.Printf("Queue size: %d", a.GetQueueSize())
 	log.Printf("gRPC enabled: true")
 
 	// Start gRPC server ONLY - no HTTP fallback
@@ -602,6 +715,10 @@ func (a *App) startServer() {
 		log.Printf("gRPC server started successfully on port %s", a.grpcPort)
 	}
 
+	a.startSessionClock()
+	a.startScheduler()
+	a.startClientMonitor()
+	a.startAlertMonitor()
 	a.bridgeActive = true
 }
 
@@ -663,7 +780,10 @@ func (a *App) GetStatus() map[string]interface{} {
 		"tradeLogSenderActive": a.tradeLogSenderActive,
 		"netPosition":          a.netPosition,
 		"hedgeSize":            a.hedgeLot,
-		"queueSize":            len(a.tradeQueue),
+		"queueSize":            a.GetQueueSize(),
+		"heldTrades":           a.grpcServer.HeldTradeCount(),
+		"bridgeMode":           a.BridgeMode(),
+		"terminals":            a.GetTerminals(),
 	}
 }
 
@@ -677,9 +797,13 @@ func (a *App) GetHedgeSize() float64 {
 	return a.hedgeLot
 }
 
-// GetQueueSize returns the current queue size
+// GetQueueSize returns the current queue size across all terminals
 func (a *App) GetQueueSize() int {
-	return len(a.tradeQueue)
+	total := 0
+	for _, n := range a.QueueSizes() {
+		total += n
+	}
+	return total
 }
 
 // IsAddonConnected returns whether the addon is connected
@@ -692,10 +816,15 @@ func (a *App) IsAddonConnected() bool {
 // SetAddonConnected sets the addon connection status
 func (a *App) SetAddonConnected(connected bool) {
 	a.addonStatusMux.Lock()
-	defer a.addonStatusMux.Unlock()
+	prev := a.platformConnected
 	a.platformConnected = connected
-	if connected {
-		a.lastAddonRequestTime = time.Now()
+	a.addonStatusMux.Unlock()
+	if prev != connected {
+		if connected {
+			a.publishStatus(grpcserver.StatusAddonConnected, "")
+		} else {
+			a.publishStatus(grpcserver.StatusAddonDisconnected, "")
+		}
 	}
 }
 
@@ -718,42 +847,111 @@ func (a *App) SetHedgebotActive(active bool) {
 	// Only log on state changes to avoid spam
 	if prev != active {
 		log.Printf("Hedgebot active status changed: %v -> %v", prev, active)
+		if active {
+			a.publishStatus(grpcserver.StatusMT5Connected, "")
+		} else {
+			a.publishStatus(grpcserver.StatusMT5Disconnected, "")
+			a.notifyMT5StreamLost()
+		}
 	}
 }
 
 // GetTradeQueue returns the current trade queue
 func (a *App) GetTradeQueue() chan interface{} {
 	// Convert to interface{} channel for gRPC compatibility
-	ch := make(chan interface{}, len(a.tradeQueue))
+	ch := make(chan interface{}, a.GetQueueSize())
 
 	a.queueMux.Lock()
 	defer a.queueMux.Unlock()
 
-	// Copy current trades to the interface channel
-	for {
-		select {
-		case trade := <-a.tradeQueue:
+	// Copy current trades of every terminal to the interface channel
+	a.tradeQueuesMux.RLock()
+	defer a.tradeQueuesMux.RUnlock()
+	for _, q := range a.tradeQueues {
+		for len(ch) < cap(ch) {
+			trade, ok := q.Pop()
+			if !ok {
+				break
+			}
 			ch <- trade
-		default:
-			close(ch)
-			return ch
 		}
 	}
+	close(ch)
+	return ch
 }
 
-// PollTradeFromQueue returns a trade from the queue (non-blocking)
+// PollTradeFromQueue returns a trade from the default terminal's queue (non-blocking)
 func (a *App) PollTradeFromQueue() interface{} {
-	select {
-	case trade := <-a.tradeQueue:
-		return trade
-	default:
-		return nil
-	}
+	return a.PollTradeFromQueueFor(a.grpcServer.DefaultTerminal())
 }
 
 // AddToTradeQueue adds a trade to the queue
 func (a *App) AddToTradeQueue(trade interface{}) error {
-	// Use reflection to convert the incoming trade to our Trade type
+	t, err := decodeTrade(trade)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("AddToTradeQueue: Successfully converted trade - ID: %s, Action: %s", t.ID, t.Action)
+	normalizeTrade(&t)
+	log.Printf("AddToTradeQueue: Normalized trade - canonical_id: %s (qt_trade_id=%s base_id=%s)", t.ID, t.QTTradeID, t.BaseID)
+
+	// If this is an EVENT trade, emit a concise debug snapshot of the enrichment payload
+	if strings.EqualFold(strings.TrimSpace(t.Action), "EVENT") {
+		log.Printf("DEBUG: EVENT enqueue base_id=%s event_type=%s elastic_current_profit=%.4f elastic_profit_level=%d nt_points_per_1k_loss=%.4f", strings.TrimSpace(t.BaseID), strings.TrimSpace(t.EventType), t.ElasticCurrentProfit, t.ElasticProfitLevel, t.NTPointsPer1kLoss)
+	}
+
+	// Track instrument/account and nt_points_per_1k_loss per BaseID for later use
+	if b := strings.TrimSpace(t.BaseID); b != "" {
+		actLower := strings.ToLower(strings.TrimSpace(t.Action))
+		if actLower == "buy" || actLower == "sell" {
+			inst := strings.TrimSpace(t.Instrument)
+			acct := strings.TrimSpace(t.AccountName)
+			symbol := strings.TrimSpace(t.MT5Symbol)
+			points := t.NTPointsPer1kLoss
+
+			a.mt5TicketMux.Lock()
+			if inst != "" {
+				a.baseIdToInstrument[b] = inst
+			}
+			if acct != "" {
+				a.baseIdToAccount[b] = acct
+			}
+
+			info := a.baseIdToElastic[b]
+			if points > 0 {
+				info.PointsPer1kLoss = points
+			}
+			if inst != "" {
+				info.Instrument = inst
+			}
+			if acct != "" {
+				info.Account = acct
+			}
+			if symbol != "" {
+				info.MT5Symbol = symbol
+			}
+			a.baseIdToElastic[b] = info
+			a.mt5TicketMux.Unlock()
+		}
+	}
+
+	a.recordAccountActivity(t)
+	t.Terminal = a.resolveTerminal(&t)
+
+	// Closes overtake events and entries, but never an earlier trade of the same BaseID
+	class := queue.Classify(t.Action)
+	if err := a.queueFor(t.Terminal).Push(t, class, t.BaseID); err != nil {
+		log.Printf("AddToTradeQueue: %s queue for terminal %s is full, rejecting trade %s", class, t.Terminal, t.ID)
+		return err
+	}
+	a.publishStatus(grpcserver.StatusQueue, fmt.Sprintf("queued %s %s for %s", t.Action, t.ID, t.Terminal))
+	a.recordFeedTrade(feed.Enqueued, t)
+	return nil
+}
+
+// decodeTrade converts an incoming trade (InternalTrade, main.Trade or a map) into our Trade type
+func decodeTrade(trade interface{}) (Trade, error) {
 	var t Trade
 
 	log.Printf("AddToTradeQueue: Received trade type: %T", trade)
@@ -763,7 +961,7 @@ func (a *App) AddToTradeQueue(trade interface{}) error {
 	jsonBytes, err := json.Marshal(trade)
 	if err != nil {
 		log.Printf("AddToTradeQueue: Failed to marshal trade: %v", err)
-		return fmt.Errorf("failed to marshal trade: %v", err)
+		return t, fmt.Errorf("failed to marshal trade: %v", err)
 	}
 
 	// First try to unmarshal directly into our Trade struct
@@ -789,64 +987,28 @@ func (a *App) AddToTradeQueue(trade interface{}) error {
 			if reb, err3 := json.Marshal(aux); err3 == nil {
 				if err4 := json.Unmarshal(reb, &t); err4 != nil {
 					log.Printf("AddToTradeQueue: Failed to unmarshal normalized trade: %v", err4)
-					return fmt.Errorf("failed to unmarshal trade: %v", err4)
+					return t, fmt.Errorf("failed to unmarshal trade: %v", err4)
 				}
 			} else {
 				log.Printf("AddToTradeQueue: Failed to re-marshal normalized trade: %v", err3)
-				return fmt.Errorf("failed to marshal trade: %v", err3)
+				return t, fmt.Errorf("failed to marshal trade: %v", err3)
 			}
 		} else {
 			log.Printf("AddToTradeQueue: Failed to unmarshal trade to map: %v", err2)
-			return fmt.Errorf("failed to unmarshal trade: %v", err)
+			return t, fmt.Errorf("failed to unmarshal trade: %v", err)
 		}
 	}
 
-	log.Printf("AddToTradeQueue: Successfully converted trade - ID: %s, Action: %s", t.ID, t.Action)
-	normalizeTrade(&t)
-	log.Printf("AddToTradeQueue: Normalized trade - canonical_id: %s (qt_trade_id=%s base_id=%s)", t.ID, t.QTTradeID, t.BaseID)
-
-	// If this is an EVENT trade, emit a concise debug snapshot of the enrichment payload
-	if strings.EqualFold(strings.TrimSpace(t.Action), "EVENT") {
-		log.Printf("DEBUG: EVENT enqueue base_id=%s event_type=%s elastic_current_profit=%.4f elastic_profit_level=%d nt_points_per_1k_loss=%.4f", strings.TrimSpace(t.BaseID), strings.TrimSpace(t.EventType), t.ElasticCurrentProfit, t.ElasticProfitLevel, t.NTPointsPer1kLoss)
-	}
-
-	// Track instrument/account and nt_points_per_1k_loss per BaseID for later use
-	if b := strings.TrimSpace(t.BaseID); b != "" {
-		actLower := strings.ToLower(strings.TrimSpace(t.Action))
-		if actLower == "buy" || actLower == "sell" {
-			inst := strings.TrimSpace(t.Instrument)
-			acct := strings.TrimSpace(t.AccountName)
-			points := t.NTPointsPer1kLoss
-
-			a.mt5TicketMux.Lock()
-			if inst != "" {
-				a.baseIdToInstrument[b] = inst
-			}
-			if acct != "" {
-				a.baseIdToAccount[b] = acct
-			}
-
-			info := a.baseIdToElastic[b]
-			if points > 0 {
-				info.PointsPer1kLoss = points
-			}
-			if inst != "" {
-				info.Instrument = inst
-			}
-			if acct != "" {
-				info.Account = acct
-			}
-			a.baseIdToElastic[b] = info
-			a.mt5TicketMux.Unlock()
+	// InternalTrade carries the instrument as "instrument_name"; recover it so it survives the queue
+	if t.Instrument == "" {
+		var alias struct {
+			InstrumentName string `json:"instrument_name"`
+		}
+		if err := json.Unmarshal(jsonBytes, &alias); err == nil {
+			t.Instrument = alias.InstrumentName
 		}
 	}
-
-	select {
-	case a.tradeQueue <- t:
-		return nil
-	default:
-		return fmt.Errorf("trade queue is full")
-	}
+	return t, nil
 }
 
 // AddToTradeHistory adds a trade to the history
@@ -916,46 +1078,29 @@ func (a *App) HandleHedgeCloseNotification(notification interface{}) error {
 		a.markElasticClose(baseID, mt5Ticket, closureReason, quantity)
 	}
 
-	closeNotification := struct {
-		ID              string    `json:"id"`
-		BaseID          string    `json:"base_id"`
-		Time            time.Time `json:"time"`
-		Action          string    `json:"action"`
-		Quantity        float64   `json:"quantity"`
-		Price           float64   `json:"price"`
-		TotalQuantity   float64   `json:"total_quantity"`
-		ContractNum     int       `json:"contract_num"`
-		OrderType       string    `json:"order_type"`
-		MeasurementPips float64   `json:"measurement_pips"`
-		RawMeasurement  float64   `json:"raw_measurement"`
-		Instrument      string    `json:"instrument"`
-		AccountName     string    `json:"account_name"`
-		NTBalance       float64   `json:"nt_balance"`
-		NTDailyPnL      float64   `json:"nt_daily_pnl"`
-		NTTradeResult   string    `json:"nt_trade_result"`
-		NTSessionTrades int       `json:"nt_session_trades"`
-		MT5Ticket       uint64    `json:"mt5_ticket"`
-		ClosureReason   string    `json:"closure_reason"`
-	}{
-		ID:              fmt.Sprintf("mt5close_%d", time.Now().UnixNano()),
-		BaseID:          baseID,
-		Time:            time.Now(),
-		Action:          "MT5_CLOSE_NOTIFICATION",
-		Quantity:        quantity,
-		Price:           0,
-		TotalQuantity:   quantity,
-		ContractNum:     1,
-		OrderType:       "MT5_CLOSE",
-		MeasurementPips: 0,
-		RawMeasurement:  0,
-		Instrument:      inst,
-		AccountName:     acct,
-		NTBalance:       0,
-		NTDailyPnL:      0,
-		NTTradeResult:   "mt5_closed",
-		NTSessionTrades: 0,
-		MT5Ticket:       mt5Ticket,
-		ClosureReason:   closureReason,
+	deal := getCloseDealFromNotification(notification)
+	if deal.Volume == 0 {
+		deal.Volume = quantity
+	}
+	deal = a.hedgeLedger.RecordClose(baseID, mt5Ticket, deal, strings.EqualFold(lowerReason, "elastic_partial_close"))
+	a.logCombinedPnL(baseID, mt5Ticket, closureReason)
+
+	closeNotification := grpcserver.HedgeClosed{
+		ID:          fmt.Sprintf("mt5close_%d", time.Now().UnixNano()),
+		BaseID:      baseID,
+		Time:        time.Now(),
+		Ticket:      mt5Ticket,
+		Volume:      quantity,
+		Reason:      closureReason,
+		OrderType:   "MT5_CLOSE",
+		Price:       deal.Price,
+		Profit:      deal.Profit,
+		Instrument:  inst,
+		AccountName: acct,
+		OpenPrice:   deal.OpenPrice,
+		Commission:  deal.Commission,
+		Swap:        deal.Swap,
+		ServerTime:  deal.ServerTime,
 	}
 
 	if mt5Ticket == 0 {
@@ -966,6 +1111,7 @@ func (a *App) HandleHedgeCloseNotification(notification interface{}) error {
 
 	if mt5Ticket != 0 && !strings.EqualFold(lowerReason, "elastic_partial_close") {
 		a.removeTicketFromPool(baseID, mt5Ticket)
+		a.settleCloseRequest(baseID, mt5Ticket, quantity) // the EA reports closed lots in closed_hedge_quantity
 	}
 
 	log.Printf("gRPC: Successfully processed MT5 closure notification for BaseID: %s (reason=%s, ticket=%d)", baseID, closureReason, mt5Ticket)
@@ -995,6 +1141,23 @@ func (a *App) HandleMT5TradeResult(result interface{}) error {
 		if idVal, ok := mt5Result["ID"].(string); ok {
 			converted.ID = idVal
 		}
+		if profitVal, ok := mt5Result["Profit"].(float64); ok {
+			converted.Profit = profitVal
+		}
+		for key, field := range map[string]*float64{"Price": &converted.Price, "OpenPrice": &converted.OpenPrice, "Commission": &converted.Commission, "Swap": &converted.Swap} {
+			if v, ok := mt5Result[key].(float64); ok {
+				*field = v
+			}
+		}
+		if v, ok := mt5Result["ServerTime"].(float64); ok {
+			converted.ServerTime = int64(v)
+		}
+		if v, ok := mt5Result["DealTicket"].(float64); ok {
+			converted.DealTicket = uint64(v)
+		}
+		if v, ok := mt5Result["Terminal"].(string); ok {
+			converted.Terminal = v
+		}
 		return a.handleInternalMT5TradeResult(converted)
 	default:
 		log.Printf("gRPC: WARNING - Unknown MT5 trade result type: %T", result)
@@ -1013,6 +1176,17 @@ func (a *App) handleInternalMT5TradeResult(res *grpcserver.InternalMT5TradeResul
 		log.Printf("gRPC: Ignoring MT5 trade result with no identifiers: %+v", res)
 		return nil
 	}
+	baseID = a.resolveResultOwner(res.Terminal, baseID, ticket)
+	if strings.EqualFold(strings.TrimSpace(res.Status), statusPositionUpdate) {
+		a.recordPositionUpdate(res.Terminal, baseID, ticket, res.Volume, res.Profit)
+		a.hedgeLedger.RecordFloating(baseID, ticket, res.Profit)
+		a.refreshCombinedPnL(baseID)
+		return nil // a snapshot of an open position, not the outcome of a delivered trade
+	}
+	a.recordFeedResult(res)
+	if a.settleDelivery(res) {
+		return nil // retried or escalated; never treated as a fill or close
+	}
 
 	if res.IsClose {
 		closureReason := strings.TrimSpace(res.Status)
@@ -1041,58 +1215,52 @@ func (a *App) handleInternalMT5TradeResult(res *grpcserver.InternalMT5TradeResul
 		}
 		a.clientCloseMux.Unlock()
 
-		shouldPrune := ticket != 0 && !strings.EqualFold(closureReason, "elastic_partial_close")
+		partial := strings.EqualFold(closureReason, statusPartialClose) || strings.EqualFold(closureReason, "elastic_partial_close")
+		a.settleCloseVolume(baseID, ticket, res.Volume, partial)
+		a.settleCloseRequest(baseID, ticket, res.Volume)
+		deal := a.hedgeLedger.RecordClose(baseID, ticket, dealFromResult(res), partial)
+		a.logCombinedPnL(baseID, ticket, closureReason)
+		shouldPrune := ticket != 0 && !partial
 		if shouldPrune {
 			a.removeTicketFromPool(baseID, ticket)
 		}
 
 		inst, acct := a.bestInstAcctFor(baseID)
-		closeNotification := struct {
-			ID              string    `json:"id"`
-			BaseID          string    `json:"base_id"`
-			Time            time.Time `json:"time"`
-			Action          string    `json:"action"`
-			Quantity        float64   `json:"quantity"`
-			Price           float64   `json:"price"`
-			TotalQuantity   float64   `json:"total_quantity"`
-			ContractNum     int       `json:"contract_num"`
-			OrderType       string    `json:"order_type"`
-			MeasurementPips float64   `json:"measurement_pips"`
-			RawMeasurement  float64   `json:"raw_measurement"`
-			Instrument      string    `json:"instrument"`
-			AccountName     string    `json:"account_name"`
-			NTBalance       float64   `json:"nt_balance"`
-			NTDailyPnL      float64   `json:"nt_daily_pnl"`
-			NTTradeResult   string    `json:"nt_trade_result"`
-			NTSessionTrades int       `json:"nt_session_trades"`
-			MT5Ticket       uint64    `json:"mt5_ticket"`
-			ClosureReason   string    `json:"closure_reason"`
-		}{
-			ID:              fmt.Sprintf("mt5close_result_%d", time.Now().UnixNano()),
-			BaseID:          baseID,
-			Time:            time.Now(),
-			Action:          "MT5_CLOSE_NOTIFICATION",
-			Quantity:        res.Volume,
-			Price:           0,
-			TotalQuantity:   res.Volume,
-			ContractNum:     1,
-			OrderType:       orderType,
-			MeasurementPips: 0,
-			RawMeasurement:  0,
-			Instrument:      inst,
-			AccountName:     acct,
-			NTBalance:       0,
-			NTDailyPnL:      0,
-			NTTradeResult:   "mt5_closed",
-			NTSessionTrades: 0,
-			MT5Ticket:       ticket,
-			ClosureReason:   closureReason,
+		closeNotification := grpcserver.HedgeClosed{
+			ID:          fmt.Sprintf("mt5close_result_%d", time.Now().UnixNano()),
+			BaseID:      baseID,
+			Time:        time.Now(),
+			Ticket:      ticket,
+			Volume:      res.Volume,
+			Reason:      closureReason,
+			OrderType:   orderType,
+			Price:       deal.Price,
+			Profit:      deal.Profit,
+			Instrument:  inst,
+			AccountName: acct,
+			OpenPrice:   deal.OpenPrice,
+			Commission:  deal.Commission,
+			Swap:        deal.Swap,
+			ServerTime:  deal.ServerTime,
 		}
 
 		if suppressBroadcast {
 			log.Printf("gRPC: Suppressed MT5 close broadcast for ticket %d (BaseID: %s) due to elastic_partial_close context.", ticket, baseID)
 		} else {
 			a.grpcServer.BroadcastMT5CloseToAddonStreams(closeNotification)
+			a.recordFeed(feed.Event{
+				Kind:       feed.CloseNotice,
+				BaseID:     baseID,
+				TradeID:    closeNotification.ID,
+				Action:     orderType,
+				Instrument: inst,
+				Account:    acct,
+				Ticket:     ticket,
+				Volume:     res.Volume,
+				Price:      deal.Price,
+				Profit:     deal.Profit,
+				Status:     closureReason,
+			})
 			log.Printf("gRPC: Processed MT5 close result for ticket %d (BaseID: %s) reason=%s", ticket, baseID, closureReason)
 		}
 		return nil
@@ -1103,9 +1271,13 @@ func (a *App) handleInternalMT5TradeResult(res *grpcserver.InternalMT5TradeResul
 		return nil
 	}
 
+	a.recordTicketOpen(baseID, ticket, res.Volume)
+	a.hedgeLedger.RecordOpen(baseID, ticket, dealFromResult(res))
+	a.refreshCombinedPnL(baseID)
 	a.mt5TicketMux.Lock()
-	prevBase, exists := a.mt5TicketToBaseId[ticket]
-	a.mt5TicketToBaseId[ticket] = baseID
+	key := a.ticketKeyLocked(baseID, ticket)
+	prevBase, exists := a.mt5TicketToBaseId[key]
+	a.mt5TicketToBaseId[key] = baseID
 	list := a.baseIdToTickets[baseID]
 	for _, existing := range list {
 		if existing == ticket {
@@ -1174,6 +1346,8 @@ func (a *App) HandleElasticUpdate(update interface{}) error {
 		}
 	}
 
+	a.combinedPnL.RecordQTProfit(baseID, acct, inst, curProfit)
+
 	// Generate unique ID using sequence counter to prevent duplicate rejection
 	a.elasticSeqMux.Lock()
 	a.elasticSeqCount++
@@ -1197,6 +1371,7 @@ func (a *App) HandleElasticUpdate(update interface{}) error {
 		AccountName:          acct,
 		MT5Ticket:            mt5tk,
 		NTPointsPer1kLoss:    ntPts,
+		MT5Symbol:            info.MT5Symbol,
 		EventType:            "elastic_hedge_update",
 		ElasticCurrentProfit: curProfit,
 		ElasticProfitLevel:   int32(profitLvl),
@@ -1204,6 +1379,18 @@ func (a *App) HandleElasticUpdate(update interface{}) error {
 	if err := a.AddToTradeQueue(ct); err != nil {
 		return fmt.Errorf("failed to enqueue elastic event: %v", err)
 	}
+	a.recordFeed(feed.Event{
+		Kind:       feed.Elastic,
+		BaseID:     baseID,
+		TradeID:    uniqueID,
+		Action:     "ELASTIC_UPDATE",
+		Instrument: inst,
+		Account:    acct,
+		Ticket:     mt5tk,
+		Profit:     curProfit,
+		Status:     "elastic_hedge_update",
+		Detail:     fmt.Sprintf("profit level %d", profitLvl),
+	})
 	if ntPts <= 0 {
 		log.Printf("WARN: Enqueued elastic event without nt_points_per_1k_loss (base_id=%s, inst=%s)", baseID, inst)
 	}
@@ -1217,11 +1404,36 @@ func (a *App) HandleTrailingStopUpdate(update interface{}) error {
 }
 
 func (a *App) HandleCloseHedgeRequest(request interface{}) error {
+	_, err := a.CloseHedge(request)
+	return err
+}
+
+// CloseHedge resolves a close request into CLOSE_HEDGE trades and reports the tickets chosen as
+// response metadata.
+func (a *App) CloseHedge(request interface{}) (map[string]string, error) {
+	plan, err := a.closeHedge(request)
+	if err != nil {
+		return nil, err
+	}
+	md := plan.metadata()
+	// A duplicate of a close still in flight reports the request already tracking it
+	if plan.outcome == closePending {
+		if active, ok := a.closeRequests.Active(plan.baseID); ok {
+			md["close_request_id"], md["status"] = active.ID, string(active.Status)
+			return md, nil
+		}
+	}
+	r := a.trackCloseRequest(plan)
+	md["close_request_id"], md["status"] = r.ID, string(r.Status)
+	return md, nil
+}
+
+func (a *App) closeHedge(request interface{}) (closePlan, error) {
 	log.Printf("gRPC: Received close hedge request: %+v", request)
 
 	baseID := strings.TrimSpace(getBaseIDFromRequest(request))
 	if baseID == "" {
-		return fmt.Errorf("close hedge request missing base_id")
+		return closePlan{}, fmt.Errorf("close hedge request missing base_id")
 	}
 
 	inst := strings.TrimSpace(getInstrumentFromRequest(request))
@@ -1229,6 +1441,7 @@ func (a *App) HandleCloseHedgeRequest(request interface{}) error {
 	if inst != "" || acct != "" {
 		a.recordInstrumentAccount(baseID, inst, acct)
 	}
+	plan := closePlan{baseID: baseID, policy: a.closePolicyFor(baseID), outcome: closeEnqueued}
 
 	// CRITICAL: Read closed_hedge_quantity from request to close the correct number of hedges
 	// This supports n QT trades = n MT5 hedges (each contract gets its own hedge)
@@ -1238,15 +1451,53 @@ func (a *App) HandleCloseHedgeRequest(request interface{}) error {
 		qty = 1 // Safety fallback
 	}
 
+	closeVolume := roundVolume(getCloseVolumeFromRequest(request))
+	if closeVolume < 0 {
+		return plan, fmt.Errorf("close hedge request for base_id %s has negative close_volume %v", baseID, closeVolume)
+	}
+	// EAs that did not negotiate partial_close ignore close_volume and close whole positions, so
+	// the close is resolved into whole tickets here instead
+	if closeVolume > 0 && !a.terminalSupports(baseID, protocol.PartialClose) {
+		if getMT5TicketFromRequest(request) == 0 {
+			qty = a.ticketsCoveringVolume(baseID, closeVolume)
+		}
+		log.Printf("gRPC: EA for BaseID %s did not negotiate %s; closing %.2f lots as %d whole ticket(s)", baseID, protocol.PartialClose, closeVolume, qty)
+		blog.L().Warn("close_sync", "close volume sent as whole-ticket close", map[string]interface{}{
+			"base_id": baseID,
+			"volume":  closeVolume,
+			"tickets": qty,
+		})
+		closeVolume = 0
+	}
+
 	providedTicket := getMT5TicketFromRequest(request)
 	if providedTicket != 0 {
+		plan.policy = selection.Explicit
 		a.evictTicketFromQueue(baseID, providedTicket)
-		if err := a.enqueueCloseTrade(baseID, providedTicket, inst, acct, request); err != nil {
-			return fmt.Errorf("failed to enqueue targeted CLOSE_HEDGE for ticket %d: %w", providedTicket, err)
+		if err := a.enqueueCloseTrade(baseID, providedTicket, closeVolume, inst, acct, request); err != nil {
+			return plan, fmt.Errorf("failed to enqueue targeted CLOSE_HEDGE for ticket %d: %w", providedTicket, err)
 		}
 		a.trackPendingTicket(baseID, providedTicket)
+		plan.tickets = []closeSlice{{ticket: providedTicket, volume: closeVolume}}
 		log.Printf("gRPC: Enqueued targeted CLOSE_HEDGE for BaseID %s (ticket=%d)", baseID, providedTicket)
-		return nil
+		return plan, nil
+	}
+
+	// A lot volume is spread across live tickets in policy order; the last one may close partially
+	if closeVolume > 0 {
+		err := a.closeHedgeVolume(&plan, closeVolume, inst, acct, request)
+		return plan, err
+	}
+
+	// Entries still waiting in the queue are cancelled before any live hedge is closed
+	if cancelled := a.cancelQueuedEntries(baseID, qty); cancelled > 0 {
+		plan.cancelled = cancelled
+		qty -= cancelled
+		if qty == 0 {
+			plan.outcome = closeCancelledEntries
+			log.Printf("gRPC: Close for BaseID %s fully satisfied by cancelling undelivered entries", baseID)
+			return plan, nil
+		}
 	}
 
 	const (
@@ -1254,7 +1505,7 @@ func (a *App) HandleCloseHedgeRequest(request interface{}) error {
 		pollInterval  = 50 * time.Millisecond
 	)
 
-	log.Printf("gRPC: Closing %d MT5 ticket(s) for BaseID %s (n QT trades = n MT5 hedges)", qty, baseID)
+	log.Printf("gRPC: Closing %d MT5 ticket(s) for BaseID %s (n QT trades = n MT5 hedges, policy=%s)", qty, baseID, plan.policy)
 
 	allocated := make([]uint64, 0, qty)
 	for i := 0; i < qty; i++ {
@@ -1268,44 +1519,47 @@ func (a *App) HandleCloseHedgeRequest(request interface{}) error {
 			for _, tk := range allocated {
 				a.pushTicket(baseID, tk)
 				a.mt5TicketMux.Lock()
-				a.mt5TicketToBaseId[tk] = baseID
+				a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, tk)] = baseID
 				a.mt5TicketMux.Unlock()
 			}
 			if a.hasRecentPendingClose(baseID, maxTicketWait) {
+				plan.outcome = closePending
 				log.Printf("gRPC: Duplicate CLOSE_HEDGE detected for BaseID %s; pending MT5 closure still in flight", baseID)
-				return nil
+				return plan, nil
 			}
 			if a.openTicketCount(baseID) == 0 {
+				plan.outcome = closeIdempotent
 				log.Printf("gRPC: No tracked MT5 tickets remain for BaseID %s; treating close request as idempotent", baseID)
-				return nil
+				return plan, nil
 			}
-			return fmt.Errorf("no MT5 tickets available for base_id %s (requested %d, got %d)", baseID, qty, len(allocated))
+			return plan, fmt.Errorf("no MT5 tickets available for base_id %s (requested %d, got %d)", baseID, qty, len(allocated))
 		}
 		allocated = append(allocated, ticket)
 	}
 
 	for idx, tk := range allocated {
-		if err := a.enqueueCloseTrade(baseID, tk, inst, acct, request); err != nil {
+		if err := a.enqueueCloseTrade(baseID, tk, 0, inst, acct, request); err != nil {
 			// restore current ticket and any remaining ones
 			a.pushTicket(baseID, tk)
 			a.mt5TicketMux.Lock()
-			a.mt5TicketToBaseId[tk] = baseID
+			a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, tk)] = baseID
 			a.mt5TicketMux.Unlock()
 			for j := idx + 1; j < len(allocated); j++ {
 				pending := allocated[j]
 				a.pushTicket(baseID, pending)
 				a.mt5TicketMux.Lock()
-				a.mt5TicketToBaseId[pending] = baseID
+				a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, pending)] = baseID
 				a.mt5TicketMux.Unlock()
 			}
-			return fmt.Errorf("failed to enqueue CLOSE_HEDGE for ticket %d: %w", tk, err)
+			return plan, fmt.Errorf("failed to enqueue CLOSE_HEDGE for ticket %d: %w", tk, err)
 		}
 
 		a.trackPendingTicket(baseID, tk)
+		plan.tickets = append(plan.tickets, closeSlice{ticket: tk})
 	}
 
 	log.Printf("gRPC: Enqueued CLOSE_HEDGE for BaseID %s using %d ticket(s)", baseID, len(allocated))
-	return nil
+	return plan, nil
 }
 
 func getBaseIDFromRequest(request interface{}) string {
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	grpcserver "BridgeApp/internal/grpc"
+	trading "BridgeApp/internal/grpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestHedgeClosedEventOnTypedAndLegacyAddonStreams(t *testing.T) {
+	a := NewApp()
+	openTicket(t, a, "BASE_EVT", 9101, 0.3)
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	trading.RegisterStreamingServiceServer(srv, a.grpcServer)
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := trading.NewStreamingServiceClient(conn)
+
+	a.SetAddonConnected(false)
+	typed, err := client.AddonEventStream(ctx)
+	if err != nil {
+		t.Fatalf("AddonEventStream: %v", err)
+	}
+	for !a.IsAddonConnected() {
+		time.Sleep(5 * time.Millisecond)
+	}
+	a.SetAddonConnected(false)
+	legacy, err := client.TradingStream(ctx)
+	if err != nil {
+		t.Fatalf("TradingStream: %v", err)
+	}
+	for !a.IsAddonConnected() {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "elastic_completion", ID: "BASE_EVT", Ticket: 9101, Volume: 0.3, IsClose: true, Profit: 42.5})
+
+	ev, err := typed.Recv()
+	if err != nil {
+		t.Fatalf("typed Recv: %v", err)
+	}
+	closed := ev.GetHedgeClosed()
+	if closed == nil || closed.BaseId != "BASE_EVT" || closed.Mt5Ticket != 9101 || closed.ClosedVolume != 0.3 ||
+		closed.ClosureReason != "elastic_completion" || closed.Profit != 42.5 || closed.OrderType != "MT5_CLOSE" {
+		t.Fatalf("unexpected addon event: %+v", ev)
+	}
+
+	old, err := legacy.Recv()
+	if err != nil {
+		t.Fatalf("legacy Recv: %v", err)
+	}
+	if old.Action != "MT5_CLOSE_NOTIFICATION" || old.Mt5Ticket != 9101 || old.NtTradeResult != "elastic_completion" {
+		t.Fatalf("unexpected legacy close notification: %+v", old)
+	}
+
+	// Bridge notices reach the typed stream wrapped as trades
+	a.grpcServer.NotifyAddonStreams(Trade{ID: "n1", BaseID: "BASE_EVT", Action: "HEDGE_FAILED"})
+	ev, err = typed.Recv()
+	if err != nil {
+		t.Fatalf("typed Recv: %v", err)
+	}
+	if ev.GetTrade().GetAction() != "HEDGE_FAILED" {
+		t.Fatalf("expected wrapped HEDGE_FAILED notice, got %+v", ev)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/alerts"
+	blog "BridgeApp/internal/logging"
+)
+
+// alertCheckInterval is how often the MT5 connection and queue backlog rules are evaluated.
+const alertCheckInterval = 5 * time.Second
+
+// setAlertsConfig applies the "alerts" section.
+func (a *App) setAlertsConfig(cfg alerts.Config) {
+	if err := a.alerter.Configure(cfg); err != nil {
+		log.Printf("ERROR: Alerts not configured: %v", err)
+	}
+}
+
+// startAlertMonitor starts evaluating the polled alert rules once.
+func (a *App) startAlertMonitor() {
+	a.alertMonitor.Do(func() {
+		go func() {
+			ticker := time.NewTicker(alertCheckInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				a.checkAlerts(now)
+			}
+		}()
+	})
+}
+
+// checkAlerts raises mt5_disconnected once an EA that was connected has been gone for
+// mt5_disconnected_after, and queue_backlog for every terminal queue at or above queue_backlog.
+func (a *App) checkAlerts(now time.Time) {
+	cfg := a.alerter.Config()
+
+	a.alertMux.Lock()
+	var down time.Duration
+	if a.IsHedgebotActive() {
+		a.mt5Seen, a.mt5DownSince = true, time.Time{}
+	} else if a.mt5Seen {
+		if a.mt5DownSince.IsZero() {
+			a.mt5DownSince = now
+		}
+		down = now.Sub(a.mt5DownSince)
+	}
+	a.alertMux.Unlock()
+	if after := cfg.MT5After(); down >= after && after > 0 {
+		a.raiseAlert(alerts.Alert{
+			Rule:     alerts.RuleMT5Disconnected,
+			Severity: alerts.Critical,
+			Subject:  "mt5",
+			Title:    "MT5 disconnected",
+			Message:  fmt.Sprintf("No MT5 EA connected for %s; hedges are not being placed or closed.", down.Round(time.Second)),
+			Fields:   map[string]string{"down_seconds": fmt.Sprint(int(down.Seconds()))},
+		})
+	}
+
+	if cfg.QueueBacklog > 0 {
+		for terminal, n := range a.QueueSizes() {
+			if n < cfg.QueueBacklog {
+				continue
+			}
+			a.raiseAlert(alerts.Alert{
+				Rule:     alerts.RuleQueueBacklog,
+				Severity: alerts.Warning,
+				Subject:  terminal,
+				Title:    "Trade queue backlog",
+				Message:  fmt.Sprintf("%d trades waiting for terminal %q (alert at %d).", n, terminal, cfg.QueueBacklog),
+				Fields:   map[string]string{"terminal": terminal, "queued": fmt.Sprint(n)},
+			})
+		}
+	}
+}
+
+// raiseAlert hands an alert to the alerter. Sinks are called in the background, but the alert is
+// recorded and reported synchronously, so callers must not hold mt5TicketMux.
+func (a *App) raiseAlert(al alerts.Alert) {
+	if a.alerter != nil {
+		a.alerter.Raise(al)
+	}
+}
+
+// logAlert records every alert the alerter raised and where it was delivered.
+func logAlert(s alerts.Sent) {
+	fields := map[string]interface{}{
+		"rule":     s.Rule,
+		"severity": s.Severity,
+		"subject":  s.Subject,
+		"message":  s.Message,
+	}
+	if s.Suppressed > 0 {
+		fields["suppressed"] = s.Suppressed
+	}
+	if s.TestMode {
+		log.Printf("Alert (test mode, not sent): [%s] %s - %s", s.Rule, s.Title, s.Message)
+		fields["test_mode"] = true
+		blog.L().Info("alerts", "alert raised", fields)
+		return
+	}
+	var failed []string
+	for _, d := range s.Deliveries {
+		if d.Error != "" {
+			failed = append(failed, d.Sink+": "+d.Error)
+		}
+	}
+	log.Printf("Alert: [%s] %s - %s (%d sink(s), %d failed)", s.Rule, s.Title, s.Message, len(s.Deliveries), len(failed))
+	if len(failed) > 0 {
+		fields["failed"] = strings.Join(failed, "; ")
+		blog.L().Warn("alerts", "alert delivery failed", fields)
+		return
+	}
+	blog.L().Info("alerts", "alert raised", fields)
+}
+
+// GetAlerts returns the latest alerts for the UI, newest first.
+func (a *App) GetAlerts() []map[string]interface{} {
+	recent := a.alerter.Recent()
+	out := make([]map[string]interface{}, 0, len(recent))
+	for _, s := range recent {
+		out = append(out, map[string]interface{}{
+			"rule":       s.Rule,
+			"severity":   s.Severity,
+			"subject":    s.Subject,
+			"title":      s.Title,
+			"message":    s.Message,
+			"time":       s.Time.Format(time.RFC3339),
+			"suppressed": s.Suppressed,
+			"testMode":   s.TestMode,
+			"deliveries": s.Deliveries,
+		})
+	}
+	return out
+}
+
+// SendTestAlert sends a test alert to every configured sink and reports each delivery.
+func (a *App) SendTestAlert(message string) map[string]interface{} {
+	s := a.alerter.Test(message)
+	ok := len(s.Deliveries) > 0
+	for _, d := range s.Deliveries {
+		if d.Error != "" {
+			ok = false
+		}
+	}
+	result := map[string]interface{}{
+		"success":    ok,
+		"deliveries": s.Deliveries,
+	}
+	if len(s.Deliveries) == 0 {
+		result["message"] = "No alert sinks configured"
+	}
+	return result
+}
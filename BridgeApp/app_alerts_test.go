@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"BridgeApp/internal/alerts"
+	grpcserver "BridgeApp/internal/grpc"
+)
+
+func TestAlertRulesRaiseInTestMode(t *testing.T) {
+	a := NewApp()
+	a.setAlertsConfig(alerts.Config{TestMode: true, MT5DisconnectedAfter: "30s", QueueBacklog: 2})
+
+	// Never connected: nothing to alert on
+	now := time.Now()
+	a.checkAlerts(now.Add(time.Hour))
+	if len(a.GetAlerts()) != 0 {
+		t.Fatalf("no alert expected before MT5 ever connected, got %+v", a.GetAlerts())
+	}
+
+	a.SetHedgebotActive(true)
+	a.checkAlerts(now)
+	a.SetHedgebotActive(false)
+	a.checkAlerts(now.Add(10 * time.Second))
+	a.checkAlerts(now.Add(20 * time.Second))
+	if len(a.GetAlerts()) != 0 {
+		t.Fatal("MT5 gone for less than mt5_disconnected_after must not alert")
+	}
+	a.checkAlerts(now.Add(45 * time.Second))
+
+	a.AddToTradeQueue(Trade{ID: "q-1", BaseID: "BASE_AL", Action: "buy", Quantity: 1})
+	a.AddToTradeQueue(Trade{ID: "q-2", BaseID: "BASE_AL", Action: "buy", Quantity: 1})
+	a.checkAlerts(now.Add(50 * time.Second))
+
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, ID: "", Ticket: 4242, Volume: 0.2, Profit: -5})
+
+	rules := map[string]bool{}
+	for _, al := range a.GetAlerts() {
+		if al["testMode"] != true {
+			t.Fatalf("test mode alerts must not be sent: %+v", al)
+		}
+		rules[al["rule"].(string)] = true
+	}
+	for _, want := range []string{alerts.RuleMT5Disconnected, alerts.RuleQueueBacklog, alerts.RuleOrphanHedge} {
+		if !rules[want] {
+			t.Errorf("expected a %s alert, got %v", want, rules)
+		}
+	}
+
+	if res := a.SendTestAlert(""); res["success"] != false || res["message"] != "No alert sinks configured" {
+		t.Fatalf("SendTestAlert without sinks = %+v", res)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"BridgeApp/internal/clients"
+	blog "BridgeApp/internal/logging"
+)
+
+// clientSweepInterval is how often silent client sessions are checked for staleness.
+const clientSweepInterval = 5 * time.Second
+
+// setClientsConfig applies the "clients" section to the session registry.
+func (a *App) setClientsConfig(cfg clients.Config) {
+	d, err := cfg.Window()
+	if err != nil {
+		return
+	}
+	a.grpcServer.SetClientStaleAfter(d)
+}
+
+// startClientMonitor starts sweeping the client session registry once.
+func (a *App) startClientMonitor() {
+	a.clientMonitor.Do(func() {
+		go func() {
+			ticker := time.NewTicker(clientSweepInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				a.sweepClients(now)
+			}
+		}()
+	})
+}
+
+// sweepClients marks silent sessions stale and clears the addon/EA connection flag once no
+// live session of that kind remains.
+func (a *App) sweepClients(now time.Time) {
+	for _, c := range a.grpcServer.SweepClients(now) {
+		log.Printf("Client session stale - kind=%s id=%s remote=%s (last message %s ago)", c.Kind, c.ID, c.RemoteAddr, now.Sub(c.LastMessage).Round(time.Second))
+		blog.L().Warn("clients", "client session stale", map[string]interface{}{
+			"kind":         string(c.Kind),
+			"id":           c.ID,
+			"version":      c.Version,
+			"remote":       c.RemoteAddr,
+			"last_message": c.LastMessage.Format(time.RFC3339),
+		})
+	}
+	if a.IsAddonConnected() && !a.grpcServer.ClientLive(clients.Addon) {
+		log.Printf("No live addon session left - marking addon disconnected")
+		a.SetAddonConnected(false)
+	}
+	if a.IsHedgebotActive() && !a.grpcServer.ClientLive(clients.EA) {
+		log.Printf("No live EA session left - marking hedgebot inactive")
+		a.SetHedgebotActive(false)
+	}
+}
+
+// GetClients returns the add-on and EA sessions for the UI, live ones first.
+func (a *App) GetClients() []map[string]interface{} {
+	now := time.Now()
+	list := a.grpcServer.Clients()
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, c := range list {
+		out = append(out, map[string]interface{}{
+			"kind":           string(c.Kind),
+			"id":             c.ID,
+			"version":        c.Version,
+			"remoteAddr":     c.RemoteAddr,
+			"connectedSince": c.ConnectedSince.Format(time.RFC3339),
+			"lastMessage":    c.LastMessage.Format(time.RFC3339),
+			"idleSeconds":    int(now.Sub(c.LastMessage).Seconds()),
+			"lastMethod":     c.LastMethod,
+			"messages":       c.Messages,
+			"streams":        c.Streams,
+			"stale":          c.Stale,
+			"hello":          c.Hello,
+			"protocol":       c.Protocol,
+			"features":       c.Features,
+			"rejected":       c.Rejected,
+		})
+	}
+	return out
+}
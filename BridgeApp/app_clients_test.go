@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	trading "BridgeApp/internal/grpc/proto"
+)
+
+func TestClientSessionsRecordVersionsAndGoStale(t *testing.T) {
+	a := NewApp()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("client-id", "qt-desk-1"))
+	if _, err := a.grpcServer.SystemHeartbeat(ctx, &trading.HeartbeatRequest{Component: "QT_ADDON", Status: "ok", Version: "3.4.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.grpcServer.HealthCheck(context.Background(), &trading.HealthRequest{Source: "hedgebot"}); err != nil {
+		t.Fatal(err)
+	}
+	if !a.IsAddonConnected() || !a.IsHedgebotActive() {
+		t.Fatal("heartbeat and health check must mark the clients connected")
+	}
+
+	var addon map[string]interface{}
+	for _, c := range a.GetClients() {
+		if c["kind"] == "addon" {
+			addon = c
+		}
+	}
+	if addon == nil || addon["id"] != "qt-desk-1" || addon["version"] != "3.4.1" || addon["stale"] != false {
+		t.Fatalf("unexpected addon session %+v", addon)
+	}
+
+	resp, err := a.grpcServer.ListClients(context.Background(), &trading.ClientListRequest{Kind: "ea"})
+	if err != nil || len(resp.Clients) != 1 || resp.Clients[0].Id != "ea" || resp.Clients[0].Stale {
+		t.Fatalf("ListClients = %+v, %v", resp, err)
+	}
+
+	// Silence beyond stale_after disconnects both clients
+	a.sweepClients(time.Now().Add(time.Minute))
+	if a.IsAddonConnected() || a.IsHedgebotActive() {
+		t.Fatal("stale sessions must clear the connection flags")
+	}
+	resp, _ = a.grpcServer.ListClients(context.Background(), &trading.ClientListRequest{})
+	if len(resp.Clients) != 2 || !resp.Clients[0].Stale || !resp.Clients[1].Stale {
+		t.Fatalf("expected two stale sessions, got %+v", resp.Clients)
+	}
+}
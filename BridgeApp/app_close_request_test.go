@@ -11,14 +11,19 @@ func drainTrade(app *App) (Trade, bool) {
 	return trade, ok
 }
 
+// trackTicket maps ticket to baseID on the BaseID's terminal, as an open result does.
+func trackTicket(a *App, baseID string, ticket uint64) {
+	a.mt5TicketMux.Lock()
+	a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, ticket)] = baseID
+	a.mt5TicketMux.Unlock()
+}
+
 func TestHandleCloseHedgeRequestWithExplicitTicket(t *testing.T) {
 	a := NewApp()
 	baseID := "BASE_EXPLICIT"
 	ticket := uint64(9001)
 
-	a.mt5TicketMux.Lock()
-	a.mt5TicketToBaseId[ticket] = baseID
-	a.mt5TicketMux.Unlock()
+	trackTicket(a, baseID, ticket)
 
 	req := map[string]interface{}{
 		"BaseID":              baseID,
@@ -41,7 +46,7 @@ func TestHandleCloseHedgeRequestWithExplicitTicket(t *testing.T) {
 	}
 
 	a.mt5TicketMux.RLock()
-	if mapped, exists := a.mt5TicketToBaseId[ticket]; !exists || mapped != baseID {
+	if mapped, exists := a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, ticket)]; !exists || mapped != baseID {
 		a.mt5TicketMux.RUnlock()
 		t.Fatalf("expected ticket %d to remain mapped to %s", ticket, baseID)
 	}
@@ -67,7 +72,7 @@ func TestHandleCloseHedgeRequestAllocatesFromPool(t *testing.T) {
 	a.mt5TicketMux.Lock()
 	a.baseIdToTickets[baseID] = append([]uint64{}, tickets...)
 	for _, tk := range tickets {
-		a.mt5TicketToBaseId[tk] = baseID
+		a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, tk)] = baseID
 	}
 	a.mt5TicketMux.Unlock()
 
@@ -111,7 +116,7 @@ func TestHandleCloseHedgeRequestAllocatesFromPool(t *testing.T) {
 		t.Fatalf("expected %d pending entries, got %d", len(tickets), len(entries))
 	}
 	for _, tk := range tickets {
-		if mapped, exists := a.mt5TicketToBaseId[tk]; !exists || mapped != baseID {
+		if mapped, exists := a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, tk)]; !exists || mapped != baseID {
 			a.mt5TicketMux.RUnlock()
 			t.Fatalf("expected ticket %d to remain mapped to %s", tk, baseID)
 		}
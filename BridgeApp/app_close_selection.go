@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/alerts"
+	"BridgeApp/internal/notify"
+	"BridgeApp/internal/selection"
+)
+
+// statusPositionUpdate is the MT5 result status of a periodic snapshot of an open hedge position.
+const statusPositionUpdate = "position_update"
+
+// How a close request was resolved.
+const (
+	closeEnqueued         = "enqueued"          // CLOSE_HEDGE trades queued for MT5
+	closeCancelledEntries = "cancelled_entries" // satisfied by cancelling entries MT5 never received
+	closePending          = "pending"           // a close of the same BaseID is still in flight
+	closeIdempotent       = "idempotent"        // no hedge left to close
+)
+
+// ticketState is what MT5 results told the bridge about one hedge ticket.
+type ticketState struct {
+	volume    float64   // open lots remaining (0 = unknown)
+	opened    time.Time // when the open result arrived
+	profit    float64   // floating PnL from the latest position_update
+	hasProfit bool
+}
+
+// closePlan records how a close request was resolved, for the SubmitCloseHedge response.
+type closePlan struct {
+	baseID    string
+	policy    selection.Policy
+	tickets   []closeSlice
+	cancelled int
+	outcome   string
+}
+
+// metadata renders the plan as SubmitCloseHedge response metadata. tickets lists the chosen
+// tickets in close order; volumes (volume closes only) pairs each with its lots, "all" for whole.
+func (p closePlan) metadata() map[string]string {
+	md := map[string]string{
+		"base_id": p.baseID,
+		"outcome": p.outcome,
+		"policy":  string(p.policy),
+	}
+	if p.cancelled > 0 {
+		md["cancelled_entries"] = strconv.Itoa(p.cancelled)
+	}
+	if len(p.tickets) == 0 {
+		return md
+	}
+	tickets := make([]string, len(p.tickets))
+	volumes := make([]string, len(p.tickets))
+	partial := false
+	for i, sl := range p.tickets {
+		tickets[i] = strconv.FormatUint(sl.ticket, 10)
+		volumes[i] = "all"
+		if sl.volume > 0 {
+			volumes[i] = strconv.FormatFloat(sl.volume, 'f', -1, 64)
+			partial = true
+		}
+	}
+	md["tickets"] = strings.Join(tickets, ",")
+	if partial {
+		md["volumes"] = strings.Join(volumes, ",")
+	}
+	return md
+}
+
+// setCloseSelectionConfig installs the ticket selection policies for pooled closes.
+func (a *App) setCloseSelectionConfig(cfg selection.Config) {
+	a.mt5TicketMux.Lock()
+	a.closeSelection = cfg
+	a.mt5TicketMux.Unlock()
+}
+
+// closePolicyFor returns the selection policy for the account hedged under baseID.
+func (a *App) closePolicyFor(baseID string) selection.Policy {
+	a.mt5TicketMux.RLock()
+	defer a.mt5TicketMux.RUnlock()
+	return a.closeSelection.For(a.baseIdToAccount[baseID])
+}
+
+// orderedPoolLocked returns the pooled tickets of baseID in the order its policy closes them.
+// The caller holds mt5TicketMux.
+func (a *App) orderedPoolLocked(baseID string) []uint64 {
+	list := a.baseIdToTickets[baseID]
+	if len(list) < 2 {
+		return list
+	}
+	candidates := make([]selection.Candidate, len(list))
+	for i, tk := range list {
+		candidates[i] = selection.Candidate{Ticket: tk}
+		if st := a.ticketStates[a.ticketKeyLocked(baseID, tk)]; st != nil {
+			candidates[i].Opened, candidates[i].Profit, candidates[i].HasProfit = st.opened, st.profit, st.hasProfit
+		}
+	}
+	policy := a.closeSelection.For(a.baseIdToAccount[baseID])
+	ordered := make([]uint64, len(list))
+	for i, c := range selection.Order(policy, candidates) {
+		ordered[i] = c.Ticket
+	}
+	return ordered
+}
+
+// ticketStateLocked returns the state of the ticket, creating it. The caller holds mt5TicketMux.
+func (a *App) ticketStateLocked(key ticketKey) *ticketState {
+	st := a.ticketStates[key]
+	if st == nil {
+		st = &ticketState{}
+		a.ticketStates[key] = st
+	}
+	return st
+}
+
+// recordTicketOpen remembers when a ticket of baseID opened and its lots, from its open result.
+func (a *App) recordTicketOpen(baseID string, ticket uint64, volume float64) {
+	if ticket == 0 {
+		return
+	}
+	a.mt5TicketMux.Lock()
+	st := a.ticketStateLocked(a.ticketKeyLocked(baseID, ticket))
+	if st.opened.IsZero() {
+		st.opened = time.Now()
+	}
+	if volume > 0 {
+		st.volume = roundVolume(volume)
+	}
+	a.mt5TicketMux.Unlock()
+}
+
+// recordPositionUpdate applies a periodic MT5 snapshot (lots and floating PnL) of a ticket reported
+// by terminal. An unknown ticket the EA reports with a BaseID is adopted, as after a bridge restart;
+// one without a BaseID is an orphan.
+func (a *App) recordPositionUpdate(terminal, baseID string, ticket uint64, volume, profit float64) {
+	a.mt5TicketMux.Lock()
+	key := a.reportedKeyLocked(terminal, baseID, ticket)
+	_, tracked := a.mt5TicketToBaseId[key]
+	if !tracked && baseID != "" && ticket != 0 {
+		log.Printf("gRPC: Adopting MT5 ticket %d of BaseID %s reported by terminal %s", ticket, baseID, key.terminal)
+		a.mt5TicketToBaseId[key] = baseID
+		if !containsUint64(a.baseIdToTickets[baseID], ticket) {
+			a.pushTicketLocked(baseID, ticket)
+		}
+		if st := a.ticketStateLocked(key); st.opened.IsZero() {
+			st.opened = time.Now()
+		}
+		delete(a.orphanTickets, key)
+		tracked = true
+	}
+	if !tracked || ticket == 0 {
+		if ticket == 0 {
+			a.mt5TicketMux.Unlock()
+			log.Printf("gRPC: Ignoring position update without an MT5 ticket (terminal %s)", key.terminal)
+			return
+		}
+		first := a.noteOrphanLocked(key, volume, profit)
+		a.mt5TicketMux.Unlock()
+
+		// The alerter and the notification center report synchronously; they run without the ticket
+		// lock. The EA repeats the update every 15 s: the log and notification go out once per orphan,
+		// the alerter rate-limits its own repeats.
+		if first {
+			log.Printf("gRPC: Ignoring position updates for untracked MT5 ticket %d (terminal %s)", ticket, key.terminal)
+			a.notify(notify.Notification{
+				Kind:     notify.OrphanDetected,
+				Severity: notify.Warning,
+				Title:    "Orphan hedge detected",
+				Message:  fmt.Sprintf("MT5 ticket %d (%.2f lots) is open but no BaseID tracks it.", ticket, volume),
+				Ticket:   ticket,
+			})
+		}
+		a.raiseAlert(alerts.Alert{
+			Rule:     alerts.RuleOrphanHedge,
+			Severity: alerts.Critical,
+			Subject:  fmt.Sprint(ticket),
+			Title:    "Orphan hedge found",
+			Message:  fmt.Sprintf("MT5 reports open ticket %d (%.2f lots, profit %.2f) that no BaseID tracks.", ticket, volume, profit),
+			Fields:   map[string]string{"ticket": fmt.Sprint(ticket), "terminal": key.terminal},
+		})
+		return
+	}
+	st := a.ticketStateLocked(key)
+	if volume > 0 {
+		st.volume = roundVolume(volume)
+	}
+	st.profit, st.hasProfit = profit, true
+	a.mt5TicketMux.Unlock()
+}
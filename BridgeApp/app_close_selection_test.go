@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	grpcserver "BridgeApp/internal/grpc"
+	"BridgeApp/internal/protocol"
+	"BridgeApp/internal/selection"
+)
+
+// openTickets opens tickets for baseID in order, each a millisecond after the previous one.
+func openTickets(t *testing.T, a *App, baseID string, tickets ...uint64) {
+	t.Helper()
+	for _, tk := range tickets {
+		openTicket(t, a, baseID, tk, 0.1)
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPooledCloseFollowsAccountPolicy(t *testing.T) {
+	a := NewApp()
+	a.setCloseSelectionConfig(selection.Config{AccountPolicies: map[string]string{"Apex-1": "lifo"}})
+	a.recordInstrumentAccount("BASE_LIFO", "NQZ5", "Apex-1")
+	openTickets(t, a, "BASE_LIFO", 301, 302, 303)
+
+	md, err := a.CloseHedge(map[string]interface{}{"BaseID": "BASE_LIFO", "ClosedHedgeQuantity": 2.0})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	if md["policy"] != "lifo" || md["tickets"] != "303,302" || md["outcome"] != closeEnqueued {
+		t.Fatalf("unexpected metadata: %v", md)
+	}
+	for _, want := range []uint64{303, 302} {
+		if tr, ok := drainTrade(a); !ok || tr.MT5Ticket != want {
+			t.Fatalf("expected CLOSE_HEDGE for ticket %d, got %+v (ok=%v)", want, tr, ok)
+		}
+	}
+
+	// Other accounts keep the FIFO default, and a restored ticket does not jump the queue
+	openTickets(t, a, "BASE_FIFO", 401, 402)
+	a.mt5TicketMux.Lock()
+	a.baseIdToTickets["BASE_FIFO"] = []uint64{402, 401}
+	a.mt5TicketMux.Unlock()
+	md, err = a.CloseHedge(map[string]interface{}{"BaseID": "BASE_FIFO", "ClosedHedgeQuantity": 1.0})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	if md["policy"] != "fifo" || md["tickets"] != "401" {
+		t.Fatalf("unexpected metadata: %v", md)
+	}
+}
+
+func TestProfitPoliciesUseMT5PositionUpdates(t *testing.T) {
+	a := NewApp()
+	negotiateEA(t, a, protocol.PartialClose)
+	a.setCloseSelectionConfig(selection.Config{Policy: "most_profitable"})
+	openTickets(t, a, "BASE_PNL", 501, 502, 503)
+	for tk, profit := range map[uint64]float64{501: -40, 502: 85.5, 503: 12} {
+		a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, ID: "BASE_PNL", Ticket: tk, Volume: 0.1, Profit: profit})
+	}
+	if outcomes := a.GetExecutionOutcomes("BASE_PNL"); len(outcomes) != 3 {
+		t.Fatalf("position updates must not be recorded as execution outcomes, got %d outcomes", len(outcomes))
+	}
+
+	md, err := a.CloseHedge(map[string]interface{}{"BaseID": "BASE_PNL", "CloseVolume": 0.15})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	if md["policy"] != "most_profitable" || md["tickets"] != "502,503" || md["volumes"] != "all,0.05" {
+		t.Fatalf("unexpected metadata: %v", md)
+	}
+
+	md, err = a.CloseHedge(map[string]interface{}{"BaseID": "BASE_PNL", "MT5Ticket": float64(501)})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	if md["policy"] != "explicit" || md["tickets"] != "501" {
+		t.Fatalf("unexpected metadata for an explicit ticket: %v", md)
+	}
+}
+
+func TestPositionUpdatesAdoptTicketsOfKnownBaseIDs(t *testing.T) {
+	a := NewApp()
+	// After a restart the bridge tracks nothing, but the EA still reports the BaseID of its hedges
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, ID: "BASE_ADOPT", Ticket: 601, Volume: 0.3, Profit: 7})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, Ticket: 602, Volume: 0.2})
+	if n := a.openTicketCount("BASE_ADOPT"); n != 1 {
+		t.Fatalf("ticket 601 should be adopted by BASE_ADOPT, open=%d", n)
+	}
+	if orphans := a.GetOrphanTickets(); len(orphans) != 1 || orphans[0]["ticket"] != uint64(602) {
+		t.Fatalf("only the ticket without a BaseID is an orphan, got %+v", orphans)
+	}
+
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, ID: "BASE_ADOPT", Ticket: 601, Volume: 0.3, Profit: 9})
+	if n := a.openTicketCount("BASE_ADOPT"); n != 1 {
+		t.Fatalf("a second update must not add the ticket twice, open=%d", n)
+	}
+	if res := a.CloseBaseID("BASE_ADOPT"); res["success"] != true {
+		t.Fatalf("CloseBaseID = %+v", res)
+	}
+	if tr, ok := drainTrade(a); !ok || tr.Action != "CLOSE_HEDGE" || tr.MT5Ticket != 601 {
+		t.Fatalf("expected CLOSE_HEDGE for the adopted 601, got %+v (ok=%v)", tr, ok)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/alerts"
+	"BridgeApp/internal/closereq"
+	grpcserver "BridgeApp/internal/grpc"
+	blog "BridgeApp/internal/logging"
+)
+
+// defaultCloseTimeout is how long a close request waits for MT5 to confirm its tickets.
+const defaultCloseTimeout = 30 * time.Second
+
+// trackCloseRequest registers the tickets a close plan sent to MT5 and arms its timeout.
+func (a *App) trackCloseRequest(plan closePlan) closereq.Request {
+	tickets := make([]closereq.Ticket, len(plan.tickets))
+	for i, sl := range plan.tickets {
+		tickets[i] = closereq.Ticket{Ticket: sl.ticket, RequestedVolume: sl.volume}
+	}
+	r := a.closeRequests.Open(plan.baseID, string(plan.policy), plan.outcome, plan.cancelled, tickets)
+	if r.Status != closereq.StatusPending {
+		a.reportCloseCompletion(r)
+		return r
+	}
+	a.publishStatus(grpcserver.StatusCloses, fmt.Sprintf("close request %s opened for %s (%d tickets)", r.ID, r.BaseID, len(r.Tickets)))
+	id := r.ID
+	time.AfterFunc(a.closeTimeout, func() {
+		if done, completed := a.closeRequests.Expire(id); completed {
+			a.reportCloseCompletion(done)
+		}
+	})
+	return r
+}
+
+// settleCloseRequest records an MT5 close confirmation of ticket against its close request.
+func (a *App) settleCloseRequest(baseID string, ticket uint64, volume float64) {
+	if ticket == 0 {
+		return
+	}
+	if r, completed := a.closeRequests.Confirm(baseID, ticket, volume); completed {
+		a.reportCloseCompletion(r)
+	}
+}
+
+// failCloseRequestTicket records that MT5 permanently rejected the close of ticket.
+func (a *App) failCloseRequestTicket(baseID string, ticket uint64, detail string) {
+	if ticket == 0 {
+		return
+	}
+	if r, completed := a.closeRequests.Fail(baseID, ticket, detail); completed {
+		a.reportCloseCompletion(r)
+	}
+}
+
+// reportCloseCompletion logs a finished close request and tells the addon with a
+// CLOSE_REQUEST_COMPLETED notice.
+func (a *App) reportCloseCompletion(r closereq.Request) {
+	var closedVolume float64
+	for _, tk := range r.Tickets {
+		closedVolume += tk.ClosedVolume
+	}
+	summary := fmt.Sprintf("%d/%d tickets closed", r.Closed(), len(r.Tickets))
+	log.Printf("gRPC: Close request %s for BaseID %s completed: %s (%s)", r.ID, r.BaseID, r.Status, summary)
+	fields := map[string]interface{}{
+		"close_request_id": r.ID,
+		"base_id":          r.BaseID,
+		"status":           string(r.Status),
+		"tickets":          len(r.Tickets),
+		"closed":           r.Closed(),
+		"closed_volume":    roundVolume(closedVolume),
+	}
+	if r.Status == closereq.StatusDone {
+		blog.L().Info("close_sync", "close request completed", fields)
+	} else {
+		blog.L().Warn("close_sync", "close request completed", fields)
+	}
+	a.publishStatus(grpcserver.StatusCloses, fmt.Sprintf("close request %s for %s %s: %s", r.ID, r.BaseID, r.Status, summary))
+	if r.Status == closereq.StatusTimedOut {
+		a.raiseAlert(alerts.Alert{
+			Rule:     alerts.RuleCloseTimedOut,
+			Severity: alerts.Critical,
+			Subject:  r.BaseID,
+			Title:    "Close request timed out",
+			Message:  fmt.Sprintf("Close request %s for BaseID %s timed out: %s. Check MT5 for open hedges.", r.ID, r.BaseID, summary),
+			Fields:   map[string]string{"close_request_id": r.ID, "base_id": r.BaseID},
+		})
+	}
+
+	if a.grpcServer == nil {
+		return
+	}
+	notice := Trade{
+		ID:            r.ID,
+		BaseID:        r.BaseID,
+		Action:        "CLOSE_REQUEST_COMPLETED",
+		OrderType:     strings.ToUpper(string(r.Status)),
+		Quantity:      roundVolume(closedVolume),
+		TotalQuantity: len(r.Tickets),
+		ContractNum:   r.Closed(),
+		NTTradeResult: summary,
+		Time:          time.Now(),
+	}
+	notice.Instrument, notice.AccountName = a.bestInstAcctFor(r.BaseID)
+	a.grpcServer.NotifyAddonStreams(notice)
+}
+
+// CloseRequestStatus returns the close request with id, for GetCloseRequestStatus and the UI.
+func (a *App) CloseRequestStatus(id string) (closereq.Request, error) {
+	r, ok := a.closeRequests.Get(strings.TrimSpace(id))
+	if !ok {
+		return closereq.Request{}, fmt.Errorf("%w: %q", closereq.ErrNotFound, id)
+	}
+	return r, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"BridgeApp/internal/closereq"
+	grpcserver "BridgeApp/internal/grpc"
+)
+
+func TestCloseRequestDoneAfterMT5ConfirmsEveryTicket(t *testing.T) {
+	a := NewApp()
+	openTickets(t, a, "BASE_CR", 701, 702)
+
+	md, err := a.CloseHedge(map[string]interface{}{"BaseID": "BASE_CR", "ClosedHedgeQuantity": 2.0})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	id := md["close_request_id"]
+	if id == "" || md["status"] != string(closereq.StatusPending) {
+		t.Fatalf("unexpected metadata: %v", md)
+	}
+
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "closed", ID: "BASE_CR", Ticket: 701, Volume: 0.1, IsClose: true})
+	if r, _ := a.CloseRequestStatus(id); r.Status != closereq.StatusPending || r.Closed() != 1 {
+		t.Fatalf("request must stay pending until both tickets close: %+v", r)
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "closed", ID: "BASE_CR", Ticket: 702, Volume: 0.1, IsClose: true})
+	r, err := a.CloseRequestStatus(id)
+	if err != nil || r.Status != closereq.StatusDone || r.Completed.IsZero() {
+		t.Fatalf("expected done request, got %+v (err=%v)", r, err)
+	}
+}
+
+func TestCloseRequestFailsOrTimesOut(t *testing.T) {
+	a := NewApp()
+	a.closeTimeout = 20 * time.Millisecond
+	openTickets(t, a, "BASE_CRF", 801, 802)
+
+	md, err := a.CloseHedge(map[string]interface{}{"BaseID": "BASE_CRF", "ClosedHedgeQuantity": 2.0})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	for range []int{0, 1} {
+		if _, ok := drainTrade(a); !ok {
+			t.Fatalf("expected two CLOSE_HEDGE trades")
+		}
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "failed:10018", ID: "BASE_CRF", Ticket: 801, IsClose: true})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "closed", ID: "BASE_CRF", Ticket: 802, Volume: 0.1, IsClose: true})
+	r, _ := a.CloseRequestStatus(md["close_request_id"])
+	if r.Status != closereq.StatusPartiallyDone || r.Tickets[0].State != closereq.TicketFailed || r.Tickets[0].Detail != "market_closed" {
+		t.Fatalf("expected partially_done with a failed ticket, got %+v", r)
+	}
+
+	// Nothing answers the retried close of 801 before the deadline
+	md, err = a.CloseHedge(map[string]interface{}{"BaseID": "BASE_CRF", "ClosedHedgeQuantity": 1.0})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if r, _ = a.CloseRequestStatus(md["close_request_id"]); r.Status != closereq.StatusPending {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if r.Status != closereq.StatusTimedOut || r.Tickets[0].State != closereq.TicketTimedOut {
+		t.Fatalf("expected timed_out request, got %+v", r)
+	}
+}
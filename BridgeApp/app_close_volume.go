@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"time"
+
+	blog "BridgeApp/internal/logging"
+)
+
+// statusPartialClose is the MT5 result status of a close that left part of the position open.
+const statusPartialClose = "partial_close"
+
+// volumeEpsilon absorbs float noise when comparing lot volumes.
+const volumeEpsilon = 1e-6
+
+// closeSlice is one ticket's share of a volume close; volume 0 closes the whole position.
+type closeSlice struct {
+	ticket uint64
+	volume float64
+}
+
+func roundVolume(v float64) float64 {
+	return math.Round(v*1e8) / 1e8
+}
+
+// allocateCloseVolume takes tickets of baseID from the pool, in policy order, until volume lots are
+// covered. The last ticket may be closed partially. Tickets whose volume is unknown receive the
+// whole remainder and MT5 closes what the position holds. It returns the slices and the volume no
+// tracked ticket could cover.
+func (a *App) allocateCloseVolume(baseID string, volume float64) ([]closeSlice, float64) {
+	a.mt5TicketMux.Lock()
+	defer a.mt5TicketMux.Unlock()
+
+	list := a.orderedPoolLocked(baseID)
+	left := volume
+	taken := 0
+	var slices []closeSlice
+	for _, tk := range list {
+		if left <= volumeEpsilon {
+			break
+		}
+		taken++
+		var open float64
+		if st := a.ticketStates[a.ticketKeyLocked(baseID, tk)]; st != nil {
+			open = st.volume
+		}
+		switch {
+		case open <= 0:
+			log.Printf("gRPC: Volume of MT5 ticket %d (BaseID %s) unknown; requesting %.2f lots from it", tk, baseID, left)
+			slices = append(slices, closeSlice{ticket: tk, volume: roundVolume(left)})
+			left = 0
+		case open <= left+volumeEpsilon:
+			slices = append(slices, closeSlice{ticket: tk})
+			left -= open
+		default:
+			slices = append(slices, closeSlice{ticket: tk, volume: roundVolume(left)})
+			left = 0
+		}
+	}
+	if taken == len(list) {
+		delete(a.baseIdToTickets, baseID)
+	} else {
+		a.baseIdToTickets[baseID] = list[taken:]
+	}
+	if left < volumeEpsilon {
+		left = 0
+	}
+	return slices, roundVolume(left)
+}
+
+// ticketsCoveringVolume counts the tickets of baseID, in policy order, whole closes of which
+// cover volume lots. A ticket of unknown volume is assumed to cover the rest. It returns at least 1.
+func (a *App) ticketsCoveringVolume(baseID string, volume float64) int {
+	a.mt5TicketMux.RLock()
+	defer a.mt5TicketMux.RUnlock()
+	n := 0
+	left := volume
+	for _, tk := range a.orderedPoolLocked(baseID) {
+		if left <= volumeEpsilon {
+			break
+		}
+		n++
+		st := a.ticketStates[a.ticketKeyLocked(baseID, tk)]
+		if st == nil || st.volume <= 0 {
+			break
+		}
+		left -= st.volume
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// closeHedgeVolume resolves a close of volume lots into per-ticket CLOSE_HEDGE trades.
+func (a *App) closeHedgeVolume(plan *closePlan, volume float64, inst, acct string, request interface{}) error {
+	baseID := plan.baseID
+	const (
+		maxTicketWait = 2 * time.Second
+		pollInterval  = 50 * time.Millisecond
+	)
+	deadline := time.Now().Add(maxTicketWait)
+	for a.pooledTicketCount(baseID) == 0 && time.Now().Before(deadline) {
+		time.Sleep(pollInterval)
+	}
+	if a.pooledTicketCount(baseID) == 0 {
+		a.rehydrateTickets(baseID)
+	}
+
+	slices, uncovered := a.allocateCloseVolume(baseID, volume)
+	if len(slices) == 0 {
+		if a.hasRecentPendingClose(baseID, maxTicketWait) {
+			plan.outcome = closePending
+			log.Printf("gRPC: Duplicate CLOSE_HEDGE detected for BaseID %s; pending MT5 closure still in flight", baseID)
+			return nil
+		}
+		if a.openTicketCount(baseID) == 0 {
+			plan.outcome = closeIdempotent
+			log.Printf("gRPC: No tracked MT5 tickets remain for BaseID %s; treating volume close as idempotent", baseID)
+			return nil
+		}
+		return fmt.Errorf("no MT5 tickets available for base_id %s (requested %.2f lots)", baseID, volume)
+	}
+	if uncovered > 0 {
+		log.Printf("WARN: Close of %.2f lots for BaseID %s exceeds tracked volume by %.2f lots", volume, baseID, uncovered)
+		blog.L().Warn("close_sync", "close volume exceeds tracked hedge volume", map[string]interface{}{
+			"base_id":   baseID,
+			"requested": volume,
+			"uncovered": uncovered,
+		})
+	}
+
+	for idx, sl := range slices {
+		if err := a.enqueueCloseTrade(baseID, sl.ticket, sl.volume, inst, acct, request); err != nil {
+			for _, pending := range slices[idx:] {
+				a.pushTicket(baseID, pending.ticket)
+				a.mt5TicketMux.Lock()
+				a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, pending.ticket)] = baseID
+				a.mt5TicketMux.Unlock()
+			}
+			return fmt.Errorf("failed to enqueue CLOSE_HEDGE for ticket %d: %w", sl.ticket, err)
+		}
+		a.trackPendingTicket(baseID, sl.ticket)
+		plan.tickets = append(plan.tickets, sl)
+	}
+	log.Printf("gRPC: Enqueued CLOSE_HEDGE of %.2f lots for BaseID %s across %d ticket(s)", volume, baseID, len(slices))
+	return nil
+}
+
+// pooledTicketCount returns the tickets of baseID available for allocation.
+func (a *App) pooledTicketCount(baseID string) int {
+	a.mt5TicketMux.RLock()
+	defer a.mt5TicketMux.RUnlock()
+	return len(a.baseIdToTickets[baseID])
+}
+
+// settleCloseVolume applies a close result to the ticket's remaining volume. A ticket that stays
+// open after a partial close it was allocated for returns to the pool; after a full close its state
+// is forgotten.
+func (a *App) settleCloseVolume(baseID string, ticket uint64, closed float64, partial bool) {
+	if ticket == 0 {
+		return
+	}
+	a.mt5TicketMux.Lock()
+	defer a.mt5TicketMux.Unlock()
+
+	key := a.ticketKeyLocked(baseID, ticket)
+	if !partial {
+		delete(a.ticketStates, key)
+		return
+	}
+	st := a.ticketStates[key]
+	if st != nil && st.volume > 0 && closed > 0 {
+		st.volume = roundVolume(math.Max(st.volume-closed, 0))
+	}
+
+	allocated := false
+	if entries, ok := a.pendingCloseByBase[baseID]; ok {
+		filtered := make([]pendingTicket, 0, len(entries))
+		for _, entry := range entries {
+			if entry.ticket == ticket {
+				allocated = true
+				continue
+			}
+			filtered = append(filtered, entry)
+		}
+		if len(filtered) == 0 {
+			delete(a.pendingCloseByBase, baseID)
+		} else {
+			a.pendingCloseByBase[baseID] = filtered
+		}
+	}
+	if allocated && !containsUint64(a.baseIdToTickets[baseID], ticket) {
+		a.baseIdToTickets[baseID] = append([]uint64{ticket}, a.baseIdToTickets[baseID]...)
+	}
+	if st != nil {
+		log.Printf("gRPC: Partial close of %.2f lots on ticket %d (BaseID %s); %.2f lots remain", closed, ticket, baseID, st.volume)
+	}
+}
+
+func getCloseVolumeFromRequest(request interface{}) float64 {
+	if req, ok := request.(map[string]interface{}); ok {
+		if v, ok := req["CloseVolume"].(float64); ok {
+			return v
+		}
+		if v, ok := req["close_volume"].(float64); ok {
+			return v
+		}
+		return 0
+	}
+	val := reflect.ValueOf(request)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() == reflect.Struct {
+		field := val.FieldByName("CloseVolume")
+		if field.IsValid() && field.Kind() == reflect.Float64 {
+			return field.Float()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	grpcserver "BridgeApp/internal/grpc"
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/protocol"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func openTicket(t *testing.T, a *App, baseID string, ticket uint64, volume float64) {
+	t.Helper()
+	if err := a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: baseID, Ticket: ticket, Volume: volume}); err != nil {
+		t.Fatalf("open result for ticket %d: %v", ticket, err)
+	}
+}
+
+// negotiateEA has an EA say Hello with features and serve the default terminal once, then
+// disconnect, so the bridge knows what the terminal's EA handles while trades stay queued.
+func negotiateEA(t *testing.T, a *App, features ...string) {
+	t.Helper()
+	client := dialBridge(t, a)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if hello, err := client.Hello(ctx, &trading.HelloRequest{Kind: "ea", ClientId: "T1", ProtocolVersion: protocol.Version, Features: features}); err != nil || !hello.Accepted {
+		t.Fatalf("Hello = %+v, %v", hello, err)
+	}
+	streamCtx, stop := context.WithCancel(metadata.AppendToOutgoingContext(ctx, "terminal-id", "T1"))
+	stream, err := client.GetTrades(streamCtx)
+	if err != nil {
+		t.Fatalf("GetTrades: %v", err)
+	}
+	if err := stream.Send(&trading.GetTradesRequest{Source: "hedgebot"}); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	terminal := a.grpcServer.DefaultTerminal()
+	waitFor(t, func() bool {
+		return a.grpcServer.TerminalSupports(terminal, protocol.PartialClose) == contains(features, protocol.PartialClose) && connected(a, terminal)
+	})
+	stop()
+	waitFor(t, func() bool { return !connected(a, terminal) })
+	time.Sleep(50 * time.Millisecond) // let the stream's forwarder stop
+}
+
+func connected(a *App, terminal string) bool {
+	for _, st := range a.grpcServer.TerminalStatuses() {
+		if st.Terminal == terminal {
+			return st.Connected
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCloseVolumeSpansTicketsOldestFirst(t *testing.T) {
+	a := NewApp()
+	negotiateEA(t, a, protocol.PartialClose)
+	baseID := "BASE_VOL"
+	openTicket(t, a, baseID, 101, 0.5)
+	openTicket(t, a, baseID, 102, 0.5)
+	openTicket(t, a, baseID, 103, 0.5)
+
+	if err := a.HandleCloseHedgeRequest(&grpcserver.InternalHedgeCloseNotification{BaseID: baseID, ClosedHedgeQuantity: 1, CloseVolume: 0.7}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+	first, ok := drainTrade(a)
+	if !ok || first.MT5Ticket != 101 || first.CloseVolume != 0 {
+		t.Fatalf("expected a whole close of the oldest ticket 101, got %+v (ok=%v)", first, ok)
+	}
+	second, ok := drainTrade(a)
+	if !ok || second.MT5Ticket != 102 || second.CloseVolume != 0.2 {
+		t.Fatalf("expected a 0.2 lot close of ticket 102, got %+v (ok=%v)", second, ok)
+	}
+	if extra, ok := drainTrade(a); ok {
+		t.Fatalf("ticket 103 must stay open, got %+v", extra)
+	}
+
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: baseID, Ticket: 101, Volume: 0.5, IsClose: true})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPartialClose, ID: baseID, Ticket: 102, Volume: 0.2, IsClose: true})
+
+	a.mt5TicketMux.RLock()
+	pool := append([]uint64(nil), a.baseIdToTickets[baseID]...)
+	st, tracked := a.ticketStates[a.ticketKeyLocked(baseID, 102)]
+	_, closedTracked := a.ticketStates[a.ticketKeyLocked(baseID, 101)]
+	a.mt5TicketMux.RUnlock()
+	if len(pool) != 2 || pool[0] != 102 || pool[1] != 103 {
+		t.Fatalf("partially closed ticket 102 should lead the pool, got %v", pool)
+	}
+	if !tracked || st.volume != 0.3 {
+		t.Fatalf("ticket 102 should have 0.3 lots left, got %+v (tracked=%v)", st, tracked)
+	}
+	if closedTracked {
+		t.Fatal("fully closed ticket 101 must no longer be tracked")
+	}
+
+	// The remainder of ticket 102 closes whole before ticket 103 is touched
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{"BaseID": baseID, "CloseVolume": 0.4}); err != nil {
+		t.Fatalf("second HandleCloseHedgeRequest: %v", err)
+	}
+	if tr, ok := drainTrade(a); !ok || tr.MT5Ticket != 102 || tr.CloseVolume != 0 {
+		t.Fatalf("expected a whole close of ticket 102, got %+v (ok=%v)", tr, ok)
+	}
+	if tr, ok := drainTrade(a); !ok || tr.MT5Ticket != 103 || tr.CloseVolume != 0.1 {
+		t.Fatalf("expected a 0.1 lot close of ticket 103, got %+v (ok=%v)", tr, ok)
+	}
+}
+
+func TestCloseVolumeOnExplicitTicket(t *testing.T) {
+	a := NewApp()
+	negotiateEA(t, a, protocol.PartialClose)
+	baseID := "BASE_VOL_TK"
+	openTicket(t, a, baseID, 201, 1.0)
+
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{"BaseID": baseID, "MT5Ticket": float64(201), "CloseVolume": 0.25}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+	tr, ok := drainTrade(a)
+	if !ok || tr.MT5Ticket != 201 || tr.CloseVolume != 0.25 || tr.Action != "CLOSE_HEDGE" {
+		t.Fatalf("expected a 0.25 lot CLOSE_HEDGE of ticket 201, got %+v (ok=%v)", tr, ok)
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPartialClose, ID: baseID, Ticket: 201, Volume: 0.25, IsClose: true})
+	if n := a.openTicketCount(baseID); n != 1 {
+		t.Fatalf("ticket 201 should still be open after a partial close, open=%d", n)
+	}
+}
+
+func TestCloseVolumeFallsBackToWholeTicketsWithoutPartialClose(t *testing.T) {
+	a := NewApp()
+	negotiateEA(t, a, protocol.HedgeLot)
+	baseID := "BASE_VOL_LEGACY"
+	openTicket(t, a, baseID, 301, 0.5)
+	openTicket(t, a, baseID, 302, 0.5)
+	openTicket(t, a, baseID, 303, 0.5)
+
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{"BaseID": baseID, "CloseVolume": 0.7}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+	for _, want := range []uint64{301, 302} {
+		if tr, ok := drainTrade(a); !ok || tr.MT5Ticket != want || tr.CloseVolume != 0 {
+			t.Fatalf("expected a whole close of ticket %d, got %+v (ok=%v)", want, tr, ok)
+		}
+	}
+	if extra, ok := drainTrade(a); ok {
+		t.Fatalf("ticket 303 must stay open, got %+v", extra)
+	}
+
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{"BaseID": baseID, "MT5Ticket": float64(303), "CloseVolume": 0.25}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+	if tr, ok := drainTrade(a); !ok || tr.MT5Ticket != 303 || tr.CloseVolume != 0 {
+		t.Fatalf("expected a whole close of ticket 303, got %+v (ok=%v)", tr, ok)
+	}
+}
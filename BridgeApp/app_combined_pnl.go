@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/pnl"
+)
+
+// recordAccountActivity counts entries per account and trading day and keeps the balance, daily
+// PnL and session trade count Quantower sent with a trade of its account.
+func (a *App) recordAccountActivity(t Trade) {
+	switch strings.ToLower(strings.TrimSpace(t.Action)) {
+	case "buy", "sell":
+		a.combinedPnL.RecordEntry(t.AccountName)
+	}
+	if t.NTBalance == 0 && t.NTDailyPnL == 0 && t.NTSessionTrades == 0 {
+		return // not reported (the fields are omitted when empty)
+	}
+	a.combinedPnL.RecordQTAccount(t.AccountName, t.NTBalance, t.NTDailyPnL, t.NTSessionTrades)
+	a.checkLossLimit(t.AccountName)
+}
+
+// refreshCombinedPnL copies the latest hedge summary of baseID into the combined view.
+func (a *App) refreshCombinedPnL(baseID string) (pnl.Position, bool) {
+	s, err := a.hedgeLedger.Summary(baseID)
+	if err != nil {
+		return pnl.Position{}, false
+	}
+	inst, acct := a.bestInstAcctFor(baseID)
+	p := a.combinedPnL.RecordHedge(s, acct, inst)
+	a.checkLossLimit(p.Account)
+	return p, true
+}
+
+// logCombinedPnL refreshes baseID after a hedge close and writes the combined figures of the
+// position, its account and the day into the unified log.
+func (a *App) logCombinedPnL(baseID string, ticket uint64, reason string) {
+	p, ok := a.refreshCombinedPnL(baseID)
+	if !ok {
+		return
+	}
+	r := a.combinedPnL.Report(p.Day)
+	fields := map[string]interface{}{
+		"base_id":        p.BaseID,
+		"mt5_ticket":     ticket,
+		"reason":         reason,
+		"account":        p.Account,
+		"instrument":     p.Instrument,
+		"day":            p.Day,
+		"qt_profit":      p.QTProfit,
+		"hedge_realized": p.HedgeRealized,
+		"hedge_floating": p.HedgeFloating,
+		"hedge_costs":    p.HedgeCosts,
+		"hedge_net":      p.HedgeNet,
+		"combined":       p.Combined,
+		"day_qt_pnl":     r.Day.QTDailyPnL,
+		"day_hedge_net":  r.Day.HedgeNet,
+		"day_combined":   r.Day.Combined,
+	}
+	for _, acct := range r.Accounts {
+		if acct.Account == p.Account {
+			fields["account_qt_balance"] = acct.QTBalance
+			fields["account_qt_daily_pnl"] = acct.QTDailyPnL
+			fields["account_hedge_net"] = acct.HedgeNet
+			fields["account_combined"] = acct.Combined
+		}
+	}
+	blog.L().Info("pnl", "hedge closed, combined PnL updated", fields)
+}
+
+// CombinedPnL returns the Quantower and MT5 hedge PnL of day ("" = today) per BaseID and
+// account, for the UI and GetCombinedPnL.
+func (a *App) CombinedPnL(day string) pnl.Report {
+	return a.combinedPnL.Report(strings.TrimSpace(day))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	grpcserver "BridgeApp/internal/grpc"
+)
+
+func TestCombinedPnLMergesQuantowerAndHedges(t *testing.T) {
+	a := NewApp()
+	if err := a.AddToTradeQueue(Trade{ID: "qt-1", BaseID: "BASE_CMB", Action: "buy", Quantity: 1, Instrument: "NQ",
+		AccountName: "Sim101", NTBalance: 50300, NTDailyPnL: 300}); err != nil {
+		t.Fatalf("AddToTradeQueue: %v", err)
+	}
+	if err := a.HandleElasticUpdate(map[string]interface{}{"base_id": "BASE_CMB", "current_profit": 120.0}); err != nil {
+		t.Fatalf("HandleElasticUpdate: %v", err)
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: "BASE_CMB", Ticket: 7001, Volume: 0.2, Commission: -1.4})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: "BASE_CMB", Ticket: 7001, Volume: 0.2, IsClose: true,
+		Commission: -1.4, Profit: -80, DealTicket: 9101})
+
+	r := a.CombinedPnL("")
+	if len(r.Positions) != 1 || len(r.Accounts) != 1 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	p := r.Positions[0]
+	if p.Account != "Sim101" || p.Instrument != "NQ" || p.QTProfit != 120 || p.HedgeNet != -82.8 || p.Combined != 37.2 || p.HedgeOpen {
+		t.Fatalf("unexpected position: %+v", p)
+	}
+	if acct := r.Accounts[0]; acct.QTBalance != 50300 || acct.QTDailyPnL != 300 || acct.HedgeNet != -82.8 || acct.Combined != 217.2 {
+		t.Fatalf("unexpected account: %+v", acct)
+	}
+	if r.Day.Combined != 217.2 || len(r.Days) != 1 {
+		t.Fatalf("unexpected day: %+v", r.Day)
+	}
+}
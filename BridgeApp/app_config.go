@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"BridgeApp/internal/config"
+	"BridgeApp/internal/hours"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/routing"
+	"BridgeApp/internal/schedule"
+	"BridgeApp/internal/session"
+	"BridgeApp/internal/sizing"
+	"BridgeApp/internal/symbols"
+)
+
+// loadConfig reads the bridge configuration file. Invalid files are reported and the
+// defaults are used so a typo cannot keep the bridge from starting.
+func loadConfig(path string) *config.Config {
+	cfg, err := config.Load(path)
+	if err != nil {
+		log.Printf("ERROR: %v — continuing with default configuration", err)
+		blog.L().Error("config", "invalid bridge configuration; using defaults", map[string]interface{}{"path": path, "error": err.Error()})
+		return &config.Config{}
+	}
+	if cfg.Path() != "" {
+		log.Printf("Configuration: loaded %s", cfg.Path())
+	}
+	return cfg
+}
+
+// applyConfig installs a validated configuration on the app and gRPC server.
+func (a *App) applyConfig(cfg *config.Config) error {
+	symbolMap, err := symbols.New(cfg.Symbols)
+	if err != nil {
+		return err
+	}
+	sizer, err := sizing.New(cfg.Sizing)
+	if err != nil {
+		return err
+	}
+	router, err := routing.New(cfg.Routing)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Queue.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.DeadLetter.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Retry.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Idempotency.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Clients.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Alerts.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Notifications.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.CloseSelection.Validate(); err != nil {
+		return err
+	}
+	calendar, err := session.New(cfg.Session)
+	if err != nil {
+		return err
+	}
+	rules, err := schedule.New(cfg.Schedule, calendar.Location())
+	if err != nil {
+		return err
+	}
+	tradingHours, err := hours.New(cfg.TradingHours, calendar.Location())
+	if err != nil {
+		return err
+	}
+
+	a.configMux.Lock()
+	a.config = cfg
+	a.configMux.Unlock()
+
+	a.setQueueConfig(cfg.Queue)
+	a.setDeadLetterConfig(cfg.DeadLetter)
+	a.setRetryConfig(cfg.Retry)
+	a.setIdempotencyConfig(cfg.Idempotency)
+	a.setClientsConfig(cfg.Clients)
+	a.setAlertsConfig(cfg.Alerts)
+	a.setNotificationsConfig(cfg.Notifications)
+	a.setCloseSelectionConfig(cfg.CloseSelection)
+	a.setSessionCalendar(calendar, cfg.Session.LossLimit())
+	a.setSchedule(rules)
+	a.grpcServer.SetSizer(sizer)
+	a.grpcServer.SetRouter(router)
+	a.grpcServer.SetTradingHours(tradingHours)
+	a.grpcServer.SetSymbolMap(symbolMap)
+	return nil
+}
+
+// ReloadConfig re-reads the configuration file and applies it. The current configuration
+// stays in place when the file is invalid.
+func (a *App) ReloadConfig() map[string]interface{} {
+	path := config.DefaultPath()
+	cfg, err := config.Load(path)
+	if err == nil {
+		err = a.applyConfig(cfg)
+	}
+	if err != nil {
+		log.Printf("ERROR: Config reload failed: %v", err)
+		return map[string]interface{}{
+			"success": false,
+			"message": "Configuration not reloaded",
+			"error":   err.Error(),
+		}
+	}
+	log.Printf("Configuration reloaded from %s", path)
+	return map[string]interface{}{
+		"success":       true,
+		"message":       fmt.Sprintf("Configuration reloaded from %s", path),
+		"heldTrades":    a.grpcServer.HeldTradeCount(),
+		"symbolRules":   len(cfg.Symbols.Rules),
+		"sizingSpecs":   len(cfg.Sizing.Specs),
+		"scheduleRules": len(cfg.Schedule.Rules),
+		"hoursTables":   len(cfg.TradingHours.Tables),
+		"terminals":     a.grpcServer.TerminalStatuses(),
+	}
+}
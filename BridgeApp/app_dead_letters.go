@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/config"
+	"BridgeApp/internal/deadletter"
+	blog "BridgeApp/internal/logging"
+)
+
+// deliveredTTL bounds how long a delivered trade is remembered while waiting for its MT5 result.
+const deliveredTTL = 10 * time.Minute
+
+// deliveredTrade is a trade handed to an MT5 stream whose execution result has not arrived yet.
+type deliveredTrade struct {
+	trade Trade
+	at    time.Time
+}
+
+// setDeadLetterConfig opens the dead-letter store on first use (or when its file moves) and
+// applies the entry limit otherwise.
+func (a *App) setDeadLetterConfig(cfg deadletter.Config) {
+	if strings.TrimSpace(cfg.Path) == "" {
+		cfg.Path = config.DefaultDeadLetterPath()
+	}
+	a.deadLetterMux.Lock()
+	defer a.deadLetterMux.Unlock()
+	if a.deadLetters != nil && (a.deadLetters.Path() == cfg.Path || (a.deadLetters.Path() == "" && strings.EqualFold(cfg.Path, "off"))) {
+		a.deadLetters.SetMaxEntries(cfg.MaxEntries)
+		return
+	}
+	a.deadLetters = deadletter.Open(cfg)
+	if n := a.deadLetters.Len(); n > 0 {
+		log.Printf("Dead letters: %d undelivered trade(s) loaded from %s", n, a.deadLetters.Path())
+	}
+}
+
+func (a *App) deadLetterStore() *deadletter.Store {
+	a.deadLetterMux.RLock()
+	defer a.deadLetterMux.RUnlock()
+	return a.deadLetters
+}
+
+// DeadLetterTrade records a trade that could not be delivered to or executed by MT5.
+func (a *App) DeadLetterTrade(trade interface{}, reason, detail, terminal string) {
+	e := deadletter.Entry{Reason: reason, Detail: detail, Terminal: terminal}
+	if t, err := decodeTrade(trade); err == nil {
+		e.TradeID, e.BaseID, e.Action = t.ID, t.BaseID, t.Action
+		if terminal == "" {
+			e.Terminal = t.Terminal
+		}
+		e.Payload, _ = json.Marshal(t)
+		if reason != deadletter.ReasonMT5Rejected {
+			// Never reached MT5: stop waiting for its result
+			a.takeDelivered(strings.TrimSpace(t.BaseID), strings.EqualFold(t.Action, "CLOSE_HEDGE"), t.MT5Ticket)
+		}
+	} else {
+		// Keep whatever can be serialised so the payload is not lost with the trade
+		raw, mErr := json.Marshal(trade)
+		if mErr != nil {
+			raw, _ = json.Marshal(fmt.Sprintf("%+v", trade))
+		}
+		e.Payload = raw
+	}
+	e = a.deadLetterStore().Add(e)
+	a.recordError(fmt.Sprintf("trade %s dead-lettered: %s %s", e.TradeID, e.Reason, e.Detail))
+
+	log.Printf("WARN: Dead-lettered trade %s (base_id=%s action=%s terminal=%s): %s %s (attempts=%d)",
+		e.TradeID, e.BaseID, e.Action, e.Terminal, e.Reason, e.Detail, e.Attempts)
+	blog.L().Warn("dead_letter", "trade dead-lettered", map[string]interface{}{
+		"dead_letter_id": e.ID,
+		"trade_id":       e.TradeID,
+		"base_id":        e.BaseID,
+		"action":         e.Action,
+		"terminal":       e.Terminal,
+		"reason":         e.Reason,
+		"detail":         e.Detail,
+		"attempts":       e.Attempts,
+	})
+}
+
+// DeadLetters returns the dead-lettered trades, most recent failure first.
+func (a *App) DeadLetters() []deadletter.Entry {
+	return a.deadLetterStore().List()
+}
+
+// ResolveDeadLetter handles an operator action on a dead letter: "retry" re-queues the original
+// trade, "edit_retry" first merges the given JSON fields over it, "discard" drops it. A retried
+// entry is refused, and the dead letter kept, when the entry gates would refuse a new one.
+func (a *App) ResolveDeadLetter(id, action string, edits []byte) error {
+	store := a.deadLetterStore()
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "discard":
+		e, ok := store.Discard(id)
+		if !ok {
+			return fmt.Errorf("dead letter %s not found", id)
+		}
+		log.Printf("Dead letter %s (trade %s) discarded", id, e.TradeID)
+		blog.L().Info("dead_letter", "dead letter discarded", map[string]interface{}{"dead_letter_id": id, "trade_id": e.TradeID, "base_id": e.BaseID})
+		return nil
+	case "retry", "edit_retry":
+	default:
+		return fmt.Errorf("unknown dead letter action %q (want retry, edit_retry or discard)", action)
+	}
+
+	e, ok := store.Take(id)
+	if !ok {
+		return fmt.Errorf("dead letter %s not found", id)
+	}
+	var t Trade
+	err := json.Unmarshal(e.Payload, &t)
+	orig := t
+	if err == nil && strings.EqualFold(strings.TrimSpace(action), "edit_retry") {
+		if len(strings.TrimSpace(string(edits))) == 0 {
+			err = fmt.Errorf("edit_retry needs the fields to change")
+		} else if err = json.Unmarshal(edits, &t); err != nil {
+			err = fmt.Errorf("invalid edits: %w", err)
+		}
+	}
+	if err == nil && (strings.TrimSpace(t.BaseID) == "" || strings.TrimSpace(t.Action) == "") {
+		err = fmt.Errorf("trade needs base_id and action to be retried")
+	}
+	if act := strings.ToLower(strings.TrimSpace(t.Action)); err == nil && (act == "buy" || act == "sell") {
+		// Entries pass the same gates as a new one. An mt5_symbol set by the edits is kept, else the
+		// symbol is resolved again
+		var symbol, terminal string
+		if symbol, terminal, err = a.grpcServer.RetryEntry(t); err == nil {
+			if t.MT5Symbol == orig.MT5Symbol && (symbol != "" || t.Instrument != orig.Instrument) {
+				t.MT5Symbol = symbol
+			}
+			if terminal != "" {
+				t.Terminal = terminal
+			}
+		}
+	}
+	if err == nil {
+		err = a.AddToTradeQueue(t)
+	}
+	if err != nil {
+		store.Restore(e)
+		return fmt.Errorf("dead letter %s not retried: %w", id, err)
+	}
+	log.Printf("Dead letter %s (trade %s) re-queued (%s, previous attempts=%d)", id, t.ID, action, e.Attempts)
+	blog.L().Info("dead_letter", "dead letter re-queued", map[string]interface{}{
+		"dead_letter_id": id,
+		"trade_id":       t.ID,
+		"base_id":        t.BaseID,
+		"action":         action,
+		"attempts":       e.Attempts,
+	})
+	return nil
+}
+
+// trackDelivered remembers a trade handed to an MT5 stream so a failed result can be dead-lettered
+// with its original payload.
+func (a *App) trackDelivered(t Trade) {
+	base := strings.TrimSpace(t.BaseID)
+	if base == "" {
+		return
+	}
+	act := strings.ToUpper(strings.TrimSpace(t.Action))
+	if act != "BUY" && act != "SELL" && act != "CLOSE_HEDGE" {
+		return // MT5 reports no execution result for events
+	}
+	now := time.Now()
+	a.deliveredMux.Lock()
+	defer a.deliveredMux.Unlock()
+	for b, list := range a.delivered {
+		for len(list) > 0 && now.Sub(list[0].at) > deliveredTTL {
+			list = list[1:]
+		}
+		if len(list) == 0 {
+			delete(a.delivered, b)
+		} else {
+			a.delivered[b] = list
+		}
+	}
+	a.delivered[base] = append(a.delivered[base], deliveredTrade{trade: t, at: now})
+}
+
+// takeDelivered returns the oldest delivered trade an MT5 result refers to: a close for ticket
+// (when given) or an entry of baseID.
+func (a *App) takeDelivered(baseID string, isClose bool, ticket uint64) (Trade, bool) {
+	a.deliveredMux.Lock()
+	defer a.deliveredMux.Unlock()
+	list := a.delivered[baseID]
+	for i, d := range list {
+		closing := strings.EqualFold(d.trade.Action, "CLOSE_HEDGE")
+		if closing != isClose || (isClose && ticket != 0 && d.trade.MT5Ticket != ticket) {
+			continue
+		}
+		list = append(list[:i], list[i+1:]...)
+		if len(list) == 0 {
+			delete(a.delivered, baseID)
+		} else {
+			a.delivered[baseID] = list
+		}
+		return d.trade, true
+	}
+	return Trade{}, false
+}
+
+// GetDeadLetters returns the dead-lettered trades for the UI
+func (a *App) GetDeadLetters() []map[string]interface{} {
+	entries := a.DeadLetters()
+	out := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, map[string]interface{}{
+			"id":          e.ID,
+			"reason":      e.Reason,
+			"detail":      e.Detail,
+			"attempts":    e.Attempts,
+			"tradeId":     e.TradeID,
+			"baseId":      e.BaseID,
+			"action":      e.Action,
+			"terminal":    e.Terminal,
+			"payload":     string(e.Payload),
+			"firstFailed": e.FirstFailed,
+			"lastFailed":  e.LastFailed,
+		})
+	}
+	return out
+}
+
+// RetryDeadLetter re-queues a dead-lettered trade unchanged
+func (a *App) RetryDeadLetter(id string) map[string]interface{} {
+	return deadLetterResult(id, "retry", a.ResolveDeadLetter(id, "retry", nil))
+}
+
+// EditAndRetryDeadLetter merges the JSON fields in edits over a dead-lettered trade and re-queues it
+func (a *App) EditAndRetryDeadLetter(id string, edits string) map[string]interface{} {
+	return deadLetterResult(id, "edit_retry", a.ResolveDeadLetter(id, "edit_retry", []byte(edits)))
+}
+
+// DiscardDeadLetter drops a dead-lettered trade
+func (a *App) DiscardDeadLetter(id string) map[string]interface{} {
+	return deadLetterResult(id, "discard", a.ResolveDeadLetter(id, "discard", nil))
+}
+
+func deadLetterResult(id, action string, err error) map[string]interface{} {
+	if err != nil {
+		return map[string]interface{}{"success": false, "id": id, "action": action, "error": err.Error()}
+	}
+	return map[string]interface{}{"success": true, "id": id, "action": action}
+}
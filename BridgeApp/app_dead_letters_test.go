@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"BridgeApp/internal/deadletter"
+	grpcserver "BridgeApp/internal/grpc"
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/schedule"
+	"BridgeApp/internal/symbols"
+)
+
+func newDeadLetterApp(t *testing.T) *App {
+	a := NewApp()
+	a.setDeadLetterConfig(deadletter.Config{Path: filepath.Join(t.TempDir(), "dead-letters.json")})
+	return a
+}
+
+func TestMT5RejectionIsDeadLetteredAndRetried(t *testing.T) {
+	a := newDeadLetterApp(t)
+	if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "rej-1", BaseId: "BASE_REJ", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Sim101",
+	}); err != nil {
+		t.Fatalf("SubmitTrade: %v", err)
+	}
+	if _, ok := drainTrade(a); !ok {
+		t.Fatal("expected the entry to be delivered")
+	}
+	if err := a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "failed", ID: "BASE_REJ", Volume: 0.1}); err != nil {
+		t.Fatalf("HandleMT5TradeResult: %v", err)
+	}
+
+	letters := a.GetDeadLetters()
+	if len(letters) != 1 || letters[0]["reason"] != deadletter.ReasonMT5Rejected || letters[0]["baseId"] != "BASE_REJ" || letters[0]["attempts"] != 1 {
+		t.Fatalf("unexpected dead letters: %+v", letters)
+	}
+	id := letters[0]["id"].(string)
+
+	if res := a.RetryDeadLetter(id); res["success"] != true {
+		t.Fatalf("RetryDeadLetter: %+v", res)
+	}
+	trade, ok := drainTrade(a)
+	if !ok || trade.ID != "rej-1" || trade.AccountName != "Sim101" {
+		t.Fatalf("expected the original trade to be re-queued, got %+v (ok=%v)", trade, ok)
+	}
+
+	// Failing again continues the same dead letter
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "failed", ID: "BASE_REJ"})
+	if letters = a.GetDeadLetters(); len(letters) != 1 || letters[0]["id"] != id || letters[0]["attempts"] != 2 {
+		t.Fatalf("expected attempts to accumulate, got %+v", letters)
+	}
+}
+
+func TestEditRetryAndDiscardDeadLetters(t *testing.T) {
+	a := newDeadLetterApp(t)
+	a.DeadLetterTrade(Trade{ID: "edit-1", BaseID: "BASE_EDIT", Action: "buy", Quantity: 1, MT5Symbol: "NAS100"}, deadletter.ReasonStreamFull, "test", "default")
+	a.DeadLetterTrade(Trade{ID: "drop-1", BaseID: "BASE_DROP", Action: "sell", Quantity: 1}, deadletter.ReasonStreamFull, "test", "default")
+
+	var editID, dropID string
+	for _, l := range a.GetDeadLetters() {
+		switch l["tradeId"] {
+		case "edit-1":
+			editID = l["id"].(string)
+		case "drop-1":
+			dropID = l["id"].(string)
+		}
+	}
+
+	if res := a.EditAndRetryDeadLetter(editID, `{"mt5_symbol":"USTEC"}`); res["success"] != true {
+		t.Fatalf("EditAndRetryDeadLetter: %+v", res)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.MT5Symbol != "USTEC" || trade.BaseID != "BASE_EDIT" {
+		t.Fatalf("expected the edited trade, got %+v (ok=%v)", trade, ok)
+	}
+	if res := a.EditAndRetryDeadLetter(dropID, `{not json`); res["success"] != false {
+		t.Fatalf("invalid edits must be rejected: %+v", res)
+	}
+	if res := a.DiscardDeadLetter(dropID); res["success"] != true {
+		t.Fatalf("DiscardDeadLetter: %+v", res)
+	}
+	if n := len(a.GetDeadLetters()); n != 0 {
+		t.Fatalf("expected no dead letters left, got %d", n)
+	}
+	if res := a.RetryDeadLetter("dl-missing"); res["success"] != false {
+		t.Fatalf("unknown ids must fail: %+v", res)
+	}
+}
+
+func TestRetriedEntriesPassTheEntryGates(t *testing.T) {
+	a := newDeadLetterApp(t)
+	installSymbolMap(t, a, symbols.Config{UnknownPolicy: symbols.PolicyReject, Rules: []symbols.Rule{{Match: "ES*", Symbol: "US500"}}})
+	sc, err := schedule.New(schedule.Config{Rules: []schedule.Rule{
+		{Name: "CPI", Action: "close_only", Accounts: []string{"Sim101"}, Times: []string{time.Now().Format("2006-01-02 15:04")}},
+	}}, time.Local)
+	if err != nil {
+		t.Fatalf("schedule.New: %v", err)
+	}
+	a.setSchedule(sc)
+	a.DeadLetterTrade(Trade{ID: "gate-1", BaseID: "BASE_GATE", Action: "buy", Quantity: 1, Instrument: "ESZ5", AccountName: "Sim101"}, deadletter.ReasonStreamFull, "test", "default")
+	id := a.GetDeadLetters()[0]["id"].(string)
+
+	if res := a.RetryDeadLetter(id); res["success"] != false {
+		t.Fatalf("a retry in a close_only window must be refused: %+v", res)
+	}
+	if res := a.EditAndRetryDeadLetter(id, `{"account_name":"Sim102","instrument":"CLZ5"}`); res["success"] != false {
+		t.Fatalf("a retry with an unmapped instrument must be refused: %+v", res)
+	}
+	if _, ok := drainTrade(a); ok || len(a.GetDeadLetters()) != 1 {
+		t.Fatalf("refused retries must keep the dead letter and queue nothing, letters=%+v", a.GetDeadLetters())
+	}
+
+	if res := a.EditAndRetryDeadLetter(id, `{"account_name":"Sim102","instrument":"ESH6"}`); res["success"] != true {
+		t.Fatalf("EditAndRetryDeadLetter: %+v", res)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.AccountName != "Sim102" || trade.MT5Symbol != "US500" {
+		t.Fatalf("expected the edited entry with its resolved symbol, got %+v (ok=%v)", trade, ok)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/deadletter"
+	"BridgeApp/internal/execution"
+	grpcserver "BridgeApp/internal/grpc"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/notify"
+)
+
+// retcodePositionClosed is MT5's "position already closed"; for a close it means the goal was reached.
+const retcodePositionClosed = 10036
+
+// setRetryConfig installs the backoff policy for transient MT5 failures.
+func (a *App) setRetryConfig(cfg execution.RetryConfig) {
+	a.execMux.Lock()
+	a.retryCfg = cfg
+	a.execMux.Unlock()
+}
+
+// settleDelivery classifies an MT5 result, matches it to the delivered trade and retries or
+// escalates failures. It reports whether the result was a failure, which must not be processed
+// as a fill or a close.
+func (a *App) settleDelivery(res *grpcserver.InternalMT5TradeResult) bool {
+	baseID := strings.TrimSpace(res.ID)
+	r := execution.Classify(res.Status)
+	if res.IsClose && r.Retcode == retcodePositionClosed {
+		r.Kind = execution.KindSuccess
+	}
+	t, delivered := a.takeDelivered(baseID, res.IsClose, res.Ticket)
+
+	o := execution.Outcome{Time: time.Now(), Ticket: res.Ticket, Attempt: 1, Result: r}
+	if delivered {
+		o.TradeID, o.Action = t.ID, t.Action
+	}
+	a.execMux.Lock()
+	if delivered {
+		o.Attempt = a.retryAttempts[t.ID] + 1
+	}
+	cfg := a.retryCfg
+	a.execMux.Unlock()
+
+	switch r.Kind {
+	case execution.KindSuccess:
+		o.Decision = "filled"
+		a.clearRetryAttempts(t.ID)
+	case execution.KindIgnored:
+		o.Decision = "ignored"
+		a.clearRetryAttempts(t.ID)
+	case execution.KindTransient:
+		if delay, ok := cfg.Backoff(o.Attempt); ok && delivered {
+			o.Decision = "retry_scheduled"
+			a.scheduleRetry(t, r, o.Attempt, delay)
+			break
+		}
+		fallthrough
+	default:
+		o.Decision = "escalated"
+		a.escalateFailure(baseID, t, delivered, res, r, o.Attempt)
+	}
+	a.execHistory.Record(baseID, o)
+	return r.Failed()
+}
+
+func (a *App) clearRetryAttempts(tradeID string) {
+	if tradeID == "" {
+		return
+	}
+	a.execMux.Lock()
+	delete(a.retryAttempts, tradeID)
+	a.execMux.Unlock()
+}
+
+// pendingRetry is a trade waiting for its backoff delay before it is queued again.
+type pendingRetry struct {
+	trade Trade
+	timer *time.Timer
+}
+
+// scheduleRetry re-enqueues the original trade after delay, unless a close of its BaseID cancels
+// the retry first.
+func (a *App) scheduleRetry(t Trade, r execution.Result, attempt int, delay time.Duration) {
+	baseID := strings.TrimSpace(t.BaseID)
+	p := &pendingRetry{trade: t}
+	a.execMux.Lock()
+	a.retryAttempts[t.ID] = attempt
+	p.timer = time.AfterFunc(delay, func() {
+		if !a.takePendingRetry(baseID, p) {
+			return // cancelled
+		}
+		if err := a.AddToTradeQueue(t); err != nil {
+			a.DeadLetterTrade(t, deadletter.ReasonMT5Rejected, fmt.Sprintf("retry %d after %s could not be queued: %v", attempt, r.Name, err), t.Terminal)
+		}
+	})
+	a.pendingRetries[baseID] = append(a.pendingRetries[baseID], p)
+	a.execMux.Unlock()
+
+	log.Printf("WARN: MT5 %s for trade %s (base_id=%s action=%s); retry %d in %s", r.Name, t.ID, t.BaseID, t.Action, attempt, delay)
+	blog.L().Warn("execution", "transient MT5 failure, retry scheduled", map[string]interface{}{
+		"trade_id": t.ID,
+		"base_id":  t.BaseID,
+		"action":   t.Action,
+		"retcode":  r.Retcode,
+		"reason":   r.Name,
+		"attempt":  attempt,
+		"delay":    delay.String(),
+	})
+}
+
+// takePendingRetry removes p from the retries of baseID; false means it was cancelled.
+func (a *App) takePendingRetry(baseID string, p *pendingRetry) bool {
+	a.execMux.Lock()
+	defer a.execMux.Unlock()
+	list := a.pendingRetries[baseID]
+	for i, q := range list {
+		if q == p {
+			if len(list) == 1 {
+				delete(a.pendingRetries, baseID)
+			} else {
+				a.pendingRetries[baseID] = append(list[:i:i], list[i+1:]...)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// cancelPendingRetries stops up to limit retries of baseID whose trade matches (all of them when
+// limit <= 0) and returns their trades.
+func (a *App) cancelPendingRetries(baseID string, limit int, match func(Trade) bool) []Trade {
+	a.execMux.Lock()
+	defer a.execMux.Unlock()
+	var cancelled []Trade
+	kept := a.pendingRetries[baseID][:0:0]
+	for _, p := range a.pendingRetries[baseID] {
+		if (limit <= 0 || len(cancelled) < limit) && match(p.trade) {
+			p.timer.Stop()
+			delete(a.retryAttempts, p.trade.ID)
+			cancelled = append(cancelled, p.trade)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if len(kept) == 0 {
+		delete(a.pendingRetries, baseID)
+	} else {
+		a.pendingRetries[baseID] = kept
+	}
+	return cancelled
+}
+
+// escalateFailure dead-letters a trade MT5 will not execute and tells the addon, which still holds
+// the unhedged Quantower side. A failed close puts its ticket back in the pool.
+func (a *App) escalateFailure(baseID string, t Trade, delivered bool, res *grpcserver.InternalMT5TradeResult, r execution.Result, attempt int) {
+	a.clearRetryAttempts(t.ID)
+	if delivered {
+		a.DeadLetterTrade(t, deadletter.ReasonMT5Rejected, fmt.Sprintf("MT5 %s (status %q, ticket=%d volume=%.2f, attempt %d)", r.Name, res.Status, res.Ticket, res.Volume, attempt), t.Terminal)
+	}
+	if res.IsClose && res.Ticket != 0 {
+		a.pushTicket(baseID, res.Ticket)
+		a.mt5TicketMux.Lock()
+		a.mt5TicketToBaseId[a.ticketKeyLocked(baseID, res.Ticket)] = baseID
+		a.mt5TicketMux.Unlock()
+		a.failCloseRequestTicket(baseID, res.Ticket, r.Name)
+	}
+
+	log.Printf("ERROR: MT5 permanently failed %s for base_id=%s (status=%q kind=%s attempt=%d)", r.Name, baseID, res.Status, r.Kind, attempt)
+	blog.L().Error("execution", "MT5 execution failed permanently", map[string]interface{}{
+		"trade_id":   t.ID,
+		"base_id":    baseID,
+		"action":     t.Action,
+		"retcode":    r.Retcode,
+		"reason":     r.Name,
+		"mt5_ticket": res.Ticket,
+		"attempt":    attempt,
+	})
+
+	notice := t
+	if !delivered {
+		notice = Trade{ID: fmt.Sprintf("mt5fail_%d", time.Now().UnixNano()), BaseID: baseID, MT5Ticket: res.Ticket, Quantity: res.Volume}
+		notice.Instrument, notice.AccountName = a.bestInstAcctFor(baseID)
+	}
+	notice.Action = "HEDGE_FAILED"
+	notice.OrderType = "MT5_REJECTED"
+	if res.IsClose {
+		notice.OrderType = "MT5_CLOSE_REJECTED"
+	}
+	notice.NTTradeResult = r.Name
+	a.grpcServer.NotifyAddonStreams(notice)
+
+	n := notify.Notification{
+		Kind:     notify.HedgeFailed,
+		Severity: notify.Critical,
+		Title:    "Hedge failed",
+		Message:  fmt.Sprintf("MT5 rejected the hedge for BaseID %s: %s (attempt %d). The Quantower side is unhedged.", baseID, r.Name, attempt),
+		BaseID:   baseID,
+		Ticket:   res.Ticket,
+	}
+	if res.IsClose {
+		n.Title = "Hedge close failed"
+		n.Message = fmt.Sprintf("MT5 rejected the close of ticket %d for BaseID %s: %s (attempt %d). The hedge is still open.", res.Ticket, baseID, r.Name, attempt)
+	}
+	a.notify(n)
+}
+
+// GetExecutionOutcomes returns the recorded MT5 results for a BaseID, oldest first
+func (a *App) GetExecutionOutcomes(baseID string) []map[string]interface{} {
+	outcomes := a.execHistory.For(strings.TrimSpace(baseID))
+	out := make([]map[string]interface{}, 0, len(outcomes))
+	for _, o := range outcomes {
+		out = append(out, map[string]interface{}{
+			"time":     o.Time,
+			"tradeId":  o.TradeID,
+			"action":   o.Action,
+			"ticket":   o.Ticket,
+			"attempt":  o.Attempt,
+			"decision": o.Decision,
+			"kind":     string(o.Kind),
+			"retcode":  o.Retcode,
+			"reason":   o.Name,
+			"status":   o.Status,
+		})
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"BridgeApp/internal/execution"
+	grpcserver "BridgeApp/internal/grpc"
+	trading "BridgeApp/internal/grpc/proto"
+)
+
+func waitForTrade(t *testing.T, a *App, within time.Duration) (Trade, bool) {
+	t.Helper()
+	deadline := time.Now().Add(within)
+	for time.Now().Before(deadline) {
+		if trade, ok := drainTrade(a); ok {
+			return trade, true
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	return Trade{}, false
+}
+
+func TestTransientFailureRetriesThenEscalates(t *testing.T) {
+	a := newDeadLetterApp(t)
+	a.setRetryConfig(execution.RetryConfig{MaxRetries: 2, BaseDelay: "1ms", MaxDelay: "5ms"})
+	if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "rq-1", BaseId: "BASE_RQ", Action: "buy", Quantity: 1, Instrument: "NQZ5",
+	}); err != nil {
+		t.Fatalf("SubmitTrade: %v", err)
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		trade, ok := waitForTrade(t, a, time.Second)
+		if !ok || trade.ID != "rq-1" {
+			t.Fatalf("attempt %d: expected the original trade to be (re)delivered, got %+v (ok=%v)", attempt, trade, ok)
+		}
+		a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "failed:10004", ID: "BASE_RQ"})
+	}
+	if trade, ok := waitForTrade(t, a, 50*time.Millisecond); ok {
+		t.Fatalf("retries must stop after max_retries, got %+v", trade)
+	}
+
+	outcomes := a.GetExecutionOutcomes("BASE_RQ")
+	if len(outcomes) != 3 {
+		t.Fatalf("expected 3 outcomes, got %+v", outcomes)
+	}
+	for i, want := range []string{"retry_scheduled", "retry_scheduled", "escalated"} {
+		if outcomes[i]["decision"] != want || outcomes[i]["reason"] != "requote" || outcomes[i]["attempt"] != i+1 {
+			t.Fatalf("outcome %d = %+v; want %s", i, outcomes[i], want)
+		}
+	}
+	if letters := a.GetDeadLetters(); len(letters) != 1 || letters[0]["tradeId"] != "rq-1" {
+		t.Fatalf("expected the exhausted trade to be dead-lettered, got %+v", letters)
+	}
+}
+
+func TestCloseCancelsPendingEntryRetry(t *testing.T) {
+	a := newDeadLetterApp(t)
+	a.setRetryConfig(execution.RetryConfig{MaxRetries: 2, BaseDelay: "30ms", MaxDelay: "30ms"})
+	if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "rc-1", BaseId: "BASE_RC", Action: "buy", Quantity: 1, Instrument: "NQZ5",
+	}); err != nil {
+		t.Fatalf("SubmitTrade: %v", err)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.ID != "rc-1" {
+		t.Fatalf("expected rc-1 delivered, got %+v (ok=%v)", trade, ok)
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "failed:10004", ID: "BASE_RC"})
+
+	// Quantower closes while the entry waits for its retry: the retry is cancelled, nothing opens
+	md, err := a.CloseHedge(map[string]interface{}{"BaseID": "BASE_RC", "ClosedHedgeQuantity": 1.0})
+	if err != nil {
+		t.Fatalf("CloseHedge: %v", err)
+	}
+	if md["status"] == "" {
+		t.Fatalf("expected a close request status, got %+v", md)
+	}
+	if trade, ok := waitForTrade(t, a, 100*time.Millisecond); ok {
+		t.Fatalf("the cancelled retry must not reach MT5, got %+v", trade)
+	}
+}
+
+func TestPermanentCloseFailureRestoresTicket(t *testing.T) {
+	a := newDeadLetterApp(t)
+	a.pushTicket("BASE_PC", 9001)
+	trackTicket(a, "BASE_PC", 9001)
+
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{"BaseID": "BASE_PC", "ClosedHedgeQuantity": 1.0}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.MT5Ticket != 9001 {
+		t.Fatalf("expected CLOSE_HEDGE for ticket 9001, got %+v (ok=%v)", trade, ok)
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "failed:10018", ID: "BASE_PC", Ticket: 9001, IsClose: true})
+
+	if n := a.openTicketCount("BASE_PC"); n < 1 {
+		t.Fatalf("ticket 9001 must return to the pool after a failed close, open=%d", n)
+	}
+	outcomes := a.GetExecutionOutcomes("BASE_PC")
+	if len(outcomes) != 1 || outcomes[0]["decision"] != "escalated" || outcomes[0]["reason"] != "market_closed" {
+		t.Fatalf("unexpected outcomes: %+v", outcomes)
+	}
+}
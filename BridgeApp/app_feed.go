@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+
+	"BridgeApp/internal/feed"
+	grpcserver "BridgeApp/internal/grpc"
+)
+
+// feedEvent is the Wails runtime event carrying each new feed.Event to the UI.
+const feedEvent = "bridge:event"
+
+// recordFeed adds e to the live feed and pushes it to the UI.
+func (a *App) recordFeed(e feed.Event) {
+	if a.feed == nil {
+		return
+	}
+	a.emitEvent(feedEvent, a.feed.Add(e))
+}
+
+// recordFeedTrade adds a queued or delivered trade to the live feed.
+func (a *App) recordFeedTrade(kind feed.Kind, t Trade) {
+	e := feed.Event{
+		Kind:       kind,
+		BaseID:     strings.TrimSpace(t.BaseID),
+		TradeID:    t.ID,
+		Action:     strings.ToUpper(strings.TrimSpace(t.Action)),
+		Instrument: t.Instrument,
+		Account:    t.AccountName,
+		Terminal:   t.Terminal,
+		Ticket:     t.MT5Ticket,
+		Volume:     t.Quantity,
+		Price:      t.Price,
+		Status:     t.EventType,
+	}
+	if t.HedgeLot > 0 {
+		e.Volume = t.HedgeLot
+	}
+	a.recordFeed(e)
+}
+
+// recordFeedResult adds an MT5 execution result to the live feed.
+func (a *App) recordFeedResult(res *grpcserver.InternalMT5TradeResult) {
+	e := feed.Event{
+		Kind:   feed.MT5Result,
+		BaseID: strings.TrimSpace(res.ID),
+		Ticket: res.Ticket,
+		Volume: res.Volume,
+		Price:  res.Price,
+		Profit: res.Profit,
+		Status: res.Status,
+		Action: "OPEN",
+	}
+	if res.IsClose {
+		e.Action = "CLOSE"
+	}
+	e.Instrument, e.Account = a.bestInstAcctFor(e.BaseID)
+	a.recordFeed(e)
+}
+
+// GetEventFeed returns up to limit of the latest feed events, oldest first, for the blotter.
+func (a *App) GetEventFeed(limit int) []feed.Event {
+	return a.feed.Recent(limit)
+}
+
+// GetEventsSince returns the feed events after seq, so the UI can catch up after a reload.
+func (a *App) GetEventsSince(seq uint64) []feed.Event {
+	return a.feed.Since(seq)
+}
+
+// GetBaseTimeline returns every feed event still held for a BaseID, oldest first.
+func (a *App) GetBaseTimeline(baseID string) []feed.Event {
+	return a.feed.Timeline(baseID)
+}
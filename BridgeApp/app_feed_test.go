@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"BridgeApp/internal/feed"
+	grpcserver "BridgeApp/internal/grpc"
+)
+
+func TestEventFeedFollowsATradeThroughTheBridge(t *testing.T) {
+	a := newDeadLetterApp(t)
+	if err := a.AddToTradeQueue(Trade{ID: "fd-1", BaseID: "BASE_FD", Action: "buy", Quantity: 1, Instrument: "NQ", AccountName: "Sim1"}); err != nil {
+		t.Fatal(err)
+	}
+	if trade := a.PollTradeFromQueue(); trade == nil {
+		t.Fatal("expected the queued trade to be delivered")
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: "BASE_FD", Ticket: 3101, Volume: 0.1, Price: 100})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, ID: "BASE_FD", Ticket: 3101, Volume: 0.1, Profit: 2})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "closed", ID: "BASE_FD", Ticket: 3101, Volume: 0.1, IsClose: true, Price: 101, Profit: 10})
+	a.AddToTradeQueue(Trade{ID: "fd-other", BaseID: "BASE_OTHER", Action: "sell", Quantity: 1})
+
+	want := []feed.Kind{feed.Enqueued, feed.SentToMT5, feed.MT5Result, feed.MT5Result, feed.CloseNotice}
+	timeline := a.GetBaseTimeline("BASE_FD")
+	if len(timeline) != len(want) {
+		t.Fatalf("expected %d timeline events (position snapshots excluded), got %+v", len(want), timeline)
+	}
+	for i, k := range want {
+		if timeline[i].Kind != k {
+			t.Fatalf("event %d = %s, want %s (%+v)", i, timeline[i].Kind, k, timeline)
+		}
+	}
+	if e := timeline[0]; e.TradeID != "fd-1" || e.Action != "BUY" || e.Instrument != "NQ" || e.Account != "Sim1" {
+		t.Fatalf("unexpected enqueue event %+v", e)
+	}
+	if e := timeline[4]; e.Ticket != 3101 || e.Profit != 10 || e.Status != "closed" || e.Instrument != "NQ" {
+		t.Fatalf("unexpected close notice %+v", e)
+	}
+
+	all := a.GetEventFeed(0)
+	if len(all) != len(want)+1 || all[len(all)-1].BaseID != "BASE_OTHER" {
+		t.Fatalf("the blotter must hold every event in order, got %+v", all)
+	}
+	if since := a.GetEventsSince(all[len(all)-2].Seq); len(since) != 1 || since[0].TradeID != "fd-other" {
+		t.Fatalf("GetEventsSince = %+v", since)
+	}
+}
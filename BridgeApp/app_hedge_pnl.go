@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	grpcserver "BridgeApp/internal/grpc"
+	"BridgeApp/internal/hedgepnl"
+)
+
+// dealFromResult extracts the MT5 deal economics an EA trade result carries.
+func dealFromResult(res *grpcserver.InternalMT5TradeResult) hedgepnl.Deal {
+	return hedgepnl.Deal{
+		Ticket:     res.DealTicket,
+		Price:      res.Price,
+		OpenPrice:  res.OpenPrice,
+		Volume:     res.Volume,
+		Commission: res.Commission,
+		Swap:       res.Swap,
+		Profit:     res.Profit,
+		ServerTime: res.ServerTime,
+		Received:   time.Now(),
+	}
+}
+
+// getCloseDealFromNotification extracts the closing deal economics of an EA hedge close
+// notification; the volume is left to the caller.
+func getCloseDealFromNotification(notification interface{}) hedgepnl.Deal {
+	deal := hedgepnl.Deal{Received: time.Now()}
+	switch n := notification.(type) {
+	case *grpcserver.InternalHedgeCloseNotification:
+		deal.Ticket, deal.Price, deal.OpenPrice = n.DealTicket, n.ClosePrice, n.OpenPrice
+		deal.Commission, deal.Swap, deal.Profit, deal.ServerTime = n.Commission, n.Swap, n.Profit, n.ServerTime
+	case map[string]interface{}:
+		for key, field := range map[string]*float64{"close_price": &deal.Price, "open_price": &deal.OpenPrice, "commission": &deal.Commission, "swap": &deal.Swap, "profit": &deal.Profit} {
+			if v, ok := n[key].(float64); ok {
+				*field = v
+			}
+		}
+		if v, ok := n["server_time"].(float64); ok {
+			deal.ServerTime = int64(v)
+		}
+		if v, ok := n["deal_ticket"].(float64); ok {
+			deal.Ticket = uint64(v)
+		}
+	}
+	return deal
+}
+
+// HedgePnL returns the MT5 prices, costs and PnL recorded for baseID's hedges, for GetHedgePnL.
+func (a *App) HedgePnL(baseID string) (hedgepnl.Summary, error) {
+	return a.hedgeLedger.Summary(strings.TrimSpace(baseID))
+}
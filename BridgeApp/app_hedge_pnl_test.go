@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	grpcserver "BridgeApp/internal/grpc"
+)
+
+func TestHedgePnLFromMT5ResultsAndNotifications(t *testing.T) {
+	a := NewApp()
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: "BASE_PNL", Ticket: 6001, Volume: 0.2,
+		Price: 2050.5, Commission: -1.4, DealTicket: 9001, ServerTime: 1760000000})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, ID: "BASE_PNL", Ticket: 6001, Volume: 0.2, Profit: 30})
+
+	s, err := a.HedgePnL("BASE_PNL")
+	if err != nil {
+		t.Fatalf("HedgePnL: %v", err)
+	}
+	if s.OpenTickets != 1 || s.FloatingProfit != 30 || s.Commission != -1.4 || s.Tickets[0].Open.Price != 2050.5 {
+		t.Fatalf("unexpected summary while open: %+v", s)
+	}
+
+	// The EA reports the close as a result and again as a notification for the same deal
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: "BASE_PNL", Ticket: 6001, Volume: 0.2, IsClose: true,
+		Price: 2055.5, Commission: -1.4, Profit: 100, DealTicket: 9002, ServerTime: 1760000600})
+	if err := a.HandleHedgeCloseNotification(&grpcserver.InternalHedgeCloseNotification{BaseID: "BASE_PNL", MT5Ticket: 6001,
+		ClosedHedgeQuantity: 0.2, ClosureReason: "MT5_position_closed", ClosePrice: 2055.5, Profit: 100, Swap: -0.3, DealTicket: 9002}); err != nil {
+		t.Fatalf("HandleHedgeCloseNotification: %v", err)
+	}
+
+	s, err = a.HedgePnL("BASE_PNL")
+	if err != nil {
+		t.Fatalf("HedgePnL: %v", err)
+	}
+	closes := s.Tickets[0].Closes
+	if s.OpenTickets != 0 || len(closes) != 1 || closes[0].OpenPrice != 2050.5 || closes[0].Swap != -0.3 {
+		t.Fatalf("unexpected closes: %+v", closes)
+	}
+	if s.RealizedProfit != 100 || s.Commission != -2.8 || s.Swap != -0.3 || s.FloatingProfit != 0 || s.Net != 96.9 {
+		t.Fatalf("unexpected summary after close: %+v", s)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/sizing"
+)
+
+func TestSubmitTradeAttachesHedgeLotToEachSplit(t *testing.T) {
+	a := NewApp()
+	z, err := sizing.New(sizing.Config{
+		HedgeRatio: 0.5,
+		Specs: []sizing.Spec{{
+			Match: "NQ*", TickSize: 0.25, TickValue: 5,
+			MT5TickSize: 0.01, MT5TickValue: 0.01, LotStep: 0.01, LotMin: 0.01, LotMax: 50,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("sizing.New: %v", err)
+	}
+	a.grpcServer.SetSizer(z)
+
+	if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "size-1", BaseId: "BASE_SIZE", Action: "buy", Quantity: 2, Instrument: "NQZ5", AccountName: "Sim101",
+	}); err != nil {
+		t.Fatalf("SubmitTrade: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		trade, ok := drainTrade(a)
+		if !ok || trade.HedgeLot != 10 {
+			t.Fatalf("split %d: expected hedge_lot 10, got %+v (ok=%v)", i+1, trade, ok)
+		}
+	}
+
+	// Instruments without a spec keep the EA fallback
+	if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "size-2", BaseId: "BASE_NOSPEC", Action: "sell", Quantity: 1, Instrument: "CLZ5",
+	}); err != nil {
+		t.Fatalf("SubmitTrade: %v", err)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.HedgeLot != 0 {
+		t.Fatalf("expected unsized trade, got %+v (ok=%v)", trade, ok)
+	}
+
+	resp, _ := a.grpcServer.GetSettings(context.Background(), &trading.SettingsRequest{SettingName: "hedge-ratio"})
+	if resp.SettingValue != "0.5" {
+		t.Fatalf("expected hedge-ratio setting 0.5, got %q", resp.SettingValue)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/protocol"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func dialBridge(t *testing.T, a *App) trading.TradingServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	trading.RegisterTradingServiceServer(srv, a.grpcServer)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return trading.NewTradingServiceClient(conn)
+}
+
+func TestEAWithoutEventTradesSkipsElasticEvents(t *testing.T) {
+	a := NewApp()
+	client := dialBridge(t, a)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hello, err := client.Hello(ctx, &trading.HelloRequest{Kind: "ea", ClientId: "T1", ClientVersion: "5.2", ProtocolVersion: protocol.Version, Features: []string{protocol.HedgeLot}})
+	if err != nil || !hello.Accepted || hello.ProtocolVersion != protocol.Version || len(hello.Negotiated) != 1 || hello.Negotiated[0] != protocol.HedgeLot {
+		t.Fatalf("Hello = %+v, %v", hello, err)
+	}
+
+	stream, err := client.GetTrades(metadata.AppendToOutgoingContext(ctx, "terminal-id", "T1"))
+	if err != nil {
+		t.Fatalf("GetTrades: %v", err)
+	}
+	if err := stream.Send(&trading.GetTradesRequest{Source: "hedgebot"}); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	a.AddToTradeQueue(Trade{ID: "ev-1", BaseID: "BASE_EV", Action: "EVENT", OrderType: "EVENT", EventType: "elastic_hedge_update"})
+	a.AddToTradeQueue(Trade{ID: "tr-1", BaseID: "BASE_EV", Action: "buy", Quantity: 1})
+	got, err := stream.Recv()
+	if err != nil || got.Id != "tr-1" {
+		t.Fatalf("expected the EVENT to be skipped and tr-1 delivered, got %+v, %v", got, err)
+	}
+
+	resp, _ := client.ListClients(ctx, &trading.ClientListRequest{Kind: "ea"})
+	if len(resp.GetClients()) != 1 || resp.Clients[0].Id != "T1" || resp.Clients[0].ProtocolVersion != protocol.Version || resp.Clients[0].Version != "5.2" || len(resp.Clients[0].StreamIds) != 1 {
+		t.Fatalf("unexpected EA session %+v", resp.GetClients())
+	}
+}
+
+func TestEAReceivesOnlyNegotiatedTradeFields(t *testing.T) {
+	a := NewApp()
+	client := dialBridge(t, a)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if hello, err := client.Hello(ctx, &trading.HelloRequest{Kind: "ea", ClientId: "T2", ProtocolVersion: protocol.Version, Features: []string{protocol.HedgeLot}}); err != nil || !hello.Accepted {
+		t.Fatalf("Hello = %+v, %v", hello, err)
+	}
+	stream, err := client.GetTrades(metadata.AppendToOutgoingContext(ctx, "terminal-id", "T2"))
+	if err != nil {
+		t.Fatalf("GetTrades: %v", err)
+	}
+	if err := stream.Send(&trading.GetTradesRequest{Source: "hedgebot"}); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	a.AddToTradeQueue(Trade{ID: "nf-1", BaseID: "BASE_NF", Action: "buy", Quantity: 1, MT5Symbol: "NAS100", HedgeLot: 0.3})
+	a.AddToTradeQueue(Trade{ID: "nf-2", BaseID: "BASE_NF", Action: "CLOSE_HEDGE", Quantity: 1, MT5Ticket: 77, CloseVolume: 0.2})
+	entry, err := stream.Recv()
+	if err != nil || entry.Id != "nf-1" || entry.HedgeLot != 0.3 || entry.Mt5Symbol != "" {
+		t.Fatalf("expected hedge_lot kept and mt5_symbol dropped, got %+v, %v", entry, err)
+	}
+	closeTrade, err := stream.Recv()
+	if err != nil || closeTrade.Id != "nf-2" || closeTrade.Mt5Ticket != 77 || closeTrade.CloseVolume != 0 {
+		t.Fatalf("expected close_volume dropped for an EA without partial_close, got %+v, %v", closeTrade, err)
+	}
+}
+
+func TestIncompatibleClientIsRejected(t *testing.T) {
+	a := NewApp()
+	client := dialBridge(t, a)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hello, err := client.Hello(ctx, &trading.HelloRequest{Kind: "addon", ClientId: "qt-future", ProtocolVersion: 9, MinProtocolVersion: 9})
+	if err != nil || hello.Accepted || hello.Message == "" {
+		t.Fatalf("expected a rejection with a reason, got %+v, %v", hello, err)
+	}
+
+	_, err = client.SubmitTrade(metadata.AppendToOutgoingContext(ctx, "client-id", "qt-future"), &trading.Trade{Id: "rej-1", BaseId: "BASE_REJ", Action: "buy", Quantity: 1})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("a rejected client's trade must fail with FailedPrecondition, got %v", err)
+	}
+	if tr, ok := drainTrade(a); ok {
+		t.Fatalf("a rejected client's trade must not be queued, got %+v", tr)
+	}
+
+	// Another add-on declaring qt_position_id may leave base_id to it
+	if hello, _ := client.Hello(ctx, &trading.HelloRequest{Kind: "addon", ClientId: "qt-desk", ProtocolVersion: protocol.Version, Features: []string{protocol.QTPositionID}}); !hello.GetAccepted() {
+		t.Fatalf("expected qt-desk to be accepted, got %+v", hello)
+	}
+	md := metadata.AppendToOutgoingContext(ctx, "client-id", "qt-desk")
+	if resp, err := client.SubmitTrade(md, &trading.Trade{Id: "qt-1", QtPositionId: "POS_QT", Action: "buy", Quantity: 1}); err != nil || resp.Status != "success" {
+		t.Fatalf("SubmitTrade = %+v, %v", resp, err)
+	}
+	if tr, ok := drainTrade(a); !ok || tr.BaseID != "POS_QT" {
+		t.Fatalf("expected base_id from qt_position_id, got %+v", tr)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"BridgeApp/internal/config"
+	"BridgeApp/internal/idempotency"
+)
+
+// setIdempotencyConfig opens the idempotency key store on first use (or when its file moves),
+// applies the window otherwise, and hands the store to the gRPC server.
+func (a *App) setIdempotencyConfig(cfg idempotency.Config) {
+	if strings.TrimSpace(cfg.Path) == "" {
+		cfg.Path = config.DefaultIdempotencyPath()
+	}
+	a.idempotencyMux.Lock()
+	defer a.idempotencyMux.Unlock()
+	if a.idempotency != nil && (a.idempotency.Path() == cfg.Path || (a.idempotency.Path() == "" && strings.EqualFold(cfg.Path, "off"))) {
+		a.idempotency.SetWindow(cfg)
+		return
+	}
+	a.idempotency = idempotency.Open(cfg)
+	if n := a.idempotency.Len(); n > 0 {
+		log.Printf("Idempotency: %d key(s) loaded from %s", n, a.idempotency.Path())
+	}
+	a.grpcServer.SetIdempotencyStore(a.idempotency)
+}
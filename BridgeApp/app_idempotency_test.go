@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/idempotency"
+)
+
+func TestIdempotencyKeyReplaysTheOriginalResponse(t *testing.T) {
+	a := NewApp()
+	a.setIdempotencyConfig(idempotency.Config{Path: "off"})
+	key := fmt.Sprintf("retry-%d", time.Now().UnixNano())
+	trade := &trading.Trade{Id: "idem-1", BaseId: "BASE_IDEM", Action: "buy", Quantity: 2, IdempotencyKey: key}
+
+	first, err := a.grpcServer.SubmitTrade(context.Background(), trade)
+	if err != nil || first.Status != "success" || first.Metadata["idempotency_key"] != key {
+		t.Fatalf("SubmitTrade = %+v, %v", first, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok := drainTrade(a); !ok {
+			t.Fatalf("expected split trade %d in queue", i+1)
+		}
+	}
+
+	// A retry sending the key as metadata only
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("idempotency-key", key))
+	again, err := a.grpcServer.SubmitTrade(ctx, &trading.Trade{Id: "idem-1", BaseId: "BASE_IDEM", Action: "buy", Quantity: 2})
+	if err != nil || again.Status != "success" || again.Metadata["idempotent_replay"] != "true" || again.Metadata["trade_id"] != "idem-1" {
+		t.Fatalf("expected a replay, got %+v, %v", again, err)
+	}
+	if tr, ok := drainTrade(a); ok {
+		t.Fatalf("a replay must not hedge again, got %+v", tr)
+	}
+
+	// The same trade id under a new key is a new request
+	fresh, _ := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{Id: "idem-2", BaseId: "BASE_IDEM", Action: "buy", Quantity: 1, IdempotencyKey: key + "-b"})
+	if fresh.Metadata["idempotent_replay"] != "" {
+		t.Fatalf("a new key must be processed, got %+v", fresh)
+	}
+	if _, ok := drainTrade(a); !ok {
+		t.Fatal("expected the new trade in queue")
+	}
+}
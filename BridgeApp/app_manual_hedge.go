@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/feed"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/protocol"
+)
+
+// orphanTTL is how long an untracked MT5 ticket stays listed after its last position update.
+const orphanTTL = 10 * time.Minute
+
+// orphanTicket is an open MT5 position no BaseID tracks, as last reported by the EA.
+type orphanTicket struct {
+	volume    float64
+	profit    float64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// noteOrphanLocked records a position update of an untracked ticket and reports whether it is the
+// first one. mt5TicketMux must be held.
+func (a *App) noteOrphanLocked(key ticketKey, volume, profit float64) bool {
+	now := time.Now()
+	o, ok := a.orphanTickets[key]
+	if !ok {
+		o.firstSeen = now
+	}
+	o.volume, o.profit, o.lastSeen = roundVolume(volume), profit, now
+	a.orphanTickets[key] = o
+	return !ok
+}
+
+// operatorAction logs an action taken from the UI and adds it to the BaseID's timeline.
+func (a *App) operatorAction(action, baseID string, ticket uint64, detail string, err error) {
+	fields := map[string]interface{}{
+		"action":  action,
+		"base_id": baseID,
+		"ticket":  ticket,
+		"detail":  detail,
+	}
+	status := "ok"
+	if err != nil {
+		status = "failed"
+		fields["error"] = err.Error()
+		log.Printf("Operator: %s failed (base_id=%s ticket=%d %s): %v", action, baseID, ticket, detail, err)
+		blog.L().Warn("operator", "operator action failed", fields)
+	} else {
+		log.Printf("Operator: %s (base_id=%s ticket=%d %s)", action, baseID, ticket, detail)
+		blog.L().Info("operator", "operator action", fields)
+	}
+	a.recordFeed(feed.Event{Kind: feed.Operator, BaseID: baseID, Action: strings.ToUpper(action), Ticket: ticket, Status: status, Detail: detail})
+}
+
+// operatorResult is the answer of a manual hedge operation to the UI.
+func operatorResult(err error, fields map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	fields["success"] = err == nil
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	return fields
+}
+
+// OpenManualHedge queues a hedge for a new BaseID as if the add-on had sent an entry: side is the
+// Quantower side ("buy" or "sell") the EA hedges, lots is sent as hedge_lot. It is refused when the
+// EA of the target terminal has not negotiated hedge_lot.
+func (a *App) OpenManualHedge(instrument, side string, lots float64, account string) map[string]interface{} {
+	instrument, account = strings.TrimSpace(instrument), strings.TrimSpace(account)
+	side = strings.ToLower(strings.TrimSpace(side))
+	now := time.Now()
+	baseID := fmt.Sprintf("MANUAL-%d", now.UnixNano())
+	detail := fmt.Sprintf("%s %.2f lots %s account=%s", side, lots, instrument, account)
+
+	err := func() error {
+		if side != "buy" && side != "sell" {
+			return fmt.Errorf("side must be buy or sell, got %q", side)
+		}
+		if lots <= 0 {
+			return fmt.Errorf("lots must be positive, got %v", lots)
+		}
+		if instrument == "" {
+			return fmt.Errorf("instrument is required")
+		}
+		symbol, terminal, err := a.grpcServer.ManualEntry(baseID, baseID, instrument, account, side)
+		if err != nil {
+			return err
+		}
+		if !a.grpcServer.TerminalSupports(terminal, protocol.HedgeLot) {
+			return fmt.Errorf("the EA of terminal %s has not negotiated %s and would size the hedge itself", terminal, protocol.HedgeLot)
+		}
+		t := Trade{
+			ID:            baseID,
+			BaseID:        baseID,
+			Time:          now,
+			Action:        side,
+			Quantity:      1,
+			TotalQuantity: 1,
+			ContractNum:   1,
+			OrderType:     "MANUAL",
+			Instrument:    instrument,
+			AccountName:   account,
+			Origin:        "bridge_ui",
+			MT5Symbol:     symbol,
+			HedgeLot:      roundVolume(lots),
+			Terminal:      terminal,
+		}
+		if err := a.AddToTradeQueue(t); err != nil {
+			return err
+		}
+		a.AddToTradeHistory(t)
+		return nil
+	}()
+	a.operatorAction("open_manual_hedge", baseID, 0, detail, err)
+	if err != nil {
+		return operatorResult(err, nil)
+	}
+	return operatorResult(nil, map[string]interface{}{"baseId": baseID})
+}
+
+// CloseTicket closes one tracked MT5 ticket through the targeted CLOSE_HEDGE path. terminal may be
+// left empty when only one terminal tracks that ticket number.
+func (a *App) CloseTicket(ticket uint64, terminal string) map[string]interface{} {
+	a.mt5TicketMux.RLock()
+	_, baseID, tracked := a.lookupTicketLocked(terminal, ticket)
+	a.mt5TicketMux.RUnlock()
+
+	var md map[string]string
+	var err error
+	if ticket == 0 || !tracked {
+		err = fmt.Errorf("MT5 ticket %d is not tracked on one terminal; pick its terminal or re-link it to a BaseID first", ticket)
+	} else {
+		md, err = a.CloseHedge(map[string]interface{}{"BaseID": baseID, "MT5Ticket": ticket})
+	}
+	a.operatorAction("close_ticket", baseID, ticket, "", err)
+	return operatorResult(err, closeResultFields(md))
+}
+
+// CloseBaseID closes every MT5 ticket of a BaseID, cancelling its undelivered entries first.
+func (a *App) CloseBaseID(baseID string) map[string]interface{} {
+	baseID = strings.TrimSpace(baseID)
+	a.rehydrateTickets(baseID)
+	a.mt5TicketMux.RLock()
+	open := len(a.baseIdToTickets[baseID])
+	a.mt5TicketMux.RUnlock()
+
+	var md map[string]string
+	var err error
+	if baseID == "" || open == 0 {
+		err = fmt.Errorf("BaseID %q has no open MT5 tickets", baseID)
+	} else {
+		md, err = a.CloseHedge(map[string]interface{}{"BaseID": baseID, "ClosedHedgeQuantity": float64(open)})
+	}
+	a.operatorAction("close_base_id", baseID, 0, fmt.Sprintf("%d ticket(s)", open), err)
+	return operatorResult(err, closeResultFields(md))
+}
+
+func closeResultFields(md map[string]string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, v := range md {
+		out[k] = v
+	}
+	return out
+}
+
+// RelinkTicket attaches an orphaned (or wrongly linked) MT5 ticket of terminal to baseID so closes
+// of that BaseID include it. terminal may be left empty when only one terminal reports the ticket.
+func (a *App) RelinkTicket(ticket uint64, terminal, baseID string) map[string]interface{} {
+	baseID = strings.TrimSpace(baseID)
+	var prev string
+	err := func() error {
+		if ticket == 0 || baseID == "" {
+			return fmt.Errorf("a ticket and a BaseID are required")
+		}
+		a.mt5TicketMux.Lock()
+		defer a.mt5TicketMux.Unlock()
+		terminal, err := a.ticketTerminalLocked(ticket, terminal, baseID)
+		if err != nil {
+			return err
+		}
+		if pinned, ok := a.baseIdToTerminal[baseID]; ok && pinned != terminal {
+			return fmt.Errorf("BaseID %s hedges on terminal %s, not on %s", baseID, pinned, terminal)
+		}
+		a.baseIdToTerminal[baseID] = terminal
+		key := ticketKey{terminal: terminal, ticket: ticket}
+		prev = a.mt5TicketToBaseId[key]
+		if prev == baseID {
+			return fmt.Errorf("MT5 ticket %d is already linked to %s", ticket, baseID)
+		}
+		if list, ok := a.baseIdToTickets[prev]; ok && prev != "" {
+			filtered := make([]uint64, 0, len(list))
+			for _, tk := range list {
+				if tk != ticket {
+					filtered = append(filtered, tk)
+				}
+			}
+			if len(filtered) == 0 {
+				delete(a.baseIdToTickets, prev)
+			} else {
+				a.baseIdToTickets[prev] = filtered
+			}
+		}
+		a.mt5TicketToBaseId[key] = baseID
+		if !containsUint64(a.baseIdToTickets[baseID], ticket) {
+			a.baseIdToTickets[baseID] = append(a.baseIdToTickets[baseID], ticket)
+		}
+		st := a.ticketStateLocked(key)
+		if o, ok := a.orphanTickets[key]; ok {
+			if st.opened.IsZero() {
+				st.opened = o.firstSeen
+			}
+			if o.volume > 0 {
+				st.volume = o.volume
+			}
+			delete(a.orphanTickets, key)
+		}
+		return nil
+	}()
+	detail := "orphan"
+	if prev != "" {
+		detail = "was " + prev
+	}
+	a.operatorAction("relink_ticket", baseID, ticket, detail, err)
+	return operatorResult(err, map[string]interface{}{"baseId": baseID, "ticket": ticket, "previousBaseId": prev})
+}
+
+// ticketTerminalLocked returns the terminal a ticket being re-linked lives on: the given one, else
+// the only terminal reporting or tracking that ticket number, else the terminal of baseID.
+// The caller holds mt5TicketMux.
+func (a *App) ticketTerminalLocked(ticket uint64, terminal, baseID string) (string, error) {
+	if terminal = strings.TrimSpace(terminal); terminal != "" {
+		return terminal, nil
+	}
+	seen := map[string]bool{}
+	for key := range a.orphanTickets {
+		if key.ticket == ticket {
+			seen[key.terminal] = true
+		}
+	}
+	for key := range a.mt5TicketToBaseId {
+		if key.ticket == ticket {
+			seen[key.terminal] = true
+		}
+	}
+	switch len(seen) {
+	case 0:
+		return a.terminalOfLocked(baseID), nil
+	case 1:
+		for t := range seen {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("MT5 ticket %d exists on several terminals; pick one", ticket)
+}
+
+// GetOrphanTickets lists the open MT5 tickets no BaseID tracks, oldest first, so they can be
+// re-linked to a BaseID and then closed.
+func (a *App) GetOrphanTickets() []map[string]interface{} {
+	now := time.Now()
+	a.mt5TicketMux.Lock()
+	defer a.mt5TicketMux.Unlock()
+	out := make([]map[string]interface{}, 0, len(a.orphanTickets))
+	for key, o := range a.orphanTickets {
+		if _, tracked := a.mt5TicketToBaseId[key]; tracked || now.Sub(o.lastSeen) > orphanTTL {
+			delete(a.orphanTickets, key)
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"ticket":    key.ticket,
+			"terminal":  key.terminal,
+			"volume":    o.volume,
+			"profit":    o.profit,
+			"firstSeen": o.firstSeen.Format(time.RFC3339),
+			"lastSeen":  o.lastSeen.Format(time.RFC3339),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i]["firstSeen"].(string) < out[j]["firstSeen"].(string) })
+	return out
+}
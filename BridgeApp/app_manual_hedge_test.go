@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"BridgeApp/internal/feed"
+	grpcserver "BridgeApp/internal/grpc"
+	"BridgeApp/internal/protocol"
+)
+
+func TestManualHedgeOperations(t *testing.T) {
+	a := newDeadLetterApp(t)
+
+	if res := a.OpenManualHedge("NQ", "hold", 0.5, "Sim1"); res["success"] != false {
+		t.Fatalf("an unknown side must be refused, got %+v", res)
+	}
+	if res := a.OpenManualHedge("NQ", "Buy", 0.5, "Sim1"); res["success"] != false {
+		t.Fatalf("a terminal whose EA did not negotiate hedge_lot must be refused, got %+v", res)
+	}
+	if _, ok := drainTrade(a); ok {
+		t.Fatal("a refused manual hedge must not be queued")
+	}
+	negotiateEA(t, a, protocol.HedgeLot)
+	res := a.OpenManualHedge("NQ", "Buy", 0.5, "Sim1")
+	baseID, _ := res["baseId"].(string)
+	if res["success"] != true || baseID == "" {
+		t.Fatalf("OpenManualHedge = %+v", res)
+	}
+	trade, ok := drainTrade(a)
+	if !ok || trade.BaseID != baseID || trade.Action != "buy" || trade.HedgeLot != 0.5 || trade.Origin != "bridge_ui" || trade.AccountName != "Sim1" {
+		t.Fatalf("expected the manual entry to be queued, got %+v (ok=%v)", trade, ok)
+	}
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "success", ID: baseID, Ticket: 6001, Volume: 0.5})
+
+	// An orphan reported by MT5 is listed, re-linked to the manual BaseID and closed with it
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, Ticket: 6002, Volume: 0.2, Profit: -3})
+	if orphans := a.GetOrphanTickets(); len(orphans) != 1 || orphans[0]["ticket"] != uint64(6002) {
+		t.Fatalf("expected ticket 6002 listed as orphan, got %+v", orphans)
+	}
+	if res := a.CloseTicket(6002, ""); res["success"] != false {
+		t.Fatalf("closing an untracked ticket must be refused, got %+v", res)
+	}
+	if res := a.RelinkTicket(6002, "", baseID); res["success"] != true || res["previousBaseId"] != "" {
+		t.Fatalf("RelinkTicket = %+v", res)
+	}
+	if len(a.GetOrphanTickets()) != 0 || a.openTicketCount(baseID) != 2 {
+		t.Fatalf("re-linked ticket must join the BaseID's pool, open=%d", a.openTicketCount(baseID))
+	}
+
+	if res := a.CloseTicket(6001, ""); res["success"] != true || res["close_request_id"] == "" {
+		t.Fatalf("CloseTicket = %+v", res)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.Action != "CLOSE_HEDGE" || trade.MT5Ticket != 6001 {
+		t.Fatalf("expected CLOSE_HEDGE for 6001, got %+v (ok=%v)", trade, ok)
+	}
+	if res := a.CloseBaseID(baseID); res["success"] != true {
+		t.Fatalf("CloseBaseID = %+v", res)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.Action != "CLOSE_HEDGE" || trade.MT5Ticket != 6002 {
+		t.Fatalf("expected CLOSE_HEDGE for the re-linked 6002, got %+v (ok=%v)", trade, ok)
+	}
+
+	var actions []string
+	for _, e := range a.GetBaseTimeline(baseID) {
+		if e.Kind == feed.Operator {
+			actions = append(actions, e.Action)
+		}
+	}
+	want := []string{"OPEN_MANUAL_HEDGE", "RELINK_TICKET", "CLOSE_TICKET", "CLOSE_BASE_ID"}
+	if len(actions) != len(want) {
+		t.Fatalf("operator actions on the timeline = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("operator actions on the timeline = %v, want %v", actions, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/notify"
+)
+
+// notificationEvent is the Wails runtime event carrying a new notify.Notification to the UI.
+const notificationEvent = "bridge:notification"
+
+// emitEvent sends a runtime event to the UI. Without a Wails window (headless, tests) it does nothing.
+func (a *App) emitEvent(name string, data interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data)
+}
+
+// setNotificationsConfig applies the "notifications" section.
+func (a *App) setNotificationsConfig(cfg notify.Config) {
+	if err := a.notifications.Configure(cfg); err != nil {
+		log.Printf("ERROR: Notifications not configured: %v", err)
+	}
+}
+
+// notify records a critical event in the notification history, pushes it to the UI, which plays
+// its sound, and shows it as a native desktop notification.
+func (a *App) notify(n notify.Notification) {
+	n, ok := a.notifications.Add(n)
+	if !ok {
+		return
+	}
+	blog.L().Info("notifications", n.Title, map[string]interface{}{
+		"kind":    n.Kind,
+		"message": n.Message,
+		"base_id": n.BaseID,
+		"ticket":  n.Ticket,
+	})
+	a.emitEvent(notificationEvent, n)
+	if n.Desktop && a.ctx != nil {
+		go a.showDesktop(n)
+	}
+}
+
+// showDesktop runs the OS notifier for n; a failure leaves the notification in the history only.
+func (a *App) showDesktop(n notify.Notification) {
+	if err := notify.ShowDesktop(n); err != nil {
+		log.Printf("WARN: Desktop notification %q not shown: %v", n.Title, err)
+	}
+}
+
+// notifyMT5StreamLost warns when the EA goes away while hedges are still open in MT5.
+func (a *App) notifyMT5StreamLost() {
+	a.mt5TicketMux.RLock()
+	open := len(a.mt5TicketToBaseId)
+	bases := len(a.baseIdToTickets)
+	a.mt5TicketMux.RUnlock()
+	if open == 0 {
+		return
+	}
+	a.notify(notify.Notification{
+		Kind:     notify.MT5StreamLost,
+		Severity: notify.Critical,
+		Title:    "MT5 stream lost",
+		Message:  fmt.Sprintf("The MT5 EA disconnected with %d open hedge ticket(s) across %d position(s). Closes cannot reach MT5 until it reconnects.", open, bases),
+	})
+}
+
+// GetNotifications returns the notification history for the UI, newest first.
+func (a *App) GetNotifications() []notify.Notification {
+	return a.notifications.History()
+}
+
+// MarkNotificationsRead marks notification id read, or every notification when id is 0.
+func (a *App) MarkNotificationsRead(id uint64) int {
+	return a.notifications.MarkRead(id)
+}
+
+// ClearNotifications empties the notification history.
+func (a *App) ClearNotifications() {
+	a.notifications.Clear()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	grpcserver "BridgeApp/internal/grpc"
+	"BridgeApp/internal/notify"
+)
+
+func TestCriticalEventsReachNotificationHistory(t *testing.T) {
+	a := newDeadLetterApp(t)
+	a.setNotificationsConfig(notify.Config{Sounds: map[string]string{notify.Warning: "beep"}})
+
+	// MT5 going away with nothing open is not worth a notification
+	a.SetHedgebotActive(true)
+	a.SetHedgebotActive(false)
+	if n := len(a.GetNotifications()); n != 0 {
+		t.Fatalf("expected no notification without open hedges, got %d", n)
+	}
+
+	a.pushTicket("BASE_NF", 5001)
+	trackTicket(a, "BASE_NF", 5001)
+	a.SetHedgebotActive(true)
+	a.SetHedgebotActive(false)
+
+	// The EA repeats the position update of the orphan every 15 s; it is notified once
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, Ticket: 7777, Volume: 0.3})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: statusPositionUpdate, Ticket: 7777, Volume: 0.3})
+	a.HandleMT5TradeResult(&grpcserver.InternalMT5TradeResult{Status: "failed:10018", ID: "BASE_NF", Ticket: 5001, IsClose: true})
+
+	history := a.GetNotifications()
+	if len(history) != 3 {
+		t.Fatalf("expected 3 notifications, got %+v", history)
+	}
+	failed, orphan, lost := history[0], history[1], history[2]
+	if failed.Kind != notify.HedgeFailed || failed.Title != "Hedge close failed" || failed.BaseID != "BASE_NF" || failed.Sound != "alarm" {
+		t.Fatalf("unexpected hedge failure notification %+v", failed)
+	}
+	if orphan.Kind != notify.OrphanDetected || orphan.Ticket != 7777 || orphan.Sound != "beep" {
+		t.Fatalf("unexpected orphan notification %+v", orphan)
+	}
+	if lost.Kind != notify.MT5StreamLost || !lost.Desktop {
+		t.Fatalf("unexpected stream lost notification %+v", lost)
+	}
+
+	if a.MarkNotificationsRead(0) != 3 || a.notifications.Unread() != 0 {
+		t.Fatal("MarkNotificationsRead(0) must mark every notification read")
+	}
+	a.ClearNotifications()
+	if len(a.GetNotifications()) != 0 {
+		t.Fatal("ClearNotifications must empty the history")
+	}
+}
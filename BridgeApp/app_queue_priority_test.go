@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/queue"
+)
+
+func TestCloseHedgeOvertakesQueuedEntries(t *testing.T) {
+	a := NewApp()
+	for i := 1; i <= 5; i++ {
+		if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+			Id: fmt.Sprintf("burst-%d", i), BaseId: fmt.Sprintf("BASE_BURST_%d", i), Action: "buy", Quantity: 1, Instrument: "NQZ5",
+		}); err != nil {
+			t.Fatalf("SubmitTrade: %v", err)
+		}
+	}
+
+	trackTicket(a, "BASE_OPEN", 8001)
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{
+		"BaseID": "BASE_OPEN", "ClosedHedgeQuantity": 1.0, "MT5Ticket": float64(8001),
+	}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+
+	if trade, ok := drainTrade(a); !ok || trade.Action != "CLOSE_HEDGE" || trade.BaseID != "BASE_OPEN" {
+		t.Fatalf("expected CLOSE_HEDGE ahead of the entry burst, got %+v (ok=%v)", trade, ok)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.BaseID != "BASE_BURST_1" {
+		t.Fatalf("expected entries to follow in order, got %+v (ok=%v)", trade, ok)
+	}
+
+	stats := a.GetQueueStats()["default"].([]map[string]interface{})
+	if stats[0]["class"] != "close" || stats[0]["delivered"] != uint64(1) || stats[2]["depth"] != 4 {
+		t.Fatalf("unexpected queue stats: %+v", stats)
+	}
+}
+
+func TestQueueOverflowSpillsToDiskInsteadOfRejecting(t *testing.T) {
+	a := NewApp()
+	a.setQueueConfig(queue.Config{EntryCapacity: 2, SpillDir: t.TempDir()})
+
+	for i := 1; i <= 4; i++ {
+		resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+			Id: fmt.Sprintf("spill-%d", i), BaseId: fmt.Sprintf("BASE_SPILL_%d", i), Action: "buy", Quantity: 1, Instrument: "NQZ5",
+		})
+		if err != nil || resp.Status != "success" {
+			t.Fatalf("SubmitTrade %d = %+v, %v", i, resp, err)
+		}
+	}
+	if a.GetQueueSize() != 4 {
+		t.Fatalf("expected 4 queued trades (2 spilled), got %d", a.GetQueueSize())
+	}
+	for i := 1; i <= 4; i++ {
+		if trade, ok := drainTrade(a); !ok || trade.BaseID != fmt.Sprintf("BASE_SPILL_%d", i) {
+			t.Fatalf("expected BASE_SPILL_%d in order, got %+v (ok=%v)", i, trade, ok)
+		}
+	}
+}
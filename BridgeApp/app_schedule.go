@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	grpcserver "BridgeApp/internal/grpc"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/schedule"
+)
+
+const (
+	// scheduleInterval is how often scheduled flattens and close_only windows are evaluated.
+	scheduleInterval = 5 * time.Second
+	// upcomingHorizon is how far ahead GetSchedule lists actions (a weekend flatten on Friday
+	// is visible from Wednesday).
+	upcomingHorizon = 72 * time.Hour
+
+	modeNormal    = "normal"
+	modeCloseOnly = string(schedule.CloseOnly)
+)
+
+// setSchedule installs the scheduled rules. Flattens fire from now on; times already passed are
+// not caught up.
+func (a *App) setSchedule(sc *schedule.Schedule) {
+	a.scheduleMux.Lock()
+	a.schedule = sc
+	a.scheduleChecked = time.Now()
+	a.scheduleMux.Unlock()
+	a.grpcServer.SetSchedule(sc)
+	log.Printf("Schedule: %d rule(s) installed (timezone %s)", sc.Len(), sc.Location())
+}
+
+// startScheduler starts evaluating the schedule once.
+func (a *App) startScheduler() {
+	a.scheduler.Do(func() {
+		go func() {
+			ticker := time.NewTicker(scheduleInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				a.runSchedule(now)
+			}
+		}()
+	})
+}
+
+// runSchedule fires the flattens due since the previous run and switches the bridge mode to
+// close_only while a close_only window is open.
+func (a *App) runSchedule(now time.Time) {
+	a.scheduleMux.Lock()
+	sc, from := a.schedule, a.scheduleChecked
+	if now.After(from) {
+		a.scheduleChecked = now
+	}
+	a.scheduleMux.Unlock()
+
+	for _, o := range sc.Due(from, now) {
+		a.flatten(o)
+	}
+	a.updateBridgeMode(sc.Active(now))
+}
+
+// flatten enqueues a targeted CLOSE_HEDGE for every open hedge ticket of the accounts o applies to,
+// after cancelling their entries still queued, held or waiting for a retry.
+func (a *App) flatten(o schedule.Occurrence) {
+	type target struct {
+		baseID, account string
+		ticket          uint64
+	}
+	var targets []target
+	var baseIDs []string
+	a.mt5TicketMux.RLock()
+	for baseID, acct := range a.baseIdToAccount {
+		if o.Matches(acct) {
+			baseIDs = append(baseIDs, baseID)
+		}
+	}
+	for baseID, tickets := range a.baseIdToTickets {
+		acct := a.baseIdToAccount[baseID]
+		if !o.Matches(acct) {
+			continue
+		}
+		for _, tk := range tickets {
+			targets = append(targets, target{baseID: baseID, account: acct, ticket: tk})
+		}
+	}
+	a.mt5TicketMux.RUnlock()
+
+	cancelled := 0
+	for _, baseID := range baseIDs {
+		cancelled += a.cancelQueuedEntries(baseID, 0)
+	}
+	cancelled += len(a.grpcServer.DropHeldTrades(func(_, account string) bool { return o.Matches(account) }, 0, "scheduled flatten "+o.Rule))
+
+	var failed []string
+	for _, t := range targets {
+		_, err := a.CloseHedge(map[string]interface{}{
+			"BaseID":        t.baseID,
+			"MT5Ticket":     t.ticket,
+			"NTAccountName": t.account,
+			"ClosureReason": "scheduled_flatten",
+		})
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%d: %v", t.ticket, err))
+		}
+	}
+
+	summary := fmt.Sprintf("%s: %d ticket(s) closing", o.Rule, len(targets)-len(failed))
+	if cancelled > 0 {
+		summary += fmt.Sprintf(", %d undelivered entries cancelled", cancelled)
+	}
+	if len(failed) > 0 {
+		summary += fmt.Sprintf(", %d failed", len(failed))
+	}
+	log.Printf("Schedule: flatten %s", summary)
+	fields := map[string]interface{}{
+		"rule":      o.Rule,
+		"at":        o.At.Format(time.RFC3339),
+		"accounts":  strings.Join(o.Accounts, ","),
+		"tickets":   len(targets),
+		"cancelled": cancelled,
+	}
+	if len(failed) > 0 {
+		fields["failed"] = failed
+		blog.L().Warn("schedule", "scheduled flatten", fields)
+	} else {
+		blog.L().Info("schedule", "scheduled flatten", fields)
+	}
+
+	if a.grpcServer != nil {
+		a.grpcServer.NotifyAddonStreams(Trade{
+			ID:            fmt.Sprintf("flatten_%d", o.At.Unix()),
+			Action:        "SCHEDULED_FLATTEN",
+			OrderType:     strings.ToUpper(string(schedule.Flatten)),
+			AccountName:   strings.Join(o.Accounts, ","),
+			Quantity:      float64(len(targets) - len(failed)),
+			NTTradeResult: summary,
+			Time:          time.Now(),
+		})
+	}
+}
+
+// updateBridgeMode records the close_only windows open now and reports mode changes.
+func (a *App) updateBridgeMode(active []schedule.Occurrence) {
+	mode := modeNormal
+	if len(active) > 0 {
+		mode = modeCloseOnly
+	}
+	a.scheduleMux.Lock()
+	prev := a.bridgeMode
+	if prev == "" {
+		prev = modeNormal
+	}
+	a.bridgeMode, a.modeWindows = mode, active
+	a.scheduleMux.Unlock()
+	if prev == mode {
+		return
+	}
+
+	rules := make([]string, 0, len(active))
+	for _, o := range active {
+		rules = append(rules, fmt.Sprintf("%s until %s", o.Rule, o.Until.Format("15:04")))
+	}
+	detail := strings.Join(rules, "; ")
+	log.Printf("Schedule: bridge mode %s -> %s %s", prev, mode, detail)
+	blog.L().Info("schedule", "bridge mode changed", map[string]interface{}{"from": prev, "to": mode, "windows": detail})
+	a.publishStatus(grpcserver.StatusMode, strings.TrimSpace(mode+" "+detail))
+	if a.grpcServer != nil {
+		a.grpcServer.NotifyAddonStreams(Trade{
+			ID:            fmt.Sprintf("mode_%d", time.Now().UnixNano()),
+			Action:        "BRIDGE_MODE",
+			OrderType:     strings.ToUpper(mode),
+			NTTradeResult: detail,
+			Time:          time.Now(),
+		})
+	}
+}
+
+// BridgeMode returns "close_only" while a scheduled close_only window is open, else "normal".
+func (a *App) BridgeMode() string {
+	a.scheduleMux.Lock()
+	defer a.scheduleMux.Unlock()
+	if a.bridgeMode == "" {
+		return modeNormal
+	}
+	return a.bridgeMode
+}
+
+// GetSchedule lists the bridge mode, the open close_only windows and the scheduled actions of
+// the next three days for the UI.
+func (a *App) GetSchedule() map[string]interface{} {
+	a.scheduleMux.Lock()
+	sc, windows := a.schedule, a.modeWindows
+	a.scheduleMux.Unlock()
+	return map[string]interface{}{
+		"mode":     a.BridgeMode(),
+		"timezone": sc.Location().String(),
+		"active":   occurrencesForUI(windows),
+		"upcoming": occurrencesForUI(sc.Upcoming(time.Now(), upcomingHorizon)),
+	}
+}
+
+func occurrencesForUI(list []schedule.Occurrence) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, o := range list {
+		out = append(out, map[string]interface{}{
+			"rule":     o.Rule,
+			"action":   string(o.Action),
+			"at":       o.At.Format(time.RFC3339),
+			"from":     o.From.Format(time.RFC3339),
+			"until":    o.Until.Format(time.RFC3339),
+			"accounts": o.Accounts,
+		})
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/schedule"
+)
+
+func TestScheduledFlattenClosesMatchingAccounts(t *testing.T) {
+	a := NewApp()
+	at := time.Now().Add(time.Minute).Truncate(time.Minute)
+	sc, err := schedule.New(schedule.Config{Rules: []schedule.Rule{
+		{Name: "eod", Action: "flatten", Accounts: []string{"Apex-*"}, At: at.Format("15:04")},
+	}}, time.Local)
+	if err != nil {
+		t.Fatalf("schedule.New: %v", err)
+	}
+	a.setSchedule(sc)
+	a.recordInstrumentAccount("BASE_APEX", "NQZ5", "Apex-1")
+	a.recordInstrumentAccount("BASE_SIM", "NQZ5", "Sim101")
+	openTickets(t, a, "BASE_APEX", 601, 602)
+	openTickets(t, a, "BASE_SIM", 701)
+
+	a.runSchedule(at.Add(-time.Second))
+	if _, ok := drainTrade(a); ok {
+		t.Fatal("nothing may close before the rule's time")
+	}
+	// Entries of flattened accounts still waiting to reach MT5 are cancelled
+	if err := a.AddToTradeQueue(Trade{ID: "e1", BaseID: "BASE_APEX_NEW", Action: "buy", Quantity: 1, AccountName: "Apex-2"}); err != nil {
+		t.Fatalf("AddToTradeQueue: %v", err)
+	}
+	a.grpcServer.HoldTrade(Trade{ID: "h1", BaseID: "BASE_APEX_HELD", Action: "buy", Quantity: 1, AccountName: "Apex-3"}, "test", time.Time{})
+	a.grpcServer.HoldTrade(Trade{ID: "h2", BaseID: "BASE_SIM_HELD", Action: "buy", Quantity: 1, AccountName: "Sim101"}, "test", time.Time{})
+	a.runSchedule(at)
+	closed := map[uint64]bool{}
+	for {
+		tr, ok := drainTrade(a)
+		if !ok {
+			break
+		}
+		if tr.Action != "CLOSE_HEDGE" || tr.BaseID != "BASE_APEX" {
+			t.Fatalf("unexpected trade: %+v", tr)
+		}
+		closed[tr.MT5Ticket] = true
+	}
+	if len(closed) != 2 || !closed[601] || !closed[602] {
+		t.Fatalf("expected tickets 601 and 602 to close, got %v", closed)
+	}
+	if n := a.grpcServer.HeldTradeCount(); n != 1 {
+		t.Fatalf("only the held entry of Sim101 may stay held, got %d", n)
+	}
+	a.runSchedule(at.Add(scheduleInterval))
+	if tr, ok := drainTrade(a); ok {
+		t.Fatalf("the flatten must fire once, got %+v", tr)
+	}
+}
+
+func TestCloseOnlyWindowRejectsEntries(t *testing.T) {
+	a := NewApp()
+	now := time.Now()
+	sc, err := schedule.New(schedule.Config{Rules: []schedule.Rule{
+		{Name: "CPI", Action: "close_only", Accounts: []string{"Sim101"}, Times: []string{now.Format("2006-01-02 15:04")}},
+	}}, time.Local)
+	if err != nil {
+		t.Fatalf("schedule.New: %v", err)
+	}
+	a.setSchedule(sc)
+	a.runSchedule(now)
+	if mode := a.BridgeMode(); mode != modeCloseOnly {
+		t.Fatalf("unexpected bridge mode %q", mode)
+	}
+
+	resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{Id: "t1", BaseId: "BASE_CPI", Action: "buy", Quantity: 1, AccountName: "Sim101"})
+	if err != nil || resp.Status != "rejected" || resp.Metadata["entry_block"] != "close_only" || resp.Metadata["schedule_rule"] != "CPI" {
+		t.Fatalf("entry must be rejected: %+v, %v", resp, err)
+	}
+	resp, err = a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{Id: "t2", BaseId: "BASE_OTHER", Action: "buy", Quantity: 1, AccountName: "Sim102"})
+	if err != nil || resp.Status == "rejected" {
+		t.Fatalf("other accounts must pass: %+v, %v", resp, err)
+	}
+
+	s := a.GetSchedule()
+	if s["mode"] != modeCloseOnly || len(s["active"].([]map[string]interface{})) != 1 {
+		t.Fatalf("unexpected schedule view: %v", s)
+	}
+	a.runSchedule(now.Add(5 * time.Minute))
+	if mode := a.BridgeMode(); mode != modeNormal {
+		t.Fatalf("the mode must return to normal after the window, got %q", mode)
+	}
+}
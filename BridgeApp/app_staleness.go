@@ -0,0 +1,143 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/queue"
+)
+
+// Reasons a queued entry is not delivered to MT5.
+const (
+	staleClosed = "closed_before_delivery" // Quantower closed the position while the entry waited
+	staleMaxAge = "max_age_exceeded"       // the entry waited longer than entry_max_age
+)
+
+// cancelQueuedEntries cancels up to qty entries of baseID that are still waiting for a retry, held
+// by the bridge or in the queue (all of them when qty <= 0), so a Quantower close that overtakes its
+// own entry does not leave MT5 opening a hedge for a dead position. It returns the number
+// cancelled; queued entries are discarded when they are dequeued.
+func (a *App) cancelQueuedEntries(baseID string, qty int) int {
+	retries := a.cancelPendingRetries(baseID, qty, func(t Trade) bool { return queue.Classify(t.Action) == queue.ClassEntry })
+	for _, t := range retries {
+		log.Printf("Queue: Close for BaseID %s cancels retry of entry %s", baseID, t.ID)
+	}
+	if qty > 0 {
+		if qty -= len(retries); qty <= 0 {
+			return len(retries)
+		}
+	}
+	held := a.grpcServer.DropHeldTrades(func(b, _ string) bool { return b == baseID }, qty, "position closed before release")
+	done := len(retries) + len(held)
+	if qty > 0 {
+		if qty -= len(held); qty <= 0 {
+			return done
+		}
+	}
+
+	a.mt5TicketMux.RLock()
+	terminal, ok := a.baseIdToTerminal[baseID]
+	a.mt5TicketMux.RUnlock()
+	if !ok {
+		terminal = a.grpcServer.DefaultTerminal()
+	}
+	queued := a.queueFor(terminal).CountKey(baseID, queue.ClassEntry)
+
+	a.mt5TicketMux.Lock()
+	n := queued - a.cancelledEntries[baseID]
+	if qty > 0 && n > qty {
+		n = qty
+	}
+	if n > 0 {
+		a.cancelledEntries[baseID] += n
+	}
+	a.mt5TicketMux.Unlock()
+
+	if n <= 0 {
+		return done
+	}
+	log.Printf("Queue: Close for BaseID %s cancels %d undelivered entries on terminal %s", baseID, n, terminal)
+	return done + n
+}
+
+// takeCancelledEntry consumes one cancellation recorded for baseID.
+func (a *App) takeCancelledEntry(baseID string) bool {
+	a.mt5TicketMux.Lock()
+	defer a.mt5TicketMux.Unlock()
+	n := a.cancelledEntries[baseID]
+	if n == 0 {
+		return false
+	}
+	if n == 1 {
+		delete(a.cancelledEntries, baseID)
+	} else {
+		a.cancelledEntries[baseID] = n - 1
+	}
+	return true
+}
+
+// staleEntry validates an entry as it leaves the queue and handles it when it must not reach MT5:
+// its BaseID was closed in the meantime, or it waited longer than entry_max_age.
+func (a *App) staleEntry(terminal string, t Trade, waited time.Duration) bool {
+	if queue.Classify(t.Action) != queue.ClassEntry {
+		return false
+	}
+	if a.takeCancelledEntry(strings.TrimSpace(t.BaseID)) {
+		a.reportStaleEntry(terminal, t, staleClosed, queue.StaleDrop, waited)
+		return true
+	}
+
+	a.tradeQueuesMux.RLock()
+	cfg := a.tradeQueueCfg
+	a.tradeQueuesMux.RUnlock()
+	ttl, _ := cfg.EntryTTL()
+	if ttl == 0 || waited <= ttl {
+		return false
+	}
+	policy := cfg.StalePolicy()
+	if policy == queue.StaleHold {
+		a.grpcServer.HoldTrade(t, staleMaxAge, time.Now().Add(-waited))
+	}
+	a.reportStaleEntry(terminal, t, staleMaxAge, policy, waited)
+	return true
+}
+
+// EntryMaxAge returns entry_max_age, 0 when queued entries never go stale.
+func (a *App) EntryMaxAge() time.Duration {
+	a.tradeQueuesMux.RLock()
+	cfg := a.tradeQueueCfg
+	a.tradeQueuesMux.RUnlock()
+	ttl, _ := cfg.EntryTTL()
+	return ttl
+}
+
+// ReleaseHeldTrade sends a held entry (one held as stale included) to MT5 on an operator's request.
+func (a *App) ReleaseHeldTrade(tradeID string) map[string]interface{} {
+	err := a.grpcServer.ReleaseHeldTrade(tradeID)
+	a.operatorAction("release_held_trade", "", 0, tradeID, err)
+	return operatorResult(err, map[string]interface{}{"tradeId": tradeID})
+}
+
+// reportStaleEntry logs a skipped entry and tells the addon, which still holds the Quantower side.
+func (a *App) reportStaleEntry(terminal string, t Trade, reason, outcome string, waited time.Duration) {
+	log.Printf("WARN: Stale entry %s (base_id=%s) for terminal %s not sent to MT5: %s after %s in queue (%s)",
+		t.ID, t.BaseID, terminal, reason, waited.Truncate(time.Millisecond), outcome)
+	blog.L().Warn("queue", "stale entry not delivered", map[string]interface{}{
+		"trade_id":   t.ID,
+		"base_id":    t.BaseID,
+		"instrument": t.Instrument,
+		"account":    t.AccountName,
+		"terminal":   terminal,
+		"reason":     reason,
+		"outcome":    outcome,
+		"waited":     waited.String(),
+	})
+
+	notice := t
+	notice.Action = "HEDGE_SKIPPED"
+	notice.OrderType = "STALE_ENTRY"
+	notice.NTTradeResult = reason
+	a.grpcServer.NotifyAddonStreams(notice)
+}
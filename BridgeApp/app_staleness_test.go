@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/queue"
+	"BridgeApp/internal/symbols"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestCloseBeforeDeliveryCancelsQueuedEntry(t *testing.T) {
+	a := NewApp()
+	for _, id := range []string{"dead-1", "live-1"} {
+		if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+			Id: id, BaseId: "BASE_" + id, Action: "buy", Quantity: 1, Instrument: "NQZ5",
+		}); err != nil {
+			t.Fatalf("SubmitTrade: %v", err)
+		}
+	}
+
+	// Quantower closes BASE_dead-1 while MT5 is offline: no ticket exists yet, the entry is cancelled
+	if _, err := a.grpcServer.SubmitCloseHedge(context.Background(), &trading.HedgeCloseNotification{
+		BaseId: "BASE_dead-1", ClosedHedgeQuantity: 1,
+	}); err != nil {
+		t.Fatalf("SubmitCloseHedge: %v", err)
+	}
+
+	trade, ok := drainTrade(a)
+	if !ok || trade.BaseID != "BASE_live-1" {
+		t.Fatalf("expected only the live entry to be delivered, got %+v (ok=%v)", trade, ok)
+	}
+	if _, ok := drainTrade(a); ok || a.GetQueueSize() != 0 {
+		t.Fatalf("expected an empty queue, size=%d", a.GetQueueSize())
+	}
+	if len(a.cancelledEntries) != 0 {
+		t.Fatalf("cancellation should be consumed, got %v", a.cancelledEntries)
+	}
+}
+
+func TestEntriesOlderThanMaxAgeAreDroppedOrHeld(t *testing.T) {
+	a := NewApp()
+	a.setQueueConfig(queue.Config{EntryMaxAge: "20ms", SpillDir: "off"})
+	submit := func(id string) {
+		if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+			Id: id, BaseId: "BASE_" + id, Action: "sell", Quantity: 1, Instrument: "NQZ5",
+		}); err != nil {
+			t.Fatalf("SubmitTrade: %v", err)
+		}
+	}
+
+	submit("aged-1")
+	time.Sleep(40 * time.Millisecond)
+	if trade, ok := drainTrade(a); ok {
+		t.Fatalf("expected the aged entry to be dropped, got %+v", trade)
+	}
+
+	a.setQueueConfig(queue.Config{EntryMaxAge: "20ms", StaleEntryPolicy: "hold", SpillDir: "off"})
+	submit("aged-2")
+	time.Sleep(40 * time.Millisecond)
+	if trade, ok := drainTrade(a); ok {
+		t.Fatalf("expected the aged entry to be held, got %+v", trade)
+	}
+	if n := a.grpcServer.HeldTradeCount(); n != 1 {
+		t.Fatalf("expected 1 held trade, got %d", n)
+	}
+
+	// A reload re-checks the age from when the entry was first queued: still stale, still held
+	installSymbolMap(t, a, symbols.Config{})
+	if n := a.grpcServer.HeldTradeCount(); n != 1 {
+		t.Fatalf("a stale entry must stay held across a reload, got %d held", n)
+	}
+	if trade, ok := drainTrade(a); ok {
+		t.Fatalf("a stale entry must not be re-queued by a reload, got %+v", trade)
+	}
+
+	// Only an operator release sends it
+	if res := a.ReleaseHeldTrade("aged-2"); res["success"] != true {
+		t.Fatalf("ReleaseHeldTrade = %+v", res)
+	}
+	if trade, ok := drainTrade(a); !ok || trade.ID != "aged-2" {
+		t.Fatalf("expected the released entry queued, got %+v (ok=%v)", trade, ok)
+	}
+}
+
+func TestReparkedEntryKeepsItsAge(t *testing.T) {
+	a := NewApp()
+	a.setQueueConfig(queue.Config{EntryMaxAge: "20ms", StaleEntryPolicy: "hold", SpillDir: "off"})
+	if _, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "aged-3", BaseId: "BASE_aged-3", Action: "buy", Quantity: 1, Instrument: "ESZ5",
+	}); err != nil {
+		t.Fatalf("SubmitTrade: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if trade, ok := drainTrade(a); ok {
+		t.Fatalf("expected the aged entry to be held, got %+v", trade)
+	}
+
+	// A longer entry_max_age lets the reload release it, but its instrument is now held: parked again
+	a.setQueueConfig(queue.Config{EntryMaxAge: "1h", StaleEntryPolicy: "hold", SpillDir: "off"})
+	installSymbolMap(t, a, symbols.Config{UnknownPolicy: symbols.PolicyHold})
+	if n := a.grpcServer.HeldTradeCount(); n != 1 {
+		t.Fatalf("expected the entry parked again, got %d held", n)
+	}
+
+	// Parking again must not reset the age it is checked against
+	a.setQueueConfig(queue.Config{EntryMaxAge: "20ms", StaleEntryPolicy: "hold", SpillDir: "off"})
+	installSymbolMap(t, a, symbols.Config{UnknownPolicy: symbols.PolicyHold, Rules: []symbols.Rule{{Match: "ES*", Symbol: "US500"}}})
+	if n := a.grpcServer.HeldTradeCount(); n != 1 {
+		t.Fatalf("a re-parked entry must still count as stale, got %d held", n)
+	}
+	if trade, ok := drainTrade(a); ok {
+		t.Fatalf("a re-parked stale entry must not be re-queued by a reload, got %+v", trade)
+	}
+}
+
+func TestStaleEntryIsReportedToAddonStream(t *testing.T) {
+	a := NewApp()
+	a.setQueueConfig(queue.Config{EntryMaxAge: "10ms", SpillDir: "off"})
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	trading.RegisterStreamingServiceServer(srv, a.grpcServer)
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addon, err := trading.NewStreamingServiceClient(conn).TradingStream(ctx)
+	if err != nil {
+		t.Fatalf("TradingStream: %v", err)
+	}
+	for !a.IsAddonConnected() {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := a.grpcServer.SubmitTrade(ctx, &trading.Trade{
+		Id: "late-1", BaseId: "BASE_LATE", Action: "buy", Quantity: 1, Instrument: "NQZ5",
+	}); err != nil {
+		t.Fatalf("SubmitTrade: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	drainTrade(a)
+
+	got, err := addon.Recv()
+	if err != nil {
+		t.Fatalf("addon Recv: %v", err)
+	}
+	if got.Action != "HEDGE_SKIPPED" || got.BaseId != "BASE_LATE" || got.NtTradeResult != staleMaxAge {
+		t.Fatalf("unexpected addon notice: %+v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	grpcserver "BridgeApp/internal/grpc"
+)
+
+// publishStatus pushes a status change to the StatusStream subscribers.
+func (a *App) publishStatus(reason, message string) {
+	if a.grpcServer != nil {
+		a.grpcServer.PublishStatus(reason, message)
+	}
+}
+
+// recordError remembers msg as the last error and publishes it.
+func (a *App) recordError(msg string) {
+	msg = strings.TrimSpace(msg)
+	a.statusMux.Lock()
+	a.lastError, a.lastErrorAt = msg, time.Now()
+	a.statusMux.Unlock()
+	a.publishStatus(grpcserver.StatusError, msg)
+}
+
+// StatusDetail reports the bridge mode, pending close requests and last error for StatusStream.
+func (a *App) StatusDetail() grpcserver.StatusDetail {
+	a.statusMux.Lock()
+	d := grpcserver.StatusDetail{LastError: a.lastError, LastErrorAt: a.lastErrorAt}
+	a.statusMux.Unlock()
+	d.Mode = a.BridgeMode()
+	if a.closeRequests != nil {
+		d.PendingCloses = a.closeRequests.Pending()
+	}
+	return d
+}
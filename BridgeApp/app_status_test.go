@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"BridgeApp/internal/deadletter"
+	grpcserver "BridgeApp/internal/grpc"
+	trading "BridgeApp/internal/grpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestStatusStreamPushesChanges(t *testing.T) {
+	a := NewApp()
+	a.setDeadLetterConfig(deadletter.Config{Path: "off"})
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	trading.RegisterTradingServiceServer(srv, a.grpcServer)
+	trading.RegisterStreamingServiceServer(srv, a.grpcServer)
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := trading.NewStreamingServiceClient(conn).StatusStream(ctx)
+	if err != nil {
+		t.Fatalf("StatusStream: %v", err)
+	}
+	initial, err := stream.Recv()
+	if err != nil || initial.Reason != grpcserver.StatusInitial || initial.Status != "degraded" || initial.BridgeMode != "normal" {
+		t.Fatalf("initial status = %+v, %v", initial, err)
+	}
+	next := func(reason string) *trading.HealthResponse {
+		t.Helper()
+		for {
+			st, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("waiting for %s status: %v", reason, err)
+			}
+			if st.Reason == reason {
+				if st.Sequence <= initial.Sequence {
+					t.Fatalf("sequence must increase, got %d after %d", st.Sequence, initial.Sequence)
+				}
+				return st
+			}
+		}
+	}
+
+	a.SetHedgebotActive(true)
+	if st := next(grpcserver.StatusMT5Connected); !st.Mt5Connected || st.Status != "healthy" {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	a.AddToTradeQueue(Trade{ID: "st-1", BaseID: "BASE_ST", Action: "buy", Quantity: 1})
+	if st := next(grpcserver.StatusQueue); st.QueueSize != 1 || st.QueueSizes[a.grpcServer.DefaultTerminal()] != 1 {
+		t.Fatalf("unexpected queue status %+v", st)
+	}
+
+	a.DeadLetterTrade(Trade{ID: "st-2", BaseID: "BASE_ST", Action: "buy"}, "conversion_failed", "bad payload", "")
+	if st := next(grpcserver.StatusError); st.LastError == "" || st.LastErrorAt == 0 {
+		t.Fatalf("unexpected error status %+v", st)
+	}
+}
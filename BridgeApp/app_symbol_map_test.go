@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/symbols"
+)
+
+func installSymbolMap(t *testing.T, a *App, cfg symbols.Config) {
+	t.Helper()
+	m, err := symbols.New(cfg)
+	if err != nil {
+		t.Fatalf("symbols.New: %v", err)
+	}
+	a.grpcServer.SetSymbolMap(m)
+}
+
+func TestSubmitTradeMapsInstrumentToMT5Symbol(t *testing.T) {
+	a := NewApp()
+	installSymbolMap(t, a, symbols.Config{
+		Suffix: ".r",
+		Rules:  []symbols.Rule{{Match: "NQ*", Symbol: "NAS100"}},
+	})
+
+	resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "map-1", BaseId: "BASE_MAP", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Sim101",
+	})
+	if err != nil || resp.Status != "success" {
+		t.Fatalf("SubmitTrade = %+v, %v", resp, err)
+	}
+	if resp.Metadata["mt5_symbol"] != "NAS100.r" {
+		t.Fatalf("expected mt5_symbol metadata NAS100.r, got %v", resp.Metadata)
+	}
+
+	trade, ok := drainTrade(a)
+	if !ok {
+		t.Fatalf("expected mapped trade in queue")
+	}
+	if trade.Instrument != "NQZ5" || trade.MT5Symbol != "NAS100.r" {
+		t.Fatalf("expected instrument kept and symbol mapped, got instrument=%s mt5_symbol=%s", trade.Instrument, trade.MT5Symbol)
+	}
+}
+
+func TestSubmitTradeRejectsUnknownInstrument(t *testing.T) {
+	a := NewApp()
+	installSymbolMap(t, a, symbols.Config{
+		UnknownPolicy: symbols.PolicyReject,
+		Rules:         []symbols.Rule{{Match: "NQ*", Symbol: "NAS100"}},
+	})
+
+	resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "map-2", BaseId: "BASE_REJECT", Action: "sell", Quantity: 1, Instrument: "CLZ5",
+	})
+	if err != nil {
+		t.Fatalf("rejection must be reported in-band, got error %v", err)
+	}
+	if resp.Status != "rejected" || resp.Metadata["symbol_status"] != "unmapped" {
+		t.Fatalf("expected rejected response, got %+v", resp)
+	}
+	if _, ok := drainTrade(a); ok {
+		t.Fatalf("rejected trade must not be enqueued")
+	}
+}
+
+func TestSubmitTradeHoldsUntilMappingAdded(t *testing.T) {
+	a := NewApp()
+	installSymbolMap(t, a, symbols.Config{UnknownPolicy: symbols.PolicyHold})
+
+	resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "map-3", BaseId: "BASE_HOLD", Action: "buy", Quantity: 2, Instrument: "ESZ5",
+	})
+	if err != nil || resp.Status != "held" {
+		t.Fatalf("expected held response, got %+v, %v", resp, err)
+	}
+	if a.grpcServer.HeldTradeCount() != 1 {
+		t.Fatalf("expected one held trade, got %d", a.grpcServer.HeldTradeCount())
+	}
+	if _, ok := drainTrade(a); ok {
+		t.Fatalf("held trade must not be enqueued")
+	}
+
+	installSymbolMap(t, a, symbols.Config{
+		UnknownPolicy: symbols.PolicyHold,
+		Rules:         []symbols.Rule{{Match: "ES*", Symbol: "US500"}},
+	})
+	if a.grpcServer.HeldTradeCount() != 0 {
+		t.Fatalf("expected held trade to be released")
+	}
+	for i := 0; i < 2; i++ {
+		trade, ok := drainTrade(a)
+		if !ok || trade.MT5Symbol != "US500" || trade.BaseID != "BASE_HOLD" {
+			t.Fatalf("expected released split %d with mapped symbol, got %+v (ok=%v)", i+1, trade, ok)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/config"
+	"BridgeApp/internal/feed"
+	grpcserver "BridgeApp/internal/grpc"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/queue"
+)
+
+// queueFor returns the queue feeding terminal, creating it on first use.
+func (a *App) queueFor(terminal string) *queue.Scheduler[Trade] {
+	a.tradeQueuesMux.RLock()
+	q, ok := a.tradeQueues[terminal]
+	a.tradeQueuesMux.RUnlock()
+	if ok {
+		return q
+	}
+	a.tradeQueuesMux.Lock()
+	defer a.tradeQueuesMux.Unlock()
+	if q, ok = a.tradeQueues[terminal]; !ok {
+		q = queue.New[Trade](terminal, a.tradeQueueCfg)
+		q.OnExpire(func(t Trade, class queue.Class, age time.Duration) { a.reportExpiredTrade(terminal, t, class, age) })
+		a.tradeQueues[terminal] = q
+	}
+	return q
+}
+
+// setQueueConfig applies queue capacities to existing and future terminal queues and restores
+// any trades a previous run spilled to disk.
+func (a *App) setQueueConfig(cfg queue.Config) {
+	if strings.TrimSpace(cfg.SpillDir) == "" {
+		cfg.SpillDir = config.DefaultSpillDir()
+	}
+	a.tradeQueuesMux.Lock()
+	a.tradeQueueCfg = cfg
+	for _, q := range a.tradeQueues {
+		q.SetConfig(cfg)
+	}
+	a.tradeQueuesMux.Unlock()
+
+	if cfg.Spilling() {
+		for _, terminal := range queue.SpilledQueues(cfg.SpillDir) {
+			a.queueFor(terminal)
+		}
+	}
+}
+
+// reportExpiredTrade records a spilled trade that outlived spill_max_age instead of sending it late.
+func (a *App) reportExpiredTrade(terminal string, t Trade, class queue.Class, age time.Duration) {
+	log.Printf("WARN: Expired spilled %s trade %s (base_id=%s action=%s) for terminal %s after %s; not sent to MT5",
+		class, t.ID, t.BaseID, t.Action, terminal, age.Truncate(time.Second))
+	blog.L().Warn("queue", "spilled trade expired", map[string]interface{}{
+		"trade_id":   t.ID,
+		"base_id":    t.BaseID,
+		"action":     t.Action,
+		"instrument": t.Instrument,
+		"account":    t.AccountName,
+		"terminal":   terminal,
+		"class":      class.String(),
+		"age":        age.String(),
+	})
+	if class == queue.ClassEntry {
+		a.takeCancelledEntry(strings.TrimSpace(t.BaseID))
+	}
+}
+
+// resolveTerminal decides which terminal receives t. Entries carry the terminal chosen by the
+// gRPC router and pin their BaseID to it; closes and events follow their BaseID so they reach
+// the terminal that holds the hedge.
+func (a *App) resolveTerminal(t *Trade) string {
+	baseID := strings.TrimSpace(t.BaseID)
+	terminal := strings.TrimSpace(t.Terminal)
+	act := strings.ToLower(strings.TrimSpace(t.Action))
+
+	a.mt5TicketMux.Lock()
+	defer a.mt5TicketMux.Unlock()
+	if terminal != "" && baseID != "" && (act == "buy" || act == "sell") {
+		a.baseIdToTerminal[baseID] = terminal
+		return terminal
+	}
+	if terminal == "" && baseID != "" {
+		terminal = a.baseIdToTerminal[baseID]
+	}
+	if terminal == "" {
+		terminal = a.grpcServer.DefaultTerminal()
+	}
+	return terminal
+}
+
+// ticketKey identifies an MT5 ticket: ticket numbers are only unique within one terminal.
+type ticketKey struct {
+	terminal string
+	ticket   uint64
+}
+
+// terminalOfLocked returns the terminal hedging baseID, the default terminal when the BaseID was
+// never pinned. The caller holds mt5TicketMux.
+func (a *App) terminalOfLocked(baseID string) string {
+	if terminal := a.baseIdToTerminal[strings.TrimSpace(baseID)]; terminal != "" {
+		return terminal
+	}
+	return a.grpcServer.DefaultTerminal()
+}
+
+// terminalSupports reports whether the EA hedging baseID negotiated feature in its Hello.
+func (a *App) terminalSupports(baseID, feature string) bool {
+	a.mt5TicketMux.RLock()
+	terminal := a.terminalOfLocked(baseID)
+	a.mt5TicketMux.RUnlock()
+	return a.grpcServer.TerminalSupports(terminal, feature)
+}
+
+// ticketKeyLocked keys ticket on the terminal hedging baseID. The caller holds mt5TicketMux.
+func (a *App) ticketKeyLocked(baseID string, ticket uint64) ticketKey {
+	return ticketKey{terminal: a.terminalOfLocked(baseID), ticket: ticket}
+}
+
+// reportedKeyLocked keys a ticket an EA reported: on its BaseID's terminal when the BaseID is
+// known, else on the reporting terminal (the default one for EAs that did not identify).
+// The caller holds mt5TicketMux.
+func (a *App) reportedKeyLocked(terminal, baseID string, ticket uint64) ticketKey {
+	if terminal = strings.TrimSpace(terminal); baseID == "" && terminal != "" {
+		return ticketKey{terminal: terminal, ticket: ticket}
+	}
+	return a.ticketKeyLocked(baseID, ticket)
+}
+
+// lookupTicketLocked finds a tracked ticket and its BaseID. An empty terminal matches the ticket
+// on any terminal, as long as only one terminal tracks that number. The caller holds mt5TicketMux.
+func (a *App) lookupTicketLocked(terminal string, ticket uint64) (ticketKey, string, bool) {
+	if terminal = strings.TrimSpace(terminal); terminal != "" {
+		key := ticketKey{terminal: terminal, ticket: ticket}
+		baseID, ok := a.mt5TicketToBaseId[key]
+		return key, baseID, ok
+	}
+	var found ticketKey
+	var baseID string
+	matches := 0
+	for key, base := range a.mt5TicketToBaseId {
+		if key.ticket == ticket {
+			found, baseID = key, base
+			matches++
+		}
+	}
+	return found, baseID, matches == 1
+}
+
+// resolveResultOwner returns the BaseID an MT5 result belongs to, looking it up by ticket on the
+// reporting terminal when the EA left it empty. A BaseID not pinned yet is pinned to the terminal
+// that reported it, so its tickets key the same way as the closes routed to it.
+func (a *App) resolveResultOwner(terminal, baseID string, ticket uint64) string {
+	terminal = strings.TrimSpace(terminal)
+	a.mt5TicketMux.Lock()
+	defer a.mt5TicketMux.Unlock()
+	if baseID == "" && ticket != 0 {
+		if _, mapped, ok := a.lookupTicketLocked(terminal, ticket); ok {
+			baseID = mapped
+		}
+	}
+	if baseID != "" && terminal != "" {
+		if _, pinned := a.baseIdToTerminal[baseID]; !pinned {
+			a.baseIdToTerminal[baseID] = terminal
+		}
+	}
+	return baseID
+}
+
+// PollTradeFromQueueFor returns the next trade queued for terminal (non-blocking), skipping stale entries
+func (a *App) PollTradeFromQueueFor(terminal string) interface{} {
+	q := a.queueFor(terminal)
+	for {
+		trade, waited, ok := q.PopWait()
+		if !ok {
+			return nil
+		}
+		if a.staleEntry(terminal, trade, waited) {
+			continue
+		}
+		a.trackDelivered(trade)
+		a.publishStatus(grpcserver.StatusQueue, "delivered "+trade.ID+" to "+terminal)
+		a.recordFeedTrade(feed.SentToMT5, trade)
+		return trade
+	}
+}
+
+// QueueSizes returns the number of queued trades per terminal
+func (a *App) QueueSizes() map[string]int {
+	a.tradeQueuesMux.RLock()
+	defer a.tradeQueuesMux.RUnlock()
+	sizes := make(map[string]int, len(a.tradeQueues))
+	for terminal, q := range a.tradeQueues {
+		sizes[terminal] = q.Len()
+	}
+	return sizes
+}
+
+// GetQueueStats returns per-terminal, per-class queue depth and wait-time metrics
+func (a *App) GetQueueStats() map[string]interface{} {
+	a.tradeQueuesMux.RLock()
+	defer a.tradeQueuesMux.RUnlock()
+	out := make(map[string]interface{}, len(a.tradeQueues))
+	for terminal, q := range a.tradeQueues {
+		classes := make([]map[string]interface{}, 0, 3)
+		for _, st := range q.Stats() {
+			classes = append(classes, map[string]interface{}{
+				"class":      st.Class,
+				"depth":      st.Depth,
+				"capacity":   st.Capacity,
+				"delivered":  st.Delivered,
+				"rejected":   st.Rejected,
+				"spilled":    st.Spilled,
+				"expired":    st.Expired,
+				"avgWaitMs":  st.AvgWait.Milliseconds(),
+				"maxWaitMs":  st.MaxWait.Milliseconds(),
+				"lastWaitMs": st.LastWait.Milliseconds(),
+			})
+		}
+		out[terminal] = classes
+	}
+	return out
+}
+
+// GetTerminals returns per-terminal stream health, queue depth and open hedge tickets
+func (a *App) GetTerminals() []map[string]interface{} {
+	tickets := make(map[string]int)
+	a.mt5TicketMux.RLock()
+	for key := range a.mt5TicketToBaseId {
+		if key.ticket != 0 {
+			tickets[key.terminal]++
+		}
+	}
+	a.mt5TicketMux.RUnlock()
+
+	statuses := a.grpcServer.TerminalStatuses()
+	out := make([]map[string]interface{}, 0, len(statuses))
+	for _, st := range statuses {
+		out = append(out, map[string]interface{}{
+			"terminal":    st.Terminal,
+			"connected":   st.Connected,
+			"streamId":    st.StreamID,
+			"terminalId":  st.TerminalID,
+			"accountId":   st.AccountID,
+			"connectedAt": st.ConnectedAt,
+			"lastSeen":    st.LastSeen,
+			"sent":        st.Sent,
+			"queueSize":   st.QueueSize,
+			"openTickets": tickets[st.Terminal],
+		})
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/routing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func installRouter(t *testing.T, a *App, cfg routing.Config) {
+	t.Helper()
+	r, err := routing.New(cfg)
+	if err != nil {
+		t.Fatalf("routing.New: %v", err)
+	}
+	a.grpcServer.SetRouter(r)
+}
+
+func pollTerminal(a *App, terminal string) (Trade, bool) {
+	out := a.PollTradeFromQueueFor(terminal)
+	if out == nil {
+		return Trade{}, false
+	}
+	trade, ok := out.(Trade)
+	return trade, ok
+}
+
+func TestTradesRouteToTerminalQueuesByAccount(t *testing.T) {
+	a := NewApp()
+	installRouter(t, a, routing.Config{Rules: []routing.Rule{
+		{Account: "Apex-1", Terminal: "T1"},
+		{Account: "Apex-2", Terminal: "T2"},
+	}})
+
+	for _, tr := range []*trading.Trade{
+		{Id: "r-1", BaseId: "BASE_T1", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Apex-1"},
+		{Id: "r-2", BaseId: "BASE_T2", Action: "sell", Quantity: 1, Instrument: "NQZ5", AccountName: "Apex-2"},
+	} {
+		if _, err := a.grpcServer.SubmitTrade(context.Background(), tr); err != nil {
+			t.Fatalf("SubmitTrade: %v", err)
+		}
+	}
+	if sizes := a.QueueSizes(); sizes["T1"] != 1 || sizes["T2"] != 1 {
+		t.Fatalf("expected one trade per terminal queue, got %v", sizes)
+	}
+	if trade, ok := pollTerminal(a, "T2"); !ok || trade.BaseID != "BASE_T2" || trade.Terminal != "T2" {
+		t.Fatalf("expected BASE_T2 on T2, got %+v (ok=%v)", trade, ok)
+	}
+	if trade, ok := pollTerminal(a, "T1"); !ok || trade.BaseID != "BASE_T1" {
+		t.Fatalf("expected BASE_T1 on T1, got %+v (ok=%v)", trade, ok)
+	}
+
+	// The close for BASE_T1 must reach the terminal holding its hedge
+	trackTicket(a, "BASE_T1", 7001)
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{
+		"BaseID": "BASE_T1", "ClosedHedgeQuantity": 1.0, "MT5Ticket": float64(7001),
+	}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+	if trade, ok := pollTerminal(a, "T1"); !ok || trade.Action != "CLOSE_HEDGE" || trade.MT5Ticket != 7001 {
+		t.Fatalf("expected CLOSE_HEDGE on T1, got %+v (ok=%v)", trade, ok)
+	}
+	if _, ok := drainTrade(a); ok {
+		t.Fatalf("default terminal queue should be empty")
+	}
+}
+
+func TestConcurrentEAStreamsReceiveOwnTerminalTrades(t *testing.T) {
+	a := NewApp()
+	installRouter(t, a, routing.Config{Rules: []routing.Rule{
+		{Account: "Apex-1", Terminal: "T1"},
+		{Account: "Apex-2", Terminal: "T2"},
+	}})
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	trading.RegisterTradingServiceServer(srv, a.grpcServer)
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := trading.NewTradingServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	open := func(terminal string) trading.TradingService_GetTradesClient {
+		stream, err := client.GetTrades(metadata.AppendToOutgoingContext(ctx, "terminal-id", terminal))
+		if err != nil {
+			t.Fatalf("GetTrades(%s): %v", terminal, err)
+		}
+		if err := stream.Send(&trading.GetTradesRequest{Source: "hedgebot"}); err != nil {
+			t.Fatalf("ping: %v", err)
+		}
+		return stream
+	}
+	s1, s2 := open("T1"), open("T2")
+
+	// Both EAs stay bound: the second connection must not supersede the first
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		connected := 0
+		for _, st := range a.grpcServer.TerminalStatuses() {
+			if st.Connected {
+				connected++
+			}
+		}
+		if connected == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected two connected terminals, got %+v", a.grpcServer.TerminalStatuses())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, tr := range []*trading.Trade{
+		{Id: "s-1", BaseId: "BASE_S1", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Apex-1"},
+		{Id: "s-2", BaseId: "BASE_S2", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Apex-2"},
+	} {
+		if _, err := client.SubmitTrade(ctx, tr); err != nil {
+			t.Fatalf("SubmitTrade: %v", err)
+		}
+	}
+	if got, err := s1.Recv(); err != nil || got.BaseId != "BASE_S1" {
+		t.Fatalf("T1 stream: got %+v, %v", got, err)
+	}
+	if got, err := s2.Recv(); err != nil || got.BaseId != "BASE_S2" {
+		t.Fatalf("T2 stream: got %+v, %v", got, err)
+	}
+}
+
+func TestTicketsAreKeyedPerTerminal(t *testing.T) {
+	a := NewApp()
+	installRouter(t, a, routing.Config{Rules: []routing.Rule{
+		{Account: "Apex-1", Terminal: "T1"},
+		{Account: "Apex-2", Terminal: "T2"},
+	}})
+	for _, tr := range []*trading.Trade{
+		{Id: "k-1", BaseId: "BASE_K1", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Apex-1"},
+		{Id: "k-2", BaseId: "BASE_K2", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Apex-2"},
+	} {
+		if _, err := a.grpcServer.SubmitTrade(context.Background(), tr); err != nil {
+			t.Fatalf("SubmitTrade: %v", err)
+		}
+	}
+	pollTerminal(a, "T1")
+	pollTerminal(a, "T2")
+
+	// Both brokers number their positions independently: the same ticket opens on each terminal
+	fromEA := func(terminal string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("terminal-id", terminal))
+	}
+	for _, r := range []struct{ terminal, baseID string }{{"T1", "BASE_K1"}, {"T2", "BASE_K2"}} {
+		if _, err := a.grpcServer.SubmitTradeResult(fromEA(r.terminal), &trading.MT5TradeResult{Status: "success", Id: r.baseID, Ticket: 4242, Volume: 1}); err != nil {
+			t.Fatalf("SubmitTradeResult: %v", err)
+		}
+	}
+	if a.openTicketCount("BASE_K1") != 1 || a.openTicketCount("BASE_K2") != 1 {
+		t.Fatalf("each BaseID must keep its own ticket 4242")
+	}
+
+	// A position update without a BaseID resolves on the reporting terminal, not as an orphan
+	a.grpcServer.SubmitTradeResult(fromEA("T2"), &trading.MT5TradeResult{Status: statusPositionUpdate, Ticket: 4242, Volume: 0.7, Profit: 5})
+	if orphans := a.GetOrphanTickets(); len(orphans) != 0 {
+		t.Fatalf("ticket 4242 is tracked on T2, got orphans %+v", orphans)
+	}
+	a.mt5TicketMux.RLock()
+	st := a.ticketStates[ticketKey{terminal: "T2", ticket: 4242}]
+	a.mt5TicketMux.RUnlock()
+	if st == nil || st.volume != 0.7 {
+		t.Fatalf("expected the T2 ticket state updated, got %+v", st)
+	}
+
+	// Closing the ticket on T1 leaves the T2 hedge tracked
+	a.grpcServer.SubmitTradeResult(fromEA("T1"), &trading.MT5TradeResult{Status: "success", Ticket: 4242, Volume: 1, IsClose: true})
+	if a.openTicketCount("BASE_K1") != 0 || a.openTicketCount("BASE_K2") != 1 {
+		t.Fatalf("close on T1 must not touch T2: K1=%d K2=%d", a.openTicketCount("BASE_K1"), a.openTicketCount("BASE_K2"))
+	}
+
+	// Untracked tickets are listed with the terminal that reported them
+	a.grpcServer.SubmitTradeResult(fromEA("T2"), &trading.MT5TradeResult{Status: statusPositionUpdate, Ticket: 4343, Volume: 0.2})
+	if orphans := a.GetOrphanTickets(); len(orphans) != 1 || orphans[0]["terminal"] != "T2" {
+		t.Fatalf("expected orphan 4343 on T2, got %+v", orphans)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/hours"
+	"BridgeApp/internal/symbols"
+)
+
+// closedNow builds trading hours under which symbol opens in two hours.
+func closedNow(t *testing.T, a *App, symbol string, policy hours.Policy, terminal string) time.Time {
+	t.Helper()
+	now := time.Now().UTC()
+	opens := now.Add(2 * time.Hour).Truncate(time.Minute)
+	g, err := hours.New(hours.Config{Tables: []hours.Table{{
+		Symbol:   symbol,
+		Sessions: []hours.Session{{Hours: opens.Format("15:04") + "-" + opens.Add(time.Hour).Format("15:04")}},
+		Policy:   policy,
+		Terminal: terminal,
+	}}}, time.UTC)
+	if err != nil {
+		t.Fatalf("hours.New: %v", err)
+	}
+	a.grpcServer.SetTradingHours(g)
+	return opens
+}
+
+func TestClosedSymbolEntriesAreHeldUntilOpen(t *testing.T) {
+	a := NewApp()
+	installSymbolMap(t, a, symbols.Config{Rules: []symbols.Rule{{Match: "NQ*", Symbol: "NAS100"}}})
+	opens := closedNow(t, a, "NAS100", hours.PolicyHold, "")
+
+	resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "hrs-1", BaseId: "BASE_HRS", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Sim101",
+	})
+	if err != nil || resp.Status != "held" {
+		t.Fatalf("SubmitTrade = %+v, %v", resp, err)
+	}
+	if md := resp.Metadata; md["trading_hours"] != "closed" || md["hours_policy"] != "hold" || md["mt5_symbol"] != "NAS100" ||
+		md["opens_at"] != opens.Format(time.RFC3339) || md["held_count"] != "1" {
+		t.Fatalf("unexpected metadata: %v", md)
+	}
+	if _, ok := drainTrade(a); ok {
+		t.Fatal("a held entry must not reach the queue")
+	}
+
+	// Closes are never gated
+	resp, _ = a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "hrs-2", BaseId: "BASE_HRS", Action: "CLOSE_HEDGE", Quantity: 1, Instrument: "NQZ5",
+	})
+	if resp.Status != "success" || resp.Metadata["trading_hours"] != "" {
+		t.Fatalf("closes must pass: %+v", resp)
+	}
+	drainTrade(a)
+
+	// Reloading without the table releases the entry
+	a.grpcServer.SetTradingHours(nil)
+	installSymbolMap(t, a, symbols.Config{Rules: []symbols.Rule{{Match: "NQ*", Symbol: "NAS100"}}})
+	if tr, ok := drainTrade(a); !ok || tr.ID != "hrs-1" || a.grpcServer.HeldTradeCount() != 0 {
+		t.Fatalf("expected the held entry to be released, got %+v (ok=%v)", tr, ok)
+	}
+}
+
+func TestClosedSymbolEntriesAreRejectedOrRouted(t *testing.T) {
+	a := NewApp()
+	closedNow(t, a, "GER40", hours.PolicyReject, "")
+	resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "hrs-3", BaseId: "BASE_GER", Action: "sell", Quantity: 1, Instrument: "GER40",
+	})
+	if err != nil || resp.Status != "rejected" || resp.Metadata["hours_reason"] != "outside trading hours" {
+		t.Fatalf("SubmitTrade = %+v, %v", resp, err)
+	}
+
+	closedNow(t, a, "GER40", hours.PolicyRoute, "ic-24h")
+	resp, err = a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+		Id: "hrs-4", BaseId: "BASE_GER", Action: "sell", Quantity: 1, Instrument: "GER40",
+	})
+	if err != nil || resp.Status != "success" || resp.Metadata["routed_terminal"] != "ic-24h" {
+		t.Fatalf("SubmitTrade = %+v, %v", resp, err)
+	}
+	if tr, ok := drainTrade(a); ok {
+		t.Fatalf("a routed entry must only reach its terminal's queue, got %+v", tr)
+	}
+	if a.QueueSizes()["ic-24h"] != 1 {
+		t.Fatalf("expected the entry in the ic-24h queue, got %v", a.QueueSizes())
+	}
+}
+
+func TestCloseDropsHeldEntriesOfItsBaseID(t *testing.T) {
+	a := NewApp()
+	installSymbolMap(t, a, symbols.Config{Rules: []symbols.Rule{{Match: "NQ*", Symbol: "NAS100"}}})
+	closedNow(t, a, "NAS100", hours.PolicyHold, "")
+	for _, id := range []string{"hcl-1", "hcl-2"} {
+		resp, err := a.grpcServer.SubmitTrade(context.Background(), &trading.Trade{
+			Id: id, BaseId: "BASE_HCL", Action: "buy", Quantity: 1, Instrument: "NQZ5", AccountName: "Sim101",
+		})
+		if err != nil || resp.Status != "held" {
+			t.Fatalf("SubmitTrade(%s) = %+v, %v", id, resp, err)
+		}
+	}
+
+	if err := a.HandleCloseHedgeRequest(map[string]interface{}{"BaseID": "BASE_HCL", "ClosedHedgeQuantity": float64(1)}); err != nil {
+		t.Fatalf("HandleCloseHedgeRequest: %v", err)
+	}
+	if n := a.grpcServer.HeldTradeCount(); n != 1 {
+		t.Fatalf("the close must drop one held entry, %d held", n)
+	}
+	if tr, ok := drainTrade(a); ok {
+		t.Fatalf("a close satisfied by a held entry must not reach MT5, got %+v", tr)
+	}
+
+	a.grpcServer.SetTradingHours(nil)
+	installSymbolMap(t, a, symbols.Config{Rules: []symbols.Rule{{Match: "NQ*", Symbol: "NAS100"}}})
+	if tr, ok := drainTrade(a); !ok || tr.ID != "hcl-2" {
+		t.Fatalf("expected the remaining held entry to be released, got %+v (ok=%v)", tr, ok)
+	}
+	if tr, ok := drainTrade(a); ok {
+		t.Fatalf("the dropped entry must not be released, got %+v", tr)
+	}
+}
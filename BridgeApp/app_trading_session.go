@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/alerts"
+	grpcserver "BridgeApp/internal/grpc"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/session"
+)
+
+// sessionClockInterval is how often the session clock looks for a trading-day rollover.
+const sessionClockInterval = 30 * time.Second
+
+// setSessionCalendar installs the trading-session calendar everywhere a trading day is used. The
+// day in progress is taken from the new calendar without closing it.
+func (a *App) setSessionCalendar(c *session.Calendar, lossLimit float64) {
+	a.sessionMux.Lock()
+	a.calendar, a.lossLimit = c, lossLimit
+	a.sessionDay = c.Day(time.Now())
+	a.sessionMux.Unlock()
+
+	a.combinedPnL.SetDayFunc(c.Day)
+	blog.L().SetDayFunc(c.Day)
+	a.grpcServer.SetSessionCalendar(c, lossLimit)
+}
+
+// startSessionClock starts watching for trading-day rollovers once.
+func (a *App) startSessionClock() {
+	a.sessionClock.Do(func() {
+		go func() {
+			ticker := time.NewTicker(sessionClockInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				a.checkSessionRollover(now)
+			}
+		}()
+	})
+}
+
+// checkSessionRollover closes the previous trading day once now belongs to a new one.
+func (a *App) checkSessionRollover(now time.Time) {
+	a.sessionMux.Lock()
+	day := a.calendar.Day(now)
+	prev := a.sessionDay
+	a.sessionDay = day
+	a.sessionMux.Unlock()
+	if prev != "" && prev != day {
+		a.closeTradingDay(prev)
+	}
+}
+
+// closeTradingDay writes the end-of-day summary of day into the unified log and sends one
+// TRADING_DAY_CLOSED notice per account to the addon.
+func (a *App) closeTradingDay(day string) {
+	r := a.combinedPnL.Report(day)
+	accounts := make([]map[string]interface{}, 0, len(r.Accounts))
+	for _, acct := range r.Accounts {
+		accounts = append(accounts, map[string]interface{}{
+			"account":           acct.Account,
+			"qt_balance":        acct.QTBalance,
+			"qt_daily_pnl":      acct.QTDailyPnL,
+			"qt_session_trades": acct.QTSessionTrades,
+			"entries":           acct.Entries,
+			"hedge_net":         acct.HedgeNet,
+			"combined":          acct.Combined,
+			"positions":         acct.Positions,
+			"loss_limit_hit":    acct.LossLimitHit,
+		})
+	}
+	log.Printf("Session: trading day %s closed: combined %.2f (QT %.2f, hedges %.2f) across %d accounts", day, r.Day.Combined, r.Day.QTDailyPnL, r.Day.HedgeNet, len(r.Accounts))
+	blog.L().Info("session", "trading day closed", map[string]interface{}{
+		"day":          day,
+		"qt_daily_pnl": r.Day.QTDailyPnL,
+		"hedge_net":    r.Day.HedgeNet,
+		"combined":     r.Day.Combined,
+		"positions":    r.Day.Positions,
+		"accounts":     accounts,
+	})
+
+	if a.grpcServer == nil {
+		return
+	}
+	for _, acct := range r.Accounts {
+		a.grpcServer.NotifyAddonStreams(Trade{
+			ID:              fmt.Sprintf("eod_%s_%s", day, acct.Account),
+			Action:          "TRADING_DAY_CLOSED",
+			OrderType:       day,
+			AccountName:     acct.Account,
+			NTBalance:       acct.QTBalance,
+			NTDailyPnL:      acct.Combined,
+			NTSessionTrades: acct.Entries,
+			NTTradeResult:   fmt.Sprintf("combined %.2f (QT %.2f, hedges %.2f), %d entries", acct.Combined, acct.QTDailyPnL, acct.HedgeNet, acct.Entries),
+			Time:            time.Now(),
+		})
+	}
+}
+
+// checkLossLimit reports, once per trading day, an account whose combined PnL reached the daily
+// loss limit. The bridge does not block entries; the addon enforces the limit.
+func (a *App) checkLossLimit(account string) {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return
+	}
+	a.sessionMux.Lock()
+	limit := a.lossLimit
+	a.sessionMux.Unlock()
+	if limit <= 0 {
+		return
+	}
+	for _, acct := range a.combinedPnL.Report("").Accounts {
+		if acct.Account != account || acct.Combined > -limit || !a.combinedPnL.MarkLossLimit(account) {
+			continue
+		}
+		log.Printf("Session: account %s reached the daily loss limit on %s: combined %.2f (limit %.2f)", account, acct.Day, acct.Combined, limit)
+		blog.L().Warn("session", "daily loss limit reached", map[string]interface{}{
+			"account":  account,
+			"day":      acct.Day,
+			"combined": acct.Combined,
+			"limit":    limit,
+		})
+		a.publishStatus(grpcserver.StatusRiskLimit, fmt.Sprintf("%s combined %.2f reached the %.2f daily loss limit", account, acct.Combined, limit))
+		a.raiseAlert(alerts.Alert{
+			Rule:     alerts.RuleRiskLimit,
+			Severity: alerts.Critical,
+			Subject:  account,
+			Title:    "Daily loss limit reached",
+			Message:  fmt.Sprintf("Account %s combined PnL %.2f reached the %.2f daily loss limit for %s.", account, acct.Combined, limit, acct.Day),
+			Fields:   map[string]string{"account": account, "day": acct.Day},
+		})
+		if a.grpcServer != nil {
+			a.grpcServer.NotifyAddonStreams(Trade{
+				ID:            fmt.Sprintf("loss_limit_%s_%s", acct.Day, account),
+				Action:        "DAILY_LOSS_LIMIT",
+				OrderType:     "REACHED",
+				AccountName:   account,
+				NTDailyPnL:    acct.Combined,
+				NTTradeResult: fmt.Sprintf("combined %.2f reached the %.2f limit for %s", acct.Combined, limit, acct.Day),
+				Time:          time.Now(),
+			})
+		}
+	}
+}
+
+// GetTradingSession describes the trading day in progress for the UI.
+func (a *App) GetTradingSession() map[string]interface{} {
+	a.sessionMux.Lock()
+	c, limit := a.calendar, a.lossLimit
+	a.sessionMux.Unlock()
+	now := time.Now()
+	start, end := c.Bounds(now)
+	return map[string]interface{}{
+		"day":            c.Day(now),
+		"start":          start.Format(time.RFC3339),
+		"end":            end.Format(time.RFC3339),
+		"timezone":       c.Location().String(),
+		"dailyLossLimit": limit,
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/session"
+)
+
+func TestTradingSessionDrivesDaysLossLimitAndSettings(t *testing.T) {
+	a := NewApp()
+	cal, err := session.New(session.Config{Timezone: "America/Chicago", Start: "17:00"})
+	if err != nil {
+		t.Fatalf("session.New: %v", err)
+	}
+	a.setSessionCalendar(cal, 500)
+	today := cal.Day(time.Now())
+
+	resp, err := a.grpcServer.GetSettings(context.Background(), &trading.SettingsRequest{SettingName: "trading-day"})
+	if err != nil || resp.SettingValue != today {
+		t.Fatalf("trading-day setting: %+v, %v", resp, err)
+	}
+	resp, _ = a.grpcServer.GetSettings(context.Background(), &trading.SettingsRequest{SettingName: "daily-loss-limit"})
+	if resp.SettingValue != "500" {
+		t.Fatalf("daily-loss-limit setting: %+v", resp)
+	}
+
+	for i, pnl := range []float64{-200, -650} {
+		if err := a.AddToTradeQueue(Trade{ID: fmt.Sprintf("qt-%d", i), BaseID: "BASE_SES", Action: "buy", Quantity: 1,
+			AccountName: "Sim101", NTBalance: 50000 + pnl, NTDailyPnL: pnl, NTSessionTrades: i + 1}); err != nil {
+			t.Fatalf("AddToTradeQueue: %v", err)
+		}
+	}
+	r := a.CombinedPnL("")
+	if r.Day.Day != today || len(r.Accounts) != 1 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	if acct := r.Accounts[0]; acct.Entries != 2 || acct.QTSessionTrades != 2 || !acct.LossLimitHit {
+		t.Fatalf("unexpected account counters: %+v", acct)
+	}
+
+	// The session clock closes the day once the calendar moves on
+	a.checkSessionRollover(time.Now().Add(24 * time.Hour))
+	if a.sessionDay == today {
+		t.Fatal("the session clock must move to the next trading day")
+	}
+}
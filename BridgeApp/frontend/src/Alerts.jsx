@@ -0,0 +1,56 @@
+import React, { useState, useEffect } from 'react';
+import { GetAlerts, SendTestAlert } from '../wailsjs/go/main/App';
+
+const failures = (a) => (a.deliveries ?? []).filter((d) => d.error);
+
+// Alerts raised by the bridge rules and whether the webhook/Telegram/email sinks took them.
+function Alerts({ onResult }) {
+  const [alerts, setAlerts] = useState([]);
+
+  const fetchAlerts = async () => {
+    try {
+      setAlerts((await GetAlerts()) ?? []);
+    } catch (err) {
+      console.error("Failed to fetch alerts:", err);
+    }
+  };
+
+  useEffect(() => {
+    fetchAlerts();
+    const interval = setInterval(fetchAlerts, 5000);
+    return () => clearInterval(interval);
+  }, []);
+
+  const handleTest = async () => {
+    const res = await SendTestAlert('');
+    if (res?.success) {
+      onResult?.('Test alert sent', 'success');
+    } else {
+      const errors = (res?.deliveries ?? []).filter((d) => d.error).map((d) => `${d.sink}: ${d.error}`);
+      onResult?.(res?.message ?? `Test alert failed - ${errors.join('; ')}`, 'error');
+    }
+    fetchAlerts();
+  };
+
+  return (
+    <div className="status-lines">
+      <h4>Alerts</h4>
+      {alerts.slice(0, 10).map((a) => (
+        <div className="status-item" key={`${a.rule}-${a.subject}-${a.time}`}>
+          <span className="status-label">
+            {new Date(a.time).toLocaleTimeString()} {a.title}:
+          </span>
+          <span className={`status-value ${a.severity === 'critical' || failures(a).length ? 'disconnected' : 'connected'}`}>
+            {a.message}
+            {a.suppressed ? ` (+${a.suppressed} suppressed)` : ''}
+            {a.testMode ? ' - test mode, not sent' : ''}
+            {failures(a).length ? ` - failed: ${failures(a).map((d) => d.sink).join(', ')}` : ''}
+          </span>
+        </div>
+      ))}
+      <button onClick={handleTest}>Send Test Alert</button>
+    </div>
+  );
+}
+
+export default Alerts;
@@ -2,6 +2,14 @@ import React, { useState, useEffect } from 'react';
 import { EventsOn } from '../wailsjs/runtime'; // Added for Wails event handling
 import './App.css';
 import { GetStatus, AttemptReconnect } from '../wailsjs/go/main/App';
+import DeadLetters from './DeadLetters';
+import CombinedPnL from './CombinedPnL';
+import Schedule from './Schedule';
+import Clients from './Clients';
+import Alerts from './Alerts';
+import Notifications from './Notifications';
+import EventFeed from './EventFeed';
+import ManualHedge from './ManualHedge';
 
 function App() {
   // State structure based on GetStatus return value, now includes hedgebotActive and tradeLogSenderActive
@@ -57,6 +65,7 @@ function App() {
           netPosition: currentStatusFromServer?.netPosition ?? 0,
           hedgeSize: currentStatusFromServer?.hedgeSize ?? 0,
           queueSize: currentStatusFromServer?.queueSize ?? 0,
+          terminals: currentStatusFromServer?.terminals ?? [],
         };
       });
 
@@ -234,6 +243,47 @@ function App() {
           </div>
         </div>
 
+        {/* Per-terminal health (only shown when routing to more than one MT5 terminal) */}
+        {bridgeStatus.terminals?.length > 1 && (
+          <div className="status-lines">
+            {bridgeStatus.terminals.map((term) => {
+              const termDisplay = getStatusDisplay(term.connected, { on: 'Connected', off: 'Disconnected' });
+              return (
+                <div className="status-item" key={term.terminal}>
+                  <span className="status-label">{term.terminal}:</span>
+                  <span className={`status-value ${termDisplay.className}`}>
+                    {termDisplay.text} (queue {term.queueSize}, tickets {term.openTickets})
+                  </span>
+                </div>
+              );
+            })}
+          </div>
+        )}
+
+        {/* Hedge failures, orphans and lost MT5 streams pushed by the bridge */}
+        <Notifications onResult={showNotification} />
+
+        {/* Queue, MT5 and close events as they happen, with per-BaseID timelines */}
+        <EventFeed />
+
+        {/* Add-on and EA sessions with versions and staleness */}
+        <Clients />
+
+        {/* Bridge mode and upcoming scheduled actions */}
+        <Schedule />
+
+        {/* Quantower and MT5 hedge PnL of the trading day */}
+        <CombinedPnL />
+
+        {/* Operator hedge actions: open, close and re-link orphaned tickets */}
+        <ManualHedge onResult={showNotification} />
+
+        {/* Undeliverable trades awaiting an operator decision */}
+        <DeadLetters onResult={showNotification} />
+
+        {/* Alerts sent to the webhook/Telegram/email sinks */}
+        <Alerts onResult={showNotification} />
+
         {/* Reset Button */}
         <button className="reset-btn" onClick={handleResetClick}>
           Reset Bridge State
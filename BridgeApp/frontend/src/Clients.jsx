@@ -0,0 +1,51 @@
+import React, { useState, useEffect } from 'react';
+import { GetClients } from '../wailsjs/go/main/App';
+
+const kinds = { addon: 'Addon', ea: 'EA' };
+
+const idle = (seconds) => (seconds < 60 ? `${seconds}s` : `${Math.floor(seconds / 60)}m`);
+
+// Add-on and EA sessions seen by the bridge; stale ones have been silent for clients.stale_after.
+function Clients() {
+  const [clients, setClients] = useState([]);
+
+  useEffect(() => {
+    const fetchClients = async () => {
+      try {
+        setClients((await GetClients()) ?? []);
+      } catch (err) {
+        console.error("Failed to fetch clients:", err);
+      }
+    };
+    fetchClients();
+    const interval = setInterval(fetchClients, 5000);
+    return () => clearInterval(interval);
+  }, []);
+
+  if (!clients.length) {
+    return null;
+  }
+
+  return (
+    <div className="status-lines">
+      <h4>Clients</h4>
+      {clients.map((c) => (
+        <div className="status-item" key={`${c.kind}-${c.id}-${c.remoteAddr}`}>
+          <span className="status-label">
+            {kinds[c.kind] ?? c.kind} {c.id}{c.version ? ` v${c.version}` : ''}:
+          </span>
+          <span className={`status-value ${c.stale ? 'disconnected' : 'connected'}`}>
+            {c.stale ? 'Stale' : 'Live'}
+            {` - ${c.remoteAddr || 'local'}, since ${new Date(c.connectedSince).toLocaleTimeString()}`}
+            {`, last ${c.lastMethod || 'message'} ${idle(c.idleSeconds)} ago`}
+            {c.streams?.length ? `, ${c.streams.length} stream(s)` : ''}
+            {c.hello ? `, protocol ${c.protocol}` : ''}
+            {c.rejected ? ` - rejected: ${c.rejected}` : ''}
+          </span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+export default Clients;
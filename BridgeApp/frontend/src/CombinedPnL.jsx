@@ -0,0 +1,78 @@
+import React, { useState, useEffect } from 'react';
+import { CombinedPnL as GetCombinedPnL, GetTradingSession } from '../wailsjs/go/main/App';
+
+const money = (v) => (v ?? 0).toFixed(2);
+const tone = (v) => ((v ?? 0) < 0 ? 'disconnected' : 'connected');
+
+// Quantower and MT5 hedge PnL of a trading day, per account and BaseID.
+function CombinedPnL() {
+  const [day, setDay] = useState('');
+  const [report, setReport] = useState(null);
+  const [session, setSession] = useState(null);
+
+  useEffect(() => {
+    const fetchReport = async () => {
+      try {
+        setReport(await GetCombinedPnL(day));
+        setSession(await GetTradingSession());
+      } catch (err) {
+        console.error("Failed to fetch combined PnL:", err);
+      }
+    };
+    fetchReport();
+    const interval = setInterval(fetchReport, 5000);
+    return () => clearInterval(interval);
+  }, [day]);
+
+  if (!report || (!report.accounts?.length && !report.positions?.length && !report.days?.length)) {
+    return null;
+  }
+
+  return (
+    <div className="status-lines">
+      <h4>
+        Combined PnL{' '}
+        <select value={day} onChange={(e) => setDay(e.target.value)}>
+          <option value="">Today</option>
+          {(report.days ?? []).map((d) => (
+            <option key={d} value={d}>{d}</option>
+          ))}
+        </select>
+      </h4>
+      {session && (
+        <div className="status-item">
+          <span className="status-label">Trading day {session.day}:</span>
+          <span className="status-value">
+            until {new Date(session.end).toLocaleString()} ({session.timezone}), loss limit {money(session.dailyLossLimit)}
+          </span>
+        </div>
+      )}
+      <div className="status-item">
+        <span className="status-label">{report.day.day}:</span>
+        <span className={`status-value ${tone(report.day.combined)}`}>
+          {money(report.day.combined)} (QT {money(report.day.qt_daily_pnl)}, hedges {money(report.day.hedge_net)})
+        </span>
+      </div>
+      {(report.accounts ?? []).map((a) => (
+        <div className="status-item" key={`acct-${a.account}`}>
+          <span className="status-label">{a.account || 'Unknown account'}:</span>
+          <span className={`status-value ${tone(a.combined)}`}>
+            {money(a.combined)} (QT {a.has_qt ? money(a.qt_daily_pnl) : 'n/a'}, hedges {money(a.hedge_net)}, balance {a.has_qt ? money(a.qt_balance) : 'n/a'}, {a.entries} entries)
+            {a.loss_limit_hit ? ' loss limit reached' : ''}
+          </span>
+        </div>
+      ))}
+      {(report.positions ?? []).map((p) => (
+        <div className="status-item" key={`pos-${p.base_id}`}>
+          <span className="status-label">{p.instrument} {p.base_id}:</span>
+          <span className={`status-value ${tone(p.combined)}`}>
+            {money(p.combined)} (QT {p.has_qt_profit ? money(p.qt_profit) : 'n/a'}, hedges {money(p.hedge_net)}
+            {p.hedge_open ? ', open' : ''})
+          </span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+export default CombinedPnL;
@@ -0,0 +1,64 @@
+import React, { useState, useEffect } from 'react';
+import { GetDeadLetters, RetryDeadLetter, EditAndRetryDeadLetter, DiscardDeadLetter } from '../wailsjs/go/main/App';
+
+// Trades that could not be delivered to or executed by MT5, with operator actions.
+function DeadLetters({ onResult }) {
+  const [letters, setLetters] = useState([]);
+
+  const fetchLetters = async () => {
+    try {
+      setLetters((await GetDeadLetters()) ?? []);
+    } catch (err) {
+      console.error("Failed to fetch dead letters:", err);
+    }
+  };
+
+  useEffect(() => {
+    fetchLetters();
+    const interval = setInterval(fetchLetters, 5000);
+    return () => clearInterval(interval);
+  }, []);
+
+  const report = (res, verb) => {
+    if (res?.success) {
+      onResult?.(`Dead letter ${res.id} ${verb}`, 'success');
+    } else {
+      onResult?.(`Dead letter ${res?.id}: ${res?.error ?? 'action failed'}`, 'error');
+    }
+    fetchLetters();
+  };
+
+  const handleRetry = async (id) => report(await RetryDeadLetter(id), 'retried');
+  const handleDiscard = async (id) => report(await DiscardDeadLetter(id), 'discarded');
+  const handleEdit = async (letter) => {
+    // Only the fields present in the edited JSON are changed
+    const edits = window.prompt(`Edit trade ${letter.tradeId} (JSON fields to change):`, letter.payload);
+    if (edits === null) {
+      return;
+    }
+    report(await EditAndRetryDeadLetter(letter.id, edits), 'edited and retried');
+  };
+
+  if (letters.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className="status-lines">
+      <h4>Dead Letters ({letters.length})</h4>
+      {letters.map((l) => (
+        <div className="status-item" key={l.id}>
+          <span className="status-label">{l.action} {l.baseId}:</span>
+          <span className="status-value disconnected">
+            {l.reason} ×{l.attempts} {l.detail}
+          </span>
+          <button onClick={() => handleRetry(l.id)}>Retry</button>
+          <button onClick={() => handleEdit(l)}>Edit &amp; Retry</button>
+          <button onClick={() => handleDiscard(l.id)}>Discard</button>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+export default DeadLetters;
@@ -0,0 +1,94 @@
+import React, { useState, useEffect, useRef } from 'react';
+import { EventsOn } from '../wailsjs/runtime';
+import { GetEventFeed, GetEventsSince, GetBaseTimeline } from '../wailsjs/go/main/App';
+
+const MAX_ROWS = 200;
+
+const kinds = {
+  enqueued: 'Queued',
+  sent_to_mt5: 'Sent to MT5',
+  mt5_result: 'MT5 result',
+  close_notice: 'Close',
+  elastic: 'Elastic',
+};
+
+const describe = (e) => [
+  e.action,
+  e.instrument,
+  e.volume ? `${e.volume}` : '',
+  e.price ? `@ ${e.price}` : '',
+  e.ticket ? `#${e.ticket}` : '',
+  e.status,
+  e.profit ? `PnL ${e.profit.toFixed(2)}` : '',
+  e.detail,
+].filter(Boolean).join(' ');
+
+const row = (e, onBase) => (
+  <div className="status-item" key={e.seq}>
+    <span className="status-label">
+      {new Date(e.time).toLocaleTimeString()} {kinds[e.kind] ?? e.kind}
+      {e.baseId ? <> <a href="#" onClick={(ev) => { ev.preventDefault(); onBase(e.baseId); }}>{e.baseId}</a></> : ''}:
+    </span>
+    <span className={`status-value ${/fail|reject/i.test(e.status ?? '') ? 'disconnected' : 'connected'}`}>
+      {describe(e)}
+    </span>
+  </div>
+);
+
+// Live blotter of every trade the bridge queues, sends to MT5 and hears back about, pushed from
+// Go as "bridge:event"; clicking a BaseID shows its timeline.
+function EventFeed() {
+  const [events, setEvents] = useState([]);
+  const [baseId, setBaseId] = useState('');
+  const [timeline, setTimeline] = useState([]);
+  const lastSeq = useRef(0);
+
+  const append = (incoming) => {
+    if (!incoming?.length) {
+      return;
+    }
+    const fresh = incoming.filter((e) => e.seq > lastSeq.current);
+    if (!fresh.length) {
+      return;
+    }
+    lastSeq.current = fresh[fresh.length - 1].seq;
+    setEvents((prev) => [...prev, ...fresh].slice(-MAX_ROWS));
+  };
+
+  useEffect(() => {
+    GetEventFeed(MAX_ROWS).then(append).catch((err) => console.error("Failed to fetch event feed:", err));
+    EventsOn("bridge:event", (e) => {
+      if (e.seq > lastSeq.current + 1) {
+        // Missed events (window hidden, reload): catch up from the ring buffer
+        GetEventsSince(lastSeq.current).then(append);
+        return;
+      }
+      append([e]);
+    });
+  }, []);
+
+  useEffect(() => {
+    if (baseId) {
+      GetBaseTimeline(baseId).then((t) => setTimeline(t ?? []));
+    }
+  }, [baseId, events]);
+
+  if (!events.length) {
+    return null;
+  }
+
+  return (
+    <div className="status-lines">
+      <h4>Live Trades</h4>
+      {[...events].reverse().slice(0, 25).map((e) => row(e, setBaseId))}
+      {baseId && (
+        <>
+          <h4>Timeline {baseId} <button onClick={() => setBaseId('')}>Close</button></h4>
+          {timeline.map((e) => row(e, setBaseId))}
+        </>
+      )}
+    </div>
+  );
+}
+
+export default EventFeed;
@@ -0,0 +1,103 @@
+import React, { useState, useEffect } from 'react';
+import { OpenManualHedge, CloseTicket, CloseBaseID, RelinkTicket, GetOrphanTickets, ReleaseHeldTrade } from '../wailsjs/go/main/App';
+
+// Operator actions on hedges without going through Quantower: open a manual hedge, close a ticket
+// or a whole BaseID, re-link orphaned MT5 tickets and release held entries.
+function ManualHedge({ onResult }) {
+  const [form, setForm] = useState({ instrument: '', side: 'buy', lots: '0.1', account: '' });
+  const [ticket, setTicket] = useState('');
+  const [terminal, setTerminal] = useState('');
+  const [baseId, setBaseId] = useState('');
+  const [heldId, setHeldId] = useState('');
+  const [orphans, setOrphans] = useState([]);
+
+  const fetchOrphans = async () => {
+    try {
+      setOrphans((await GetOrphanTickets()) ?? []);
+    } catch (err) {
+      console.error("Failed to fetch orphan tickets:", err);
+    }
+  };
+
+  useEffect(() => {
+    fetchOrphans();
+    const interval = setInterval(fetchOrphans, 5000);
+    return () => clearInterval(interval);
+  }, []);
+
+  const report = (res, done) => {
+    if (res?.success) {
+      onResult?.(done, 'success');
+    } else {
+      onResult?.(res?.error ?? 'Action failed', 'error');
+    }
+    fetchOrphans();
+  };
+
+  const handleOpen = async () => {
+    const res = await OpenManualHedge(form.instrument, form.side, parseFloat(form.lots), form.account);
+    report(res, `Manual hedge queued as ${res?.baseId}`);
+  };
+  const handleCloseTicket = async () => {
+    if (window.confirm(`Close MT5 ticket ${ticket}?`)) {
+      report(await CloseTicket(Number(ticket), terminal), `Close of ticket ${ticket} queued`);
+    }
+  };
+  const handleCloseBase = async () => {
+    if (window.confirm(`Close every MT5 ticket of ${baseId}?`)) {
+      report(await CloseBaseID(baseId), `Close of ${baseId} queued`);
+    }
+  };
+  const handleRelink = async (o) => {
+    const target = window.prompt(`Link MT5 ticket ${o.ticket} (${o.terminal}) to BaseID:`, baseId);
+    if (target) {
+      report(await RelinkTicket(o.ticket, o.terminal, target), `Ticket ${o.ticket} linked to ${target}`);
+    }
+  };
+
+  const handleRelease = async () => {
+    if (window.confirm(`Send held trade ${heldId} to MT5 now?`)) {
+      report(await ReleaseHeldTrade(heldId), `Held trade ${heldId} released`);
+    }
+  };
+
+  const set = (key) => (e) => setForm({ ...form, [key]: e.target.value });
+
+  return (
+    <div className="status-lines">
+      <h4>Manual Hedge</h4>
+      <div className="status-item">
+        <input placeholder="Instrument" value={form.instrument} onChange={set('instrument')} />
+        <select value={form.side} onChange={set('side')}>
+          <option value="buy">Buy</option>
+          <option value="sell">Sell</option>
+        </select>
+        <input type="number" step="0.01" min="0.01" value={form.lots} onChange={set('lots')} />
+        <input placeholder="Account" value={form.account} onChange={set('account')} />
+        <button onClick={handleOpen}>Open Hedge</button>
+      </div>
+      <div className="status-item">
+        <input placeholder="MT5 ticket" value={ticket} onChange={(e) => setTicket(e.target.value)} />
+        <input placeholder="Terminal (optional)" value={terminal} onChange={(e) => setTerminal(e.target.value)} />
+        <button onClick={handleCloseTicket} disabled={!ticket}>Close Ticket</button>
+        <input placeholder="BaseID" value={baseId} onChange={(e) => setBaseId(e.target.value)} />
+        <button onClick={handleCloseBase} disabled={!baseId}>Close BaseID</button>
+      </div>
+      <div className="status-item">
+        <input placeholder="Held trade ID" value={heldId} onChange={(e) => setHeldId(e.target.value)} />
+        <button onClick={handleRelease} disabled={!heldId}>Release Held</button>
+      </div>
+      {orphans.map((o) => (
+        <div className="status-item" key={`${o.terminal}-${o.ticket}`}>
+          <span className="status-label">Orphan #{o.ticket} ({o.terminal}):</span>
+          <span className="status-value disconnected">
+            {`${o.volume} lots, PnL ${o.profit.toFixed(2)}, since ${new Date(o.firstSeen).toLocaleTimeString()} `}
+          </span>
+          <button onClick={() => handleRelink(o)}>Re-link</button>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+export default ManualHedge;
@@ -0,0 +1,92 @@
+import React, { useState, useEffect } from 'react';
+import { EventsOn } from '../wailsjs/runtime';
+import { GetNotifications, MarkNotificationsRead, ClearNotifications } from '../wailsjs/go/main/App';
+
+// Tones for notifications.sounds: [frequency Hz, start s, length s]
+const tones = {
+  alarm: [[880, 0, 0.18], [660, 0.2, 0.18], [880, 0.4, 0.18], [660, 0.6, 0.18]],
+  chime: [[660, 0, 0.25], [990, 0.25, 0.4]],
+  beep: [[1000, 0, 0.15]],
+};
+
+let audio;
+const play = (sound) => {
+  const notes = tones[sound];
+  if (!notes) {
+    return;
+  }
+  try {
+    audio = audio ?? new (window.AudioContext || window.webkitAudioContext)();
+    const now = audio.currentTime;
+    notes.forEach(([freq, start, length]) => {
+      const osc = audio.createOscillator();
+      const gain = audio.createGain();
+      osc.frequency.value = freq;
+      gain.gain.setValueAtTime(0.2, now + start);
+      gain.gain.exponentialRampToValueAtTime(0.001, now + start + length);
+      osc.connect(gain).connect(audio.destination);
+      osc.start(now + start);
+      osc.stop(now + start + length);
+    });
+  } catch (err) {
+    console.error("Failed to play notification sound:", err);
+  }
+};
+
+// Critical bridge events pushed by the Go side, which also shows the desktop notification: each
+// one plays its sound and is kept in the history until cleared.
+function Notifications({ onResult }) {
+  const [history, setHistory] = useState([]);
+
+  const fetchHistory = async () => {
+    try {
+      setHistory((await GetNotifications()) ?? []);
+    } catch (err) {
+      console.error("Failed to fetch notifications:", err);
+    }
+  };
+
+  useEffect(() => {
+    fetchHistory();
+    const off = EventsOn("bridge:notification", (n) => {
+      play(n.sound);
+      onResult?.(`${n.title}: ${n.message}`, n.severity === 'info' ? 'info' : 'error', 6000);
+      fetchHistory();
+    });
+    return off;
+  }, []);
+
+  const handleMarkRead = async () => {
+    await MarkNotificationsRead(0);
+    fetchHistory();
+  };
+  const handleClear = async () => {
+    await ClearNotifications();
+    fetchHistory();
+  };
+
+  if (history.length === 0) {
+    return null;
+  }
+
+  const unread = history.filter((n) => !n.read).length;
+  return (
+    <div className="status-lines">
+      <h4>Notifications{unread ? ` (${unread} unread)` : ''}</h4>
+      {history.slice(0, 20).map((n) => (
+        <div className="status-item" key={n.id}>
+          <span className="status-label">
+            {new Date(n.time).toLocaleTimeString()} {n.title}:
+          </span>
+          <span className={`status-value ${n.read ? 'connected' : 'disconnected'}`}>
+            {n.message}
+          </span>
+        </div>
+      ))}
+      <button onClick={handleMarkRead}>Mark All Read</button>
+      <button onClick={handleClear}>Clear</button>
+    </div>
+  );
+}
+
+export default Notifications;
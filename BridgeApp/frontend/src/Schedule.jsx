@@ -0,0 +1,52 @@
+import React, { useState, useEffect } from 'react';
+import { GetSchedule } from '../wailsjs/go/main/App';
+
+const time = (v) => new Date(v).toLocaleString();
+const accounts = (list) => (list?.length ? list.join(', ') : 'all accounts');
+
+// Bridge mode and the scheduled flattens and close_only windows of the next days.
+function Schedule() {
+  const [schedule, setSchedule] = useState(null);
+
+  useEffect(() => {
+    const fetchSchedule = async () => {
+      try {
+        setSchedule(await GetSchedule());
+      } catch (err) {
+        console.error("Failed to fetch schedule:", err);
+      }
+    };
+    fetchSchedule();
+    const interval = setInterval(fetchSchedule, 5000);
+    return () => clearInterval(interval);
+  }, []);
+
+  if (!schedule || (schedule.mode === 'normal' && !schedule.upcoming?.length)) {
+    return null;
+  }
+
+  return (
+    <div className="status-lines">
+      <h4>Schedule ({schedule.timezone})</h4>
+      <div className="status-item">
+        <span className="status-label">Bridge mode:</span>
+        <span className={`status-value ${schedule.mode === 'normal' ? 'connected' : 'disconnected'}`}>
+          {schedule.mode === 'normal' ? 'Normal' : 'Close only'}
+          {(schedule.active ?? []).map((o) => ` (${o.rule} until ${new Date(o.until).toLocaleTimeString()})`).join('')}
+        </span>
+      </div>
+      {(schedule.upcoming ?? []).map((o) => (
+        <div className="status-item" key={`${o.rule}-${o.at}`}>
+          <span className="status-label">{o.rule}:</span>
+          <span className="status-value">
+            {o.action === 'flatten'
+              ? `flatten ${accounts(o.accounts)} at ${time(o.at)}`
+              : `close only for ${accounts(o.accounts)} ${time(o.from)} - ${new Date(o.until).toLocaleTimeString()}`}
+          </span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+export default Schedule;
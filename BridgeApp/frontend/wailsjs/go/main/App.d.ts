@@ -1,6 +1,11 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {closereq} from '../models';
+import {feed} from '../models';
+import {hedgepnl} from '../models';
 import {main} from '../models';
+import {notify} from '../models';
+import {pnl} from '../models';
 
 export function AddToTradeHistory(arg1:any):Promise<void>;
 
@@ -8,20 +13,60 @@ export function AddToTradeQueue(arg1:any):Promise<void>;
 
 export function AttemptReconnect(arg1:boolean,arg2:boolean,arg3:boolean):Promise<Record<string, any>>;
 
+export function ClearNotifications():Promise<void>;
+
+export function CloseBaseID(arg1:string):Promise<Record<string, any>>;
+
+export function CloseRequestStatus(arg1:string):Promise<closereq.Request>;
+
+export function CloseTicket(arg1:number,arg2:string):Promise<Record<string, any>>;
+
+export function CombinedPnL(arg1:string):Promise<pnl.Report>;
+
 export function DisableAllProtocols(arg1:Array<string>):Promise<void>;
 
+export function DiscardDeadLetter(arg1:string):Promise<Record<string, any>>;
+
+export function EditAndRetryDeadLetter(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetAlerts():Promise<Array<Record<string, any>>>;
+
+export function GetBaseTimeline(arg1:string):Promise<Array<feed.Event>>;
+
+export function GetClients():Promise<Array<Record<string, any>>>;
+
+export function GetDeadLetters():Promise<Array<Record<string, any>>>;
+
+export function GetEventFeed(arg1:number):Promise<Array<feed.Event>>;
+
+export function GetEventsSince(arg1:number):Promise<Array<feed.Event>>;
+
+export function GetExecutionOutcomes(arg1:string):Promise<Array<Record<string, any>>>;
+
 export function GetHedgeSize():Promise<number>;
 
 export function GetNetPosition():Promise<number>;
 
+export function GetNotifications():Promise<Array<notify.Notification>>;
+
+export function GetOrphanTickets():Promise<Array<Record<string, any>>>;
+
 export function GetQueueSize():Promise<number>;
 
+export function GetQueueStats():Promise<Record<string, any>>;
+
+export function GetSchedule():Promise<Record<string, any>>;
+
 export function GetStatus():Promise<Record<string, any>>;
 
+export function GetTerminals():Promise<Array<Record<string, any>>>;
+
 export function GetTradeHistory():Promise<Array<main.Trade>>;
 
 export function GetTradeQueue():Promise<any>;
 
+export function GetTradingSession():Promise<Record<string, any>>;
+
 export function HandleCloseHedgeRequest(arg1:any):Promise<void>;
 
 export function HandleElasticUpdate(arg1:any):Promise<void>;
@@ -32,12 +77,28 @@ export function HandleMT5TradeResult(arg1:any):Promise<void>;
 
 export function HandleTrailingStopUpdate(arg1:any):Promise<void>;
 
+export function HedgePnL(arg1:string):Promise<hedgepnl.Summary>;
+
 export function IsAddonConnected():Promise<boolean>;
 
 export function IsHedgebotActive():Promise<boolean>;
 
+export function MarkNotificationsRead(arg1:number):Promise<number>;
+
+export function OpenManualHedge(arg1:string,arg2:string,arg3:number,arg4:string):Promise<Record<string, any>>;
+
 export function PollTradeFromQueue():Promise<any>;
 
+export function RelinkTicket(arg1:number,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function ReleaseHeldTrade(arg1:string):Promise<Record<string, any>>;
+
+export function ReloadConfig():Promise<Record<string, any>>;
+
+export function RetryDeadLetter(arg1:string):Promise<Record<string, any>>;
+
+export function SendTestAlert(arg1:string):Promise<Record<string, any>>;
+
 export function SetAddonConnected(arg1:boolean):Promise<void>;
 
 export function SetHedgebotActive(arg1:boolean):Promise<void>;
@@ -14,10 +14,66 @@ export function AttemptReconnect(arg1, arg2, arg3) {
   return window['go']['main']['App']['AttemptReconnect'](arg1, arg2, arg3);
 }
 
+export function ClearNotifications() {
+  return window['go']['main']['App']['ClearNotifications']();
+}
+
+export function CloseBaseID(arg1) {
+  return window['go']['main']['App']['CloseBaseID'](arg1);
+}
+
+export function CloseRequestStatus(arg1) {
+  return window['go']['main']['App']['CloseRequestStatus'](arg1);
+}
+
+export function CloseTicket(arg1, arg2) {
+  return window['go']['main']['App']['CloseTicket'](arg1, arg2);
+}
+
+export function CombinedPnL(arg1) {
+  return window['go']['main']['App']['CombinedPnL'](arg1);
+}
+
 export function DisableAllProtocols(arg1) {
   return window['go']['main']['App']['DisableAllProtocols'](arg1);
 }
 
+export function DiscardDeadLetter(arg1) {
+  return window['go']['main']['App']['DiscardDeadLetter'](arg1);
+}
+
+export function EditAndRetryDeadLetter(arg1, arg2) {
+  return window['go']['main']['App']['EditAndRetryDeadLetter'](arg1, arg2);
+}
+
+export function GetAlerts() {
+  return window['go']['main']['App']['GetAlerts']();
+}
+
+export function GetBaseTimeline(arg1) {
+  return window['go']['main']['App']['GetBaseTimeline'](arg1);
+}
+
+export function GetClients() {
+  return window['go']['main']['App']['GetClients']();
+}
+
+export function GetDeadLetters() {
+  return window['go']['main']['App']['GetDeadLetters']();
+}
+
+export function GetEventFeed(arg1) {
+  return window['go']['main']['App']['GetEventFeed'](arg1);
+}
+
+export function GetEventsSince(arg1) {
+  return window['go']['main']['App']['GetEventsSince'](arg1);
+}
+
+export function GetExecutionOutcomes(arg1) {
+  return window['go']['main']['App']['GetExecutionOutcomes'](arg1);
+}
+
 export function GetHedgeSize() {
   return window['go']['main']['App']['GetHedgeSize']();
 }
@@ -26,14 +82,34 @@ export function GetNetPosition() {
   return window['go']['main']['App']['GetNetPosition']();
 }
 
+export function GetNotifications() {
+  return window['go']['main']['App']['GetNotifications']();
+}
+
+export function GetOrphanTickets() {
+  return window['go']['main']['App']['GetOrphanTickets']();
+}
+
 export function GetQueueSize() {
   return window['go']['main']['App']['GetQueueSize']();
 }
 
+export function GetQueueStats() {
+  return window['go']['main']['App']['GetQueueStats']();
+}
+
+export function GetSchedule() {
+  return window['go']['main']['App']['GetSchedule']();
+}
+
 export function GetStatus() {
   return window['go']['main']['App']['GetStatus']();
 }
 
+export function GetTerminals() {
+  return window['go']['main']['App']['GetTerminals']();
+}
+
 export function GetTradeHistory() {
   return window['go']['main']['App']['GetTradeHistory']();
 }
@@ -42,6 +118,10 @@ export function GetTradeQueue() {
   return window['go']['main']['App']['GetTradeQueue']();
 }
 
+export function GetTradingSession() {
+  return window['go']['main']['App']['GetTradingSession']();
+}
+
 export function HandleCloseHedgeRequest(arg1) {
   return window['go']['main']['App']['HandleCloseHedgeRequest'](arg1);
 }
@@ -62,6 +142,10 @@ export function HandleTrailingStopUpdate(arg1) {
   return window['go']['main']['App']['HandleTrailingStopUpdate'](arg1);
 }
 
+export function HedgePnL(arg1) {
+  return window['go']['main']['App']['HedgePnL'](arg1);
+}
+
 export function IsAddonConnected() {
   return window['go']['main']['App']['IsAddonConnected']();
 }
@@ -70,10 +154,38 @@ export function IsHedgebotActive() {
   return window['go']['main']['App']['IsHedgebotActive']();
 }
 
+export function MarkNotificationsRead(arg1) {
+  return window['go']['main']['App']['MarkNotificationsRead'](arg1);
+}
+
+export function OpenManualHedge(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['OpenManualHedge'](arg1, arg2, arg3, arg4);
+}
+
 export function PollTradeFromQueue() {
   return window['go']['main']['App']['PollTradeFromQueue']();
 }
 
+export function RelinkTicket(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RelinkTicket'](arg1, arg2, arg3);
+}
+
+export function ReleaseHeldTrade(arg1) {
+  return window['go']['main']['App']['ReleaseHeldTrade'](arg1);
+}
+
+export function ReloadConfig() {
+  return window['go']['main']['App']['ReloadConfig']();
+}
+
+export function RetryDeadLetter(arg1) {
+  return window['go']['main']['App']['RetryDeadLetter'](arg1);
+}
+
+export function SendTestAlert(arg1) {
+  return window['go']['main']['App']['SendTestAlert'](arg1);
+}
+
 export function SetAddonConnected(arg1) {
   return window['go']['main']['App']['SetAddonConnected'](arg1);
 }
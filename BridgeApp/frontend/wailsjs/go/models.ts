@@ -1,3 +1,281 @@
+export namespace closereq {
+	
+	export class Ticket {
+	    ticket: number;
+	    state: string;
+	    requested_volume?: number;
+	    closed_volume?: number;
+	    detail?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Ticket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ticket = source["ticket"];
+	        this.state = source["state"];
+	        this.requested_volume = source["requested_volume"];
+	        this.closed_volume = source["closed_volume"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class Request {
+	    id: string;
+	    base_id: string;
+	    status: string;
+	    policy?: string;
+	    outcome?: string;
+	    cancelled_entries?: number;
+	    tickets: Ticket[];
+	    // Go type: time
+	    created: any;
+	    // Go type: time
+	    completed?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Request(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.base_id = source["base_id"];
+	        this.status = source["status"];
+	        this.policy = source["policy"];
+	        this.outcome = source["outcome"];
+	        this.cancelled_entries = source["cancelled_entries"];
+	        this.tickets = this.convertValues(source["tickets"], Ticket);
+	        this.created = this.convertValues(source["created"], null);
+	        this.completed = this.convertValues(source["completed"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace feed {
+	
+	export class Event {
+	    seq: number;
+	    kind: string;
+	    // Go type: time
+	    time: any;
+	    baseId?: string;
+	    tradeId?: string;
+	    action?: string;
+	    instrument?: string;
+	    account?: string;
+	    terminal?: string;
+	    ticket?: number;
+	    volume?: number;
+	    price?: number;
+	    profit?: number;
+	    status?: string;
+	    detail?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Event(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seq = source["seq"];
+	        this.kind = source["kind"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.baseId = source["baseId"];
+	        this.tradeId = source["tradeId"];
+	        this.action = source["action"];
+	        this.instrument = source["instrument"];
+	        this.account = source["account"];
+	        this.terminal = source["terminal"];
+	        this.ticket = source["ticket"];
+	        this.volume = source["volume"];
+	        this.price = source["price"];
+	        this.profit = source["profit"];
+	        this.status = source["status"];
+	        this.detail = source["detail"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace hedgepnl {
+	
+	export class Deal {
+	    deal_ticket?: number;
+	    price?: number;
+	    open_price?: number;
+	    volume?: number;
+	    commission?: number;
+	    swap?: number;
+	    profit?: number;
+	    server_time?: number;
+	    // Go type: time
+	    received: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Deal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deal_ticket = source["deal_ticket"];
+	        this.price = source["price"];
+	        this.open_price = source["open_price"];
+	        this.volume = source["volume"];
+	        this.commission = source["commission"];
+	        this.swap = source["swap"];
+	        this.profit = source["profit"];
+	        this.server_time = source["server_time"];
+	        this.received = this.convertValues(source["received"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Ticket {
+	    ticket: number;
+	    open?: Deal;
+	    closes?: Deal[];
+	    closed: boolean;
+	    floating_profit?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Ticket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ticket = source["ticket"];
+	        this.open = this.convertValues(source["open"], Deal);
+	        this.closes = this.convertValues(source["closes"], Deal);
+	        this.closed = source["closed"];
+	        this.floating_profit = source["floating_profit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Summary {
+	    base_id: string;
+	    tickets: Ticket[];
+	    open_tickets: number;
+	    opened_volume: number;
+	    closed_volume: number;
+	    realized_profit: number;
+	    commission: number;
+	    swap: number;
+	    floating_profit: number;
+	    net: number;
+	    // Go type: time
+	    updated: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.base_id = source["base_id"];
+	        this.tickets = this.convertValues(source["tickets"], Ticket);
+	        this.open_tickets = source["open_tickets"];
+	        this.opened_volume = source["opened_volume"];
+	        this.closed_volume = source["closed_volume"];
+	        this.realized_profit = source["realized_profit"];
+	        this.commission = source["commission"];
+	        this.swap = source["swap"];
+	        this.floating_profit = source["floating_profit"];
+	        this.net = source["net"];
+	        this.updated = this.convertValues(source["updated"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace main {
 	
 	export class Trade {
@@ -28,6 +306,9 @@ export namespace main {
 	    qt_position_id?: string;
 	    strategy_tag?: string;
 	    origin_platform?: string;
+	    mt5_symbol?: string;
+	    hedge_lot?: number;
+	    terminal?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Trade(source);
@@ -61,6 +342,65 @@ export namespace main {
 	        this.qt_position_id = source["qt_position_id"];
 	        this.strategy_tag = source["strategy_tag"];
 	        this.origin_platform = source["origin_platform"];
+	        this.mt5_symbol = source["mt5_symbol"];
+	        this.hedge_lot = source["hedge_lot"];
+	        this.terminal = source["terminal"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace notify {
+	
+	export class Notification {
+	    id: number;
+	    kind: string;
+	    severity: string;
+	    title: string;
+	    message: string;
+	    baseId?: string;
+	    ticket?: number;
+	    // Go type: time
+	    time: any;
+	    sound: string;
+	    desktop: boolean;
+	    read: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Notification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.severity = source["severity"];
+	        this.title = source["title"];
+	        this.message = source["message"];
+	        this.baseId = source["baseId"];
+	        this.ticket = source["ticket"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.sound = source["sound"];
+	        this.desktop = source["desktop"];
+	        this.read = source["read"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -84,3 +424,171 @@ export namespace main {
 
 }
 
+export namespace pnl {
+	
+	export class Account {
+	    account: string;
+	    day: string;
+	    qt_balance: number;
+	    qt_daily_pnl: number;
+	    qt_session_trades: number;
+	    has_qt: boolean;
+	    entries: number;
+	    hedge_net: number;
+	    combined: number;
+	    positions: number;
+	    loss_limit_hit: boolean;
+	    // Go type: time
+	    qt_updated?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Account(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.account = source["account"];
+	        this.day = source["day"];
+	        this.qt_balance = source["qt_balance"];
+	        this.qt_daily_pnl = source["qt_daily_pnl"];
+	        this.qt_session_trades = source["qt_session_trades"];
+	        this.has_qt = source["has_qt"];
+	        this.entries = source["entries"];
+	        this.hedge_net = source["hedge_net"];
+	        this.combined = source["combined"];
+	        this.positions = source["positions"];
+	        this.loss_limit_hit = source["loss_limit_hit"];
+	        this.qt_updated = this.convertValues(source["qt_updated"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Day {
+	    day: string;
+	    qt_daily_pnl: number;
+	    hedge_net: number;
+	    combined: number;
+	    positions: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Day(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.day = source["day"];
+	        this.qt_daily_pnl = source["qt_daily_pnl"];
+	        this.hedge_net = source["hedge_net"];
+	        this.combined = source["combined"];
+	        this.positions = source["positions"];
+	    }
+	}
+	export class Position {
+	    base_id: string;
+	    account?: string;
+	    instrument?: string;
+	    day: string;
+	    qt_profit: number;
+	    has_qt_profit: boolean;
+	    hedge_realized: number;
+	    hedge_floating: number;
+	    hedge_costs: number;
+	    hedge_net: number;
+	    combined: number;
+	    hedge_open: boolean;
+	    // Go type: time
+	    updated: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Position(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.base_id = source["base_id"];
+	        this.account = source["account"];
+	        this.instrument = source["instrument"];
+	        this.day = source["day"];
+	        this.qt_profit = source["qt_profit"];
+	        this.has_qt_profit = source["has_qt_profit"];
+	        this.hedge_realized = source["hedge_realized"];
+	        this.hedge_floating = source["hedge_floating"];
+	        this.hedge_costs = source["hedge_costs"];
+	        this.hedge_net = source["hedge_net"];
+	        this.combined = source["combined"];
+	        this.hedge_open = source["hedge_open"];
+	        this.updated = this.convertValues(source["updated"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Report {
+	    day: Day;
+	    accounts: Account[];
+	    positions: Position[];
+	    days: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.day = this.convertValues(source["day"], Day);
+	        this.accounts = this.convertValues(source["accounts"], Account);
+	        this.positions = this.convertValues(source["positions"], Position);
+	        this.days = source["days"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
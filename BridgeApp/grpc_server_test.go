@@ -7,9 +7,13 @@ import (
 	"testing"
 	"time"
 
+	"BridgeApp/internal/closereq"
+	"BridgeApp/internal/deadletter"
 	grpcserver "BridgeApp/internal/grpc"
 	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/hedgepnl"
 	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/pnl"
 	"fmt"
 	"log"
 	"net"
@@ -46,6 +50,8 @@ func (m *MockApp) PollTradeFromQueue() interface{} {
 	return nil
 }
 
+func (m *MockApp) PollTradeFromQueueFor(terminal string) interface{} { return m.PollTradeFromQueue() }
+
 func (m *MockApp) AddToTradeQueue(trade interface{}) error {
 	m.trades = append(m.trades, trade)
 	m.queueSize = len(m.trades)
@@ -55,6 +61,7 @@ func (m *MockApp) AddToTradeQueue(trade interface{}) error {
 func (m *MockApp) GetNetPosition() int                                         { return m.netPos }
 func (m *MockApp) GetHedgeSize() float64                                       { return m.hedgeSize }
 func (m *MockApp) GetQueueSize() int                                           { return m.queueSize }
+func (m *MockApp) QueueSizes() map[string]int                                  { return map[string]int{"default": m.queueSize} }
 func (m *MockApp) IsAddonConnected() bool                                      { return m.addonConn }
 func (m *MockApp) IsHedgebotActive() bool                                      { return m.hedgebotActive }
 func (m *MockApp) SetAddonConnected(connected bool)                            { m.addonConn = connected }
@@ -64,7 +71,23 @@ func (m *MockApp) HandleHedgeCloseNotification(notification interface{}) error {
 func (m *MockApp) HandleMT5TradeResult(result interface{}) error               { return nil }
 func (m *MockApp) HandleElasticUpdate(update interface{}) error                { return nil }
 func (m *MockApp) HandleTrailingStopUpdate(update interface{}) error           { return nil }
-func (m *MockApp) HandleCloseHedgeRequest(request interface{}) error          { return nil }
+
+func (m *MockApp) CloseHedge(request interface{}) (map[string]string, error) { return nil, nil }
+func (m *MockApp) CloseRequestStatus(id string) (closereq.Request, error) {
+	return closereq.Request{}, closereq.ErrNotFound
+}
+func (m *MockApp) HedgePnL(baseID string) (hedgepnl.Summary, error) {
+	return hedgepnl.Summary{}, hedgepnl.ErrUnknownBase
+}
+func (m *MockApp) CombinedPnL(day string) pnl.Report {
+	return pnl.Report{Day: pnl.Day{Day: day}}
+}
+
+func (m *MockApp) DeadLetterTrade(trade interface{}, reason, detail, terminal string) {}
+func (m *MockApp) DeadLetters() []deadletter.Entry                                    { return nil }
+func (m *MockApp) ResolveDeadLetter(id, action string, edits []byte) error            { return nil }
+func (m *MockApp) StatusDetail() grpcserver.StatusDetail                              { return grpcserver.StatusDetail{} }
+func (m *MockApp) EntryMaxAge() time.Duration                                         { return 0 }
 
 const bufSize = 1024 * 1024
 
@@ -0,0 +1,321 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rules an alert is raised for.
+const (
+	RuleMT5Disconnected = "mt5_disconnected" // no MT5 EA for longer than mt5_disconnected_after
+	RuleQueueBacklog    = "queue_backlog"    // a terminal queue reached queue_backlog trades
+	RuleCloseTimedOut   = "close_timed_out"  // a close request timed out waiting for MT5
+	RuleOrphanHedge     = "orphan_hedge"     // MT5 reported a position the bridge does not track
+	RuleRiskLimit       = "risk_limit"       // an account reached the daily loss limit
+	RuleTest            = "test"             // sent on request to check the sinks
+)
+
+// Rules lists the rules that can be disabled in the configuration.
+var Rules = []string{RuleMT5Disconnected, RuleQueueBacklog, RuleCloseTimedOut, RuleOrphanHedge, RuleRiskLimit}
+
+// Severities of an alert.
+const (
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+const (
+	// DefaultMT5DisconnectedAfter is how long MT5 may be gone before it is alerted.
+	DefaultMT5DisconnectedAfter = 60 * time.Second
+	// DefaultRateLimit is the least time between two alerts of one rule and subject.
+	DefaultRateLimit = 5 * time.Minute
+	// sendTimeout bounds the delivery of one alert to one sink.
+	sendTimeout = 15 * time.Second
+	// maxRecent bounds the alerts kept for the UI.
+	maxRecent = 50
+)
+
+// Config is the "alerts" section of the bridge configuration file. Alerts are off until a sink
+// is configured or test_mode is set.
+type Config struct {
+	MT5DisconnectedAfter string   `json:"mt5_disconnected_after,omitempty"` // Go duration (default 60s)
+	QueueBacklog         int      `json:"queue_backlog,omitempty"`          // queued trades per terminal that raise an alert (0 = off)
+	Disabled             []string `json:"disabled,omitempty"`               // rules that are not alerted
+	RateLimit            string   `json:"rate_limit,omitempty"`             // Go duration between alerts of one rule and subject (default 5m)
+	TestMode             bool     `json:"test_mode,omitempty"`              // log alerts instead of sending them
+
+	Webhook  *WebhookConfig  `json:"webhook,omitempty"`
+	Telegram *TelegramConfig `json:"telegram,omitempty"`
+	Email    *EmailConfig    `json:"email,omitempty"`
+}
+
+// Validate rejects bad durations, unknown rules and incomplete sinks.
+func (c Config) Validate() error {
+	if _, err := duration("mt5_disconnected_after", c.MT5DisconnectedAfter, DefaultMT5DisconnectedAfter); err != nil {
+		return err
+	}
+	if _, err := duration("rate_limit", c.RateLimit, DefaultRateLimit); err != nil {
+		return err
+	}
+	if c.QueueBacklog < 0 {
+		return fmt.Errorf("alerts: queue_backlog must not be negative, got %d", c.QueueBacklog)
+	}
+	for _, r := range c.Disabled {
+		if !known(r) {
+			return fmt.Errorf("alerts: unknown rule %q in disabled (use one of %s)", r, strings.Join(Rules, ", "))
+		}
+	}
+	_, err := c.sinks()
+	return err
+}
+
+// MT5After returns the configured mt5_disconnected_after.
+func (c Config) MT5After() time.Duration {
+	d, _ := duration("mt5_disconnected_after", c.MT5DisconnectedAfter, DefaultMT5DisconnectedAfter)
+	return d
+}
+
+func (c Config) sinks() ([]Sink, error) {
+	var sinks []Sink
+	if c.Webhook != nil {
+		s, err := NewWebhook(*c.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if c.Telegram != nil {
+		s, err := NewTelegram(*c.Telegram)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if c.Email != nil {
+		s, err := NewEmail(*c.Email)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+func duration(key, v string, def time.Duration) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("alerts: %s %q must be a positive duration such as %s", key, v, def)
+	}
+	return d, nil
+}
+
+func known(rule string) bool {
+	for _, r := range Rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Alert is one notification.
+type Alert struct {
+	Rule       string            `json:"rule"`
+	Severity   string            `json:"severity"`
+	Subject    string            `json:"subject,omitempty"` // what the alert is about (BaseID, ticket, account, terminal)
+	Title      string            `json:"title"`
+	Message    string            `json:"message"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	Time       time.Time         `json:"time"`
+	Suppressed int               `json:"suppressed,omitempty"` // alerts of this rule and subject held back since the last one
+}
+
+// Text renders the alert for chat and mail.
+func (a Alert) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s\n%s", strings.ToUpper(a.Severity), a.Title, a.Message)
+	if a.Suppressed > 0 {
+		fmt.Fprintf(&b, "\n(%d similar alert(s) suppressed)", a.Suppressed)
+	}
+	fmt.Fprintf(&b, "\n%s", a.Time.Format(time.RFC3339))
+	return b.String()
+}
+
+// Sink delivers alerts somewhere.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, a Alert) error
+}
+
+// Delivery is the outcome of an alert on one sink.
+type Delivery struct {
+	Sink  string `json:"sink"`
+	Error string `json:"error,omitempty"`
+}
+
+// Sent is an alert raised by the bridge and where it went.
+type Sent struct {
+	Alert
+	TestMode   bool       `json:"test_mode,omitempty"`
+	Deliveries []Delivery `json:"deliveries,omitempty"`
+}
+
+// Logger receives the outcome of every alert; the app logs it.
+type Logger func(s Sent)
+
+// Alerter applies the rules' rate limit and delivers alerts to the configured sinks.
+type Alerter struct {
+	mu         sync.Mutex
+	cfg        Config
+	sinks      []Sink
+	every      time.Duration
+	disabled   map[string]bool
+	last       map[string]time.Time
+	suppressed map[string]int
+	recent     []Sent
+	logger     Logger
+}
+
+// New returns an alerter with no sinks; alerts are dropped until Configure installs some.
+func New(logger Logger) *Alerter {
+	return &Alerter{
+		every:      DefaultRateLimit,
+		disabled:   map[string]bool{},
+		last:       map[string]time.Time{},
+		suppressed: map[string]int{},
+		logger:     logger,
+	}
+}
+
+// Configure installs cfg; the rate limit history is kept.
+func (al *Alerter) Configure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	sinks, _ := cfg.sinks()
+	every, _ := duration("rate_limit", cfg.RateLimit, DefaultRateLimit)
+	disabled := map[string]bool{}
+	for _, r := range cfg.Disabled {
+		disabled[r] = true
+	}
+	al.mu.Lock()
+	al.cfg, al.sinks, al.every, al.disabled = cfg, sinks, every, disabled
+	al.mu.Unlock()
+	return nil
+}
+
+// Config returns the configuration in use.
+func (al *Alerter) Config() Config {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return al.cfg
+}
+
+// Enabled reports whether alerts of rule would go anywhere.
+func (al *Alerter) Enabled(rule string) bool {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return al.enabledLocked(rule)
+}
+
+func (al *Alerter) enabledLocked(rule string) bool {
+	return !al.disabled[rule] && (al.cfg.TestMode || len(al.sinks) > 0)
+}
+
+// Raise sends a in the background unless its rule is off or an alert of the same rule and
+// subject went out within the rate limit. It never blocks on the sinks, so it may be called
+// with locks held. It reports whether the alert was sent.
+func (al *Alerter) Raise(a Alert) bool {
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	key := a.Rule + "|" + a.Subject
+	al.mu.Lock()
+	if !al.enabledLocked(a.Rule) {
+		al.mu.Unlock()
+		return false
+	}
+	if last, ok := al.last[key]; ok && a.Time.Sub(last) < al.every {
+		al.suppressed[key]++
+		al.mu.Unlock()
+		return false
+	}
+	al.last[key] = a.Time
+	a.Suppressed = al.suppressed[key]
+	delete(al.suppressed, key)
+	sinks, test := al.sinks, al.cfg.TestMode
+	al.mu.Unlock()
+
+	if test {
+		al.record(Sent{Alert: a, TestMode: true})
+		return true
+	}
+	go al.record(Sent{Alert: a, Deliveries: deliver(sinks, a)})
+	return true
+}
+
+// Test sends a test alert to every configured sink, ignoring test mode and the rate limit, and
+// returns the outcome.
+func (al *Alerter) Test(message string) Sent {
+	if strings.TrimSpace(message) == "" {
+		message = "Test alert from the bridge"
+	}
+	a := Alert{Rule: RuleTest, Severity: Warning, Title: "Bridge test alert", Message: message, Time: time.Now()}
+	al.mu.Lock()
+	sinks := al.sinks
+	al.mu.Unlock()
+	s := Sent{Alert: a, Deliveries: deliver(sinks, a)}
+	al.record(s)
+	return s
+}
+
+// Recent returns the latest alerts, newest first.
+func (al *Alerter) Recent() []Sent {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	out := make([]Sent, 0, len(al.recent))
+	for i := len(al.recent) - 1; i >= 0; i-- {
+		out = append(out, al.recent[i])
+	}
+	return out
+}
+
+func (al *Alerter) record(s Sent) {
+	al.mu.Lock()
+	al.recent = append(al.recent, s)
+	if len(al.recent) > maxRecent {
+		al.recent = al.recent[len(al.recent)-maxRecent:]
+	}
+	logger := al.logger
+	al.mu.Unlock()
+	if logger != nil {
+		logger(s)
+	}
+}
+
+// deliver sends a to every sink in parallel and waits for all of them.
+func deliver(sinks []Sink, a Alert) []Delivery {
+	out := make([]Delivery, len(sinks))
+	var wg sync.WaitGroup
+	for i, s := range sinks {
+		wg.Add(1)
+		go func(i int, s Sink) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			out[i] = Delivery{Sink: s.Name()}
+			if err := s.Send(ctx, a); err != nil {
+				out[i].Error = err.Error()
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,177 @@
+package alerts
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStandIn accepts one connection at a time and hands every mail body to mails.
+func smtpStandIn(t *testing.T) (host string, port int, mails chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	mails = make(chan string, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+			reply := func(s string) { w.WriteString(s + "\r\n"); w.Flush() }
+			reply("220 stand-in ESMTP")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					break
+				}
+				cmd := strings.ToUpper(strings.TrimSpace(line))
+				switch {
+				case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+					reply("250 stand-in")
+				case cmd == "DATA":
+					reply("354 go ahead")
+					var body strings.Builder
+					for {
+						l, err := r.ReadString('\n')
+						if err != nil || l == ".\r\n" {
+							break
+						}
+						body.WriteString(l)
+					}
+					mails <- body.String()
+					reply("250 queued")
+				case cmd == "QUIT":
+					reply("221 bye")
+				default:
+					reply("250 ok")
+				}
+				if cmd == "QUIT" {
+					break
+				}
+			}
+			conn.Close()
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, mails
+}
+
+func TestAlertsReachWebhookTelegramAndEmail(t *testing.T) {
+	hooks := make(chan Alert, 4)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var a Alert
+		json.NewDecoder(r.Body).Decode(&a)
+		hooks <- a
+	}))
+	defer webhook.Close()
+	messages := make(chan map[string]string, 4)
+	telegram := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botT0KEN/sendMessage" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var m map[string]string
+		json.NewDecoder(r.Body).Decode(&m)
+		messages <- m
+	}))
+	defer telegram.Close()
+	host, port, mails := smtpStandIn(t)
+
+	var logged []Sent
+	al := New(func(s Sent) { logged = append(logged, s) })
+	err := al.Configure(Config{
+		Webhook:  &WebhookConfig{URL: webhook.URL, Headers: map[string]string{"Authorization": "Bearer k"}},
+		Telegram: &TelegramConfig{BotToken: "T0KEN", ChatID: "42", APIURL: telegram.URL},
+		Email:    &EmailConfig{Host: host, Port: port, From: "bridge@example.com", To: []string{"desk@example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := al.Test("checking sinks")
+	for _, d := range sent.Deliveries {
+		if d.Error != "" {
+			t.Fatalf("%s failed: %s", d.Sink, d.Error)
+		}
+	}
+	if len(sent.Deliveries) != 3 || len(logged) != 1 {
+		t.Fatalf("expected three deliveries and one logged alert, got %+v / %d", sent.Deliveries, len(logged))
+	}
+	if a := <-hooks; a.Rule != RuleTest || a.Message != "checking sinks" {
+		t.Fatalf("webhook got %+v", a)
+	}
+	if m := <-messages; m["chat_id"] != "42" || !strings.Contains(m["text"], "checking sinks") {
+		t.Fatalf("telegram got %+v", m)
+	}
+	if mail := <-mails; !strings.Contains(mail, "Subject: [Bridge warning] Bridge test alert") || !strings.Contains(mail, "checking sinks") {
+		t.Fatalf("email got %q", mail)
+	}
+
+	// A rejected delivery is reported without leaking the bot token
+	al.Configure(Config{Telegram: &TelegramConfig{BotToken: "WRONG", ChatID: "42", APIURL: telegram.URL}})
+	sent = al.Test("")
+	if len(sent.Deliveries) != 1 || sent.Deliveries[0].Error == "" || strings.Contains(sent.Deliveries[0].Error, "WRONG") {
+		t.Fatalf("unexpected delivery %+v", sent.Deliveries)
+	}
+}
+
+func TestAlertsRateLimitAndTestMode(t *testing.T) {
+	al := New(nil)
+	if al.Raise(Alert{Rule: RuleRiskLimit, Subject: "acct"}) {
+		t.Fatal("alerts without sinks or test mode must be dropped")
+	}
+	if err := al.Configure(Config{TestMode: true, RateLimit: "1m", Disabled: []string{RuleOrphanHedge}}); err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Now()
+	raise := func(rule, subject string, at time.Duration) bool {
+		return al.Raise(Alert{Rule: rule, Severity: Critical, Subject: subject, Time: t0.Add(at)})
+	}
+	if !raise(RuleRiskLimit, "acct", 0) || raise(RuleRiskLimit, "acct", 10*time.Second) || raise(RuleRiskLimit, "acct", 20*time.Second) {
+		t.Fatal("repeats within the rate limit must be suppressed")
+	}
+	if !raise(RuleRiskLimit, "other", 0) || raise(RuleOrphanHedge, "123", 0) {
+		t.Fatal("the limit applies per subject and disabled rules never fire")
+	}
+	if !raise(RuleRiskLimit, "acct", time.Minute) {
+		t.Fatal("the rule must fire again after the rate limit")
+	}
+	recent := al.Recent()
+	if len(recent) != 3 || !recent[0].TestMode || recent[0].Suppressed != 2 || len(recent[0].Deliveries) != 0 {
+		t.Fatalf("unexpected recent alerts %+v", recent)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, bad := range []Config{
+		{MT5DisconnectedAfter: "soon"},
+		{RateLimit: "-1s"},
+		{QueueBacklog: -1},
+		{Disabled: []string{"everything"}},
+		{Webhook: &WebhookConfig{URL: "ftp://x"}},
+		{Telegram: &TelegramConfig{BotToken: "t"}},
+		{Email: &EmailConfig{Host: "smtp", From: "a@b", Port: 70000, To: []string{"c@d"}}},
+	} {
+		if bad.Validate() == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+	good := Config{MT5DisconnectedAfter: "2m", Email: &EmailConfig{Host: "smtp", From: "a@b", To: []string{"c@d"}}}
+	if err := good.Validate(); err != nil || good.MT5After() != 2*time.Minute {
+		t.Fatalf("Validate = %v, MT5After = %s", err, good.MT5After())
+	}
+}
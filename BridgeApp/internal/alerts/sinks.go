@@ -0,0 +1,166 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTelegramAPI is the Telegram Bot API endpoint.
+const DefaultTelegramAPI = "https://api.telegram.org"
+
+// WebhookConfig posts every alert as JSON to a URL.
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"` // e.g. an Authorization header
+}
+
+// TelegramConfig sends every alert as a message from a bot to a chat.
+type TelegramConfig struct {
+	BotToken string `json:"bot_token"`
+	ChatID   string `json:"chat_id"`
+	APIURL   string `json:"api_url,omitempty"` // default https://api.telegram.org
+}
+
+// EmailConfig mails every alert through an SMTP server.
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"` // default 587
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+type webhookSink struct {
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+// NewWebhook returns a sink posting alerts to cfg.URL.
+func NewWebhook(cfg WebhookConfig) (Sink, error) {
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return nil, fmt.Errorf("alerts: webhook url %q must be an http(s) URL", cfg.URL)
+	}
+	return &webhookSink{cfg: cfg, client: &http.Client{}}, nil
+}
+
+func (s *webhookSink) Name() string { return "webhook" }
+
+func (s *webhookSink) Send(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	return post(s.client, req)
+}
+
+type telegramSink struct {
+	cfg    TelegramConfig
+	client *http.Client
+}
+
+// NewTelegram returns a sink sending alerts through the Telegram Bot API.
+func NewTelegram(cfg TelegramConfig) (Sink, error) {
+	if strings.TrimSpace(cfg.BotToken) == "" || strings.TrimSpace(cfg.ChatID) == "" {
+		return nil, fmt.Errorf("alerts: telegram needs bot_token and chat_id")
+	}
+	if strings.TrimSpace(cfg.APIURL) == "" {
+		cfg.APIURL = DefaultTelegramAPI
+	}
+	return &telegramSink{cfg: cfg, client: &http.Client{}}, nil
+}
+
+func (s *telegramSink) Name() string { return "telegram" }
+
+func (s *telegramSink) Send(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(map[string]string{"chat_id": s.cfg.ChatID, "text": a.Text()})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimRight(s.cfg.APIURL, "/") + "/bot" + s.cfg.BotToken + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Keep the bot token out of errors and logs
+	if err := post(s.client, req); err != nil {
+		return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), s.cfg.BotToken, "***"))
+	}
+	return nil
+}
+
+func post(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s answered %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+type emailSink struct {
+	cfg EmailConfig
+}
+
+// NewEmail returns a sink mailing alerts through cfg.Host.
+func NewEmail(cfg EmailConfig) (Sink, error) {
+	if strings.TrimSpace(cfg.Host) == "" || strings.TrimSpace(cfg.From) == "" || len(cfg.To) == 0 {
+		return nil, fmt.Errorf("alerts: email needs host, from and to")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return nil, fmt.Errorf("alerts: email port %d out of range", cfg.Port)
+	}
+	return &emailSink{cfg: cfg}, nil
+}
+
+func (s *emailSink) Name() string { return "email" }
+
+func (s *emailSink) Send(ctx context.Context, a Alert) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [Bridge %s] %s\r\n", a.Severity, a.Title)
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(a.Text(), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(addr, auth, s.cfg.From, s.cfg.To, msg.Bytes()) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("smtp %s: %w", addr, ctx.Err())
+	}
+}
@@ -0,0 +1,357 @@
+package clients
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStaleAfter is how long a client without an open stream may stay silent before its
+// session is considered stale. The Quantower add-on probes health every 5 seconds.
+const DefaultStaleAfter = 30 * time.Second
+
+// forgetAfter is how long a stale session stays listed before it is dropped.
+const forgetAfter = time.Hour
+
+// Kind is the role of a client.
+type Kind string
+
+const (
+	Addon Kind = "addon" // Quantower (or legacy NinjaTrader) add-on
+	EA    Kind = "ea"    // MT5 hedge EA
+)
+
+// Config is the "clients" section of the bridge configuration file.
+type Config struct {
+	StaleAfter string `json:"stale_after,omitempty"` // Go duration (default 30s)
+}
+
+// Validate rejects stale_after values that cannot be parsed or are not positive.
+func (c Config) Validate() error {
+	_, err := c.Window()
+	return err
+}
+
+// Window returns the configured stale_after.
+func (c Config) Window() (time.Duration, error) {
+	v := strings.TrimSpace(c.StaleAfter)
+	if v == "" {
+		return DefaultStaleAfter, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("clients: stale_after %q must be a positive duration such as 30s", c.StaleAfter)
+	}
+	return d, nil
+}
+
+// Seen describes one message from a client. An empty ID attaches the message to the client of
+// the same kind and host that was heard from last (EAs identify themselves on their stream only).
+type Seen struct {
+	Kind       Kind
+	ID         string
+	Version    string
+	RemoteAddr string
+	Method     string
+}
+
+// Client is one client session.
+type Client struct {
+	Kind           Kind      `json:"kind"`
+	ID             string    `json:"id"`
+	Version        string    `json:"version,omitempty"`
+	RemoteAddr     string    `json:"remote_addr,omitempty"`
+	ConnectedSince time.Time `json:"connected_since"`
+	LastMessage    time.Time `json:"last_message"`
+	LastMethod     string    `json:"last_method,omitempty"`
+	Messages       uint64    `json:"messages"`
+	Streams        []string  `json:"streams,omitempty"`
+	Stale          bool      `json:"stale"`
+
+	// Declared in the Hello handshake; clients that never said Hello get the legacy output
+	Hello    bool     `json:"hello"`
+	Protocol int      `json:"protocol,omitempty"`
+	Features []string `json:"features,omitempty"` // negotiated features
+	Rejected string   `json:"rejected,omitempty"` // why the handshake failed
+
+	host string
+}
+
+// Registry tracks client sessions by kind, id and host.
+type Registry struct {
+	mu         sync.Mutex
+	staleAfter time.Duration
+	clients    map[string]*Client
+	streams    map[string]string // stream id -> client key
+}
+
+// NewRegistry returns an empty registry using DefaultStaleAfter.
+func NewRegistry() *Registry {
+	return &Registry{staleAfter: DefaultStaleAfter, clients: make(map[string]*Client), streams: make(map[string]string)}
+}
+
+// SetStaleAfter changes how long a client without streams may stay silent.
+func (r *Registry) SetStaleAfter(d time.Duration) {
+	if d <= 0 {
+		d = DefaultStaleAfter
+	}
+	r.mu.Lock()
+	r.staleAfter = d
+	r.mu.Unlock()
+}
+
+func hostOf(addr string) string {
+	if i := strings.LastIndex(addr, ":"); i > 0 && !strings.HasSuffix(addr, "]") {
+		return addr[:i]
+	}
+	return addr
+}
+
+// find returns the session s belongs to and its key (nil when there is none yet); the caller
+// holds r.mu.
+func (r *Registry) find(s Seen) (*Client, string) {
+	host := hostOf(s.RemoteAddr)
+	id := strings.TrimSpace(s.ID)
+	if id == "" {
+		var c *Client
+		for _, cand := range r.clients {
+			if cand.Kind == s.Kind && cand.host == host && (c == nil || cand.LastMessage.After(c.LastMessage)) {
+				c = cand
+			}
+		}
+		if c != nil {
+			return c, ""
+		}
+		id = string(s.Kind)
+	}
+	key := string(s.Kind) + "/" + id + "@" + host
+	return r.clients[key], key
+}
+
+// lookup returns the session s belongs to, creating (or reviving) it; the caller holds r.mu.
+// started reports a new session.
+func (r *Registry) lookup(s Seen, now time.Time) (c *Client, started bool) {
+	c, key := r.find(s)
+	if c != nil {
+		return c, r.revive(c, now)
+	}
+	id := strings.TrimSpace(s.ID)
+	if id == "" {
+		id = string(s.Kind)
+	}
+	c = &Client{Kind: s.Kind, ID: id, RemoteAddr: s.RemoteAddr, ConnectedSince: now, host: hostOf(s.RemoteAddr)}
+	r.clients[key] = c
+	return c, true
+}
+
+// revive starts a new session for a stale client; the caller holds r.mu.
+func (r *Registry) revive(c *Client, now time.Time) bool {
+	if !c.Stale && (len(c.Streams) > 0 || now.Sub(c.LastMessage) < r.staleAfter) {
+		return false
+	}
+	c.Stale, c.ConnectedSince, c.Messages = false, now, 0
+	return true
+}
+
+func (r *Registry) record(c *Client, s Seen, now time.Time) {
+	c.LastMessage = now
+	c.Messages++
+	if s.Method != "" {
+		c.LastMethod = s.Method
+	}
+	if v := strings.TrimSpace(s.Version); v != "" {
+		c.Version = v
+	}
+	if s.RemoteAddr != "" {
+		c.RemoteAddr = s.RemoteAddr
+	}
+}
+
+// Touch records a message. started reports that it opened a new session.
+func (r *Registry) Touch(s Seen, now time.Time) (Client, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, started := r.lookup(s, now)
+	r.record(c, s, now)
+	return c.snapshot(), started
+}
+
+// Declare records the Hello handshake of a client: its protocol and negotiated features, or why
+// it was rejected.
+func (r *Registry) Declare(s Seen, protocol int, features []string, rejected string, now time.Time) (Client, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, started := r.lookup(s, now)
+	r.record(c, s, now)
+	c.Hello, c.Protocol, c.Rejected = true, protocol, rejected
+	c.Features = append([]string(nil), features...)
+	return c.snapshot(), started
+}
+
+// Rejected returns why the Hello of the client s belongs to was rejected ("" when it was not).
+func (r *Registry) Rejected(s Seen) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, _ := r.find(s); c != nil {
+		return c.Rejected
+	}
+	return ""
+}
+
+// StreamClient returns the session a stream is attached to.
+func (r *Registry) StreamClient(streamID string) (Client, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c := r.clients[r.streams[streamID]]; c != nil {
+		return c.snapshot(), true
+	}
+	return Client{}, false
+}
+
+// Declared returns the session of kind and id heard from last, whatever host it came from, so
+// what an EA negotiated is still known while it reconnects.
+func (r *Registry) Declared(kind Kind, id string) (Client, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id = strings.TrimSpace(id); id == "" {
+		id = string(kind)
+	}
+	var found *Client
+	for _, c := range r.clients {
+		if c.Kind == kind && c.ID == id && (found == nil || c.LastMessage.After(found.LastMessage)) {
+			found = c
+		}
+	}
+	if found == nil {
+		return Client{}, false
+	}
+	return found.snapshot(), true
+}
+
+// OpenStream attaches streamID to the session s belongs to, moving it from another session when
+// the client re-identifies on an open stream. An open stream keeps its session live.
+func (r *Registry) OpenStream(s Seen, streamID string, now time.Time) (Client, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detach(streamID)
+	c, started := r.lookup(s, now)
+	r.record(c, s, now)
+	c.Streams = append(c.Streams, streamID)
+	r.streams[streamID] = string(c.Kind) + "/" + c.ID + "@" + c.host
+	return c.snapshot(), started
+}
+
+// CloseStream detaches streamID; the session goes stale once it has been silent for stale_after.
+func (r *Registry) CloseStream(streamID string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c := r.detach(streamID); c != nil {
+		c.LastMessage = now
+	}
+}
+
+// detach removes streamID from its session; the caller holds r.mu.
+func (r *Registry) detach(streamID string) *Client {
+	key, ok := r.streams[streamID]
+	if !ok {
+		return nil
+	}
+	delete(r.streams, streamID)
+	c := r.clients[key]
+	if c == nil {
+		return nil
+	}
+	for i, id := range c.Streams {
+		if id == streamID {
+			c.Streams = append(c.Streams[:i], c.Streams[i+1:]...)
+			break
+		}
+	}
+	return c
+}
+
+// Sweep marks sessions without streams that were silent for stale_after as stale, drops those
+// stale for an hour, and returns the sessions that went stale in this sweep.
+func (r *Registry) Sweep(now time.Time) []Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Client
+	for key, c := range r.clients {
+		if len(c.Streams) > 0 {
+			continue
+		}
+		silent := now.Sub(c.LastMessage)
+		switch {
+		case c.Stale && silent > forgetAfter:
+			delete(r.clients, key)
+		case !c.Stale && silent >= r.staleAfter:
+			c.Stale = true
+			out = append(out, c.snapshot())
+		}
+	}
+	sortClients(out)
+	return out
+}
+
+// Live reports whether a session of kind is not stale.
+func (r *Registry) Live(kind Kind) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.clients {
+		if c.Kind == kind && !c.Stale {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns every session, live ones first, then by kind and id.
+func (r *Registry) List() []Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Client, 0, len(r.clients))
+	for _, c := range r.clients {
+		out = append(out, c.snapshot())
+	}
+	sortClients(out)
+	return out
+}
+
+// Supports reports whether the client handles feature. Clients that never said Hello are
+// assumed to handle everything the bridge sent before the handshake existed.
+func (c Client) Supports(feature string) bool {
+	if !c.Hello {
+		return true
+	}
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) snapshot() Client {
+	out := *c
+	out.Streams = append([]string(nil), c.Streams...)
+	out.Features = append([]string(nil), c.Features...)
+	return out
+}
+
+func sortClients(list []Client) {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Stale != b.Stale {
+			return !a.Stale
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.RemoteAddr < b.RemoteAddr
+	})
+}
@@ -0,0 +1,85 @@
+package clients
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionsGoStaleWithoutStreams(t *testing.T) {
+	r := NewRegistry()
+	r.SetStaleAfter(10 * time.Second)
+	t0 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	if _, started := r.Touch(Seen{Kind: Addon, Version: "2.1.0", RemoteAddr: "10.0.0.5:51000", Method: "SystemHeartbeat"}, t0); !started {
+		t.Fatal("the first message must start a session")
+	}
+	r.OpenStream(Seen{Kind: EA, ID: "FTMO-1", RemoteAddr: "10.0.0.9:50100"}, "stream_1", t0)
+
+	// An unnamed call from the EA host joins the EA identified on its stream
+	if c, started := r.Touch(Seen{Kind: EA, RemoteAddr: "10.0.0.9:50200", Method: "SubmitTradeResult"}, t0.Add(time.Second)); started || c.ID != "FTMO-1" || c.Messages != 2 {
+		t.Fatalf("unexpected EA session %+v (started=%v)", c, started)
+	}
+
+	stale := r.Sweep(t0.Add(11 * time.Second))
+	if len(stale) != 1 || stale[0].Kind != Addon || stale[0].Version != "2.1.0" {
+		t.Fatalf("only the silent addon must go stale, got %+v", stale)
+	}
+	if r.Live(Addon) || !r.Live(EA) {
+		t.Fatal("the EA with an open stream must stay live")
+	}
+
+	r.CloseStream("stream_1", t0.Add(20*time.Second))
+	if len(r.Sweep(t0.Add(25*time.Second))) != 0 {
+		t.Fatal("a closed stream must leave its session live for stale_after")
+	}
+	if stale := r.Sweep(t0.Add(31 * time.Second)); len(stale) != 1 || stale[0].ID != "FTMO-1" || len(stale[0].Streams) != 0 {
+		t.Fatalf("expected the EA to go stale, got %+v", stale)
+	}
+
+	// A stale client coming back starts a new session
+	if c, started := r.Touch(Seen{Kind: Addon, RemoteAddr: "10.0.0.5:51001"}, t0.Add(time.Minute)); !started || !c.ConnectedSince.Equal(t0.Add(time.Minute)) || c.Version != "2.1.0" {
+		t.Fatalf("expected a revived session, got %+v (started=%v)", c, started)
+	}
+	if list := r.List(); len(list) != 2 || list[0].Kind != Addon || list[1].Kind != EA || !list[1].Stale {
+		t.Fatalf("unexpected listing %+v", list)
+	}
+
+	r.Sweep(t0.Add(2 * time.Hour))
+	if list := r.List(); len(list) != 1 || !list[0].Stale || list[0].Kind != Addon {
+		t.Fatalf("the long-stale EA must be forgotten, got %+v", list)
+	}
+}
+
+func TestStreamsMoveWhenTheClientReidentifies(t *testing.T) {
+	r := NewRegistry()
+	now := time.Now()
+	r.OpenStream(Seen{Kind: EA, RemoteAddr: "10.0.0.9:50100"}, "stream_1", now)
+	r.OpenStream(Seen{Kind: EA, ID: "ICM-2", RemoteAddr: "10.0.0.9:50100"}, "stream_1", now)
+
+	for _, c := range r.List() {
+		switch c.ID {
+		case "ea":
+			if len(c.Streams) != 0 {
+				t.Fatalf("the stream must leave the unnamed session, got %+v", c)
+			}
+		case "ICM-2":
+			if len(c.Streams) != 1 || c.Streams[0] != "stream_1" {
+				t.Fatalf("the stream must join the named session, got %+v", c)
+			}
+		default:
+			t.Fatalf("unexpected session %+v", c)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if d, err := (Config{}).Window(); err != nil || d != DefaultStaleAfter {
+		t.Fatalf("default stale_after = %v, %v", d, err)
+	}
+	if err := (Config{StaleAfter: "0s"}).Validate(); err == nil {
+		t.Fatal("a zero stale_after must be rejected")
+	}
+	if err := (Config{StaleAfter: "soon"}).Validate(); err == nil {
+		t.Fatal("an unparsable stale_after must be rejected")
+	}
+}
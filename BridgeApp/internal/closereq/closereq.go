@@ -0,0 +1,249 @@
+package closereq
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is the state of a close request.
+type Status string
+
+const (
+	StatusPending       Status = "pending"        // tickets still awaiting MT5 confirmation
+	StatusDone          Status = "done"           // every requested ticket confirmed (or nothing to close)
+	StatusPartiallyDone Status = "partially_done" // some tickets confirmed, others failed or timed out
+	StatusFailed        Status = "failed"         // MT5 rejected every ticket
+	StatusTimedOut      Status = "timed_out"      // no ticket confirmed before the deadline
+)
+
+// TicketState is the state of one ticket of a close request.
+type TicketState string
+
+const (
+	TicketPending  TicketState = "pending"
+	TicketClosed   TicketState = "closed"
+	TicketFailed   TicketState = "failed"
+	TicketTimedOut TicketState = "timed_out"
+)
+
+// ErrNotFound reports a close request ID the tracker does not know (or no longer retains).
+var ErrNotFound = errors.New("close request not found")
+
+// DefaultRetained is the number of completed requests kept for status queries.
+const DefaultRetained = 500
+
+// Ticket is one MT5 ticket a close request asked to close.
+type Ticket struct {
+	Ticket          uint64      `json:"ticket"`
+	State           TicketState `json:"state"`
+	RequestedVolume float64     `json:"requested_volume,omitempty"` // 0 = whole position
+	ClosedVolume    float64     `json:"closed_volume,omitempty"`
+	Detail          string      `json:"detail,omitempty"`
+}
+
+// Request is a SubmitCloseHedge call tracked until MT5 confirms its tickets.
+type Request struct {
+	ID               string    `json:"id"`
+	BaseID           string    `json:"base_id"`
+	Status           Status    `json:"status"`
+	Policy           string    `json:"policy,omitempty"`
+	Outcome          string    `json:"outcome,omitempty"`
+	CancelledEntries int       `json:"cancelled_entries,omitempty"`
+	Tickets          []Ticket  `json:"tickets"`
+	Created          time.Time `json:"created"`
+	Completed        time.Time `json:"completed,omitempty"`
+}
+
+// Closed returns the number of confirmed tickets.
+func (r Request) Closed() int {
+	n := 0
+	for _, t := range r.Tickets {
+		if t.State == TicketClosed {
+			n++
+		}
+	}
+	return n
+}
+
+// Tracker follows close requests from submission to completion.
+type Tracker struct {
+	mu        sync.Mutex
+	seq       uint64
+	retained  int
+	byID      map[string]*Request
+	completed []string // completion order, oldest first, for eviction
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{retained: DefaultRetained, byID: make(map[string]*Request)}
+}
+
+// Open registers a request for tickets. A request without tickets completes immediately as done.
+func (t *Tracker) Open(baseID, policy, outcome string, cancelled int, tickets []Ticket) Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	r := &Request{
+		ID:               fmt.Sprintf("close-%d-%d", time.Now().Unix(), t.seq),
+		BaseID:           baseID,
+		Status:           StatusPending,
+		Policy:           policy,
+		Outcome:          outcome,
+		CancelledEntries: cancelled,
+		Created:          time.Now(),
+	}
+	for _, tk := range tickets {
+		tk.State = TicketPending
+		r.Tickets = append(r.Tickets, tk)
+	}
+	t.byID[r.ID] = r
+	if len(r.Tickets) == 0 {
+		t.completeLocked(r, StatusDone)
+	}
+	return clone(r)
+}
+
+// Active returns the oldest pending request of baseID.
+func (t *Tracker) Active(baseID string) (Request, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var found *Request
+	for _, r := range t.byID {
+		if r.BaseID == baseID && r.Status == StatusPending && (found == nil || r.Created.Before(found.Created)) {
+			found = r
+		}
+	}
+	if found == nil {
+		return Request{}, false
+	}
+	return clone(found), true
+}
+
+// Pending returns the number of requests still awaiting MT5 confirmation.
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, r := range t.byID {
+		if r.Status == StatusPending {
+			n++
+		}
+	}
+	return n
+}
+
+// Get returns the request with id.
+func (t *Tracker) Get(id string) (Request, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.byID[id]
+	if !ok {
+		return Request{}, false
+	}
+	return clone(r), true
+}
+
+// Confirm marks ticket of baseID closed. It returns the request when this completed it.
+func (t *Tracker) Confirm(baseID string, ticket uint64, volume float64) (Request, bool) {
+	return t.settle(baseID, ticket, TicketClosed, volume, "")
+}
+
+// Fail marks ticket of baseID as rejected by MT5. It returns the request when this completed it.
+func (t *Tracker) Fail(baseID string, ticket uint64, detail string) (Request, bool) {
+	return t.settle(baseID, ticket, TicketFailed, 0, detail)
+}
+
+func (t *Tracker) settle(baseID string, ticket uint64, state TicketState, volume float64, detail string) (Request, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, idx := t.pendingTicketLocked(baseID, ticket)
+	if r == nil {
+		return Request{}, false
+	}
+	tk := &r.Tickets[idx]
+	tk.State, tk.ClosedVolume, tk.Detail = state, volume, detail
+	for _, other := range r.Tickets {
+		if other.State == TicketPending {
+			return Request{}, false
+		}
+	}
+	t.completeLocked(r, outcome(r))
+	return clone(r), true
+}
+
+// Expire times out the tickets of request id still pending. It returns the request when this
+// completed it.
+func (t *Tracker) Expire(id string) (Request, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.byID[id]
+	if !ok || r.Status != StatusPending {
+		return Request{}, false
+	}
+	for i := range r.Tickets {
+		if r.Tickets[i].State == TicketPending {
+			r.Tickets[i].State = TicketTimedOut
+		}
+	}
+	t.completeLocked(r, outcome(r))
+	return clone(r), true
+}
+
+// pendingTicketLocked finds the oldest pending request of baseID still waiting for ticket.
+func (t *Tracker) pendingTicketLocked(baseID string, ticket uint64) (*Request, int) {
+	var found *Request
+	idx := -1
+	for _, r := range t.byID {
+		if r.BaseID != baseID || r.Status != StatusPending || (found != nil && !r.Created.Before(found.Created)) {
+			continue
+		}
+		for i, tk := range r.Tickets {
+			if tk.Ticket == ticket && tk.State == TicketPending {
+				found, idx = r, i
+				break
+			}
+		}
+	}
+	return found, idx
+}
+
+// outcome derives the final status from the ticket states.
+func outcome(r *Request) Status {
+	closed, failed := 0, 0
+	for _, tk := range r.Tickets {
+		switch tk.State {
+		case TicketClosed:
+			closed++
+		case TicketFailed:
+			failed++
+		}
+	}
+	switch {
+	case closed == len(r.Tickets):
+		return StatusDone
+	case closed > 0:
+		return StatusPartiallyDone
+	case failed > 0:
+		return StatusFailed
+	default:
+		return StatusTimedOut
+	}
+}
+
+func (t *Tracker) completeLocked(r *Request, status Status) {
+	r.Status, r.Completed = status, time.Now()
+	t.completed = append(t.completed, r.ID)
+	for len(t.completed) > t.retained {
+		delete(t.byID, t.completed[0])
+		t.completed = t.completed[1:]
+	}
+}
+
+func clone(r *Request) Request {
+	out := *r
+	out.Tickets = append([]Ticket(nil), r.Tickets...)
+	return out
+}
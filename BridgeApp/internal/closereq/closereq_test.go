@@ -0,0 +1,69 @@
+package closereq
+
+import "testing"
+
+func TestRequestCompletesWhenEveryTicketSettles(t *testing.T) {
+	tr := NewTracker()
+	r := tr.Open("BASE_1", "fifo", "enqueued", 0, []Ticket{{Ticket: 11}, {Ticket: 12, RequestedVolume: 0.2}})
+	if r.Status != StatusPending || len(r.Tickets) != 2 {
+		t.Fatalf("unexpected request: %+v", r)
+	}
+	if active, ok := tr.Active("BASE_1"); !ok || active.ID != r.ID {
+		t.Fatalf("expected %s to be active, got %+v (ok=%v)", r.ID, active, ok)
+	}
+
+	if _, done := tr.Confirm("BASE_1", 11, 0.5); done {
+		t.Fatal("request must stay pending while ticket 12 is open")
+	}
+	if _, done := tr.Confirm("BASE_OTHER", 12, 0.2); done {
+		t.Fatal("a ticket of another BaseID must not settle the request")
+	}
+	done, ok := tr.Confirm("BASE_1", 12, 0.2)
+	if !ok || done.Status != StatusDone || done.Closed() != 2 || done.Tickets[1].ClosedVolume != 0.2 {
+		t.Fatalf("expected the request to be done, got %+v (ok=%v)", done, ok)
+	}
+	if _, ok := tr.Active("BASE_1"); ok {
+		t.Fatal("a completed request must not be active")
+	}
+	if got, ok := tr.Get(r.ID); !ok || got.Status != StatusDone || got.Completed.IsZero() {
+		t.Fatalf("Get after completion: %+v (ok=%v)", got, ok)
+	}
+}
+
+func TestRequestOutcomes(t *testing.T) {
+	tr := NewTracker()
+
+	partial := tr.Open("B", "lifo", "enqueued", 0, []Ticket{{Ticket: 1}, {Ticket: 2}})
+	tr.Confirm("B", 1, 0.1)
+	if r, ok := tr.Expire(partial.ID); !ok || r.Status != StatusPartiallyDone || r.Tickets[1].State != TicketTimedOut {
+		t.Fatalf("expected partially_done, got %+v (ok=%v)", r, ok)
+	}
+	if _, ok := tr.Expire(partial.ID); ok {
+		t.Fatal("a completed request cannot expire again")
+	}
+
+	failed := tr.Open("B", "fifo", "enqueued", 0, []Ticket{{Ticket: 3}})
+	if r, ok := tr.Fail("B", 3, "market_closed"); !ok || r.ID != failed.ID || r.Status != StatusFailed || r.Tickets[0].Detail != "market_closed" {
+		t.Fatalf("expected failed, got %+v (ok=%v)", r, ok)
+	}
+
+	timedOut := tr.Open("B", "fifo", "enqueued", 0, []Ticket{{Ticket: 4}})
+	if r, ok := tr.Expire(timedOut.ID); !ok || r.Status != StatusTimedOut {
+		t.Fatalf("expected timed_out, got %+v (ok=%v)", r, ok)
+	}
+
+	if r := tr.Open("B", "fifo", "cancelled_entries", 1, nil); r.Status != StatusDone {
+		t.Fatalf("a request without tickets completes immediately, got %+v", r)
+	}
+}
+
+func TestCompletedRequestsAreBounded(t *testing.T) {
+	tr := NewTracker()
+	tr.retained = 2
+	first := tr.Open("B", "fifo", "idempotent", 0, nil)
+	tr.Open("B", "fifo", "idempotent", 0, nil)
+	tr.Open("B", "fifo", "idempotent", 0, nil)
+	if _, ok := tr.Get(first.ID); ok {
+		t.Fatal("the oldest completed request should have been evicted")
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"BridgeApp/internal/alerts"
+	"BridgeApp/internal/clients"
+	"BridgeApp/internal/deadletter"
+	"BridgeApp/internal/execution"
+	"BridgeApp/internal/hours"
+	"BridgeApp/internal/idempotency"
+	"BridgeApp/internal/notify"
+	"BridgeApp/internal/queue"
+	"BridgeApp/internal/routing"
+	"BridgeApp/internal/schedule"
+	"BridgeApp/internal/selection"
+	"BridgeApp/internal/session"
+	"BridgeApp/internal/sizing"
+	"BridgeApp/internal/symbols"
+)
+
+// Config is the optional bridge configuration file. Every section is optional;
+// a missing file yields a zero Config, which preserves the legacy behaviour.
+type Config struct {
+	Symbols symbols.Config `json:"symbols"`
+	Sizing  sizing.Config  `json:"sizing"`
+	Routing routing.Config `json:"routing"`
+	Queue   queue.Config   `json:"queue"`
+
+	DeadLetter  deadletter.Config     `json:"dead_letter"`
+	Retry       execution.RetryConfig `json:"retry"`
+	Idempotency idempotency.Config    `json:"idempotency"`
+	Clients     clients.Config        `json:"clients"`
+	Alerts      alerts.Config         `json:"alerts"`
+
+	Notifications notify.Config `json:"notifications"`
+
+	CloseSelection selection.Config `json:"close_selection"`
+	Session        session.Config   `json:"session"`
+	Schedule       schedule.Config  `json:"schedule"`
+	TradingHours   hours.Config     `json:"trading_hours"`
+
+	path string
+}
+
+// Path returns the file the configuration was loaded from ("" when defaults are in use).
+func (c *Config) Path() string { return c.path }
+
+// DefaultPath determines the configuration file location:
+// 1) BRIDGE_CONFIG env var, if set
+// 2) "bridge-config.json" next to the current executable
+// 3) fallback to CWD "bridge-config.json"
+func DefaultPath() string {
+	if p := os.Getenv("BRIDGE_CONFIG"); p != "" {
+		return p
+	}
+	if exePath, err := os.Executable(); err == nil {
+		return filepath.Join(filepath.Dir(exePath), "bridge-config.json")
+	}
+	return "bridge-config.json"
+}
+
+// DefaultSpillDir determines where queue overflow is spilled when the configuration does not say:
+// 1) BRIDGE_SPILL_DIR env var, if set
+// 2) "spill" next to the current executable
+func DefaultSpillDir() string {
+	if p := os.Getenv("BRIDGE_SPILL_DIR"); p != "" {
+		return p
+	}
+	if exePath, err := os.Executable(); err == nil {
+		return filepath.Join(filepath.Dir(exePath), "spill")
+	}
+	return "spill"
+}
+
+// DefaultDeadLetterPath is where undeliverable trades are kept when the configuration does not say:
+// "dead-letters.json" next to the current executable.
+func DefaultDeadLetterPath() string {
+	if exePath, err := os.Executable(); err == nil {
+		return filepath.Join(filepath.Dir(exePath), "dead-letters.json")
+	}
+	return "dead-letters.json"
+}
+
+// DefaultIdempotencyPath is where idempotency keys are kept when the configuration does not say:
+// "idempotency-keys.json" next to the current executable.
+func DefaultIdempotencyPath() string {
+	if exePath, err := os.Executable(); err == nil {
+		return filepath.Join(filepath.Dir(exePath), "idempotency-keys.json")
+	}
+	return "idempotency-keys.json"
+}
+
+// Load reads and validates the configuration at path. A missing file is not an error.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("config: read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+	cfg.path = path
+	return cfg, nil
+}
+
+// Validate checks every section so bad configuration is rejected at load rather than at trade time.
+func (c *Config) Validate() error {
+	if _, err := symbols.New(c.Symbols); err != nil {
+		return err
+	}
+	if _, err := sizing.New(c.Sizing); err != nil {
+		return err
+	}
+	if _, err := routing.New(c.Routing); err != nil {
+		return err
+	}
+	if err := c.Queue.Validate(); err != nil {
+		return err
+	}
+	if err := c.DeadLetter.Validate(); err != nil {
+		return err
+	}
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
+	if err := c.Idempotency.Validate(); err != nil {
+		return err
+	}
+	if err := c.Clients.Validate(); err != nil {
+		return err
+	}
+	if err := c.Alerts.Validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.Validate(); err != nil {
+		return err
+	}
+	if err := c.CloseSelection.Validate(); err != nil {
+		return err
+	}
+	if err := c.Session.Validate(); err != nil {
+		return err
+	}
+	if err := c.Schedule.Validate(); err != nil {
+		return err
+	}
+	if err := c.TradingHours.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
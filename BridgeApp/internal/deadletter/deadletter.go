@@ -0,0 +1,209 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons a trade ends up in the dead-letter store.
+const (
+	ReasonConversion  = "conversion_failed"  // queued trade could not be converted for the MT5 stream
+	ReasonStreamFull  = "stream_buffer_full" // MT5 stream buffer was full when the trade was forwarded
+	ReasonMT5Rejected = "mt5_rejected"       // MT5 executed the trade and returned an error status
+)
+
+// DefaultMaxEntries bounds the store when the configuration leaves it unset.
+const DefaultMaxEntries = 500
+
+// Config is the "dead_letter" section of the bridge configuration file.
+type Config struct {
+	Path       string `json:"path,omitempty"`        // JSON file the store is kept in ("off" = memory only)
+	MaxEntries int    `json:"max_entries,omitempty"` // oldest entries are evicted beyond this (default 500)
+}
+
+// Validate rejects a negative limit.
+func (c Config) Validate() error {
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("dead_letter: max_entries must not be negative, got %d", c.MaxEntries)
+	}
+	return nil
+}
+
+// Entry is one undeliverable trade with the original payload, so it can be retried as it was.
+type Entry struct {
+	ID          string          `json:"id"`
+	Reason      string          `json:"reason"`
+	Detail      string          `json:"detail,omitempty"`
+	Attempts    int             `json:"attempts"`
+	TradeID     string          `json:"trade_id,omitempty"`
+	BaseID      string          `json:"base_id,omitempty"`
+	Action      string          `json:"action,omitempty"`
+	Terminal    string          `json:"terminal,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	FirstFailed time.Time       `json:"first_failed"`
+	LastFailed  time.Time       `json:"last_failed"`
+}
+
+// Store keeps dead letters in memory and mirrors them to a JSON file so they survive restarts.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	max     int
+	seq     uint64
+	entries []Entry
+	retried map[string]Entry // trade id -> entry taken out for retry, to carry attempts forward
+}
+
+// Open loads the store at path ("" or "off" keeps it in memory only).
+func Open(cfg Config) *Store {
+	s := &Store{max: cfg.MaxEntries, retried: make(map[string]Entry)}
+	if s.max == 0 {
+		s.max = DefaultMaxEntries
+	}
+	if p := strings.TrimSpace(cfg.Path); p != "" && !strings.EqualFold(p, "off") {
+		s.path = p
+	}
+	if s.path == "" {
+		return s
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("WARN: dead-letter store %s could not be read: %v", s.path, err)
+		}
+		return s
+	}
+	if err := json.Unmarshal(b, &s.entries); err != nil {
+		log.Printf("WARN: dead-letter store %s is corrupt, starting empty: %v", s.path, err)
+		s.entries = nil
+	}
+	for _, e := range s.entries {
+		var n uint64
+		if _, err := fmt.Sscanf(e.ID, "dl-%d", &n); err == nil && n > s.seq {
+			s.seq = n
+		}
+	}
+	return s
+}
+
+// Path returns the backing file ("" when the store is memory only).
+func (s *Store) Path() string { return s.path }
+
+// SetMaxEntries changes the eviction limit (0 = default).
+func (s *Store) SetMaxEntries(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n == 0 {
+		n = DefaultMaxEntries
+	}
+	s.max = n
+}
+
+// Add records a failed trade. A trade that fails again after a retry keeps its entry id and
+// first-failure time and has its attempt count incremented.
+func (s *Store) Add(e Entry) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	e.Attempts, e.FirstFailed, e.LastFailed = 1, now, now
+	if prev, ok := s.retried[e.TradeID]; ok && e.TradeID != "" {
+		delete(s.retried, e.TradeID)
+		e.ID, e.FirstFailed, e.Attempts = prev.ID, prev.FirstFailed, prev.Attempts+1
+	}
+	if e.ID == "" {
+		s.seq++
+		e.ID = fmt.Sprintf("dl-%d", s.seq)
+	}
+	s.entries = append(s.entries, e)
+	if len(s.entries) > s.max {
+		evicted := s.entries[:len(s.entries)-s.max]
+		log.Printf("WARN: dead-letter store full, evicting %d oldest entries", len(evicted))
+		s.entries = append([]Entry(nil), s.entries[len(evicted):]...)
+	}
+	s.save()
+	return e
+}
+
+// List returns the entries, most recent failure first.
+func (s *Store) List() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Entry, 0, len(s.entries))
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		out = append(out, s.entries[i]) // newest first on equal timestamps
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].LastFailed.After(out[j].LastFailed) })
+	return out
+}
+
+// Len returns the number of entries.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Take removes the entry for a retry; if the retried trade fails again, Add continues its history.
+func (s *Store) Take(id string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.remove(id)
+	if ok && e.TradeID != "" {
+		s.retried[e.TradeID] = e
+	}
+	return e, ok
+}
+
+// Restore puts back an entry whose retry could not be started.
+func (s *Store) Restore(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.retried, e.TradeID)
+	s.entries = append(s.entries, e)
+	s.save()
+}
+
+// Discard removes the entry for good.
+func (s *Store) Discard(id string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove(id)
+}
+
+func (s *Store) remove(id string) (Entry, bool) {
+	for i, e := range s.entries {
+		if e.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			s.save()
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// save rewrites the backing file; the caller holds s.mu.
+func (s *Store) save() {
+	if s.path == "" {
+		return
+	}
+	b, err := json.MarshalIndent(s.entries, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	}
+	if err == nil {
+		tmp := s.path + ".tmp"
+		if err = os.WriteFile(tmp, b, 0o644); err == nil {
+			err = os.Rename(tmp, s.path)
+		}
+	}
+	if err != nil {
+		log.Printf("ERROR: dead-letter store %s could not be saved: %v", s.path, err)
+	}
+}
@@ -0,0 +1,51 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestRetryFailureKeepsHistory(t *testing.T) {
+	s := Open(Config{Path: "off"})
+	first := s.Add(Entry{Reason: ReasonMT5Rejected, TradeID: "T1", Payload: json.RawMessage(`{"id":"T1"}`)})
+	if first.ID != "dl-1" || first.Attempts != 1 {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+	if _, ok := s.Take(first.ID); !ok || s.Len() != 0 {
+		t.Fatalf("Take should remove the entry, len=%d", s.Len())
+	}
+	again := s.Add(Entry{Reason: ReasonMT5Rejected, TradeID: "T1", Payload: json.RawMessage(`{"id":"T1"}`)})
+	if again.ID != first.ID || again.Attempts != 2 || !again.FirstFailed.Equal(first.FirstFailed) {
+		t.Fatalf("expected the retried trade to continue its entry, got %+v", again)
+	}
+	if _, ok := s.Discard(again.ID); !ok || s.Len() != 0 {
+		t.Fatal("Discard should remove the entry")
+	}
+}
+
+func TestStorePersistsAndEvicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.json")
+	s := Open(Config{Path: path, MaxEntries: 2})
+	for _, id := range []string{"A", "B", "C"} {
+		s.Add(Entry{Reason: ReasonStreamFull, TradeID: id, Payload: json.RawMessage(`{}`)})
+	}
+	if s.Len() != 2 {
+		t.Fatalf("expected eviction down to 2 entries, got %d", s.Len())
+	}
+
+	reopened := Open(Config{Path: path})
+	list := reopened.List()
+	if len(list) != 2 || list[0].TradeID != "C" || list[1].TradeID != "B" {
+		t.Fatalf("unexpected entries after reopen: %+v", list)
+	}
+	if e := reopened.Add(Entry{Reason: ReasonConversion, TradeID: "D"}); e.ID != "dl-4" {
+		t.Fatalf("ids must continue after reopen, got %s", e.ID)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{MaxEntries: -1}).Validate(); err == nil {
+		t.Fatal("expected negative max_entries to be rejected")
+	}
+}
@@ -0,0 +1,245 @@
+package execution
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is how the bridge should react to an MT5 execution result.
+type Kind string
+
+const (
+	KindSuccess   Kind = "success"   // executed (fully or partially)
+	KindTransient Kind = "transient" // worth retrying: requote, off quotes, timeout, ...
+	KindPermanent Kind = "permanent" // retrying cannot help: no money, market closed, invalid volume, ...
+	KindIgnored   Kind = "ignored"   // the EA deliberately did nothing
+)
+
+// Result is a classified MT5TradeResult.status.
+type Result struct {
+	Kind    Kind   `json:"kind"`
+	Retcode int    `json:"retcode,omitempty"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+}
+
+type retcode struct {
+	name string
+	kind Kind
+}
+
+// retcodes are the MT5 trade server return codes (MqlTradeResult.retcode).
+var retcodes = map[int]retcode{
+	10004: {"requote", KindTransient},
+	10006: {"rejected", KindPermanent},
+	10007: {"canceled", KindPermanent},
+	10008: {"placed", KindSuccess},
+	10009: {"done", KindSuccess},
+	10010: {"done_partial", KindSuccess},
+	10011: {"error", KindTransient},
+	10012: {"timeout", KindTransient},
+	10013: {"invalid", KindPermanent},
+	10014: {"invalid_volume", KindPermanent},
+	10015: {"invalid_price", KindTransient},
+	10016: {"invalid_stops", KindPermanent},
+	10017: {"trade_disabled", KindPermanent},
+	10018: {"market_closed", KindPermanent},
+	10019: {"no_money", KindPermanent},
+	10020: {"price_changed", KindTransient},
+	10021: {"price_off", KindTransient},
+	10022: {"invalid_expiration", KindPermanent},
+	10023: {"order_changed", KindTransient},
+	10024: {"too_many_requests", KindTransient},
+	10025: {"no_changes", KindPermanent},
+	10026: {"server_disables_at", KindPermanent},
+	10027: {"client_disables_at", KindPermanent},
+	10028: {"locked", KindTransient},
+	10029: {"frozen", KindTransient},
+	10030: {"invalid_fill", KindPermanent},
+	10031: {"connection", KindTransient},
+	10032: {"only_real", KindPermanent},
+	10033: {"limit_orders", KindPermanent},
+	10034: {"limit_volume", KindPermanent},
+	10035: {"invalid_order", KindPermanent},
+	10036: {"position_closed", KindPermanent},
+	10038: {"invalid_close_volume", KindPermanent},
+	10039: {"close_order_exist", KindTransient},
+	10040: {"limit_positions", KindPermanent},
+	10041: {"reject_cancel", KindPermanent},
+	10042: {"long_only", KindPermanent},
+	10043: {"short_only", KindPermanent},
+	10044: {"close_only", KindPermanent},
+	10045: {"fifo_close", KindPermanent},
+	10046: {"hedge_prohibited", KindPermanent},
+}
+
+// phrases recognise retcode descriptions sent instead of (or without) the number.
+var phrases = []struct {
+	match string
+	code  int
+}{
+	{"requote", 10004},
+	{"off quote", 10021},
+	{"off_quote", 10021},
+	{"price off", 10021},
+	{"price changed", 10020},
+	{"market closed", 10018},
+	{"market_closed", 10018},
+	{"no money", 10019},
+	{"not enough money", 10019},
+	{"invalid volume", 10014},
+	{"timeout", 10012},
+	{"too many requests", 10024},
+	{"no connection", 10031},
+	{"trade disabled", 10017},
+	{"trading is prohibited", 10017},
+}
+
+var codePattern = regexp.MustCompile(`\b(10\d{3})\b`)
+
+// Classify recognises the MT5 retcode in a result status such as "failed:10004", "requote" or
+// "success". A failure ("failed", "error", "rejected") without a recognisable retcode is treated as
+// permanent; any other status (e.g. a closure reason) counts as success.
+func Classify(status string) Result {
+	s := strings.ToLower(strings.TrimSpace(status))
+	res := Result{Status: status}
+	if m := codePattern.FindStringSubmatch(s); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		if rc, ok := retcodes[code]; ok {
+			res.Retcode, res.Name, res.Kind = code, rc.name, rc.kind
+			return res
+		}
+	}
+	for _, p := range phrases {
+		if strings.Contains(s, p.match) {
+			rc := retcodes[p.code]
+			res.Retcode, res.Name, res.Kind = p.code, rc.name, rc.kind
+			return res
+		}
+	}
+	switch {
+	case s == "ignored" || s == "skipped":
+		res.Kind, res.Name = KindIgnored, s
+	case strings.Contains(s, "fail") || strings.Contains(s, "error") || strings.Contains(s, "reject"):
+		res.Kind, res.Name = KindPermanent, "unclassified"
+	default:
+		res.Kind, res.Name = KindSuccess, "success"
+	}
+	return res
+}
+
+// Failed reports whether the result is a failure.
+func (r Result) Failed() bool { return r.Kind == KindTransient || r.Kind == KindPermanent }
+
+// RetryConfig is the "retry" section of the bridge configuration file.
+type RetryConfig struct {
+	MaxRetries int    `json:"max_retries,omitempty"` // retries of a transient failure (default 3, -1 disables)
+	BaseDelay  string `json:"base_delay,omitempty"`  // first backoff, doubled per attempt (default 500ms)
+	MaxDelay   string `json:"max_delay,omitempty"`   // backoff cap (default 10s)
+}
+
+const (
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 500 * time.Millisecond
+	DefaultMaxDelay   = 10 * time.Second
+)
+
+// Validate rejects malformed durations and retry counts.
+func (c RetryConfig) Validate() error {
+	if c.MaxRetries < -1 {
+		return fmt.Errorf("retry: max_retries must be -1 (disabled) or more, got %d", c.MaxRetries)
+	}
+	if _, _, err := c.delays(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c RetryConfig) delays() (base, max time.Duration, err error) {
+	base, max = DefaultBaseDelay, DefaultMaxDelay
+	if v := strings.TrimSpace(c.BaseDelay); v != "" {
+		if base, err = time.ParseDuration(v); err != nil || base <= 0 {
+			return 0, 0, fmt.Errorf("retry: base_delay %q must be a positive duration", c.BaseDelay)
+		}
+	}
+	if v := strings.TrimSpace(c.MaxDelay); v != "" {
+		if max, err = time.ParseDuration(v); err != nil || max < base {
+			return 0, 0, fmt.Errorf("retry: max_delay %q must be a duration no shorter than base_delay", c.MaxDelay)
+		}
+	}
+	return base, max, nil
+}
+
+// Backoff returns the delay before retry number attempt (1-based); ok is false once retries are exhausted.
+func (c RetryConfig) Backoff(attempt int) (time.Duration, bool) {
+	maxRetries := c.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	if attempt < 1 || attempt > maxRetries {
+		return 0, false
+	}
+	base, max, err := c.delays()
+	if err != nil {
+		base, max = DefaultBaseDelay, DefaultMaxDelay
+	}
+	d := base << (attempt - 1)
+	if d > max || d <= 0 {
+		d = max
+	}
+	return d, true
+}
+
+// Outcome is one MT5 result recorded against its BaseID.
+type Outcome struct {
+	Time     time.Time `json:"time"`
+	TradeID  string    `json:"trade_id,omitempty"`
+	Action   string    `json:"action,omitempty"`
+	Ticket   uint64    `json:"ticket,omitempty"`
+	Attempt  int       `json:"attempt"`
+	Decision string    `json:"decision"` // "filled", "retry_scheduled", "escalated", "ignored"
+	Result
+}
+
+// historyPerBase bounds the outcomes kept per BaseID.
+const historyPerBase = 20
+
+// History keeps the most recent outcomes per BaseID.
+type History struct {
+	mu     sync.Mutex
+	byBase map[string][]Outcome
+}
+
+// NewHistory creates an empty History.
+func NewHistory() *History {
+	return &History{byBase: make(map[string][]Outcome)}
+}
+
+// Record appends o to baseID's outcomes.
+func (h *History) Record(baseID string, o Outcome) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	list := append(h.byBase[baseID], o)
+	if len(list) > historyPerBase {
+		list = list[len(list)-historyPerBase:]
+	}
+	h.byBase[baseID] = list
+}
+
+// For returns baseID's outcomes, oldest first.
+func (h *History) For(baseID string) []Outcome {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Outcome(nil), h.byBase[baseID]...)
+}
+
+// Forget drops baseID's outcomes.
+func (h *History) Forget(baseID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.byBase, baseID)
+}
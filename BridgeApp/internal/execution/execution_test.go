@@ -0,0 +1,71 @@
+package execution
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		status  string
+		kind    Kind
+		retcode int
+	}{
+		{"success", KindSuccess, 0},
+		{"MT5_position_closed", KindSuccess, 0},
+		{"failed:10004", KindTransient, 10004},
+		{"failed retcode=10021 off quotes", KindTransient, 10021},
+		{"Requote", KindTransient, 10004},
+		{"failed:10018", KindPermanent, 10018},
+		{"market closed", KindPermanent, 10018},
+		{"failed:10019", KindPermanent, 10019},
+		{"10009", KindSuccess, 10009},
+		{"ignored", KindIgnored, 0},
+		{"failed", KindPermanent, 0},
+	}
+	for _, c := range cases {
+		got := Classify(c.status)
+		if got.Kind != c.kind || got.Retcode != c.retcode {
+			t.Fatalf("Classify(%q) = %+v; want kind=%s retcode=%d", c.status, got, c.kind, c.retcode)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := RetryConfig{MaxRetries: 3, BaseDelay: "100ms", MaxDelay: "250ms"}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond}
+	for i, w := range want {
+		if d, ok := cfg.Backoff(i + 1); !ok || d != w {
+			t.Fatalf("Backoff(%d) = %v, %v; want %v", i+1, d, ok, w)
+		}
+	}
+	if _, ok := cfg.Backoff(4); ok {
+		t.Fatal("expected retries to be exhausted after max_retries")
+	}
+	if _, ok := (RetryConfig{MaxRetries: -1}).Backoff(1); ok {
+		t.Fatal("max_retries -1 must disable retries")
+	}
+	if d, ok := (RetryConfig{}).Backoff(1); !ok || d != DefaultBaseDelay {
+		t.Fatalf("default Backoff(1) = %v, %v", d, ok)
+	}
+	for _, bad := range []RetryConfig{{BaseDelay: "soon"}, {BaseDelay: "1s", MaxDelay: "10ms"}, {MaxRetries: -2}} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestHistoryKeepsRecentOutcomesPerBase(t *testing.T) {
+	h := NewHistory()
+	for i := 0; i < historyPerBase+5; i++ {
+		h.Record("A", Outcome{Attempt: i})
+	}
+	got := h.For("A")
+	if len(got) != historyPerBase || got[0].Attempt != 5 {
+		t.Fatalf("unexpected history: len=%d first=%d", len(got), got[0].Attempt)
+	}
+	h.Forget("A")
+	if len(h.For("A")) != 0 {
+		t.Fatal("Forget should drop the outcomes")
+	}
+}
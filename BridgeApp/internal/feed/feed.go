@@ -0,0 +1,123 @@
+package feed
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is what happened to a trade on its way through the bridge.
+type Kind string
+
+const (
+	Enqueued    Kind = "enqueued"     // a trade was accepted into a terminal queue
+	SentToMT5   Kind = "sent_to_mt5"  // an MT5 stream took the trade
+	MT5Result   Kind = "mt5_result"   // MT5 reported the outcome of a trade
+	CloseNotice Kind = "close_notice" // the add-on was told an MT5 hedge closed
+	Elastic     Kind = "elastic"      // an elastic hedge update arrived from the add-on
+	Operator    Kind = "operator"     // an operator opened, closed or re-linked a hedge from the UI
+)
+
+// DefaultSize is how many events the feed keeps.
+const DefaultSize = 1000
+
+// Event is one entry of the live feed.
+type Event struct {
+	Seq        uint64    `json:"seq"`
+	Kind       Kind      `json:"kind"`
+	Time       time.Time `json:"time"`
+	BaseID     string    `json:"baseId,omitempty"`
+	TradeID    string    `json:"tradeId,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	Instrument string    `json:"instrument,omitempty"`
+	Account    string    `json:"account,omitempty"`
+	Terminal   string    `json:"terminal,omitempty"`
+	Ticket     uint64    `json:"ticket,omitempty"`
+	Volume     float64   `json:"volume,omitempty"`
+	Price      float64   `json:"price,omitempty"`
+	Profit     float64   `json:"profit,omitempty"`
+	Status     string    `json:"status,omitempty"` // MT5 status, close reason or event type
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// Ring keeps the latest events in a fixed-size buffer; the oldest is overwritten when full.
+type Ring struct {
+	mu   sync.Mutex
+	buf  []Event
+	next int // slot the next event goes to
+	full bool
+	seq  uint64
+}
+
+// NewRing returns a feed holding up to size events (DefaultSize when size <= 0).
+func NewRing(size int) *Ring {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Ring{buf: make([]Event, size)}
+}
+
+// Add stamps e with the next sequence number (and the current time when unset) and stores it.
+func (r *Ring) Add(e Event) Event {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	e.Seq = r.seq
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+	return e
+}
+
+// allLocked returns the stored events, oldest first.
+func (r *Ring) allLocked() []Event {
+	if !r.full {
+		return append([]Event(nil), r.buf[:r.next]...)
+	}
+	out := make([]Event, 0, len(r.buf))
+	out = append(out, r.buf[r.next:]...)
+	return append(out, r.buf[:r.next]...)
+}
+
+// Recent returns up to limit of the latest events, oldest first (all of them when limit <= 0).
+func (r *Ring) Recent(limit int) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := r.allLocked()
+	if limit > 0 && len(all) > limit {
+		all = all[len(all)-limit:]
+	}
+	return all
+}
+
+// Since returns the events after seq, oldest first, so a UI that missed runtime events can catch up.
+func (r *Ring) Since(seq uint64) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Event
+	for _, e := range r.allLocked() {
+		if e.Seq > seq {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Timeline returns the events of one BaseID, oldest first.
+func (r *Ring) Timeline(baseID string) []Event {
+	baseID = strings.TrimSpace(baseID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Event
+	for _, e := range r.allLocked() {
+		if e.BaseID == baseID {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package feed
+
+import "testing"
+
+func TestRingKeepsLatestEventsInOrder(t *testing.T) {
+	r := NewRing(3)
+	for i, base := range []string{"A", "B", "A", "C", "A"} {
+		e := r.Add(Event{Kind: Enqueued, BaseID: base})
+		if e.Seq != uint64(i+1) || e.Time.IsZero() {
+			t.Fatalf("event %d not stamped: %+v", i, e)
+		}
+	}
+
+	recent := r.Recent(0)
+	if len(recent) != 3 || recent[0].Seq != 3 || recent[2].Seq != 5 {
+		t.Fatalf("expected events 3..5 oldest first, got %+v", recent)
+	}
+	if last := r.Recent(1); len(last) != 1 || last[0].Seq != 5 {
+		t.Fatalf("Recent(1) = %+v", last)
+	}
+	if since := r.Since(4); len(since) != 1 || since[0].BaseID != "A" {
+		t.Fatalf("Since(4) = %+v", since)
+	}
+	if tl := r.Timeline(" A "); len(tl) != 2 || tl[0].Seq != 3 || tl[1].Seq != 5 {
+		t.Fatalf("Timeline(A) must hold the retained A events only, got %+v", tl)
+	}
+}
+
+func TestRingBeforeWrap(t *testing.T) {
+	r := NewRing(0)
+	r.Add(Event{Kind: Elastic, BaseID: "X"})
+	if got := r.Recent(10); len(got) != 1 || got[0].Kind != Elastic {
+		t.Fatalf("Recent = %+v", got)
+	}
+	if len(r.Since(1)) != 0 || len(r.Timeline("Y")) != 0 {
+		t.Fatal("no events expected")
+	}
+}
@@ -0,0 +1,171 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/clients"
+	trading "BridgeApp/internal/grpc/proto"
+)
+
+// HedgeClosed is an MT5 hedge closure reported to the addon.
+type HedgeClosed struct {
+	ID          string
+	BaseID      string
+	Time        time.Time
+	Ticket      uint64
+	Volume      float64 // MT5 lots closed
+	Reason      string  // EA closure reason
+	OrderType   string  // "MT5_CLOSE", or "NT_CLOSE_ACK" when the addon requested the close
+	Price       float64 // close price, 0 when unknown
+	Profit      float64 // realized PnL, 0 when unknown
+	Instrument  string
+	AccountName string
+
+	// Costs of the closing deal (0 when unknown)
+	OpenPrice  float64
+	Commission float64
+	Swap       float64
+	ServerTime int64 // MT5 trade server clock, Unix seconds
+}
+
+func (h HedgeClosed) toProto() *trading.HedgeClosedEvent {
+	return &trading.HedgeClosedEvent{
+		EventId:       h.ID,
+		BaseId:        h.BaseID,
+		Mt5Ticket:     h.Ticket,
+		ClosedVolume:  h.Volume,
+		ClosureReason: h.Reason,
+		OrderType:     h.OrderType,
+		ClosePrice:    h.Price,
+		Profit:        h.Profit,
+		Timestamp:     h.Time.Unix(),
+		Instrument:    h.Instrument,
+		AccountName:   h.AccountName,
+		OpenPrice:     h.OpenPrice,
+		Commission:    h.Commission,
+		Swap:          h.Swap,
+		ServerTime:    h.ServerTime,
+	}
+}
+
+// legacyTrade renders the closure as the MT5_CLOSE_NOTIFICATION trade TradingStream add-ons
+// expect: the closure reason travels in nt_trade_result and the lots in quantity/total_quantity.
+func (h HedgeClosed) legacyTrade() *trading.Trade {
+	result := h.Reason
+	if result == "" {
+		result = "mt5_closed"
+	}
+	return &trading.Trade{
+		Id:            h.ID,
+		BaseId:        h.BaseID,
+		Timestamp:     h.Time.Unix(),
+		Action:        "MT5_CLOSE_NOTIFICATION",
+		Quantity:      h.Volume,
+		Price:         h.Price,
+		TotalQuantity: int32(h.Volume),
+		ContractNum:   1,
+		OrderType:     h.OrderType,
+		Instrument:    h.Instrument,
+		AccountName:   h.AccountName,
+		NtTradeResult: result,
+		Mt5Ticket:     h.Ticket,
+	}
+}
+
+// BroadcastMT5CloseToAddonStreams reports an MT5 closure to the addon streams only (never to MT5
+// streams, to prevent circular trades): AddonEventStream clients receive a HedgeClosedEvent,
+// TradingStream clients the legacy MT5_CLOSE_NOTIFICATION trade.
+func (s *Server) BroadcastMT5CloseToAddonStreams(ev HedgeClosed) {
+	log.Printf("gRPC: Broadcasting MT5 close notification to addon streams only: %+v", ev)
+	if ev.OrderType == "NT_CLOSE_ACK" {
+		log.Printf("gRPC: Tagging close as NT_CLOSE_ACK (legacy label for addon acknowledgement)")
+	}
+
+	s.streamsMux.RLock()
+	defer s.streamsMux.RUnlock()
+
+	s.sendToEventStreamsLocked(&trading.AddonEvent{Event: &trading.AddonEvent_HedgeClosed{HedgeClosed: ev.toProto()}}, "MT5 closure event", "")
+	legacy := ev.legacyTrade()
+	for streamID, streamChan := range s.tradeStreams {
+		if !strings.HasPrefix(streamID, "bidir_stream_") {
+			continue
+		}
+		select {
+		case streamChan <- legacy:
+			log.Printf("gRPC: MT5 closure notification sent to addon stream %s", streamID)
+		default:
+			log.Printf("gRPC: Addon stream %s buffer full, skipping MT5 closure notification", streamID)
+		}
+	}
+}
+
+// sendToEventStreamsLocked delivers ev to every AddonEventStream whose client supports feature
+// ("" = every stream). The caller holds streamsMux.
+func (s *Server) sendToEventStreamsLocked(ev *trading.AddonEvent, what, feature string) {
+	for streamID, streamChan := range s.eventStreams {
+		if feature != "" && !s.streamSupports(streamID, feature) {
+			log.Printf("gRPC: Skipping %s for addon event stream %s: client does not support %s", what, streamID, feature)
+			continue
+		}
+		select {
+		case streamChan <- ev:
+			log.Printf("gRPC: %s sent to addon event stream %s", what, streamID)
+		default:
+			log.Printf("gRPC: Addon event stream %s buffer full, skipping %s", streamID, what)
+		}
+	}
+}
+
+// AddonEventStream is TradingStream with a typed envelope: the addon sends trades as on
+// TradingStream and receives AddonEvents (trades, notices and HedgeClosedEvents).
+func (s *Server) AddonEventStream(stream trading.StreamingService_AddonEventStreamServer) error {
+	streamChan := make(chan *trading.AddonEvent, 100)
+	streamID := fmt.Sprintf("event_stream_%d", time.Now().UnixNano())
+	if err := s.refuseRejected(stream.Context(), clients.Addon, ""); err != nil {
+		log.Printf("gRPC: Refusing addon event stream %s: %v", streamID, err)
+		return err
+	}
+	log.Printf("gRPC: New addon event stream %s connected", streamID)
+
+	s.streamsMux.Lock()
+	s.eventStreams[streamID] = streamChan
+	s.streamsMux.Unlock()
+
+	defer func() {
+		s.streamsMux.Lock()
+		delete(s.eventStreams, streamID)
+		close(streamChan)
+		s.streamsMux.Unlock()
+		s.closeClientStream(streamID)
+		log.Printf("gRPC: Addon event stream %s disconnected", streamID)
+	}()
+
+	s.openClientStream(stream.Context(), clients.Addon, "", streamID)
+
+	errChan := make(chan error, 2)
+	go func() { errChan <- s.receiveAddonTrades(stream.Context(), streamID, stream.Recv) }()
+	go func() {
+		for {
+			select {
+			case <-stream.Context().Done():
+				errChan <- stream.Context().Err()
+				return
+			case ev := <-streamChan:
+				if err := stream.Send(ev); err != nil {
+					log.Printf("gRPC: Addon event stream %s send error: %v", streamID, err)
+					errChan <- err
+					return
+				}
+			}
+		}
+	}()
+
+	err := <-errChan
+	if err != nil {
+		log.Printf("gRPC: Addon event stream %s closed with error: %v", streamID, err)
+	}
+	return err
+}
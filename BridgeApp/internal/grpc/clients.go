@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"BridgeApp/internal/clients"
+	trading "BridgeApp/internal/grpc/proto"
+	blog "BridgeApp/internal/logging"
+)
+
+// clientKind classifies a health/heartbeat/log source as an add-on or an EA.
+func clientKind(source string) (clients.Kind, bool) {
+	switch strings.ToLower(strings.TrimSpace(source)) {
+	case "hedgebot", "mt5_ea", "mt5", "ea":
+		return clients.EA, true
+	}
+	if isAddonSource(source) {
+		return clients.Addon, true
+	}
+	return "", false
+}
+
+// seenFrom describes a call: "client-id"/"client-version" metadata override id and version, and
+// the peer address and method come from the gRPC context.
+func seenFrom(ctx context.Context, kind clients.Kind, id, version string) clients.Seen {
+	seen := clients.Seen{Kind: kind, ID: id, Version: version}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("client-id"); len(v) > 0 && strings.TrimSpace(v[0]) != "" {
+			seen.ID = strings.TrimSpace(v[0])
+		}
+		if v := md.Get("client-version"); len(v) > 0 && strings.TrimSpace(v[0]) != "" {
+			seen.Version = strings.TrimSpace(v[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		seen.RemoteAddr = p.Addr.String()
+	}
+	if m, ok := grpc.Method(ctx); ok {
+		seen.Method = path.Base(m)
+	}
+	return seen
+}
+
+// clientSeen records a message from a client and refreshes the app's connection flag.
+func (s *Server) clientSeen(ctx context.Context, kind clients.Kind, id, version string) clients.Client {
+	c, started := s.clients.Touch(seenFrom(ctx, kind, id, version), time.Now())
+	s.clientActive(c, started)
+	return c
+}
+
+// openClientStream attaches a stream to its client's session; calling it again on the same
+// stream moves it to the session of the new identity.
+func (s *Server) openClientStream(ctx context.Context, kind clients.Kind, id, streamID string) {
+	c, started := s.clients.OpenStream(seenFrom(ctx, kind, id, ""), streamID, time.Now())
+	s.clientActive(c, started)
+}
+
+// closeClientStream detaches a stream from its session.
+func (s *Server) closeClientStream(streamID string) {
+	s.clients.CloseStream(streamID, time.Now())
+}
+
+func (s *Server) clientActive(c clients.Client, started bool) {
+	if started {
+		log.Printf("gRPC: Client session started - kind=%s id=%s version=%s remote=%s", c.Kind, c.ID, c.Version, c.RemoteAddr)
+		blog.L().Info("clients", "client session started", map[string]interface{}{
+			"kind":    string(c.Kind),
+			"id":      c.ID,
+			"version": c.Version,
+			"remote":  c.RemoteAddr,
+			"method":  c.LastMethod,
+		})
+	}
+	switch c.Kind {
+	case clients.Addon:
+		s.app.SetAddonConnected(true)
+	case clients.EA:
+		s.app.SetHedgebotActive(true)
+	}
+}
+
+// SetClientStaleAfter changes how long a client without streams may stay silent.
+func (s *Server) SetClientStaleAfter(d time.Duration) {
+	s.clients.SetStaleAfter(d)
+}
+
+// Clients returns the client sessions, live ones first.
+func (s *Server) Clients() []clients.Client {
+	return s.clients.List()
+}
+
+// SweepClients marks silent sessions stale and returns those that went stale now.
+func (s *Server) SweepClients(now time.Time) []clients.Client {
+	return s.clients.Sweep(now)
+}
+
+// ClientLive reports whether a client of kind has a live session.
+func (s *Server) ClientLive(kind clients.Kind) bool {
+	return s.clients.Live(kind)
+}
+
+// ListClients reports the client session registry.
+func (s *Server) ListClients(ctx context.Context, req *trading.ClientListRequest) (*trading.ClientListResponse, error) {
+	kind := strings.ToLower(strings.TrimSpace(req.GetKind()))
+	resp := &trading.ClientListResponse{}
+	for _, c := range s.clients.List() {
+		if kind != "" && string(c.Kind) != kind {
+			continue
+		}
+		resp.Clients = append(resp.Clients, clientSessionProto(c))
+	}
+	return resp, nil
+}
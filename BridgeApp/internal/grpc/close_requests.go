@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	trading "BridgeApp/internal/grpc/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetCloseRequestStatus reports the progress of a SubmitCloseHedge request.
+func (s *Server) GetCloseRequestStatus(ctx context.Context, req *trading.CloseRequestStatusRequest) (*trading.CloseRequestStatus, error) {
+	id := strings.TrimSpace(req.GetCloseRequestId())
+	r, err := s.app.CloseRequestStatus(id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	resp := &trading.CloseRequestStatus{
+		CloseRequestId:   r.ID,
+		BaseId:           r.BaseID,
+		Status:           string(r.Status),
+		Policy:           r.Policy,
+		Outcome:          r.Outcome,
+		CancelledEntries: int32(r.CancelledEntries),
+		Tickets:          make([]*trading.CloseTicketStatus, 0, len(r.Tickets)),
+		Created:          r.Created.Unix(),
+	}
+	if !r.Completed.IsZero() {
+		resp.Completed = r.Completed.Unix()
+	}
+	for _, tk := range r.Tickets {
+		resp.Tickets = append(resp.Tickets, &trading.CloseTicketStatus{
+			Ticket:          tk.Ticket,
+			State:           string(tk.State),
+			RequestedVolume: tk.RequestedVolume,
+			ClosedVolume:    tk.ClosedVolume,
+			Detail:          tk.Detail,
+		})
+	}
+	return resp, nil
+}
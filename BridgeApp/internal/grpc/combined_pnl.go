@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	trading "BridgeApp/internal/grpc/proto"
+)
+
+// GetCombinedPnL reports the Quantower and MT5 hedge PnL of a trading day per BaseID and account.
+func (s *Server) GetCombinedPnL(ctx context.Context, req *trading.CombinedPnLRequest) (*trading.CombinedPnLResponse, error) {
+	r := s.app.CombinedPnL(strings.TrimSpace(req.GetDay()))
+	baseID, account := strings.TrimSpace(req.GetBaseId()), strings.TrimSpace(req.GetAccount())
+	resp := &trading.CombinedPnLResponse{
+		Day:        r.Day.Day,
+		QtDailyPnl: r.Day.QTDailyPnL,
+		HedgeNet:   r.Day.HedgeNet,
+		Combined:   r.Day.Combined,
+		Days:       r.Days,
+	}
+	for _, a := range r.Accounts {
+		if account != "" && a.Account != account {
+			continue
+		}
+		resp.Accounts = append(resp.Accounts, &trading.CombinedAccountPnL{
+			Account:    a.Account,
+			Day:        a.Day,
+			QtBalance:  a.QTBalance,
+			QtDailyPnl: a.QTDailyPnL,
+			HedgeNet:   a.HedgeNet,
+			Combined:   a.Combined,
+			Positions:  int32(a.Positions),
+		})
+	}
+	for _, p := range r.Positions {
+		if (baseID != "" && p.BaseID != baseID) || (account != "" && p.Account != account) {
+			continue
+		}
+		resp.Positions = append(resp.Positions, &trading.CombinedPositionPnL{
+			BaseId:        p.BaseID,
+			Account:       p.Account,
+			Instrument:    p.Instrument,
+			Day:           p.Day,
+			QtProfit:      p.QTProfit,
+			HedgeRealized: p.HedgeRealized,
+			HedgeFloating: p.HedgeFloating,
+			HedgeCosts:    p.HedgeCosts,
+			HedgeNet:      p.HedgeNet,
+			Combined:      p.Combined,
+			HedgeOpen:     p.HedgeOpen,
+			Updated:       p.Updated.Unix(),
+		})
+	}
+	return resp, nil
+}
@@ -31,6 +31,10 @@ type Trade struct {
 	QTPositionID      string    `json:"qt_position_id,omitempty"`
 	StrategyTag       string    `json:"strategy_tag,omitempty"`
 	Origin            string    `json:"origin_platform,omitempty"`
+	MT5Symbol         string    `json:"mt5_symbol,omitempty"`
+	HedgeLot          float64   `json:"hedge_lot,omitempty"`
+	Terminal          string    `json:"terminal,omitempty"`
+	CloseVolume       float64   `json:"close_volume,omitempty"`
 }
 
 // Internal struct definitions that match the app.go structures
@@ -64,6 +68,10 @@ type InternalTrade struct {
 	QTPositionID         string  `json:"qt_position_id,omitempty"`
 	StrategyTag          string  `json:"strategy_tag,omitempty"`
 	OriginPlatform       string  `json:"origin_platform,omitempty"`
+	MT5Symbol            string  `json:"mt5_symbol,omitempty"`
+	HedgeLot             float64 `json:"hedge_lot,omitempty"`
+	Terminal             string  `json:"terminal,omitempty"`     // destination MT5 terminal (bridge routing)
+	CloseVolume          float64 `json:"close_volume,omitempty"` // CLOSE_HEDGE: lots to close (0 = whole position)
 }
 
 type InternalHedgeCloseNotification struct {
@@ -78,6 +86,16 @@ type InternalHedgeCloseNotification struct {
 	MT5Ticket           uint64  `json:"mt5_ticket,omitempty"`
 	QTPositionID        string  `json:"qt_position_id,omitempty"`
 	QTTradeID           string  `json:"qt_trade_id,omitempty"`
+	CloseVolume         float64 `json:"close_volume,omitempty"`
+
+	// Economics of the closing MT5 deal (EA notifications; 0 = not reported)
+	ClosePrice float64 `json:"close_price,omitempty"`
+	OpenPrice  float64 `json:"open_price,omitempty"`
+	Commission float64 `json:"commission,omitempty"`
+	Swap       float64 `json:"swap,omitempty"`
+	Profit     float64 `json:"profit,omitempty"`
+	ServerTime int64   `json:"server_time,omitempty"`
+	DealTicket uint64  `json:"deal_ticket,omitempty"`
 }
 
 type InternalMT5TradeResult struct {
@@ -86,6 +104,18 @@ type InternalMT5TradeResult struct {
 	Volume  float64 `json:"volume"`
 	IsClose bool    `json:"is_close"`
 	ID      string  `json:"id"`
+	Profit  float64 `json:"profit,omitempty"`
+
+	// Economics of the MT5 deal behind the result (0 = not reported)
+	Price      float64 `json:"price,omitempty"`
+	OpenPrice  float64 `json:"open_price,omitempty"`
+	Commission float64 `json:"commission,omitempty"`
+	Swap       float64 `json:"swap,omitempty"`
+	ServerTime int64   `json:"server_time,omitempty"`
+	DealTicket uint64  `json:"deal_ticket,omitempty"`
+
+	// Terminal the reporting EA serves ("" when it did not identify itself)
+	Terminal string `json:"terminal,omitempty"`
 }
 
 type InternalElasticHedgeUpdate struct {
@@ -145,6 +175,9 @@ func convertProtoToInternalTrade(proto *trading.Trade) *InternalTrade {
 		QTPositionID:         proto.GetQtPositionId(),
 		StrategyTag:          proto.GetStrategyTag(),
 		OriginPlatform:       proto.GetOriginPlatform(),
+		MT5Symbol:            proto.GetMt5Symbol(),
+		HedgeLot:             proto.GetHedgeLot(),
+		CloseVolume:          proto.GetCloseVolume(),
 	}
 }
 
@@ -177,6 +210,9 @@ func ConvertInternalToProtoTrade(internal *InternalTrade) *trading.Trade {
 		QtPositionId:         internal.QTPositionID,
 		StrategyTag:          internal.StrategyTag,
 		OriginPlatform:       internal.OriginPlatform,
+		Mt5Symbol:            internal.MT5Symbol,
+		HedgeLot:             internal.HedgeLot,
+		CloseVolume:          internal.CloseVolume,
 	}
 }
 
@@ -194,6 +230,14 @@ func convertProtoToInternalHedgeClose(proto *trading.HedgeCloseNotification) *In
 		MT5Ticket:           proto.Mt5Ticket,
 		QTPositionID:        proto.QtPositionId,
 		QTTradeID:           proto.QtTradeId,
+		CloseVolume:         proto.CloseVolume,
+		ClosePrice:          proto.ClosePrice,
+		OpenPrice:           proto.OpenPrice,
+		Commission:          proto.Commission,
+		Swap:                proto.Swap,
+		Profit:              proto.Profit,
+		ServerTime:          proto.ServerTime,
+		DealTicket:          proto.DealTicket,
 	}
 }
 
@@ -211,6 +255,14 @@ func convertInternalToProtoHedgeClose(internal *InternalHedgeCloseNotification)
 		Mt5Ticket:           internal.MT5Ticket,
 		QtPositionId:        internal.QTPositionID,
 		QtTradeId:           internal.QTTradeID,
+		CloseVolume:         internal.CloseVolume,
+		ClosePrice:          internal.ClosePrice,
+		OpenPrice:           internal.OpenPrice,
+		Commission:          internal.Commission,
+		Swap:                internal.Swap,
+		Profit:              internal.Profit,
+		ServerTime:          internal.ServerTime,
+		DealTicket:          internal.DealTicket,
 	}
 }
 
@@ -222,6 +274,14 @@ func convertProtoToInternalMT5Result(proto *trading.MT5TradeResult) *InternalMT5
 		Volume:  proto.Volume,
 		IsClose: proto.IsClose,
 		ID:      proto.Id,
+		Profit:  proto.Profit,
+
+		Price:      proto.Price,
+		OpenPrice:  proto.OpenPrice,
+		Commission: proto.Commission,
+		Swap:       proto.Swap,
+		ServerTime: proto.ServerTime,
+		DealTicket: proto.DealTicket,
 	}
 }
 
@@ -233,6 +293,14 @@ func convertInternalToProtoMT5Result(internal *InternalMT5TradeResult) *trading.
 		Volume:  internal.Volume,
 		IsClose: internal.IsClose,
 		Id:      internal.ID,
+		Profit:  internal.Profit,
+
+		Price:      internal.Price,
+		OpenPrice:  internal.OpenPrice,
+		Commission: internal.Commission,
+		Swap:       internal.Swap,
+		ServerTime: internal.ServerTime,
+		DealTicket: internal.DealTicket,
 	}
 }
 
@@ -311,67 +379,9 @@ func convertInternalToMainTrade(internal *InternalTrade) interface{} {
 		NTSessionTrades:   internal.NTSessionTrades,
 		MT5Ticket:         internal.MT5Ticket,
 		NTPointsPer1kLoss: internal.NTPointsPer1kLoss,
-	}
-}
-
-// convertMT5CloseNotificationToProtoTrade converts an MT5 closure notification to a trading.Trade for streaming
-func convertMT5CloseNotificationToProtoTrade(notification interface{}) *trading.Trade {
-	// Type assertion to extract fields from the notification struct
-	if n, ok := notification.(struct {
-		ID              string    `json:"id"`
-		BaseID          string    `json:"base_id"`
-		Time            time.Time `json:"time"`
-		Action          string    `json:"action"`
-		Quantity        float64   `json:"quantity"`
-		Price           float64   `json:"price"`
-		TotalQuantity   float64   `json:"total_quantity"`
-		ContractNum     int       `json:"contract_num"`
-		OrderType       string    `json:"order_type"`
-		MeasurementPips float64   `json:"measurement_pips"`
-		RawMeasurement  float64   `json:"raw_measurement"`
-		Instrument      string    `json:"instrument"`
-		AccountName     string    `json:"account_name"`
-		NTBalance       float64   `json:"nt_balance"`
-		NTDailyPnL      float64   `json:"nt_daily_pnl"`
-		NTTradeResult   string    `json:"nt_trade_result"`
-		NTSessionTrades int       `json:"nt_session_trades"`
-		MT5Ticket       uint64    `json:"mt5_ticket"`
-		ClosureReason   string    `json:"closure_reason"`
-	}); ok {
-		// For MT5 close notifications, prefer carrying the EA's closure_reason back to NT.
-		// We map it onto NtTradeResult to avoid a proto change; NT will read closure_reason first and
-		// fall back to nt_trade_result if needed.
-		ntResult := n.NTTradeResult
-		if n.ClosureReason != "" {
-			ntResult = n.ClosureReason
-		}
-
-		return &trading.Trade{
-			Id:              n.ID,
-			BaseId:          n.BaseID,
-			Timestamp:       n.Time.Unix(),
-			Action:          n.Action,
-			Quantity:        n.Quantity,
-			Price:           n.Price,
-			TotalQuantity:   int32(n.TotalQuantity),
-			ContractNum:     int32(n.ContractNum),
-			OrderType:       n.OrderType,
-			MeasurementPips: int32(n.MeasurementPips),
-			RawMeasurement:  n.RawMeasurement,
-			Instrument:      n.Instrument,
-			AccountName:     n.AccountName,
-			NtBalance:       n.NTBalance,
-			NtDailyPnl:      n.NTDailyPnL,
-			NtTradeResult:   ntResult,
-			NtSessionTrades: int32(n.NTSessionTrades),
-			Mt5Ticket:       n.MT5Ticket,
-		}
-	}
-
-	// Fallback - try to extract what we can from the interface{}
-	return &trading.Trade{
-		Id:        "mt5_close_notification",
-		Action:    "MT5_CLOSE_NOTIFICATION",
-		Timestamp: time.Now().Unix(),
+		MT5Symbol:         internal.MT5Symbol,
+		HedgeLot:          internal.HedgeLot,
+		Terminal:          internal.Terminal,
+		CloseVolume:       internal.CloseVolume,
 	}
 }
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+	"log"
+
+	trading "BridgeApp/internal/grpc/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListDeadLetters returns the dead-lettered trades, most recent failure first (admin RPC).
+func (s *Server) ListDeadLetters(ctx context.Context, req *trading.DeadLetterListRequest) (*trading.DeadLetterListResponse, error) {
+	entries := s.app.DeadLetters()
+	resp := &trading.DeadLetterListResponse{Entries: make([]*trading.DeadLetter, 0, len(entries))}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &trading.DeadLetter{
+			Id:          e.ID,
+			Reason:      e.Reason,
+			Detail:      e.Detail,
+			Attempts:    int32(e.Attempts),
+			TradeId:     e.TradeID,
+			BaseId:      e.BaseID,
+			Action:      e.Action,
+			Terminal:    e.Terminal,
+			PayloadJson: string(e.Payload),
+			FirstFailed: e.FirstFailed.Unix(),
+			LastFailed:  e.LastFailed.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+// ResolveDeadLetter retries, edits and retries, or discards a dead-lettered trade (admin RPC).
+func (s *Server) ResolveDeadLetter(ctx context.Context, req *trading.DeadLetterActionRequest) (*trading.GenericResponse, error) {
+	log.Printf("gRPC: Dead letter %s: %s requested", req.Id, req.Action)
+	if err := s.app.ResolveDeadLetter(req.Id, req.Action, []byte(req.PayloadJson)); err != nil {
+		return &trading.GenericResponse{Status: "error", Message: err.Error()}, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &trading.GenericResponse{Status: "success", Message: "Dead letter " + req.Id + ": " + req.Action + " done"}, nil
+}
@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/hedgepnl"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetHedgePnL reports the MT5 prices, costs and PnL recorded for a BaseID's hedges.
+func (s *Server) GetHedgePnL(ctx context.Context, req *trading.HedgePnLRequest) (*trading.HedgePnLSummary, error) {
+	baseID := strings.TrimSpace(req.GetBaseId())
+	if baseID == "" {
+		return nil, status.Error(codes.InvalidArgument, "base_id is required")
+	}
+	sum, err := s.app.HedgePnL(baseID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", err, baseID)
+	}
+	resp := &trading.HedgePnLSummary{
+		BaseId:         sum.BaseID,
+		Tickets:        make([]*trading.HedgeTicketPnL, 0, len(sum.Tickets)),
+		OpenTickets:    int32(sum.OpenTickets),
+		OpenedVolume:   sum.OpenedVolume,
+		ClosedVolume:   sum.ClosedVolume,
+		RealizedProfit: sum.RealizedProfit,
+		Commission:     sum.Commission,
+		Swap:           sum.Swap,
+		FloatingProfit: sum.FloatingProfit,
+		Net:            sum.Net,
+		Updated:        sum.Updated.Unix(),
+	}
+	for _, t := range sum.Tickets {
+		tp := &trading.HedgeTicketPnL{Ticket: t.Ticket, Closed: t.Closed, FloatingProfit: t.FloatingProfit}
+		if t.Open != nil {
+			tp.Open = dealToProto(*t.Open)
+		}
+		for _, d := range t.Closes {
+			tp.Closes = append(tp.Closes, dealToProto(d))
+		}
+		resp.Tickets = append(resp.Tickets, tp)
+	}
+	return resp, nil
+}
+
+func dealToProto(d hedgepnl.Deal) *trading.HedgeDeal {
+	return &trading.HedgeDeal{
+		DealTicket: d.Ticket,
+		Price:      d.Price,
+		OpenPrice:  d.OpenPrice,
+		Volume:     d.Volume,
+		Commission: d.Commission,
+		Swap:       d.Swap,
+		Profit:     d.Profit,
+		ServerTime: d.ServerTime,
+	}
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"log"
+	"strings"
+
+	trading "BridgeApp/internal/grpc/proto"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/sizing"
+)
+
+// SetSizer installs the hedge sizing registry (nil leaves sizing to the EA).
+func (s *Server) SetSizer(z *sizing.Sizer) {
+	s.symbolMux.Lock()
+	s.sizer = z
+	s.symbolMux.Unlock()
+}
+
+// hedgeRatio returns the configured default hedge ratio.
+func (s *Server) hedgeRatio() float64 {
+	s.symbolMux.RLock()
+	defer s.symbolMux.RUnlock()
+	return s.sizer.HedgeRatio("")
+}
+
+// sizeHedge computes the MT5 lot for one split of an entry. Every split carries a single
+// contract, so the lot is sized per contract unless the trade is not split (quantity <= 1).
+// Non-entries and instruments without a spec return 0 and the EA keeps its own sizing.
+func (s *Server) sizeHedge(req *trading.Trade, splits int) float64 {
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case "buy", "sell":
+	default:
+		return 0
+	}
+	s.symbolMux.RLock()
+	z := s.sizer
+	s.symbolMux.RUnlock()
+
+	qty := req.Quantity
+	if splits > 1 {
+		qty = 1
+	}
+	res, ok := z.Size(req.Instrument, req.AccountName, qty)
+	if !ok {
+		return 0
+	}
+	log.Printf("gRPC: Sized hedge for %s (base_id=%s): %s x%.2f -> %.2f lot(s) (ratio=%.4f spec=%s)",
+		req.Id, req.BaseId, req.Instrument, qty, res.Lot, res.Ratio, res.Spec)
+	blog.L().Info("sizing", "hedge lot computed", map[string]interface{}{
+		"trade_id":       req.Id,
+		"base_id":        req.BaseId,
+		"instrument":     req.Instrument,
+		"account":        req.AccountName,
+		"spec":           res.Spec,
+		"contracts":      qty,
+		"splits":         splits,
+		"hedge_ratio":    res.Ratio,
+		"qt_point_value": res.QTPointValue,
+		"mt5_point_val":  res.MT5PointVal,
+		"raw_lot":        res.RawLot,
+		"hedge_lot":      res.Lot,
+		"clamped":        res.Clamped,
+	})
+	return res.Lot
+}
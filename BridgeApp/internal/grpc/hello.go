@@ -0,0 +1,160 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"BridgeApp/internal/clients"
+	trading "BridgeApp/internal/grpc/proto"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/protocol"
+)
+
+// Hello negotiates the protocol with a client and records what it declared. An incompatible
+// client gets accepted=false with the reason; its later streams and trades are refused.
+func (s *Server) Hello(ctx context.Context, req *trading.HelloRequest) (*trading.HelloResponse, error) {
+	resp := &trading.HelloResponse{
+		ProtocolVersion:    protocol.Version,
+		MinProtocolVersion: protocol.MinVersion,
+		Features:           protocol.Features,
+	}
+	kind, ok := helloKind(req.GetKind())
+	if !ok {
+		resp.Message = "unknown client kind " + strings.TrimSpace(req.GetKind()) + `; use "addon" or "ea"`
+		return resp, nil
+	}
+
+	negotiated, err := protocol.Negotiate(protocol.Hello{
+		Protocol:    int(req.GetProtocolVersion()),
+		MinProtocol: int(req.GetMinProtocolVersion()),
+		Features:    req.GetFeatures(),
+		Required:    req.GetRequiredFeatures(),
+	})
+	rejected := ""
+	if err != nil {
+		rejected = err.Error()
+	}
+	c, started := s.clients.Declare(seenFrom(ctx, kind, req.GetClientId(), req.GetClientVersion()), int(req.GetProtocolVersion()), negotiated, rejected, time.Now())
+	fields := map[string]interface{}{
+		"kind":       string(c.Kind),
+		"id":         c.ID,
+		"version":    c.Version,
+		"protocol":   req.GetProtocolVersion(),
+		"features":   strings.Join(req.GetFeatures(), ","),
+		"negotiated": strings.Join(negotiated, ","),
+	}
+	if rejected != "" {
+		log.Printf("gRPC: Rejected %s client %s (version=%s protocol=%d): %s", c.Kind, c.ID, c.Version, req.GetProtocolVersion(), rejected)
+		fields["reason"] = rejected
+		blog.L().Warn("protocol", "client rejected in hello", fields)
+		resp.Message = rejected
+		return resp, nil
+	}
+
+	log.Printf("gRPC: Hello from %s client %s (version=%s protocol=%d features=%v)", c.Kind, c.ID, c.Version, req.GetProtocolVersion(), negotiated)
+	blog.L().Info("protocol", "client hello", fields)
+	s.clientActive(c, started)
+	resp.Accepted = true
+	resp.Negotiated = negotiated
+	return resp, nil
+}
+
+func helloKind(kind string) (clients.Kind, bool) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case string(clients.Addon):
+		return clients.Addon, true
+	case string(clients.EA):
+		return clients.EA, true
+	}
+	return clientKind(kind)
+}
+
+// refuseRejected fails calls from a client whose Hello was rejected.
+func (s *Server) refuseRejected(ctx context.Context, kind clients.Kind, id string) error {
+	if reason := s.clients.Rejected(seenFrom(ctx, kind, id, "")); reason != "" {
+		return status.Error(codes.FailedPrecondition, "client rejected by protocol handshake: "+reason)
+	}
+	return nil
+}
+
+// streamSupports reports whether the client on streamID handles feature (clients that never
+// said Hello get the legacy output).
+func (s *Server) streamSupports(streamID, feature string) bool {
+	c, ok := s.clients.StreamClient(streamID)
+	return !ok || c.Supports(feature)
+}
+
+// adaptEATrade clears the fields of trade the EA on streamID did not negotiate, so it falls back
+// to the legacy behaviour: the instrument instead of mt5_symbol, its own sizing instead of
+// hedge_lot and a whole-position close instead of close_volume. These fields are newer than the
+// handshake, so EAs that never said Hello do not receive them either.
+func (s *Server) adaptEATrade(streamID string, trade *trading.Trade) {
+	c, _ := s.clients.StreamClient(streamID)
+	negotiated := func(feature string) bool { return c.Hello && c.Supports(feature) }
+	var dropped []string
+	if trade.Mt5Symbol != "" && !negotiated(protocol.MT5Symbol) {
+		trade.Mt5Symbol = ""
+		dropped = append(dropped, protocol.MT5Symbol)
+	}
+	if trade.HedgeLot != 0 && !negotiated(protocol.HedgeLot) {
+		trade.HedgeLot = 0
+		dropped = append(dropped, protocol.HedgeLot)
+	}
+	if trade.CloseVolume != 0 && !negotiated(protocol.PartialClose) {
+		log.Printf("WARN: Stream %s did not negotiate %s; CLOSE_HEDGE %s closes ticket %d whole instead of %.2f lots", streamID, protocol.PartialClose, trade.Id, trade.Mt5Ticket, trade.CloseVolume)
+		trade.CloseVolume = 0
+		dropped = append(dropped, protocol.PartialClose)
+	}
+	if len(dropped) > 0 {
+		blog.L().Info("protocol", "trade fields dropped for client without features", map[string]interface{}{
+			"trade_id":  trade.Id,
+			"base_id":   trade.BaseId,
+			"action":    trade.Action,
+			"stream_id": streamID,
+			"features":  strings.Join(dropped, ","),
+		})
+	}
+}
+
+// noticeFeature returns the feature an addon must have negotiated to receive a notice trade
+// ("" when every addon receives it).
+func noticeFeature(action string) string {
+	if strings.EqualFold(action, "CLOSE_REQUEST_COMPLETED") {
+		return protocol.CloseTracking
+	}
+	return ""
+}
+
+// TerminalSupports reports whether the EA serving terminal negotiated feature in its Hello.
+// Unlike streamSupports it is false for EAs that never said Hello: it guards fields older EAs
+// silently ignore. An offline terminal is judged by the last Hello of the EA that served it.
+func (s *Server) TerminalSupports(terminal, feature string) bool {
+	s.mt5StreamMux.RLock()
+	ts := s.mt5Streams[terminal]
+	var streamID, terminalID string
+	if ts != nil {
+		streamID, terminalID = ts.streamID, ts.terminalID
+	}
+	s.mt5StreamMux.RUnlock()
+	if ts == nil {
+		return false
+	}
+	c, ok := s.clients.StreamClient(streamID)
+	if streamID == "" || !ok {
+		c, ok = s.clients.Declared(clients.EA, terminalID)
+	}
+	return ok && c.Hello && c.Supports(feature)
+}
+
+// adaptAddonTrade fills what a client that declared qt_position_id leaves implicit: base_id is
+// the Quantower Position.Id.
+func adaptAddonTrade(c clients.Client, req *trading.Trade) {
+	if c.Hello && c.Supports(protocol.QTPositionID) && strings.TrimSpace(req.GetBaseId()) == "" && req.GetQtPositionId() != "" {
+		req.BaseId = req.GetQtPositionId()
+	}
+}
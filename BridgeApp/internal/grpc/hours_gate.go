@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/hours"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/symbols"
+)
+
+// outsideHoursError refuses or parks an entry whose MT5 symbol is closed.
+type outsideHoursError struct {
+	decision hours.Decision
+}
+
+func (e *outsideHoursError) Error() string {
+	msg := fmt.Sprintf("%s closed (%s)", e.decision.Symbol, e.decision.Reason)
+	if !e.decision.OpensAt.IsZero() {
+		msg += " until " + e.decision.OpensAt.Format(time.RFC3339)
+	}
+	return msg
+}
+
+// Is makes held entries count as errTradeHeld, so callers treat both holds alike.
+func (e *outsideHoursError) Is(target error) bool {
+	return target == errTradeHeld && e.decision.Policy == hours.PolicyHold
+}
+
+// SetTradingHours installs the trading-hours tables entries are checked against. Held entries are
+// re-evaluated by the SetSymbolMap that follows on every configuration load.
+func (s *Server) SetTradingHours(g *hours.Gate) {
+	s.symbolMux.Lock()
+	s.hours = g
+	s.symbolMux.Unlock()
+	log.Printf("gRPC: Trading hours installed (tables=%d)", g.Len())
+}
+
+// checkTradingHours decides what happens to an entry (buy/sell) whose MT5 symbol is closed: it is
+// parked until the symbol opens, refused, or sent to the route terminal. Open symbols and other
+// actions pass with an open decision.
+func (s *Server) checkTradingHours(req *trading.Trade, res symbols.Resolution, now time.Time) (hours.Decision, error) {
+	symbol := req.Instrument
+	if res.Mapped {
+		symbol = res.Symbol
+	}
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case "buy", "sell":
+	default:
+		return hours.Decision{Symbol: symbol, Open: true}, nil
+	}
+	s.symbolMux.RLock()
+	g := s.hours
+	s.symbolMux.RUnlock()
+
+	d := g.Check(symbol, now)
+	if d.Open {
+		return d, nil
+	}
+	fields := map[string]interface{}{
+		"trade_id": req.Id,
+		"base_id":  req.BaseId,
+		"symbol":   d.Symbol,
+		"table":    d.Table,
+		"reason":   d.Reason,
+		"policy":   string(d.Policy),
+	}
+	if !d.OpensAt.IsZero() {
+		fields["opens_at"] = d.OpensAt.Format(time.RFC3339)
+	}
+	switch d.Policy {
+	case hours.PolicyRoute:
+		log.Printf("gRPC: %s closed (%s); routing trade %s to terminal %s", d.Symbol, d.Reason, req.Id, d.Terminal)
+		fields["terminal"] = d.Terminal
+		blog.L().Info("hours", "entry routed: symbol closed", fields)
+		return d, nil
+	case hours.PolicyHold:
+		err := &outsideHoursError{decision: d}
+		count := s.parkTrade(req, err.Error())
+		s.wakeAtOpen(d.OpensAt)
+		log.Printf("WARN: Holding trade %s (base_id=%s): %v (held=%d)", req.Id, req.BaseId, err, count)
+		fields["held_count"] = count
+		blog.L().Warn("hours", "trade held: symbol closed", fields)
+		return d, err
+	default:
+		log.Printf("gRPC: Rejected trade %s: %s closed (%s)", req.Id, d.Symbol, d.Reason)
+		blog.L().Warn("hours", "entry rejected: symbol closed", fields)
+		return d, &outsideHoursError{decision: d}
+	}
+}
+
+// wakeAtOpen re-evaluates the held entries when the earliest held symbol opens. Entries whose
+// symbol has no opening in sight stay held until the next configuration load.
+func (s *Server) wakeAtOpen(at time.Time) {
+	if at.IsZero() {
+		return
+	}
+	s.symbolMux.Lock()
+	defer s.symbolMux.Unlock()
+	if s.hoursWake != nil && !s.hoursWakeAt.After(at) && s.hoursWakeAt.After(time.Now()) {
+		return // an earlier release is already due
+	}
+	if s.hoursWake != nil {
+		s.hoursWake.Stop()
+	}
+	s.hoursWakeAt = at
+	s.hoursWake = time.AfterFunc(time.Until(at), func() {
+		s.symbolMux.Lock()
+		if s.hoursWakeAt.Equal(at) {
+			s.hoursWake = nil
+		}
+		s.symbolMux.Unlock()
+		s.releaseHeld("trading hours opened")
+	})
+}
+
+// hoursMetadata describes a trading-hours decision for GenericResponse.metadata.
+func hoursMetadata(d hours.Decision, md map[string]string) map[string]string {
+	if d.Table == "" {
+		return md
+	}
+	if d.Open {
+		md["trading_hours"] = "open"
+		return md
+	}
+	md["trading_hours"] = "closed"
+	md["hours_table"] = d.Table
+	md["hours_reason"] = d.Reason
+	md["hours_policy"] = string(d.Policy)
+	if !d.OpensAt.IsZero() {
+		md["opens_at"] = d.OpensAt.Format(time.RFC3339)
+	}
+	if d.Policy == hours.PolicyRoute {
+		md["routed_terminal"] = d.Terminal
+	}
+	return md
+}
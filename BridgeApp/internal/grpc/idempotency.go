@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/idempotency"
+	blog "BridgeApp/internal/logging"
+)
+
+// SetIdempotencyStore installs the store client idempotency keys are remembered in.
+func (s *Server) SetIdempotencyStore(st *idempotency.Store) {
+	s.idemMux.Lock()
+	s.idempotency = st
+	s.idemMux.Unlock()
+}
+
+func (s *Server) idempotencyStore() *idempotency.Store {
+	s.idemMux.RLock()
+	defer s.idemMux.RUnlock()
+	return s.idempotency
+}
+
+// idempotencyKey returns the trade's idempotency key: the Trade field, else the "idempotency-key"
+// gRPC metadata of the call.
+func idempotencyKey(ctx context.Context, req *trading.Trade) string {
+	if key := strings.TrimSpace(req.GetIdempotencyKey()); key != "" {
+		return key
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("idempotency-key"); len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+	}
+	return ""
+}
+
+// submitIdempotent processes a trade once per key. A retry within the window gets the original
+// response back, marked idempotent_replay; server errors are not remembered so a retry is
+// processed afresh.
+func (s *Server) submitIdempotent(key string, req *trading.Trade) (*trading.GenericResponse, error) {
+	store := s.idempotencyStore()
+	if rec, replay := store.Claim(key); replay {
+		log.Printf("gRPC: Replaying response for idempotency key %s (trade %s, first seen as %s)", key, req.Id, rec.TradeID)
+		blog.L().Info("idempotency", "replayed trade submission", map[string]interface{}{
+			"idempotency_key": key,
+			"trade_id":        req.Id,
+			"original_trade":  rec.TradeID,
+			"status":          rec.Response.Status,
+		})
+		md := map[string]string{}
+		for k, v := range rec.Response.Metadata {
+			md[k] = v
+		}
+		md["idempotency_key"] = key
+		md["idempotent_replay"] = "true"
+		return &trading.GenericResponse{Status: rec.Response.Status, Message: rec.Response.Message, Metadata: md}, nil
+	}
+
+	resp, err := s.submitTrade(req)
+	if err != nil || resp.GetStatus() == "error" {
+		store.Release(key)
+		return resp, err
+	}
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+	resp.Metadata["idempotency_key"] = key
+	store.Complete(key, req.Id, idempotency.Response{Status: resp.Status, Message: resp.Message, Metadata: resp.Metadata})
+	return resp, nil
+}
@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"strings"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/hours"
+)
+
+// ManualEntry applies the add-on entry gates to a hedge an operator opens from the UI: the
+// close_only schedule, the symbol map and terminal routing. Unknown instruments are refused
+// whatever the hold policy. It returns the MT5 symbol ("" when unmapped) and the terminal.
+func (s *Server) ManualEntry(id, baseID, instrument, account, action string) (symbol, terminal string, err error) {
+	req := &trading.Trade{Id: id, BaseId: baseID, Instrument: instrument, AccountName: account, Action: action, Quantity: 1}
+	if err := s.checkEntryWindow(req, time.Now()); err != nil {
+		return "", "", err
+	}
+	res, err := s.resolveSymbol(req)
+	if err != nil {
+		return "", "", err
+	}
+	base := &InternalTrade{}
+	s.routeTrade(req, base)
+	if res.Mapped {
+		symbol = res.Symbol
+	}
+	return symbol, base.Terminal, nil
+}
+
+// RetryEntry applies the entry gates to an operator retry of a dead-lettered trade (a main.Trade):
+// the close_only schedule, the symbol map and trading hours. Unknown instruments and closed symbols
+// are refused whatever the hold policy, so the dead letter stays for a later retry. It returns the
+// MT5 symbol ("" when unmapped) and, when the symbol's trading hours route it, the terminal.
+// Other actions pass unchecked, with nothing to change.
+func (s *Server) RetryEntry(trade interface{}) (symbol, terminal string, err error) {
+	req, err := tradeFromApp(trade)
+	if err != nil {
+		return "", "", err
+	}
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case "buy", "sell":
+	default:
+		return "", "", nil
+	}
+	now := time.Now()
+	if err := s.checkEntryWindow(req, now); err != nil {
+		return "", "", err
+	}
+	res, err := s.resolveSymbol(req)
+	if err != nil {
+		return "", "", err
+	}
+	symbol = req.Instrument
+	if res.Mapped {
+		symbol = res.Symbol
+	}
+	s.symbolMux.RLock()
+	g := s.hours
+	s.symbolMux.RUnlock()
+	if d := g.Check(symbol, now); !d.Open {
+		if d.Policy != hours.PolicyRoute {
+			return "", "", &outsideHoursError{decision: d}
+		}
+		terminal = d.Terminal
+	}
+	if !res.Mapped {
+		symbol = ""
+	}
+	return symbol, terminal, nil
+}
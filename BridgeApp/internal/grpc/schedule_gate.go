@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/schedule"
+)
+
+// entryBlockedError refuses an entry inside a scheduled close_only window.
+type entryBlockedError struct {
+	window schedule.Occurrence
+}
+
+func (e *entryBlockedError) Error() string {
+	return fmt.Sprintf("entry blocked by schedule rule %q until %s", e.window.Rule, e.window.Until.Format(time.RFC3339))
+}
+
+// SetSchedule installs the scheduled rules whose close_only windows refuse new entries.
+func (s *Server) SetSchedule(sc *schedule.Schedule) {
+	s.sessionMux.Lock()
+	s.schedule = sc
+	s.sessionMux.Unlock()
+}
+
+// checkEntryWindow refuses entries (buy/sell) of an account inside a close_only window; closes
+// and events always pass.
+func (s *Server) checkEntryWindow(req *trading.Trade, now time.Time) error {
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case "buy", "sell":
+	default:
+		return nil
+	}
+	s.sessionMux.RLock()
+	sc := s.schedule
+	s.sessionMux.RUnlock()
+	window, blocked := sc.Blocked(req.AccountName, now)
+	if !blocked {
+		return nil
+	}
+	log.Printf("gRPC: Rejected entry %s (account=%s): close_only window %q until %s", req.Id, req.AccountName, window.Rule, window.Until.Format(time.RFC3339))
+	blog.L().Warn("schedule", "entry blocked by close_only window", map[string]interface{}{
+		"trade_id": req.Id,
+		"base_id":  req.BaseId,
+		"account":  req.AccountName,
+		"rule":     window.Rule,
+		"until":    window.Until.Format(time.RFC3339),
+	})
+	return &entryBlockedError{window: window}
+}
+
+// blockedMetadata describes a refused entry for GenericResponse.metadata.
+func blockedMetadata(e *entryBlockedError, md map[string]string) map[string]string {
+	md["entry_block"] = string(schedule.CloseOnly)
+	md["schedule_rule"] = e.window.Rule
+	md["blocked_until"] = e.window.Until.Format(time.RFC3339)
+	return md
+}
@@ -3,17 +3,32 @@ package grpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"BridgeApp/internal/clients"
+	"BridgeApp/internal/closereq"
+	"BridgeApp/internal/deadletter"
 	trading "BridgeApp/internal/grpc/proto"
+	"BridgeApp/internal/hedgepnl"
+	"BridgeApp/internal/hours"
+	"BridgeApp/internal/idempotency"
 	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/pnl"
+	"BridgeApp/internal/protocol"
+	"BridgeApp/internal/routing"
+	"BridgeApp/internal/schedule"
+	"BridgeApp/internal/session"
+	"BridgeApp/internal/sizing"
+	"BridgeApp/internal/symbols"
 
 	"crypto/md5"
 	"encoding/hex"
@@ -35,9 +50,13 @@ type Server struct {
 	app              AppInterface
 	server           *grpc.Server
 	tradeStreams     map[string]chan *trading.Trade
+	eventStreams     map[string]chan *trading.AddonEvent // AddonEventStream clients, guarded by streamsMux
 	streamsMux       sync.RWMutex
 	healthStreams    map[string]chan *trading.HealthResponse
 	healthStreamsMux sync.RWMutex
+	statusSeq        uint64 // sequence of published statuses (atomic)
+	lastQueueSizes   string // queue sizes of the last queue status, to skip unchanged ones
+	statusMux        sync.Mutex
 	// rate-limit noisy logs like health checks
 	healthLogMu   sync.Mutex
 	lastHealthLog map[string]time.Time
@@ -48,23 +67,61 @@ type Server struct {
 	recentTradesMux sync.Mutex
 
 	// recentlyClosedTickets tracks MT5 tickets that were just closed (via MT5 result/notification)
-	// to suppress any stale CLOSE_HEDGE requests still lingering in buffers.
-	recentlyClosedTickets map[uint64]time.Time
+	// to suppress any stale CLOSE_HEDGE requests still lingering in buffers. Tickets are per terminal.
+	recentlyClosedTickets map[closedTicket]time.Time
 	rcMux                 sync.Mutex
 
-	// currentMT5StreamID tracks the single active MT5 GetTrades stream. New connections supersede older ones.
-	currentMT5StreamID string
-	mt5StreamMux       sync.RWMutex
+	// mt5Streams tracks the active MT5 GetTrades stream per destination terminal. A new connection
+	// supersedes only an older stream serving the same terminal; streamDest maps stream id -> terminal.
+	mt5Streams   map[string]*terminalStream
+	streamDest   map[string]string
+	mt5StreamMux sync.RWMutex
+
+	// router maps Quantower account/strategy tag to the MT5 terminal that hedges it (nil = single terminal).
+	router    *routing.Router
+	routerMux sync.RWMutex
+
+	// symbolMap translates Quantower instruments to MT5 symbols before enqueue (nil = passthrough).
+	// heldTrades parks entries with unknown instruments under the "hold" policy.
+	// sizer computes the MT5 hedge lot per split from instrument specs (nil = EA sizes).
+	// hours gates entries by MT5 symbol trading hours (nil = always open); hoursWake releases
+	// the entries held outside hours when the earliest of their symbols opens.
+	symbolMap   *symbols.Map
+	heldTrades  []heldTrade
+	sizer       *sizing.Sizer
+	hours       *hours.Gate
+	hoursWake   *time.Timer
+	hoursWakeAt time.Time
+	symbolMux   sync.RWMutex
+	// releaseMux is held while a held entry moves to the queue, so a close dropping the entries of
+	// its BaseID finds each one either still held or already queued.
+	releaseMux sync.Mutex
+
+	// clients is the session registry of add-ons and EAs; its sweep replaces explicit disconnects.
+	clients *clients.Registry
+
+	// idempotency remembers client idempotency keys and their responses across restarts (nil = off).
+	idempotency *idempotency.Store
+	idemMux     sync.RWMutex
+
+	// calendar names the trading day reported to the addon; lossLimit is the daily loss limit per account.
+	// schedule refuses entries inside its close_only windows (nil = no rules).
+	calendar   *session.Calendar
+	lossLimit  float64
+	schedule   *schedule.Schedule
+	sessionMux sync.RWMutex
 }
 
 // AppInterface defines the interface that the App struct must implement for gRPC integration
 type AppInterface interface {
 	GetTradeQueue() chan interface{}
 	PollTradeFromQueue() interface{} // Non-blocking trade retrieval
+	PollTradeFromQueueFor(terminal string) interface{}
 	AddToTradeQueue(trade interface{}) error
 	GetNetPosition() int
 	GetHedgeSize() float64
 	GetQueueSize() int
+	QueueSizes() map[string]int // per destination terminal
 	IsAddonConnected() bool
 	IsHedgebotActive() bool
 	SetAddonConnected(connected bool)
@@ -74,7 +131,15 @@ type AppInterface interface {
 	HandleMT5TradeResult(result interface{}) error
 	HandleElasticUpdate(update interface{}) error
 	HandleTrailingStopUpdate(update interface{}) error
-	HandleCloseHedgeRequest(request interface{}) error
+	CloseHedge(request interface{}) (map[string]string, error) // metadata: close_request_id, chosen tickets and policy
+	CloseRequestStatus(id string) (closereq.Request, error)
+	HedgePnL(baseID string) (hedgepnl.Summary, error)
+	CombinedPnL(day string) pnl.Report
+	DeadLetterTrade(trade interface{}, reason, detail, terminal string)
+	DeadLetters() []deadletter.Entry
+	ResolveDeadLetter(id, action string, edits []byte) error
+	StatusDetail() StatusDetail
+	EntryMaxAge() time.Duration // 0 = queued entries never go stale
 }
 
 // NewGRPCServer creates a new gRPC server instance
@@ -82,10 +147,14 @@ func NewGRPCServer(app AppInterface) *Server {
 	return &Server{
 		app:                   app,
 		tradeStreams:          make(map[string]chan *trading.Trade),
+		eventStreams:          make(map[string]chan *trading.AddonEvent),
 		healthStreams:         make(map[string]chan *trading.HealthResponse),
 		lastHealthLog:         make(map[string]time.Time),
 		recentTradeIDs:        make(map[string]time.Time),
-		recentlyClosedTickets: make(map[uint64]time.Time),
+		recentlyClosedTickets: make(map[closedTicket]time.Time),
+		mt5Streams:            make(map[string]*terminalStream),
+		streamDest:            make(map[string]string),
+		clients:               clients.NewRegistry(),
 	}
 }
 
@@ -151,22 +220,18 @@ func (s *Server) StartGRPCServer(port string) error {
 	return nil
 }
 
-// monitorOfflineMT5Queue logs a warning if trades accumulate while no MT5 trade stream is active
+// monitorOfflineMT5Queue logs a warning if trades accumulate for a terminal whose MT5 trade stream is not active
 func (s *Server) monitorOfflineMT5Queue() {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 	for range ticker.C {
-		// If there are active MT5 streams skip
-		s.streamsMux.RLock()
-		mt5StreamCount := len(s.tradeStreams)
-		s.streamsMux.RUnlock()
-		if mt5StreamCount > 0 {
-			continue
-		}
-		qSize := s.app.GetQueueSize()
-		if qSize > 0 {
-			log.Printf("WARN: %d trade(s) buffered with no active MT5 stream. MT5 likely disconnected or timed out. Trades will flush on reconnection.", qSize)
-			blog.L().Warn("stream", fmt.Sprintf("buffered trades with no MT5 stream: %d", qSize), nil)
+		for terminal, qSize := range s.app.QueueSizes() {
+			// If the terminal's MT5 stream is active skip
+			if qSize == 0 || s.hasLiveMT5Stream(terminal) {
+				continue
+			}
+			log.Printf("WARN: %d trade(s) buffered for terminal %s with no active MT5 stream. MT5 likely disconnected or timed out. Trades will flush on reconnection.", qSize, terminal)
+			blog.L().Warn("stream", fmt.Sprintf("buffered trades with no MT5 stream: %d", qSize), map[string]interface{}{"terminal": terminal})
 		}
 	}
 }
@@ -179,10 +244,19 @@ func (s *Server) Stop() {
 	}
 }
 
-// SubmitTrade handles trade submission from the desktop addon (Quantower / legacy clients)
+// SubmitTrade handles trade submission from the desktop addon (Quantower / legacy clients).
+// Trades carrying an idempotency key are deduplicated by that key across restarts; others by
+// id, quantity and action for a few seconds.
 func (s *Server) SubmitTrade(ctx context.Context, req *trading.Trade) (*trading.GenericResponse, error) {
 	log.Printf("gRPC: Received trade submission - ID: %s, Action: %s, Quantity: %.2f",
 		req.Id, req.Action, req.Quantity)
+	if err := s.refuseRejected(ctx, clients.Addon, ""); err != nil {
+		return &trading.GenericResponse{Status: "rejected", Message: err.Error()}, err
+	}
+	adaptAddonTrade(s.clientSeen(ctx, clients.Addon, "", ""), req)
+	if key := idempotencyKey(ctx, req); key != "" && s.idempotencyStore() != nil {
+		return s.submitIdempotent(key, req)
+	}
 	// CRITICAL FIX: Include quantity in dedup key to allow multiple positions with same base_id
 	// This prevents blocking legitimate separate positions that share the same base_id
 	dedupKey := fmt.Sprintf("%s_%.2f_%s", req.Id, req.Quantity, req.Action)
@@ -191,12 +265,54 @@ func (s *Server) SubmitTrade(ctx context.Context, req *trading.Trade) (*trading.
 		return &trading.GenericResponse{Status: "success", Message: "Duplicate suppressed"}, nil
 	}
 	s.markProcessed(dedupKey)
+	return s.submitTrade(req)
+}
 
-	// Update addon connection status
-	s.app.SetAddonConnected(true)
-
+// submitTrade enqueues a trade that passed deduplication.
+func (s *Server) submitTrade(req *trading.Trade) (*trading.GenericResponse, error) {
 	// Enqueue with smart splitting for multi-quantity entries
-	if err := s.enqueueTradeWithSplit(req); err != nil {
+	res, hrs, err := s.enqueueTradeWithSplit(req)
+	if err != nil {
+		// Unknown instruments and closed symbols are a policy decision, not a server failure: report them in-band
+		var closed *outsideHoursError
+		if errors.As(err, &closed) {
+			status := "rejected"
+			if closed.decision.Policy == hours.PolicyHold {
+				status = "held"
+			}
+			md := map[string]string{"trade_id": req.Id}
+			if status == "held" {
+				md["held_count"] = fmt.Sprintf("%d", s.HeldTradeCount())
+			}
+			return &trading.GenericResponse{
+				Status:   status,
+				Message:  err.Error(),
+				Metadata: hoursMetadata(hrs, symbolMetadata(res, md)),
+			}, nil
+		}
+		if errors.Is(err, errTradeHeld) {
+			return &trading.GenericResponse{
+				Status:   "held",
+				Message:  err.Error(),
+				Metadata: symbolMetadata(res, map[string]string{"trade_id": req.Id, "held_count": fmt.Sprintf("%d", s.HeldTradeCount())}),
+			}, nil
+		}
+		var blocked *entryBlockedError
+		if errors.As(err, &blocked) {
+			return &trading.GenericResponse{
+				Status:   "rejected",
+				Message:  err.Error(),
+				Metadata: blockedMetadata(blocked, map[string]string{"trade_id": req.Id}),
+			}, nil
+		}
+		if errors.Is(err, symbols.ErrUnknownSymbol) {
+			log.Printf("gRPC: Rejected trade %s: %v", req.Id, err)
+			return &trading.GenericResponse{
+				Status:   "rejected",
+				Message:  err.Error(),
+				Metadata: symbolMetadata(res, map[string]string{"trade_id": req.Id}),
+			}, nil
+		}
 		log.Printf("gRPC: Failed to enqueue trade(s): %v", err)
 		return &trading.GenericResponse{
 			Status:  "error",
@@ -212,30 +328,58 @@ func (s *Server) SubmitTrade(ctx context.Context, req *trading.Trade) (*trading.
 	return &trading.GenericResponse{
 		Status:  "success",
 		Message: "Trade processed successfully",
-		Metadata: map[string]string{
+		Metadata: hoursMetadata(hrs, symbolMetadata(res, map[string]string{
 			"trade_id":   req.Id,
 			"timestamp":  time.Now().Format(time.RFC3339),
 			"queue_size": fmt.Sprintf("%d", s.app.GetQueueSize()),
-		},
+		})),
 	}, nil
 }
 
 // enqueueTradeWithSplit handles trade enqueueing WITH splitting for multi-contract trades.
 // CRITICAL: Split multi-quantity trades so each QT contract creates exactly 1 MT5 hedge.
 // This ensures n QT trades = n MT5 hedges for proper 1:1 correlation.
-func (s *Server) enqueueTradeWithSplit(req *trading.Trade) error {
+// Entries inside a scheduled close_only window are refused; the instrument is then mapped to its
+// MT5 symbol, and unknown entries are rejected or held per policy. Entries whose MT5 symbol is
+// closed are held, rejected or routed per the trading-hours policy.
+func (s *Server) enqueueTradeWithSplit(req *trading.Trade) (symbols.Resolution, hours.Decision, error) {
+	now := time.Now()
+	if err := s.checkEntryWindow(req, now); err != nil {
+		return symbols.Resolution{}, hours.Decision{}, err
+	}
+	res, err := s.resolveSymbol(req)
+	if err != nil {
+		if res.Policy == symbols.PolicyHold {
+			s.holdTrade(req, err.Error())
+			return res, hours.Decision{}, errTradeHeld
+		}
+		return res, hours.Decision{}, err
+	}
+	hrs, err := s.checkTradingHours(req, res, now)
+	if err != nil {
+		return res, hrs, err
+	}
+
 	// Convert to internal once as a base template
 	base := convertProtoToInternalTrade(req)
+	if res.Mapped {
+		base.MT5Symbol = res.Symbol
+	}
+	s.routeTrade(req, base)
+	if !hrs.Open && hrs.Policy == hours.PolicyRoute {
+		base.Terminal = hrs.Terminal
+	}
 
 	// Check if we need to split based on quantity
 	quantity := int(req.Quantity)
+	base.HedgeLot = s.sizeHedge(req, quantity)
 	if quantity <= 1 {
 		// Single contract - no splitting needed
 		if err := s.app.AddToTradeQueue(base); err != nil {
-			return err
+			return res, hrs, err
 		}
 		s.app.AddToTradeHistory(base)
-		return nil
+		return res, hrs, nil
 	}
 
 	// Multi-contract trade - split into individual hedges
@@ -258,7 +402,7 @@ func (s *Server) enqueueTradeWithSplit(req *trading.Trade) error {
 		// Enqueue this split trade
 		if err := s.app.AddToTradeQueue(&split); err != nil {
 			log.Printf("gRPC: Failed to enqueue split trade %d/%d for %s: %v", i, quantity, req.Id, err)
-			return fmt.Errorf("failed to enqueue split trade %d/%d: %w", i, quantity, err)
+			return res, hrs, fmt.Errorf("failed to enqueue split trade %d/%d: %w", i, quantity, err)
 		}
 
 		s.app.AddToTradeHistory(&split)
@@ -266,60 +410,52 @@ func (s *Server) enqueueTradeWithSplit(req *trading.Trade) error {
 	}
 
 	log.Printf("gRPC: Successfully split and enqueued %d hedges for trade %s", quantity, req.Id)
-	return nil
+	return res, hrs, nil
 }
 
 // GetTrades handles streaming trade requests from MT5
 func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error {
 	log.Println("gRPC: New trade stream connection attempt from MT5")
+	terminalID, accountID := streamIdentity(stream.Context())
+	if err := s.refuseRejected(stream.Context(), clients.EA, terminalID); err != nil {
+		log.Printf("gRPC: Refusing MT5 trade stream: %v", err)
+		return err
+	}
 
 	// Track first activity time to help diagnose client-side idle timeouts
 	startTime := time.Now()
 
-	// Mark hedgebot as active when MT5 connects via gRPC streaming
-	s.app.SetHedgebotActive(true)
-	log.Println("gRPC: Hedgebot marked as active via streaming connection")
-
 	// Create channel for this stream
 	streamChan := make(chan *trading.Trade, 100)
 	streamID := fmt.Sprintf("stream_%d", time.Now().UnixNano())
 
-	// Supersede any existing MT5 streams to enforce a single active stream
-	s.streamsMux.Lock()
-	// Remove older MT5 streams (prefix "stream_")
-	removed := 0
-	for id := range s.tradeStreams {
-		if strings.HasPrefix(id, "stream_") {
-			// Skip the new one about to be added
-			if id == streamID {
-				continue
-			}
-			delete(s.tradeStreams, id)
-			removed++
-			log.Printf("gRPC: Superseding prior MT5 stream %s (removed)", id)
-		}
-	}
 	// Register this stream as active
+	s.streamsMux.Lock()
 	s.tradeStreams[streamID] = streamChan
 	s.streamsMux.Unlock()
 
-	// Mark this stream as the current MT5 stream
-	s.mt5StreamMux.Lock()
-	s.currentMT5StreamID = streamID
-	s.mt5StreamMux.Unlock()
+	// Bind the stream to its terminal, superseding only an older stream for the same terminal.
+	// EAs that do not identify themselves serve the default terminal (legacy single-stream behaviour).
+	terminal, superseded := s.bindMT5Stream(streamID, terminalID, accountID)
+
+	// Mark hedgebot as active when MT5 connects via gRPC streaming
+	s.openClientStream(stream.Context(), clients.EA, terminalID, streamID)
+	log.Println("gRPC: Hedgebot marked as active via streaming connection")
 
 	// Log connection with stream context
 	s.streamsMux.RLock()
 	connCount := len(s.tradeStreams)
 	s.streamsMux.RUnlock()
-	if removed > 0 {
-		log.Printf("gRPC: New trade stream connected from MT5 - id=%s active_streams=%d (superseded %d older MT5 stream(s))", streamID, connCount, removed)
+	if len(superseded) > 0 {
+		log.Printf("gRPC: New trade stream connected from MT5 - id=%s terminal=%s active_streams=%d (superseded %d older MT5 stream(s))", streamID, terminal, connCount, len(superseded))
 	} else {
-		log.Printf("gRPC: New trade stream connected from MT5 - id=%s active_streams=%d", streamID, connCount)
+		log.Printf("gRPC: New trade stream connected from MT5 - id=%s terminal=%s active_streams=%d", streamID, terminal, connCount)
 	}
 
 	// Clean up on exit
 	defer func() {
+		s.unbindMT5Stream(streamID)
+		s.closeClientStream(streamID)
 		s.streamsMux.Lock()
 		delete(s.tradeStreams, streamID)
 		close(streamChan)
@@ -350,8 +486,18 @@ func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error
 				log.Printf("gRPC: Trade stream %s recv error: %v", streamID, err)
 				return
 			}
+			// EAs may identify (or re-identify) their terminal on any ping
+			if id, acct := strings.TrimSpace(req.GetTerminalId()), strings.TrimSpace(req.GetAccountId()); id != "" || acct != "" {
+				if id != terminalID || acct != accountID {
+					terminalID, accountID = id, acct
+					dest, _ := s.bindMT5Stream(streamID, terminalID, accountID)
+					s.openClientStream(stream.Context(), clients.EA, terminalID, streamID)
+					log.Printf("gRPC: Trade stream %s identified as terminal=%s account=%s -> serving %s", streamID, terminalID, accountID, dest)
+				}
+			}
 			// Treat any inbound message as proof-of-life from MT5
-			s.app.SetHedgebotActive(true)
+			s.clientSeen(stream.Context(), clients.EA, terminalID, "")
+			s.touchMT5Stream(streamID, false)
 			// Rate-limit health logs to avoid JSONL spam
 			if s.shouldLogHealth(req.GetSource(), 30*time.Second) {
 				s.streamsMux.RLock()
@@ -365,11 +511,8 @@ func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error
 	// Handle incoming health requests and send trades
 	for {
 		// If this stream has been superseded, close it proactively
-		s.mt5StreamMux.RLock()
-		currentID := s.currentMT5StreamID
-		s.mt5StreamMux.RUnlock()
-		if currentID != "" && currentID != streamID {
-			log.Printf("gRPC: Trade stream %s superseded by %s; closing stream", streamID, currentID)
+		if _, current := s.mt5StreamDestination(streamID); !current {
+			log.Printf("gRPC: Trade stream %s superseded by a newer stream for its terminal; closing stream", streamID)
 			return status.Error(codes.Canceled, "superseded by new MT5 connection")
 		}
 		select {
@@ -389,9 +532,23 @@ func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error
 				continue
 			}
 
+			// EAs that said Hello without event_trades would treat elastic EVENTs as trades
+			if strings.EqualFold(trade.Action, "EVENT") && !s.streamSupports(streamID, protocol.EventTrades) {
+				log.Printf("gRPC: Skipping EVENT %s (%s) for stream %s: client does not support %s", trade.Id, trade.EventType, streamID, protocol.EventTrades)
+				blog.L().Info("protocol", "skipped event trade for client without event_trades", map[string]interface{}{
+					"trade_id":   trade.Id,
+					"base_id":    trade.BaseId,
+					"event_type": trade.EventType,
+					"stream_id":  streamID,
+				})
+				continue
+			}
+
+			s.adaptEATrade(streamID, trade)
+
 			// Final gate: suppress stale CLOSE_HEDGE right before sending to MT5
 			if strings.EqualFold(trade.Action, "CLOSE_HEDGE") && trade.Mt5Ticket > 0 {
-				if s.wasTicketRecentlyClosed(trade.Mt5Ticket, 10*time.Second) {
+				if dest, _ := s.mt5StreamDestination(streamID); s.wasTicketRecentlyClosed(dest, trade.Mt5Ticket, 10*time.Second) {
 					log.Printf("gRPC: Suppressed stale CLOSE_HEDGE at send for ticket %d (trade %s)", trade.Mt5Ticket, trade.Id)
 					blog.L().Info("close_sync", "suppressed stale CLOSE_HEDGE at send", map[string]interface{}{
 						"mt5_ticket": trade.Mt5Ticket,
@@ -403,8 +560,8 @@ func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error
 				}
 			}
 
-			// Emit sizing hint presence for diagnostics
-			if trade.GetNtPointsPer_1KLoss() <= 0 {
+			// Emit sizing hint presence for diagnostics (a bridge-computed hedge_lot needs no hint)
+			if trade.GetNtPointsPer_1KLoss() <= 0 && trade.GetHedgeLot() <= 0 {
 				extra := map[string]interface{}{
 					"trade_id":              trade.Id,
 					"base_id":               trade.BaseId,
@@ -426,6 +583,7 @@ func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error
 					"action":                trade.Action,
 					"instrument":            trade.Instrument,
 					"nt_points_per_1k_loss": trade.GetNtPointsPer_1KLoss(),
+					"hedge_lot":             trade.GetHedgeLot(),
 				}
 				// If this is an EVENT, include event payload details for diagnostics
 				if strings.EqualFold(trade.Action, "EVENT") {
@@ -433,7 +591,7 @@ func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error
 					extra["elastic_current_profit"] = trade.ElasticCurrentProfit
 					extra["elastic_profit_level"] = trade.ElasticProfitLevel
 				}
-				blog.L().Info("stream", "sending trade with nt_points_per_1k_loss", extra)
+				blog.L().Info("stream", "sending trade with sizing", extra)
 			}
 
 			log.Printf("gRPC: Sending trade to MT5 stream - ID: %s, Action: %s", trade.Id, trade.Action)
@@ -441,6 +599,7 @@ func (s *Server) GetTrades(stream trading.TradingService_GetTradesServer) error
 				log.Printf("gRPC: Error sending trade to stream (id=%s): %v", streamID, err)
 				return err
 			}
+			s.touchMT5Stream(streamID, true)
 		}
 	}
 }
@@ -472,12 +631,13 @@ func (s *Server) forwardTradesToStream(streamChan chan<- *trading.Trade, streamI
 	defer ticker.Stop()
 
 	for range ticker.C {
-		// Check if stream is still active
+		// Check if stream is still active (and which terminal it serves)
 		s.streamsMux.RLock()
 		_, exists := s.tradeStreams[streamID]
 		s.streamsMux.RUnlock()
+		terminal, bound := s.mt5StreamDestination(streamID)
 
-		if !exists {
+		if !exists || !bound {
 			log.Printf("gRPC: Stream %s no longer exists, stopping trade forwarding", streamID)
 			return
 		}
@@ -485,7 +645,7 @@ func (s *Server) forwardTradesToStream(streamChan chan<- *trading.Trade, streamI
 		// MULTI_TRADE_FIX: Drain ALL available trades from queue in each cycle, not just one
 	drainLoop:
 		for {
-			trade := s.pollTradeFromApp()
+			trade := s.pollTradeFromApp(terminal)
 			if trade == nil {
 				break // No more trades in queue
 			}
@@ -493,7 +653,7 @@ func (s *Server) forwardTradesToStream(streamChan chan<- *trading.Trade, streamI
 			// STALE_CLOSE_SUPPRESSION: If this is a CLOSE_HEDGE for a ticket we very recently
 			// marked as closed, drop it to avoid MT5 "position not found" noise and races.
 			if strings.EqualFold(trade.Action, "CLOSE_HEDGE") && trade.Mt5Ticket > 0 {
-				if s.wasTicketRecentlyClosed(trade.Mt5Ticket, 10*time.Second) {
+				if s.wasTicketRecentlyClosed(terminal, trade.Mt5Ticket, 10*time.Second) {
 					log.Printf("gRPC: Dropping stale CLOSE_HEDGE for recently-closed ticket %d (trade %s)", trade.Mt5Ticket, trade.Id)
 					blog.L().Info("close_sync", "dropped stale CLOSE_HEDGE due to prior MT5 close", map[string]interface{}{
 						"mt5_ticket": trade.Mt5Ticket,
@@ -508,21 +668,31 @@ func (s *Server) forwardTradesToStream(streamChan chan<- *trading.Trade, streamI
 			case streamChan <- trade:
 				log.Printf("gRPC: Forwarded trade %s to stream %s", trade.Id, streamID)
 			default:
-				log.Printf("gRPC: Stream %s buffer full, dropping trade %s", streamID, trade.Id)
+				log.Printf("gRPC: Stream %s buffer full, dead-lettering trade %s", streamID, trade.Id)
 				blog.L().Warn("stream", "stream buffer full - dropping trade", map[string]interface{}{"stream_id": streamID, "trade_id": trade.Id})
+				s.app.DeadLetterTrade(convertProtoToInternalTrade(trade), deadletter.ReasonStreamFull, "buffer of stream "+streamID+" was full", terminal)
 				break drainLoop // Stop trying if buffer is full
 			}
 		}
 	}
 }
 
-// pollTradeFromApp attempts to get a trade from the app's trade queue
-func (s *Server) pollTradeFromApp() *trading.Trade {
-	tradeInterface := s.app.PollTradeFromQueue()
+// pollTradeFromApp attempts to get a trade from the app's trade queue for a terminal
+func (s *Server) pollTradeFromApp(terminal string) *trading.Trade {
+	tradeInterface := s.app.PollTradeFromQueueFor(terminal)
 	if tradeInterface == nil {
 		return nil
 	}
+	trade, err := tradeFromApp(tradeInterface)
+	if err != nil {
+		s.app.DeadLetterTrade(tradeInterface, deadletter.ReasonConversion, err.Error(), terminal)
+		return nil
+	}
+	return trade
+}
 
+// tradeFromApp converts a main.Trade handed over by the app into its protobuf form
+func tradeFromApp(tradeInterface interface{}) (*trading.Trade, error) {
 	// The app.go returns main.Trade, we need to convert it to protobuf format
 	// First convert to InternalTrade, then to proto
 	internal := &InternalTrade{}
@@ -531,32 +701,47 @@ func (s *Server) pollTradeFromApp() *trading.Trade {
 	jsonBytes, err := json.Marshal(tradeInterface)
 	if err != nil {
 		log.Printf("gRPC: Failed to marshal trade from queue: %v", err)
-		return nil
+		return nil, fmt.Errorf("marshal trade: %w", err)
 	}
 
 	if err := json.Unmarshal(jsonBytes, internal); err != nil {
 		log.Printf("gRPC: Failed to unmarshal trade to internal format: %v", err)
-		return nil
+		return nil, fmt.Errorf("unmarshal trade to internal format: %w", err)
+	}
+	// main.Trade carries the instrument as "instrument" while InternalTrade uses "instrument_name"
+	if internal.Instrument == "" {
+		var alias struct {
+			Instrument string `json:"instrument"`
+		}
+		if err := json.Unmarshal(jsonBytes, &alias); err == nil {
+			internal.Instrument = alias.Instrument
+		}
 	}
 
 	// Now convert InternalTrade to protobuf Trade
-	return ConvertInternalToProtoTrade(internal)
+	return ConvertInternalToProtoTrade(internal), nil
 }
 
 // SubmitTradeResult handles trade execution results from MT5
 func (s *Server) SubmitTradeResult(ctx context.Context, req *trading.MT5TradeResult) (*trading.GenericResponse, error) {
 	log.Printf("gRPC: Received trade result - Ticket: %d, Status: %s", req.Ticket, req.Status)
 
+	terminalID, accountID := streamIdentity(ctx)
+
 	// If this was a closure, mark ticket as recently closed to suppress stale CLOSE_HEDGE
-	if req.GetIsClose() && req.GetTicket() > 0 {
-		s.markTicketClosed(req.GetTicket())
+	// (a partial close leaves the position open for further closes)
+	if req.GetIsClose() && req.GetTicket() > 0 && !strings.EqualFold(req.GetStatus(), "partial_close") {
+		s.markTicketClosed(s.currentRouter().Destination(terminalID, accountID), req.GetTicket())
 	}
 
 	// Update hedgebot active status
-	s.app.SetHedgebotActive(true)
+	s.clientSeen(ctx, clients.EA, terminalID, "")
 
-	// Convert protobuf to internal format and handle
+	// Convert protobuf to internal format and handle; tickets are keyed by the reporting terminal
 	result := convertProtoToInternalMT5Result(req)
+	if terminalID != "" || accountID != "" {
+		result.Terminal = s.currentRouter().Destination(terminalID, accountID)
+	}
 	err := s.app.HandleMT5TradeResult(result)
 	if err != nil {
 		log.Printf("gRPC: Failed to handle MT5 trade result: %v", err)
@@ -572,10 +757,16 @@ func (s *Server) SubmitTradeResult(ctx context.Context, req *trading.MT5TradeRes
 	}, nil
 }
 
-// markTicketClosed records the given MT5 ticket as recently closed
-func (s *Server) markTicketClosed(ticket uint64) {
+// closedTicket is an MT5 ticket on one destination terminal.
+type closedTicket struct {
+	terminal string
+	ticket   uint64
+}
+
+// markTicketClosed records the given MT5 ticket of terminal as recently closed
+func (s *Server) markTicketClosed(terminal string, ticket uint64) {
 	s.rcMux.Lock()
-	s.recentlyClosedTickets[ticket] = time.Now()
+	s.recentlyClosedTickets[closedTicket{terminal, ticket}] = time.Now()
 	// Optional pruning: keep map from growing too large
 	if len(s.recentlyClosedTickets) > 1000 {
 		cutoff := time.Now().Add(-15 * time.Second)
@@ -588,11 +779,12 @@ func (s *Server) markTicketClosed(ticket uint64) {
 	s.rcMux.Unlock()
 }
 
-// wasTicketRecentlyClosed checks if a ticket was marked closed within a TTL
-func (s *Server) wasTicketRecentlyClosed(ticket uint64, ttl time.Duration) bool {
+// wasTicketRecentlyClosed checks if a ticket of terminal was marked closed within a TTL
+func (s *Server) wasTicketRecentlyClosed(terminal string, ticket uint64, ttl time.Duration) bool {
+	key := closedTicket{terminal, ticket}
 	s.rcMux.Lock()
 	defer s.rcMux.Unlock()
-	t, ok := s.recentlyClosedTickets[ticket]
+	t, ok := s.recentlyClosedTickets[key]
 	if !ok {
 		return false
 	}
@@ -600,7 +792,7 @@ func (s *Server) wasTicketRecentlyClosed(ticket uint64, ttl time.Duration) bool
 		return true
 	}
 	// Expired; cleanup
-	delete(s.recentlyClosedTickets, ticket)
+	delete(s.recentlyClosedTickets, key)
 	return false
 }
 
@@ -610,11 +802,12 @@ func (s *Server) NotifyHedgeClose(ctx context.Context, req *trading.HedgeCloseNo
 		req.BaseId, req.ClosureReason)
 
 	// Update hedgebot active status
-	s.app.SetHedgebotActive(true)
+	terminalID, accountID := streamIdentity(ctx)
+	s.clientSeen(ctx, clients.EA, terminalID, "")
 
 	// Mark ticket as recently closed if provided
 	if req.GetMt5Ticket() > 0 {
-		s.markTicketClosed(req.GetMt5Ticket())
+		s.markTicketClosed(s.currentRouter().Destination(terminalID, accountID), req.GetMt5Ticket())
 	}
 
 	// Convert and handle notification
@@ -684,17 +877,9 @@ func (s *Server) HealthCheck(ctx context.Context, req *trading.HealthRequest) (*
 		log.Printf("gRPC: Health check from source: %s", req.Source)
 	}
 
-	// Update connection status based on source
-	source := strings.TrimSpace(req.Source)
-	switch strings.ToLower(source) {
-	case "hedgebot", "mt5_ea":
-		s.app.SetHedgebotActive(true)
-		// noisy; omit per-request log
-	default:
-		if isAddonSource(source) {
-			s.app.SetAddonConnected(true)
-			// noisy; omit per-request log
-		}
+	// Update connection status based on source (noisy; omit per-request log)
+	if kind, ok := clientKind(req.Source); ok {
+		s.clientSeen(ctx, kind, "", "")
 	}
 
 	response := &trading.HealthResponse{
@@ -744,11 +929,14 @@ func (s *Server) GetSettings(ctx context.Context, req *trading.SettingsRequest)
 		"max-queue-size":        "1000",
 		"connection-timeout":    "30",
 		"retry-attempts":        "3",
-		"hedge-ratio":           "1.0",
+		"hedge-ratio":           strconv.FormatFloat(s.hedgeRatio(), 'f', -1, 64),
 		"position-size-limit":   "100",
-		"daily-loss-limit":      "5000",
+		"daily-loss-limit":      strconv.FormatFloat(s.dailyLossLimit(), 'f', -1, 64),
 		"max-concurrent-trades": "50",
 	}
+	for name, value := range s.sessionSettings(time.Now()) {
+		settings[name] = value
+	}
 
 	value, exists := settings[req.SettingName]
 	if !exists {
@@ -769,11 +957,12 @@ func (s *Server) GetSettings(ctx context.Context, req *trading.SettingsRequest)
 
 // SystemHeartbeat handles system heartbeat requests
 func (s *Server) SystemHeartbeat(ctx context.Context, req *trading.HeartbeatRequest) (*trading.HeartbeatResponse, error) {
-	log.Printf("gRPC: Heartbeat from component: %s, Status: %s", req.Component, req.Status)
+	log.Printf("gRPC: Heartbeat from component: %s, Status: %s, Version: %s", req.Component, req.Status, req.Version)
 
-	// Treat addon heartbeat as proof-of-life (Quantower primary, legacy NT kept for backwards compatibility)
-	if isAddonSource(req.GetComponent()) {
-		s.app.SetAddonConnected(true)
+	// Treat heartbeats as proof-of-life (Quantower primary, legacy NT kept for backwards compatibility)
+	// and record the client version they carry
+	if kind, ok := clientKind(req.GetComponent()); ok {
+		s.clientSeen(ctx, kind, "", req.GetVersion())
 	}
 
 	return &trading.HeartbeatResponse{
@@ -787,9 +976,9 @@ func (s *Server) Log(ctx context.Context, req *trading.LogEvent) (*trading.LogAc
 	// Map protobuf LogEvent to internal logging.Event and ingest
 	log.Printf("gRPC: LoggingService received event from source=%s level=%s component=%s", req.GetSource(), req.GetLevel(), req.GetComponent())
 
-	// Treat addon log traffic as proof-of-life to mark the desktop client connected
-	if isAddonSource(req.GetSource()) {
-		s.app.SetAddonConnected(true)
+	// Treat client log traffic as proof-of-life to mark the desktop client or EA connected
+	if kind, ok := clientKind(req.GetSource()); ok {
+		s.clientSeen(ctx, kind, "", "")
 	}
 
 	baseIDs := extractBaseIDs(req.GetBaseId(), req.GetMessage(), req.GetTags())
@@ -999,10 +1188,11 @@ func md5Hex(s string) string {
 // SubmitCloseHedge handles hedge closure requests from client add-ons.
 func (s *Server) SubmitCloseHedge(ctx context.Context, req *trading.HedgeCloseNotification) (*trading.GenericResponse, error) {
 	log.Printf("gRPC: Close hedge request - BaseID: %s", req.BaseId)
+	s.clientSeen(ctx, clients.Addon, "", "")
 
 	// Convert and handle request
 	request := convertProtoToInternalHedgeClose(req)
-	err := s.app.HandleCloseHedgeRequest(request)
+	metadata, err := s.app.CloseHedge(request)
 	if err != nil {
 		log.Printf("gRPC: Failed to handle close hedge request: %v", err)
 		return &trading.GenericResponse{
@@ -1012,8 +1202,9 @@ func (s *Server) SubmitCloseHedge(ctx context.Context, req *trading.HedgeCloseNo
 	}
 
 	return &trading.GenericResponse{
-		Status:  "success",
-		Message: "Close hedge request processed successfully",
+		Status:   "success",
+		Message:  "Close hedge request processed successfully",
+		Metadata: metadata,
 	}, nil
 }
 
@@ -1032,42 +1223,35 @@ func (s *Server) broadcastTradeToStreams(trade *trading.Trade) {
 	}
 }
 
-// BroadcastMT5CloseNotification sends an MT5 closure notification to every connected stream
-func (s *Server) BroadcastMT5CloseNotification(notification interface{}) {
-	log.Printf("gRPC: Broadcasting MT5 close notification: %+v", notification)
-
-	// Convert the notification to a trading.Trade message
-	protoTrade := convertMT5CloseNotificationToProtoTrade(notification)
-
-	// Broadcast to all connected consumers (Quantower addon streams and MT5 listeners)
-	s.broadcastTradeToStreams(protoTrade)
-}
-
-// BroadcastMT5CloseToAddonStreams sends MT5 closure notifications only to addon streams (not MT5 streams)
-// This prevents circular trades while still notifying external clients to close original positions
-func (s *Server) BroadcastMT5CloseToAddonStreams(notification interface{}) {
-	log.Printf("gRPC: Broadcasting MT5 close notification to addon streams only: %+v", notification)
-
-	// Convert the notification to a trading.Trade message
-	protoTrade := convertMT5CloseNotificationToProtoTrade(notification)
-
-	if protoTrade != nil && protoTrade.OrderType == "NT_CLOSE_ACK" {
-		log.Printf("gRPC: Tagging close as NT_CLOSE_ACK (legacy label for addon acknowledgement)")
+// NotifyAddonStreams sends a bridge-generated notice (a main.Trade, e.g. HEDGE_SKIPPED) to addon streams only
+func (s *Server) NotifyAddonStreams(notice interface{}) {
+	protoTrade, err := tradeFromApp(notice)
+	if err != nil {
+		return
 	}
+	s.sendToAddonStreams(protoTrade, protoTrade.Action+" notice")
+}
 
-	// Send only to addon bidirectional streams (not MT5 GetTrades streams)
+// sendToAddonStreams delivers trade to the addon bidirectional streams, never to MT5 streams
+func (s *Server) sendToAddonStreams(trade *trading.Trade, what string) {
 	s.streamsMux.RLock()
 	defer s.streamsMux.RUnlock()
 
+	feature := noticeFeature(trade.Action)
+	s.sendToEventStreamsLocked(&trading.AddonEvent{Event: &trading.AddonEvent_Trade{Trade: trade}}, what, feature)
 	for streamID, streamChan := range s.tradeStreams {
 		// Only send to addon bidirectional streams (streamID starts with "bidir_stream_")
 		// Do NOT send to MT5 streams (streamID starts with "stream_") to prevent circular trades
 		if strings.HasPrefix(streamID, "bidir_stream_") {
+			if feature != "" && !s.streamSupports(streamID, feature) {
+				log.Printf("gRPC: Skipping %s for addon stream %s: client does not support %s", what, streamID, feature)
+				continue
+			}
 			select {
-			case streamChan <- protoTrade:
-				log.Printf("gRPC: MT5 closure notification sent to addon stream %s", streamID)
+			case streamChan <- trade:
+				log.Printf("gRPC: %s sent to addon stream %s", what, streamID)
 			default:
-				log.Printf("gRPC: Addon stream %s buffer full, skipping MT5 closure notification", streamID)
+				log.Printf("gRPC: Addon stream %s buffer full, skipping %s", streamID, what)
 			}
 		} else {
 			log.Printf("gRPC: Skipping MT5 stream %s to prevent circular trades", streamID)
@@ -1078,6 +1262,10 @@ func (s *Server) BroadcastMT5CloseToAddonStreams(notification interface{}) {
 // TradingStream handles bidirectional streaming for real-time updates
 func (s *Server) TradingStream(stream trading.StreamingService_TradingStreamServer) error {
 	log.Println("gRPC: New bidirectional trading stream connected")
+	if err := s.refuseRejected(stream.Context(), clients.Addon, ""); err != nil {
+		log.Printf("gRPC: Refusing addon trading stream: %v", err)
+		return err
+	}
 
 	// Create channel for this stream
 	streamChan := make(chan *trading.Trade, 100)
@@ -1093,49 +1281,18 @@ func (s *Server) TradingStream(stream trading.StreamingService_TradingStreamServ
 		delete(s.tradeStreams, streamID)
 		close(streamChan)
 		s.streamsMux.Unlock()
+		s.closeClientStream(streamID)
 		log.Printf("gRPC: Bidirectional trading stream %s disconnected", streamID)
 	}()
 
 	// Mark addon as connected on stream establishment
-	s.app.SetAddonConnected(true)
+	s.openClientStream(stream.Context(), clients.Addon, "", streamID)
 
 	// Handle the stream
 	errChan := make(chan error, 2)
 
 	// Goroutine for receiving trades from client
-	go func() {
-		for {
-			trade, err := stream.Recv()
-			if err != nil {
-				if err == io.EOF {
-					log.Printf("gRPC: Trading stream %s recv closed (EOF)", streamID)
-				} else {
-					log.Printf("gRPC: Trading stream %s recv error: %v", streamID, err)
-				}
-				errChan <- err
-				return
-			}
-
-			// Any inbound message from the addon proves life; refresh connectivity flag
-			s.app.SetAddonConnected(true)
-
-			// Process incoming trade (similar to SubmitTrade)
-			log.Printf("gRPC: Received trade via bidirectional stream - ID: %s", trade.Id)
-
-			// CRITICAL FIX: Include quantity in dedup key to allow multiple positions with same base_id
-			dedupKey := fmt.Sprintf("%s_%.2f_%s", trade.Id, trade.Quantity, trade.Action)
-			if s.wasRecentlyProcessed(dedupKey, 3*time.Second) {
-				log.Printf("gRPC: Skipping duplicate streamed trade ID: %s (qty=%.2f)", trade.Id, trade.Quantity)
-				continue
-			}
-			s.markProcessed(dedupKey)
-
-			// Enqueue with smart splitting so MT5 tickets remain 1:1 with contract count
-			if err := s.enqueueTradeWithSplit(trade); err != nil {
-				log.Printf("gRPC: Failed to add streamed trade(s) to queue: %v", err)
-			}
-		}
-	}()
+	go func() { errChan <- s.receiveAddonTrades(stream.Context(), streamID, stream.Recv) }()
 
 	// Goroutine for sending trades to client
 	go func() {
@@ -1163,6 +1320,45 @@ func (s *Server) TradingStream(stream trading.StreamingService_TradingStreamServ
 	return err
 }
 
+// receiveAddonTrades enqueues the trades an addon stream sends until recv fails.
+func (s *Server) receiveAddonTrades(ctx context.Context, streamID string, recv func() (*trading.Trade, error)) error {
+	for {
+		trade, err := recv()
+		if err != nil {
+			if err == io.EOF {
+				log.Printf("gRPC: Trading stream %s recv closed (EOF)", streamID)
+			} else {
+				log.Printf("gRPC: Trading stream %s recv error: %v", streamID, err)
+			}
+			return err
+		}
+
+		// Any inbound message from the addon proves life; refresh connectivity flag
+		adaptAddonTrade(s.clientSeen(ctx, clients.Addon, "", ""), trade)
+
+		// Process incoming trade (similar to SubmitTrade)
+		log.Printf("gRPC: Received trade via bidirectional stream - ID: %s", trade.Id)
+
+		if key := idempotencyKey(ctx, trade); key != "" && s.idempotencyStore() != nil {
+			s.submitIdempotent(key, trade) // outcome is logged; the stream has no per-trade response
+			continue
+		}
+
+		// CRITICAL FIX: Include quantity in dedup key to allow multiple positions with same base_id
+		dedupKey := fmt.Sprintf("%s_%.2f_%s", trade.Id, trade.Quantity, trade.Action)
+		if s.wasRecentlyProcessed(dedupKey, 3*time.Second) {
+			log.Printf("gRPC: Skipping duplicate streamed trade ID: %s (qty=%.2f)", trade.Id, trade.Quantity)
+			continue
+		}
+		s.markProcessed(dedupKey)
+
+		// Enqueue with smart splitting so MT5 tickets remain 1:1 with contract count
+		if _, _, err := s.enqueueTradeWithSplit(trade); err != nil && !errors.Is(err, errTradeHeld) {
+			log.Printf("gRPC: Failed to add streamed trade(s) to queue: %v", err)
+		}
+	}
+}
+
 // wasRecentlyProcessed checks and expires recentTradeIDs using ttl.
 func (s *Server) wasRecentlyProcessed(id string, ttl time.Duration) bool {
 	if id == "" {
@@ -1194,57 +1390,6 @@ func (s *Server) markProcessed(id string) {
 	s.recentTradesMux.Unlock()
 }
 
-// StatusStream handles streaming status updates
-func (s *Server) StatusStream(stream trading.StreamingService_StatusStreamServer) error {
-	log.Println("gRPC: New status stream connected")
-
-	// Create channel for this stream
-	streamChan := make(chan *trading.HealthResponse, 100)
-	streamID := fmt.Sprintf("status_stream_%d", time.Now().UnixNano())
-
-	s.healthStreamsMux.Lock()
-	s.healthStreams[streamID] = streamChan
-	s.healthStreamsMux.Unlock()
-
-	// Clean up on exit
-	defer func() {
-		s.healthStreamsMux.Lock()
-		delete(s.healthStreams, streamID)
-		close(streamChan)
-		s.healthStreamsMux.Unlock()
-		log.Printf("gRPC: Status stream %s disconnected", streamID)
-	}()
-
-	// Send periodic status updates
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-stream.Context().Done():
-			return nil
-		case <-ticker.C:
-			// Send status update
-			status := &trading.HealthResponse{
-				Status:      "healthy",
-				QueueSize:   int32(s.app.GetQueueSize()),
-				NetPosition: int32(s.app.GetNetPosition()),
-				HedgeSize:   s.app.GetHedgeSize(),
-			}
-
-			if err := stream.Send(status); err != nil {
-				log.Printf("gRPC: Error sending status update: %v", err)
-				return err
-			}
-		case status := <-streamChan:
-			if err := stream.Send(status); err != nil {
-				log.Printf("gRPC: Error sending status update: %v", err)
-				return err
-			}
-		}
-	}
-}
-
 // ElasticUpdatesStream handles streaming elastic hedge updates
 func (s *Server) ElasticUpdatesStream(stream trading.StreamingService_ElasticUpdatesStreamServer) error {
 	log.Println("gRPC: New elastic updates stream connected")
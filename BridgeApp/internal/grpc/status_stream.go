@@ -0,0 +1,171 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"BridgeApp/internal/clients"
+	trading "BridgeApp/internal/grpc/proto"
+)
+
+// Reasons a status is published on StatusStream.
+const (
+	StatusInitial           = "initial"  // first status of a new subscriber
+	StatusPeriodic          = "periodic" // keepalive while nothing changed
+	StatusQueue             = "queue"
+	StatusMT5Connected      = "mt5_connected"
+	StatusMT5Disconnected   = "mt5_disconnected"
+	StatusAddonConnected    = "addon_connected"
+	StatusAddonDisconnected = "addon_disconnected"
+	StatusMode              = "mode"       // schedule switched the bridge mode
+	StatusRiskLimit         = "risk_limit" // an account reached the daily loss limit
+	StatusError             = "error"
+	StatusCloses            = "closes" // a close request was opened or completed
+)
+
+// statusKeepalive is how often StatusStream repeats the status while nothing changes.
+const statusKeepalive = 30 * time.Second
+
+// StatusDetail is the app state StatusStream reports beyond queue, position and connections.
+type StatusDetail struct {
+	Mode          string
+	PendingCloses int
+	LastError     string
+	LastErrorAt   time.Time
+}
+
+// PublishStatus pushes the current status to every StatusStream subscriber. Queue changes that
+// leave the queue sizes as last published are not sent again.
+func (s *Server) PublishStatus(reason, message string) {
+	s.healthStreamsMux.RLock()
+	subscribers := len(s.healthStreams)
+	s.healthStreamsMux.RUnlock()
+	if subscribers == 0 {
+		return
+	}
+
+	st := s.statusSnapshot(reason, message)
+	if reason == StatusQueue {
+		key := fmt.Sprint(st.QueueSizes)
+		s.statusMux.Lock()
+		unchanged := key == s.lastQueueSizes
+		s.lastQueueSizes = key
+		s.statusMux.Unlock()
+		if unchanged {
+			return
+		}
+	}
+
+	s.healthStreamsMux.RLock()
+	defer s.healthStreamsMux.RUnlock()
+	for streamID, streamChan := range s.healthStreams {
+		select {
+		case streamChan <- st:
+		default:
+			log.Printf("gRPC: Status stream %s buffer full, skipping %s status", streamID, reason)
+		}
+	}
+}
+
+// statusSnapshot describes the bridge now.
+func (s *Server) statusSnapshot(reason, message string) *trading.HealthResponse {
+	detail := s.app.StatusDetail()
+	st := &trading.HealthResponse{
+		Status:         "healthy",
+		QueueSize:      int32(s.app.GetQueueSize()),
+		NetPosition:    int32(s.app.GetNetPosition()),
+		HedgeSize:      s.app.GetHedgeSize(),
+		Reason:         reason,
+		Message:        message,
+		Timestamp:      time.Now().Unix(),
+		Sequence:       atomic.AddUint64(&s.statusSeq, 1),
+		AddonConnected: s.app.IsAddonConnected(),
+		Mt5Connected:   s.app.IsHedgebotActive(),
+		BridgeMode:     detail.Mode,
+		QueueSizes:     map[string]int32{},
+		PendingCloses:  int32(detail.PendingCloses),
+		LastError:      detail.LastError,
+	}
+	if !st.Mt5Connected {
+		st.Status = "degraded"
+	}
+	if !detail.LastErrorAt.IsZero() {
+		st.LastErrorAt = detail.LastErrorAt.Unix()
+	}
+	for terminal, n := range s.app.QueueSizes() {
+		st.QueueSizes[terminal] = int32(n)
+	}
+	for _, c := range s.clients.List() {
+		st.Clients = append(st.Clients, clientSessionProto(c))
+	}
+	return st
+}
+
+// StatusStream sends the current status on connect, then every published change, and repeats
+// the status every 30 seconds while nothing changes.
+func (s *Server) StatusStream(stream trading.StreamingService_StatusStreamServer) error {
+	log.Println("gRPC: New status stream connected")
+
+	// Create channel for this stream
+	streamChan := make(chan *trading.HealthResponse, 100)
+	streamID := fmt.Sprintf("status_stream_%d", time.Now().UnixNano())
+
+	s.healthStreamsMux.Lock()
+	s.healthStreams[streamID] = streamChan
+	s.healthStreamsMux.Unlock()
+
+	// Clean up on exit
+	defer func() {
+		s.healthStreamsMux.Lock()
+		delete(s.healthStreams, streamID)
+		close(streamChan)
+		s.healthStreamsMux.Unlock()
+		log.Printf("gRPC: Status stream %s disconnected", streamID)
+	}()
+
+	if err := stream.Send(s.statusSnapshot(StatusInitial, "")); err != nil {
+		log.Printf("gRPC: Error sending status update: %v", err)
+		return err
+	}
+
+	ticker := time.NewTicker(statusKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+			if err := stream.Send(s.statusSnapshot(StatusPeriodic, "")); err != nil {
+				log.Printf("gRPC: Error sending status update: %v", err)
+				return err
+			}
+		case status := <-streamChan:
+			if err := stream.Send(status); err != nil {
+				log.Printf("gRPC: Error sending status update: %v", err)
+				return err
+			}
+			ticker.Reset(statusKeepalive)
+		}
+	}
+}
+
+// clientSessionProto converts a client session for ListClients and StatusStream.
+func clientSessionProto(c clients.Client) *trading.ClientSession {
+	return &trading.ClientSession{
+		Kind:            string(c.Kind),
+		Id:              c.ID,
+		Version:         c.Version,
+		RemoteAddr:      c.RemoteAddr,
+		ConnectedSince:  c.ConnectedSince.Unix(),
+		LastMessage:     c.LastMessage.Unix(),
+		LastMethod:      c.LastMethod,
+		Messages:        c.Messages,
+		StreamIds:       c.Streams,
+		Stale:           c.Stale,
+		ProtocolVersion: uint32(c.Protocol),
+		Features:        c.Features,
+	}
+}
@@ -0,0 +1,256 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/symbols"
+)
+
+// errTradeHeld signals that an entry was parked under the "hold" unknown-symbol policy.
+var errTradeHeld = errors.New("trade held: instrument has no MT5 symbol mapping")
+
+// heldTrade is an entry parked until its instrument can be mapped, its symbol opens or an
+// operator releases it.
+type heldTrade struct {
+	trade  *trading.Trade
+	reason string
+	since  time.Time
+	// queuedAt is when an entry held as stale first entered the queue (zero for other holds); it
+	// is only released automatically while that age is still within entry_max_age.
+	queuedAt time.Time
+}
+
+// SetSymbolMap installs a new instrument map and re-evaluates any held entries against it.
+func (s *Server) SetSymbolMap(m *symbols.Map) {
+	s.symbolMux.Lock()
+	s.symbolMap = m
+	s.symbolMux.Unlock()
+
+	log.Printf("gRPC: Symbol map installed (rules=%d policy=%s)", m.Len(), m.Policy())
+	s.releaseHeld("symbol map reload")
+}
+
+// releaseHeld re-submits every held entry; those still unmappable or stale stay held. Each entry
+// is taken off the held list just before it is enqueued, so one a close dropped meanwhile is skipped.
+func (s *Server) releaseHeld(why string) {
+	s.symbolMux.RLock()
+	held := append([]heldTrade(nil), s.heldTrades...)
+	s.symbolMux.RUnlock()
+	if len(held) == 0 {
+		return
+	}
+
+	log.Printf("gRPC: Re-evaluating %d held trade(s) after %s", len(held), why)
+	for _, h := range held {
+		if s.stillStale(h) {
+			log.Printf("gRPC: Held trade %s (base_id=%s) still stale after %s; waiting for an operator release", h.trade.Id, h.trade.BaseId, why)
+			continue
+		}
+		s.releaseMux.Lock()
+		if !s.unpark(h.trade) {
+			s.releaseMux.Unlock()
+			log.Printf("gRPC: Held trade %s (base_id=%s) was dropped before %s released it", h.trade.Id, h.trade.BaseId, why)
+			continue
+		}
+		_, _, err := s.enqueueTradeWithSplit(h.trade)
+		if errors.Is(err, errTradeHeld) {
+			s.reparked(h)
+		}
+		s.releaseMux.Unlock()
+		if err != nil {
+			if errors.Is(err, errTradeHeld) {
+				continue // parked again
+			}
+			log.Printf("gRPC: Dropping held trade %s (instrument=%s) after %s: %v", h.trade.Id, h.trade.Instrument, why, err)
+			blog.L().Warn("symbols", "held trade dropped after "+why, map[string]interface{}{
+				"trade_id":   h.trade.Id,
+				"base_id":    h.trade.BaseId,
+				"instrument": h.trade.Instrument,
+				"held_for":   time.Since(h.since).String(),
+				"error":      err.Error(),
+			})
+			continue
+		}
+		log.Printf("gRPC: Released held trade %s (instrument=%s) after %s", h.trade.Id, h.trade.Instrument, time.Since(h.since).Truncate(time.Millisecond))
+	}
+}
+
+// unpark removes trade from the held entries and reports whether it was still held. The caller
+// holds releaseMux.
+func (s *Server) unpark(trade *trading.Trade) bool {
+	s.symbolMux.Lock()
+	defer s.symbolMux.Unlock()
+	for i, h := range s.heldTrades {
+		if h.trade == trade {
+			s.heldTrades = append(s.heldTrades[:i:i], s.heldTrades[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// reparked gives an entry parked again on release the age it had, so held_for and the stale
+// check keep counting from when it was first held. The caller holds releaseMux.
+func (s *Server) reparked(h heldTrade) {
+	s.symbolMux.Lock()
+	defer s.symbolMux.Unlock()
+	for i := range s.heldTrades {
+		if s.heldTrades[i].trade == h.trade {
+			s.heldTrades[i].since, s.heldTrades[i].queuedAt = h.since, h.queuedAt
+			return
+		}
+	}
+}
+
+// stillStale reports whether a stale-held entry is older than entry_max_age, measured from when
+// it first entered the queue.
+func (s *Server) stillStale(h heldTrade) bool {
+	if h.queuedAt.IsZero() {
+		return false
+	}
+	maxAge := s.app.EntryMaxAge()
+	return maxAge > 0 && time.Since(h.queuedAt) > maxAge
+}
+
+// ReleaseHeldTrade re-submits one held entry on an operator's request, whatever its age.
+func (s *Server) ReleaseHeldTrade(id string) error {
+	id = strings.TrimSpace(id)
+	s.releaseMux.Lock()
+	defer s.releaseMux.Unlock()
+	s.symbolMux.RLock()
+	var found heldTrade
+	ok := false
+	for _, h := range s.heldTrades {
+		if h.trade.Id == id {
+			found, ok = h, true
+			break
+		}
+	}
+	s.symbolMux.RUnlock()
+	if !ok || !s.unpark(found.trade) {
+		return fmt.Errorf("no held trade %q", id)
+	}
+	if _, _, err := s.enqueueTradeWithSplit(found.trade); errors.Is(err, errTradeHeld) {
+		s.reparked(found)
+	} else if err != nil {
+		return err
+	}
+	log.Printf("gRPC: Operator released held trade %s (base_id=%s) after %s", id, found.trade.BaseId, time.Since(found.since).Truncate(time.Millisecond))
+	return nil
+}
+
+// DropHeldTrades discards up to limit held entries (all matching ones when limit <= 0) for which
+// match reports true, because their position was closed or flattened while they waited. It returns
+// the ids of the dropped trades.
+func (s *Server) DropHeldTrades(match func(baseID, account string) bool, limit int, why string) []string {
+	s.releaseMux.Lock()
+	defer s.releaseMux.Unlock()
+	s.symbolMux.Lock()
+	var dropped []heldTrade
+	kept := s.heldTrades[:0:0]
+	for _, h := range s.heldTrades {
+		if (limit <= 0 || len(dropped) < limit) && match(strings.TrimSpace(h.trade.BaseId), strings.TrimSpace(h.trade.AccountName)) {
+			dropped = append(dropped, h)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	s.heldTrades = kept
+	s.symbolMux.Unlock()
+
+	ids := make([]string, 0, len(dropped))
+	for _, h := range dropped {
+		ids = append(ids, h.trade.Id)
+		log.Printf("gRPC: Dropping held trade %s (base_id=%s): %s", h.trade.Id, h.trade.BaseId, why)
+		blog.L().Info("symbols", "held trade dropped: "+why, map[string]interface{}{
+			"trade_id": h.trade.Id,
+			"base_id":  h.trade.BaseId,
+			"account":  h.trade.AccountName,
+			"held_for": time.Since(h.since).String(),
+		})
+	}
+	return ids
+}
+
+// HeldTradeCount returns the number of entries parked under the hold policy.
+func (s *Server) HeldTradeCount() int {
+	s.symbolMux.RLock()
+	defer s.symbolMux.RUnlock()
+	return len(s.heldTrades)
+}
+
+// resolveSymbol maps the trade's instrument. Only entries (buy/sell) are gated by the
+// unknown-symbol policy; other actions are annotated when a rule matches and never refused.
+func (s *Server) resolveSymbol(req *trading.Trade) (symbols.Resolution, error) {
+	s.symbolMux.RLock()
+	m := s.symbolMap
+	s.symbolMux.RUnlock()
+
+	res, err := m.Resolve(req.Instrument)
+	if err == nil {
+		return res, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case "buy", "sell":
+		return res, err
+	default:
+		return res, nil
+	}
+}
+
+// parkTrade adds an entry to the held trades and returns how many are held.
+func (s *Server) parkTrade(req *trading.Trade, reason string) int {
+	s.symbolMux.Lock()
+	defer s.symbolMux.Unlock()
+	s.heldTrades = append(s.heldTrades, heldTrade{trade: req, reason: reason, since: time.Now()})
+	return len(s.heldTrades)
+}
+
+// holdTrade parks an entry until SetSymbolMap provides a mapping for its instrument.
+func (s *Server) holdTrade(req *trading.Trade, reason string) {
+	count := s.parkTrade(req, reason)
+
+	log.Printf("WARN: Holding trade %s (base_id=%s): %s (held=%d)", req.Id, req.BaseId, reason, count)
+	blog.L().Warn("symbols", "trade held: unknown instrument", map[string]interface{}{
+		"trade_id":   req.Id,
+		"base_id":    req.BaseId,
+		"instrument": req.Instrument,
+		"held_count": count,
+	})
+}
+
+// HoldTrade parks a stale queued trade (a main.Trade) with the held entries. It is re-submitted
+// with them on the next SetSymbolMap (config reload) only if its age since queuedAt is back within
+// entry_max_age; otherwise it waits for ReleaseHeldTrade.
+func (s *Server) HoldTrade(trade interface{}, reason string, queuedAt time.Time) {
+	req, err := tradeFromApp(trade)
+	if err != nil {
+		return
+	}
+	s.symbolMux.Lock()
+	s.heldTrades = append(s.heldTrades, heldTrade{trade: req, reason: reason, since: time.Now(), queuedAt: queuedAt})
+	count := len(s.heldTrades)
+	s.symbolMux.Unlock()
+	log.Printf("WARN: Holding trade %s (base_id=%s): %s (held=%d)", req.Id, req.BaseId, reason, count)
+}
+
+// symbolMetadata describes a resolution for GenericResponse.metadata.
+func symbolMetadata(res symbols.Resolution, md map[string]string) map[string]string {
+	if md == nil {
+		md = map[string]string{}
+	}
+	if res.Mapped {
+		md["mt5_symbol"] = res.Symbol
+		md["symbol_rule"] = res.Rule
+	} else if res.Instrument != "" {
+		md["symbol_status"] = "unmapped"
+		md["symbol_policy"] = string(res.Policy)
+	}
+	return md
+}
@@ -0,0 +1,227 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	trading "BridgeApp/internal/grpc/proto"
+	blog "BridgeApp/internal/logging"
+	"BridgeApp/internal/routing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// terminalStream is the MT5 GetTrades stream serving one destination terminal.
+type terminalStream struct {
+	streamID    string
+	terminalID  string // identity presented by the EA (metadata or GetTradesRequest)
+	accountID   string
+	connectedAt time.Time
+	lastSeen    time.Time
+	sent        uint64
+	live        bool
+}
+
+// TerminalStatus is the per-terminal health snapshot exposed to the UI.
+type TerminalStatus struct {
+	Terminal    string    `json:"terminal"`
+	Connected   bool      `json:"connected"`
+	StreamID    string    `json:"stream_id,omitempty"`
+	TerminalID  string    `json:"terminal_id,omitempty"`
+	AccountID   string    `json:"account_id,omitempty"`
+	ConnectedAt time.Time `json:"connected_at,omitempty"`
+	LastSeen    time.Time `json:"last_seen,omitempty"`
+	Sent        uint64    `json:"sent"`
+	QueueSize   int       `json:"queue_size"`
+}
+
+// SetRouter installs the terminal routing rules and re-binds connected EAs to their destinations.
+func (s *Server) SetRouter(r *routing.Router) {
+	s.routerMux.Lock()
+	s.router = r
+	s.routerMux.Unlock()
+
+	s.mt5StreamMux.RLock()
+	var rebinds []terminalStream
+	for _, ts := range s.mt5Streams {
+		if ts.live {
+			rebinds = append(rebinds, *ts)
+		}
+	}
+	s.mt5StreamMux.RUnlock()
+	for _, ts := range rebinds {
+		s.bindMT5Stream(ts.streamID, ts.terminalID, ts.accountID)
+	}
+	log.Printf("gRPC: Terminal routing installed (terminals=%s default=%s)", strings.Join(r.Terminals(), ","), r.Default())
+}
+
+// DefaultTerminal returns the destination for trades that match no routing rule.
+func (s *Server) DefaultTerminal() string {
+	return s.currentRouter().Default()
+}
+
+func (s *Server) currentRouter() *routing.Router {
+	s.routerMux.RLock()
+	defer s.routerMux.RUnlock()
+	return s.router
+}
+
+// routeTrade assigns an entry to its target terminal. Closes and events follow the BaseID's
+// entry, which the app resolves when the terminal is left empty.
+func (s *Server) routeTrade(req *trading.Trade, base *InternalTrade) {
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case "buy", "sell":
+	default:
+		return
+	}
+	terminal, rule := s.currentRouter().Route(req.AccountName, req.StrategyTag)
+	base.Terminal = terminal
+	if rule != "" {
+		log.Printf("gRPC: Routed trade %s (account=%s strategy_tag=%s) to terminal %s", req.Id, req.AccountName, req.StrategyTag, terminal)
+		blog.L().Info("routing", "trade routed to terminal", map[string]interface{}{
+			"trade_id": req.Id,
+			"base_id":  req.BaseId,
+			"account":  req.AccountName,
+			"strategy": req.StrategyTag,
+			"terminal": terminal,
+			"rule":     rule,
+		})
+	}
+}
+
+// streamIdentity reads the terminal/account id an EA sends as gRPC metadata on connect.
+func streamIdentity(ctx context.Context) (terminalID, accountID string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	if v := md.Get("terminal-id"); len(v) > 0 {
+		terminalID = strings.TrimSpace(v[0])
+	}
+	if v := md.Get("account-id"); len(v) > 0 {
+		accountID = strings.TrimSpace(v[0])
+	}
+	return terminalID, accountID
+}
+
+// bindMT5Stream makes streamID the active stream for the destination its identity maps to,
+// superseding any other stream serving that destination. It returns the destination and the
+// ids of superseded streams (already removed from tradeStreams).
+func (s *Server) bindMT5Stream(streamID, terminalID, accountID string) (string, []string) {
+	dest := s.currentRouter().Destination(terminalID, accountID)
+	now := time.Now()
+
+	s.mt5StreamMux.Lock()
+	if prev, ok := s.streamDest[streamID]; ok && prev != dest {
+		if ts := s.mt5Streams[prev]; ts != nil && ts.streamID == streamID {
+			ts.live = false
+			ts.streamID = ""
+		}
+	}
+	var superseded []string
+	ts := s.mt5Streams[dest]
+	if ts != nil && ts.live && ts.streamID != streamID {
+		superseded = append(superseded, ts.streamID)
+		delete(s.streamDest, ts.streamID)
+	}
+	if ts == nil || ts.streamID != streamID {
+		ts = &terminalStream{streamID: streamID, connectedAt: now}
+		s.mt5Streams[dest] = ts
+	}
+	ts.terminalID, ts.accountID, ts.lastSeen, ts.live = terminalID, accountID, now, true
+	s.streamDest[streamID] = dest
+	s.mt5StreamMux.Unlock()
+
+	if len(superseded) > 0 {
+		s.streamsMux.Lock()
+		for _, id := range superseded {
+			delete(s.tradeStreams, id)
+			log.Printf("gRPC: Superseding prior MT5 stream %s for terminal %s (removed)", id, dest)
+		}
+		s.streamsMux.Unlock()
+	}
+	return dest, superseded
+}
+
+// unbindMT5Stream marks the stream's destination offline when the stream ends.
+func (s *Server) unbindMT5Stream(streamID string) {
+	s.mt5StreamMux.Lock()
+	defer s.mt5StreamMux.Unlock()
+	dest, ok := s.streamDest[streamID]
+	if !ok {
+		return
+	}
+	delete(s.streamDest, streamID)
+	if ts := s.mt5Streams[dest]; ts != nil && ts.streamID == streamID {
+		ts.live = false
+		ts.streamID = ""
+	}
+}
+
+// mt5StreamDestination returns the destination streamID serves; ok is false once the stream
+// was superseded or unbound.
+func (s *Server) mt5StreamDestination(streamID string) (string, bool) {
+	s.mt5StreamMux.RLock()
+	defer s.mt5StreamMux.RUnlock()
+	dest, ok := s.streamDest[streamID]
+	return dest, ok
+}
+
+// touchMT5Stream records proof-of-life (and optionally a sent trade) for the stream's terminal.
+func (s *Server) touchMT5Stream(streamID string, sent bool) {
+	s.mt5StreamMux.Lock()
+	defer s.mt5StreamMux.Unlock()
+	if ts := s.mt5Streams[s.streamDest[streamID]]; ts != nil && ts.streamID == streamID {
+		ts.lastSeen = time.Now()
+		if sent {
+			ts.sent++
+		}
+	}
+}
+
+// hasLiveMT5Stream reports whether an EA is connected for dest.
+func (s *Server) hasLiveMT5Stream(dest string) bool {
+	s.mt5StreamMux.RLock()
+	defer s.mt5StreamMux.RUnlock()
+	ts := s.mt5Streams[dest]
+	return ts != nil && ts.live
+}
+
+// TerminalStatuses returns health for every configured, connected or queued terminal.
+func (s *Server) TerminalStatuses() []TerminalStatus {
+	queues := s.app.QueueSizes()
+	byName := make(map[string]*TerminalStatus)
+	get := func(name string) *TerminalStatus {
+		if st, ok := byName[name]; ok {
+			return st
+		}
+		st := &TerminalStatus{Terminal: name}
+		byName[name] = st
+		return st
+	}
+	for _, name := range s.currentRouter().Terminals() {
+		get(name)
+	}
+	for name, size := range queues {
+		get(name).QueueSize = size
+	}
+
+	s.mt5StreamMux.RLock()
+	for name, ts := range s.mt5Streams {
+		st := get(name)
+		st.Connected, st.StreamID = ts.live, ts.streamID
+		st.TerminalID, st.AccountID = ts.terminalID, ts.accountID
+		st.ConnectedAt, st.LastSeen, st.Sent = ts.connectedAt, ts.lastSeen, ts.sent
+	}
+	s.mt5StreamMux.RUnlock()
+
+	out := make([]TerminalStatus, 0, len(byName))
+	for _, st := range byName {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Terminal < out[j].Terminal })
+	return out
+}
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"time"
+
+	"BridgeApp/internal/session"
+)
+
+// SetSessionCalendar installs the trading-session calendar and the daily loss limit per account.
+func (s *Server) SetSessionCalendar(c *session.Calendar, lossLimit float64) {
+	s.sessionMux.Lock()
+	s.calendar, s.lossLimit = c, lossLimit
+	s.sessionMux.Unlock()
+}
+
+// dailyLossLimit returns the configured loss limit per account and trading day.
+func (s *Server) dailyLossLimit() float64 {
+	s.sessionMux.RLock()
+	defer s.sessionMux.RUnlock()
+	if s.lossLimit > 0 {
+		return s.lossLimit
+	}
+	return session.DefaultDailyLossLimit
+}
+
+// sessionSettings reports the trading day containing now and its bounds for GetSettings, so the
+// addon resets its daily counters and loss-limit window on the bridge's session boundary.
+func (s *Server) sessionSettings(now time.Time) map[string]string {
+	s.sessionMux.RLock()
+	c := s.calendar
+	s.sessionMux.RUnlock()
+	if c == nil {
+		return nil
+	}
+	start, end := c.Bounds(now)
+	return map[string]string{
+		"trading-day":      c.Day(now),
+		"session-start":    start.Format(time.RFC3339),
+		"session-end":      end.Format(time.RFC3339),
+		"session-timezone": c.Location().String(),
+	}
+}
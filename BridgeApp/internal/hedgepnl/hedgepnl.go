@@ -0,0 +1,229 @@
+package hedgepnl
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownBase reports a BaseID the ledger has no hedge deals for.
+var ErrUnknownBase = errors.New("no MT5 hedge deals recorded for base_id")
+
+// DefaultRetainedBases is the number of fully closed BaseIDs kept for summaries.
+const DefaultRetainedBases = 1000
+
+// Deal is the price, volume and costs MT5 reported for one deal (0 = not reported).
+type Deal struct {
+	Ticket     uint64    `json:"deal_ticket,omitempty"`
+	Price      float64   `json:"price,omitempty"`
+	OpenPrice  float64   `json:"open_price,omitempty"`
+	Volume     float64   `json:"volume,omitempty"`
+	Commission float64   `json:"commission,omitempty"`
+	Swap       float64   `json:"swap,omitempty"`
+	Profit     float64   `json:"profit,omitempty"`
+	ServerTime int64     `json:"server_time,omitempty"` // MT5 trade server clock, Unix seconds
+	Received   time.Time `json:"received"`
+}
+
+// same reports whether d and o are the same MT5 deal reported twice.
+func (d Deal) same(o Deal) bool {
+	if d.Ticket != 0 || o.Ticket != 0 {
+		return d.Ticket == o.Ticket
+	}
+	return d.ServerTime != 0 && d.ServerTime == o.ServerTime &&
+		math.Abs(d.Volume-o.Volume) < 1e-9 && d.Price == o.Price
+}
+
+// Ticket is everything recorded about one MT5 hedge position.
+type Ticket struct {
+	Ticket         uint64  `json:"ticket"`
+	Open           *Deal   `json:"open,omitempty"`
+	Closes         []Deal  `json:"closes,omitempty"`
+	Closed         bool    `json:"closed"`
+	FloatingProfit float64 `json:"floating_profit,omitempty"` // latest position_update while open
+}
+
+// Summary is the hedge result of a BaseID over all its tickets.
+type Summary struct {
+	BaseID         string    `json:"base_id"`
+	Tickets        []Ticket  `json:"tickets"`
+	OpenTickets    int       `json:"open_tickets"`
+	OpenedVolume   float64   `json:"opened_volume"`
+	ClosedVolume   float64   `json:"closed_volume"`
+	RealizedProfit float64   `json:"realized_profit"` // sum of closing deal profits
+	Commission     float64   `json:"commission"`      // open and close deals
+	Swap           float64   `json:"swap"`
+	FloatingProfit float64   `json:"floating_profit"` // open tickets, from the latest position updates
+	Net            float64   `json:"net"`             // realized + commission + swap + floating
+	Updated        time.Time `json:"updated"`
+}
+
+type base struct {
+	tickets map[uint64]*Ticket
+	updated time.Time
+}
+
+// Ledger records MT5 hedge deals per BaseID and ticket.
+type Ledger struct {
+	mu       sync.Mutex
+	bases    map[string]*base
+	closed   []string // fully closed BaseIDs, oldest first, for eviction
+	retained int
+}
+
+// NewLedger returns an empty ledger.
+func NewLedger() *Ledger {
+	return &Ledger{bases: make(map[string]*base), retained: DefaultRetainedBases}
+}
+
+func (l *Ledger) ticketLocked(baseID string, ticket uint64) *Ticket {
+	b := l.bases[baseID]
+	if b == nil {
+		b = &base{tickets: make(map[uint64]*Ticket)}
+		l.bases[baseID] = b
+	}
+	b.updated = time.Now()
+	t := b.tickets[ticket]
+	if t == nil {
+		t = &Ticket{Ticket: ticket}
+		b.tickets[ticket] = t
+	}
+	return t
+}
+
+// RecordOpen records the deal that opened ticket.
+func (l *Ledger) RecordOpen(baseID string, ticket uint64, d Deal) {
+	if baseID == "" || ticket == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.ticketLocked(baseID, ticket)
+	if d.OpenPrice == 0 {
+		d.OpenPrice = d.Price
+	}
+	t.Open = &d
+}
+
+// RecordClose records a closing deal of ticket; partial leaves the position open. A deal already
+// recorded (the EA reports a close both as a result and as a notification) is merged, not added.
+// It returns the closing deal as recorded.
+func (l *Ledger) RecordClose(baseID string, ticket uint64, d Deal, partial bool) Deal {
+	if baseID == "" || ticket == 0 {
+		return d
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.ticketLocked(baseID, ticket)
+	if d.OpenPrice == 0 && t.Open != nil {
+		d.OpenPrice = t.Open.OpenPrice
+	}
+	merged := false
+	for i := range t.Closes {
+		if t.Closes[i].same(d) {
+			t.Closes[i] = merge(t.Closes[i], d)
+			d, merged = t.Closes[i], true
+			break
+		}
+	}
+	if !merged {
+		t.Closes = append(t.Closes, d)
+	}
+	if !partial {
+		t.Closed, t.FloatingProfit = true, 0
+	}
+	l.evictLocked(baseID)
+	return d
+}
+
+// RecordFloating records the floating PnL of an open ticket.
+func (l *Ledger) RecordFloating(baseID string, ticket uint64, profit float64) {
+	if baseID == "" || ticket == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t := l.ticketLocked(baseID, ticket); !t.Closed {
+		t.FloatingProfit = profit
+	}
+}
+
+// merge fills the fields of a that b reports and a does not.
+func merge(a, b Deal) Deal {
+	pick := func(x, y float64) float64 {
+		if x == 0 {
+			return y
+		}
+		return x
+	}
+	a.Price, a.OpenPrice, a.Volume = pick(a.Price, b.Price), pick(a.OpenPrice, b.OpenPrice), pick(a.Volume, b.Volume)
+	a.Commission, a.Swap, a.Profit = pick(a.Commission, b.Commission), pick(a.Swap, b.Swap), pick(a.Profit, b.Profit)
+	if a.ServerTime == 0 {
+		a.ServerTime = b.ServerTime
+	}
+	if a.Ticket == 0 {
+		a.Ticket = b.Ticket
+	}
+	return a
+}
+
+// evictLocked remembers baseID once every ticket closed and drops the oldest closed BaseIDs
+// beyond the retention limit.
+func (l *Ledger) evictLocked(baseID string) {
+	for _, t := range l.bases[baseID].tickets {
+		if !t.Closed {
+			return
+		}
+	}
+	for _, id := range l.closed {
+		if id == baseID {
+			return
+		}
+	}
+	l.closed = append(l.closed, baseID)
+	for len(l.closed) > l.retained {
+		delete(l.bases, l.closed[0])
+		l.closed = l.closed[1:]
+	}
+}
+
+// Summary totals the hedge deals of baseID.
+func (l *Ledger) Summary(baseID string) (Summary, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bases[baseID]
+	if b == nil {
+		return Summary{}, ErrUnknownBase
+	}
+	s := Summary{BaseID: baseID, Updated: b.updated}
+	for _, t := range b.tickets {
+		c := *t
+		c.Closes = append([]Deal(nil), t.Closes...)
+		if t.Open != nil {
+			open := *t.Open
+			c.Open = &open
+			s.OpenedVolume += open.Volume
+			s.Commission += open.Commission
+			s.Swap += open.Swap
+		}
+		for _, d := range t.Closes {
+			s.ClosedVolume += d.Volume
+			s.RealizedProfit += d.Profit
+			s.Commission += d.Commission
+			s.Swap += d.Swap
+		}
+		if !t.Closed {
+			s.OpenTickets++
+			s.FloatingProfit += t.FloatingProfit
+		}
+		s.Tickets = append(s.Tickets, c)
+	}
+	sort.Slice(s.Tickets, func(i, j int) bool { return s.Tickets[i].Ticket < s.Tickets[j].Ticket })
+	s.Net = s.RealizedProfit + s.Commission + s.Swap + s.FloatingProfit
+	for _, v := range []*float64{&s.OpenedVolume, &s.ClosedVolume, &s.RealizedProfit, &s.Commission, &s.Swap, &s.FloatingProfit, &s.Net} {
+		*v = math.Round(*v*1e8) / 1e8
+	}
+	return s, nil
+}
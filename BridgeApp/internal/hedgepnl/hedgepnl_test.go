@@ -0,0 +1,67 @@
+package hedgepnl
+
+import "testing"
+
+func TestSummaryTotalsDealsAndFloatingPnL(t *testing.T) {
+	l := NewLedger()
+	l.RecordOpen("B", 1, Deal{Ticket: 100, Price: 2050.5, Volume: 0.2, Commission: -1.4})
+	l.RecordOpen("B", 2, Deal{Ticket: 101, Price: 2051, Volume: 0.1, Commission: -0.7})
+	l.RecordFloating("B", 2, 12.5)
+
+	partial := l.RecordClose("B", 1, Deal{Ticket: 200, Price: 2055.5, Volume: 0.05, Commission: -0.35, Profit: 25}, true)
+	if partial.OpenPrice != 2050.5 {
+		t.Fatalf("a closing deal without an open price must inherit the ticket's, got %+v", partial)
+	}
+	l.RecordClose("B", 1, Deal{Ticket: 201, Price: 2040.5, Volume: 0.15, Commission: -1.05, Swap: -0.2, Profit: -150}, false)
+
+	s, err := l.Summary("B")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if s.OpenTickets != 1 || s.OpenedVolume != 0.3 || s.ClosedVolume != 0.2 || s.RealizedProfit != -125 ||
+		s.Commission != -3.5 || s.Swap != -0.2 || s.FloatingProfit != 12.5 || s.Net != -116.2 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if len(s.Tickets) != 2 || s.Tickets[0].Ticket != 1 || !s.Tickets[0].Closed || len(s.Tickets[0].Closes) != 2 {
+		t.Fatalf("unexpected tickets: %+v", s.Tickets)
+	}
+	if _, err := l.Summary("UNKNOWN"); err != ErrUnknownBase {
+		t.Fatalf("expected ErrUnknownBase, got %v", err)
+	}
+}
+
+func TestSameDealReportedTwiceIsMerged(t *testing.T) {
+	l := NewLedger()
+	l.RecordClose("B", 1, Deal{Ticket: 300, Volume: 0.1, Profit: 40}, false)
+	got := l.RecordClose("B", 1, Deal{Ticket: 300, Price: 2060, Volume: 0.1, Commission: -0.7, Profit: 40}, false)
+	if got.Price != 2060 || got.Commission != -0.7 {
+		t.Fatalf("the second report must complete the first, got %+v", got)
+	}
+
+	// Without a deal ticket, the server time, volume and price identify the deal
+	l.RecordClose("C", 2, Deal{Price: 1.1, Volume: 0.2, Profit: 5, ServerTime: 1700000000}, false)
+	l.RecordClose("C", 2, Deal{Price: 1.1, Volume: 0.2, Profit: 5, ServerTime: 1700000000}, false)
+
+	for base, want := range map[string]float64{"B": 40, "C": 5} {
+		s, _ := l.Summary(base)
+		if len(s.Tickets[0].Closes) != 1 || s.RealizedProfit != want {
+			t.Fatalf("%s: duplicate deal counted twice: %+v", base, s)
+		}
+	}
+}
+
+func TestClosedBasesAreEvictedBeyondRetention(t *testing.T) {
+	l := NewLedger()
+	l.retained = 1
+	l.RecordClose("OLD", 1, Deal{Ticket: 1, Profit: 1}, false)
+	l.RecordOpen("OPEN", 3, Deal{Ticket: 3})
+	l.RecordClose("NEW", 2, Deal{Ticket: 2, Profit: 2}, false)
+	if _, err := l.Summary("OLD"); err != ErrUnknownBase {
+		t.Fatalf("OLD must be evicted, got %v", err)
+	}
+	for _, base := range []string{"NEW", "OPEN"} {
+		if _, err := l.Summary(base); err != nil {
+			t.Fatalf("%s must be kept: %v", base, err)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package hours
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/symbols"
+)
+
+// lookahead bounds the search for the next opening (long exchange closures included).
+const lookahead = 14 * 24 * time.Hour
+
+// Policy decides what happens to entries sent while their MT5 symbol is closed.
+type Policy string
+
+const (
+	// PolicyHold parks the entry on the bridge and submits it when the symbol opens.
+	PolicyHold Policy = "hold"
+	// PolicyReject refuses the entry and reports when the symbol opens.
+	PolicyReject Policy = "reject"
+	// PolicyRoute sends the entry to another MT5 terminal (e.g. a broker quoting around the clock).
+	PolicyRoute Policy = "route"
+)
+
+// Session is a weekly trading window. Hours is "HH:MM-HH:MM"; an end at or before the start
+// runs into the next day (CME: "17:00-16:00" on sun ... thu).
+type Session struct {
+	Days  []string `json:"days,omitempty"` // mon ... sun; every day when empty
+	Hours string   `json:"hours"`
+}
+
+// Holiday replaces the hours of one date. Hours are "HH:MM-HH:MM" windows within the date (end up
+// to "24:00"); the symbol is closed all day when there are none.
+type Holiday struct {
+	Date  string   `json:"date"` // YYYY-MM-DD
+	Name  string   `json:"name,omitempty"`
+	Hours []string `json:"hours,omitempty"`
+}
+
+// Table is the trading hours of the MT5 symbols matching Symbol (exact name or * / ? wildcard).
+type Table struct {
+	Symbol   string    `json:"symbol"`
+	Timezone string    `json:"timezone,omitempty"` // IANA name; Config.Timezone when empty
+	Sessions []Session `json:"sessions"`
+	Holidays []Holiday `json:"holidays,omitempty"` // in addition to Config.Holidays
+	Policy   Policy    `json:"policy,omitempty"`   // overrides Config.Policy
+	Terminal string    `json:"terminal,omitempty"` // route destination; overrides Config.Terminal
+}
+
+// Config is the "trading_hours" section of the bridge configuration file.
+type Config struct {
+	Timezone string    `json:"timezone,omitempty"` // IANA name; the session timezone when empty
+	Policy   Policy    `json:"policy,omitempty"`   // hold (default), reject or route
+	Terminal string    `json:"terminal,omitempty"` // route destination
+	Holidays []Holiday `json:"holidays,omitempty"` // exchange holidays applying to every table
+	Tables   []Table   `json:"symbols,omitempty"`
+}
+
+// Validate rejects tables that cannot be parsed and route policies without a terminal.
+func (c Config) Validate() error {
+	_, err := New(c, time.Local)
+	return err
+}
+
+// Decision is the outcome of checking an MT5 symbol at an instant.
+type Decision struct {
+	Symbol   string
+	Table    string // symbol pattern of the matching table; "" when the symbol has no hours (always open)
+	Open     bool
+	Policy   Policy    // applied when closed
+	Terminal string    // route destination when closed under PolicyRoute
+	Reason   string    // why the symbol is closed
+	OpensAt  time.Time // next opening when closed; zero when none within two weeks
+}
+
+type window struct{ start, end int } // minutes from midnight; end may exceed 1440 (next day)
+
+type weekly struct {
+	days map[time.Weekday]bool
+	window
+}
+
+type holiday struct {
+	name    string
+	windows []window
+}
+
+type compiledTable struct {
+	label    string
+	re       *regexp.Regexp
+	loc      *time.Location
+	sessions []weekly
+	holidays map[string]holiday // YYYY-MM-DD
+	policy   Policy
+	terminal string
+}
+
+// Gate is a compiled, immutable set of trading-hours tables. A nil *Gate keeps every symbol open.
+type Gate struct {
+	tables []compiledTable
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// New validates cfg and compiles its tables; hours are read in the table's timezone, then
+// cfg.Timezone, then loc.
+func New(cfg Config, loc *time.Location) (*Gate, error) {
+	if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("trading_hours: unknown timezone %q: %w", cfg.Timezone, err)
+		}
+		loc = l
+	}
+	policy, err := parsePolicy(cfg.Policy, PolicyHold)
+	if err != nil {
+		return nil, fmt.Errorf("trading_hours: %w", err)
+	}
+	common, err := compileHolidays(cfg.Holidays, nil)
+	if err != nil {
+		return nil, fmt.Errorf("trading_hours: %w", err)
+	}
+
+	g := &Gate{}
+	for i, t := range cfg.Tables {
+		ct, err := compileTable(t, loc, policy, strings.TrimSpace(cfg.Terminal), common)
+		if err != nil {
+			return nil, fmt.Errorf("trading_hours: table %d: %w", i, err)
+		}
+		g.tables = append(g.tables, ct)
+	}
+	return g, nil
+}
+
+func parsePolicy(p Policy, def Policy) (Policy, error) {
+	switch v := Policy(strings.ToLower(strings.TrimSpace(string(p)))); v {
+	case "":
+		return def, nil
+	case PolicyHold, PolicyReject, PolicyRoute:
+		return v, nil
+	default:
+		return "", fmt.Errorf("policy %q must be one of hold|reject|route", p)
+	}
+}
+
+func compileTable(t Table, loc *time.Location, policy Policy, terminal string, common map[string]holiday) (compiledTable, error) {
+	symbol := strings.TrimSpace(t.Symbol)
+	if symbol == "" {
+		return compiledTable{}, fmt.Errorf("has no symbol")
+	}
+	ct := compiledTable{label: symbol, re: symbols.Pattern(symbol), loc: loc, terminal: terminal}
+	if tz := strings.TrimSpace(t.Timezone); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return ct, fmt.Errorf("unknown timezone %q: %w", t.Timezone, err)
+		}
+		ct.loc = l
+	}
+	var err error
+	if ct.policy, err = parsePolicy(t.Policy, policy); err != nil {
+		return ct, err
+	}
+	if v := strings.TrimSpace(t.Terminal); v != "" {
+		ct.terminal = v
+	}
+	if ct.policy == PolicyRoute && ct.terminal == "" {
+		return ct, fmt.Errorf("policy route needs a terminal")
+	}
+	if len(t.Sessions) == 0 {
+		return ct, fmt.Errorf("has no sessions")
+	}
+	for _, s := range t.Sessions {
+		w, err := parseWindow(s.Hours, true)
+		if err != nil {
+			return ct, err
+		}
+		ws := weekly{window: w}
+		if len(s.Days) > 0 {
+			ws.days = make(map[time.Weekday]bool)
+			for _, d := range s.Days {
+				key := strings.ToLower(strings.TrimSpace(d))
+				if len(key) > 3 {
+					key = key[:3]
+				}
+				wd, ok := weekdays[key]
+				if !ok {
+					return ct, fmt.Errorf("unknown day %q", d)
+				}
+				ws.days[wd] = true
+			}
+		}
+		ct.sessions = append(ct.sessions, ws)
+	}
+	if ct.holidays, err = compileHolidays(t.Holidays, common); err != nil {
+		return ct, err
+	}
+	return ct, nil
+}
+
+// compileHolidays merges list over base; a table's own entry wins over a common one.
+func compileHolidays(list []Holiday, base map[string]holiday) (map[string]holiday, error) {
+	out := make(map[string]holiday, len(base)+len(list))
+	for k, v := range base {
+		out[k] = v
+	}
+	for _, h := range list {
+		date := strings.TrimSpace(h.Date)
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("holiday date %q is not YYYY-MM-DD", h.Date)
+		}
+		ch := holiday{name: strings.TrimSpace(h.Name)}
+		if ch.name == "" {
+			ch.name = date
+		}
+		for _, v := range h.Hours {
+			w, err := parseWindow(v, false)
+			if err != nil {
+				return nil, err
+			}
+			ch.windows = append(ch.windows, w)
+		}
+		out[date] = ch
+	}
+	return out, nil
+}
+
+// parseWindow reads "HH:MM-HH:MM". Weekly windows whose end is at or before the start run into
+// the next day; holiday windows must end after they start, at "24:00" at the latest.
+func parseWindow(v string, wrap bool) (window, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(v), "-")
+	if !ok {
+		return window{}, fmt.Errorf("hours %q are not HH:MM-HH:MM", v)
+	}
+	start, err1 := parseClock(from)
+	end, err2 := parseClock(to)
+	if err1 != nil || err2 != nil || start == 24*60 {
+		return window{}, fmt.Errorf("hours %q are not HH:MM-HH:MM", v)
+	}
+	if end <= start {
+		if !wrap {
+			return window{}, fmt.Errorf("holiday hours %q must end after they start", v)
+		}
+		end += 24 * 60
+	}
+	return window{start: start, end: end}, nil
+}
+
+func parseClock(v string) (int, error) {
+	v = strings.TrimSpace(v)
+	if v == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Len returns the number of tables.
+func (g *Gate) Len() int {
+	if g == nil {
+		return 0
+	}
+	return len(g.tables)
+}
+
+// Check decides whether symbol trades at now. The first table matching the symbol applies, in
+// configuration order; symbols without a table are always open.
+func (g *Gate) Check(symbol string, now time.Time) Decision {
+	symbol = strings.TrimSpace(symbol)
+	d := Decision{Symbol: symbol, Open: true}
+	if g == nil || symbol == "" {
+		return d
+	}
+	for _, t := range g.tables {
+		if !t.re.MatchString(symbol) {
+			continue
+		}
+		d.Table, d.Policy, d.Terminal = t.label, t.policy, t.terminal
+		reason, open := t.open(now)
+		if !open {
+			d.Open, d.Reason, d.OpensAt = false, reason, t.nextOpen(now)
+		}
+		return d
+	}
+	return d
+}
+
+// dayStart returns midnight of t's date in the table's timezone.
+func (t compiledTable) dayStart(at time.Time) time.Time {
+	y, m, d := at.In(t.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.loc)
+}
+
+// at returns the instant minutes after midnight of day (DST-safe).
+func (t compiledTable) at(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, t.loc)
+}
+
+// open reports whether the table trades at now, and why not. A holiday replaces the hours of its
+// date; otherwise the sessions starting that day or the day before apply.
+func (t compiledTable) open(now time.Time) (string, bool) {
+	day := t.dayStart(now)
+	if h, ok := t.holidays[day.Format("2006-01-02")]; ok {
+		for _, w := range h.windows {
+			if !now.Before(t.at(day, w.start)) && now.Before(t.at(day, w.end)) {
+				return "", true
+			}
+		}
+		return "holiday " + h.name, false
+	}
+	for _, start := range []time.Time{day, day.AddDate(0, 0, -1)} {
+		for _, s := range t.sessions {
+			if s.days != nil && !s.days[start.Weekday()] {
+				continue
+			}
+			if !now.Before(t.at(start, s.start)) && now.Before(t.at(start, s.end)) {
+				return "", true
+			}
+		}
+	}
+	return "outside trading hours", false
+}
+
+// nextOpen returns the first instant after now at which the table trades. Openings happen at a
+// session or holiday window start, or at midnight when a holiday ends mid-session.
+func (t compiledTable) nextOpen(now time.Time) time.Time {
+	var candidates []time.Time
+	first := t.dayStart(now)
+	for day := first; day.Before(now.Add(lookahead)); day = day.AddDate(0, 0, 1) {
+		candidates = append(candidates, day)
+		for _, s := range t.sessions {
+			candidates = append(candidates, t.at(day, s.start))
+		}
+		if h, ok := t.holidays[day.Format("2006-01-02")]; ok {
+			for _, w := range h.windows {
+				candidates = append(candidates, t.at(day, w.start))
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	for _, c := range candidates {
+		if !c.After(now) {
+			continue
+		}
+		if _, open := t.open(c); open {
+			return c
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,94 @@
+package hours
+
+import (
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, cfg Config) *Gate {
+	t.Helper()
+	g, err := New(cfg, time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return g
+}
+
+func TestOvernightSessionsAndHolidays(t *testing.T) {
+	g := mustNew(t, Config{
+		Timezone: "America/Chicago",
+		Holidays: []Holiday{{Date: "2026-11-26", Name: "Thanksgiving", Hours: []string{"00:00-12:00", "17:00-24:00"}}},
+		Tables: []Table{{
+			Symbol:   "US100*",
+			Sessions: []Session{{Days: []string{"sun", "mon", "tue", "wed", "thu"}, Hours: "17:00-16:00"}},
+			Holidays: []Holiday{{Date: "2026-12-25", Name: "Christmas"}},
+		}},
+	})
+	ct, _ := time.LoadLocation("America/Chicago")
+	at := func(m time.Month, d, h, min int) time.Time { return time.Date(2026, m, d, h, min, 0, 0, ct) }
+
+	for _, tc := range []struct {
+		at   time.Time
+		open bool
+	}{
+		{at(11, 23, 10, 0), true},   // Monday, inside Sunday's overnight session
+		{at(11, 23, 16, 30), false}, // daily break
+		{at(11, 27, 15, 0), true},   // Friday, inside Thursday's session
+		{at(11, 27, 17, 0), false},  // Friday evening: no session starts on Friday
+		{at(11, 28, 12, 0), false},  // Saturday
+		{at(11, 26, 11, 0), true},   // Thanksgiving morning
+		{at(11, 26, 13, 0), false},  // Thanksgiving early close
+		{at(12, 25, 9, 0), false},   // Christmas, closed all day
+	} {
+		if d := g.Check("US100.cash", tc.at); d.Open != tc.open {
+			t.Errorf("%s: expected open=%v, got %+v", tc.at, tc.open, d)
+		}
+	}
+
+	d := g.Check("US100.cash", at(11, 28, 12, 0))
+	if d.Table != "US100*" || d.Policy != PolicyHold || d.Reason != "outside trading hours" || !d.OpensAt.Equal(at(11, 29, 17, 0)) {
+		t.Fatalf("unexpected weekend decision: %+v", d)
+	}
+	if d := g.Check("US100.cash", at(11, 26, 13, 0)); d.Reason != "holiday Thanksgiving" || !d.OpensAt.Equal(at(11, 26, 17, 0)) {
+		t.Fatalf("unexpected holiday decision: %+v", d)
+	}
+	// Christmas falls on a Friday: Thursday's session ends at midnight and the market reopens Sunday
+	if d := g.Check("US100.cash", at(12, 25, 9, 0)); !d.OpensAt.Equal(at(12, 27, 17, 0)) {
+		t.Fatalf("unexpected reopening after Christmas: %+v", d)
+	}
+	if d := g.Check("EURUSD", at(11, 28, 12, 0)); !d.Open || d.Table != "" {
+		t.Fatalf("symbols without hours must stay open: %+v", d)
+	}
+}
+
+func TestTablePolicyOverridesDefault(t *testing.T) {
+	g := mustNew(t, Config{Policy: "reject", Tables: []Table{
+		{Symbol: "GER40", Sessions: []Session{{Hours: "08:00-22:00"}}, Policy: "route", Terminal: "ic-24h"},
+		{Symbol: "UK100", Sessions: []Session{{Hours: "08:00-22:00"}}},
+	}})
+	night := time.Date(2026, 3, 4, 23, 0, 0, 0, time.UTC)
+	if d := g.Check("ger40", night); d.Open || d.Policy != PolicyRoute || d.Terminal != "ic-24h" {
+		t.Fatalf("unexpected GER40 decision: %+v", d)
+	}
+	if d := g.Check("UK100", night); d.Open || d.Policy != PolicyReject {
+		t.Fatalf("unexpected UK100 decision: %+v", d)
+	}
+}
+
+func TestInvalidTablesAreRejected(t *testing.T) {
+	for _, cfg := range []Config{
+		{Policy: "wait"},
+		{Timezone: "Nowhere/Land"},
+		{Tables: []Table{{Sessions: []Session{{Hours: "08:00-22:00"}}}}},
+		{Tables: []Table{{Symbol: "GER40"}}},
+		{Tables: []Table{{Symbol: "GER40", Sessions: []Session{{Hours: "8am-10pm"}}}}},
+		{Tables: []Table{{Symbol: "GER40", Sessions: []Session{{Days: []string{"xyz"}, Hours: "08:00-22:00"}}}}},
+		{Tables: []Table{{Symbol: "GER40", Sessions: []Session{{Hours: "08:00-22:00"}}, Policy: "route"}}},
+		{Holidays: []Holiday{{Date: "26/12/2026"}}},
+		{Holidays: []Holiday{{Date: "2026-12-24", Hours: []string{"13:00-09:00"}}}},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is how long keys are remembered when the configuration leaves it unset.
+const DefaultWindow = 24 * time.Hour
+
+// waitTimeout bounds how long a replay waits for the original request still being processed.
+const waitTimeout = 10 * time.Second
+
+// compactAfter is how many records are appended to the backing file before it may be rewritten
+// without expired keys; it is rewritten once the appended records also outnumber the live ones.
+const compactAfter = 1000
+
+// Config is the "idempotency" section of the bridge configuration file.
+type Config struct {
+	Window string `json:"window,omitempty"` // Go duration keys are remembered for (default 24h)
+	Path   string `json:"path,omitempty"`   // JSON file the keys are kept in ("off" = memory only)
+}
+
+// Validate rejects windows that cannot be parsed or are not positive.
+func (c Config) Validate() error {
+	_, err := c.window()
+	return err
+}
+
+func (c Config) window() (time.Duration, error) {
+	v := strings.TrimSpace(c.Window)
+	if v == "" {
+		return DefaultWindow, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("idempotency: window %q must be a positive duration such as 24h", c.Window)
+	}
+	return d, nil
+}
+
+// Response is the answer given to the first request with a key, replayed to its retries.
+type Response struct {
+	Status   string            `json:"status"`
+	Message  string            `json:"message,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Record is one remembered key.
+type Record struct {
+	Key      string    `json:"key"`
+	TradeID  string    `json:"trade_id,omitempty"`
+	Response Response  `json:"response"`
+	At       time.Time `json:"at"`
+}
+
+// Store remembers idempotency keys in memory and appends them to a JSON lines file so retries are
+// recognised across restarts. A nil *Store remembers nothing.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	window  time.Duration
+	records map[string]Record
+	pending map[string]chan struct{} // keys whose first request is still being processed
+
+	fileMu   sync.Mutex // serialises writes to the backing file; taken before mu, never under it
+	appended int        // records appended since the file was last rewritten
+}
+
+// Open loads the store at path ("" or "off" keeps it in memory only). Expired keys are dropped.
+func Open(cfg Config) *Store {
+	s := &Store{records: make(map[string]Record), pending: make(map[string]chan struct{})}
+	s.window, _ = cfg.window()
+	if s.window == 0 {
+		s.window = DefaultWindow
+	}
+	if p := strings.TrimSpace(cfg.Path); p != "" && !strings.EqualFold(p, "off") {
+		s.path = p
+	}
+	if s.path == "" {
+		return s
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("WARN: idempotency store %s could not be read: %v", s.path, err)
+		}
+		return s
+	}
+	list, err := decodeRecords(b)
+	if err != nil {
+		log.Printf("WARN: idempotency store %s is corrupt, starting empty: %v", s.path, err)
+	}
+	cutoff := time.Now().Add(-s.window)
+	for _, r := range list {
+		if r.At.After(cutoff) {
+			s.records[r.Key] = r
+		}
+	}
+	// Start the run from a file holding only the live keys, one per line
+	s.fileMu.Lock()
+	s.rewrite()
+	s.fileMu.Unlock()
+	return s
+}
+
+// decodeRecords reads a backing file: one record per line, later lines replacing earlier ones of
+// the same key. Files written as a single JSON array by earlier versions are read as well.
+func decodeRecords(b []byte) ([]Record, error) {
+	var list []Record
+	if strings.HasPrefix(strings.TrimSpace(string(b)), "[") {
+		if err := json.Unmarshal(b, &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			// A crash can cut the last line short; the records before it are intact
+			log.Printf("WARN: idempotency store: skipping unreadable record: %v", err)
+			continue
+		}
+		list = append(list, r)
+	}
+	return list, nil
+}
+
+// Path returns the backing file ("" when the store is memory only).
+func (s *Store) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// SetWindow changes how long keys are remembered.
+func (s *Store) SetWindow(cfg Config) {
+	if s == nil {
+		return
+	}
+	w, err := cfg.window()
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.window = w
+	s.mu.Unlock()
+}
+
+// Len returns the number of remembered keys, expired ones included until the file is next rewritten.
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// Claim reserves key for a new request. When the key was already answered within the window, the
+// original record is returned with replay true. A request arriving while the first one is still
+// processed waits for its answer; the caller must Complete or Release every claim it wins.
+func (s *Store) Claim(key string) (Record, bool) {
+	if s == nil || key == "" {
+		return Record{}, false
+	}
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		s.mu.Lock()
+		if r, ok := s.records[key]; ok && time.Since(r.At) < s.window {
+			s.mu.Unlock()
+			return r, true
+		}
+		wait, busy := s.pending[key]
+		if !busy {
+			s.pending[key] = make(chan struct{})
+			s.mu.Unlock()
+			return Record{}, false
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-time.After(time.Until(deadline)):
+			return Record{Key: key, Response: Response{Status: "pending", Message: "original request with this idempotency key is still being processed"}}, true
+		}
+	}
+}
+
+// Complete remembers the answer to a claimed key and wakes the requests waiting for it.
+func (s *Store) Complete(key, tradeID string, resp Response) {
+	if s == nil || key == "" {
+		return
+	}
+	r := Record{Key: key, TradeID: tradeID, Response: resp, At: time.Now()}
+	s.mu.Lock()
+	s.records[key] = r
+	s.finish(key)
+	s.mu.Unlock()
+	s.persist(r)
+}
+
+// Release gives up a claim without remembering an answer, so a retry is processed afresh.
+func (s *Store) Release(key string) {
+	if s == nil || key == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finish(key)
+}
+
+// finish wakes the waiters of key; the caller holds s.mu.
+func (s *Store) finish(key string) {
+	if ch, ok := s.pending[key]; ok {
+		close(ch)
+		delete(s.pending, key)
+	}
+}
+
+// persist appends r to the backing file, or rewrites the file without expired keys once enough
+// records were appended. Claims are not held up meanwhile: the file has its own lock.
+func (s *Store) persist(r Record) {
+	if s.path == "" {
+		return
+	}
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if s.appended >= compactAfter && s.appended > s.Len() {
+		s.rewrite()
+		return
+	}
+	b, err := json.Marshal(r)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	}
+	if err == nil {
+		var f *os.File
+		if f, err = os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err == nil {
+			_, err = f.Write(append(b, '\n'))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("ERROR: idempotency store %s could not be saved: %v", s.path, err)
+		return
+	}
+	s.appended++
+}
+
+// rewrite drops expired keys and replaces the backing file with the live ones; the caller holds
+// s.fileMu.
+func (s *Store) rewrite() {
+	s.mu.Lock()
+	cutoff := time.Now().Add(-s.window)
+	var buf []byte
+	for k, r := range s.records {
+		if !r.At.After(cutoff) {
+			delete(s.records, k)
+			continue
+		}
+		if b, err := json.Marshal(r); err == nil {
+			buf = append(append(buf, b...), '\n')
+		}
+	}
+	s.mu.Unlock()
+	if s.path == "" {
+		return
+	}
+	err := os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err == nil {
+		tmp := s.path + ".tmp"
+		if err = os.WriteFile(tmp, buf, 0o644); err == nil {
+			err = os.Rename(tmp, s.path)
+		}
+	}
+	if err != nil {
+		log.Printf("ERROR: idempotency store %s could not be saved: %v", s.path, err)
+		return
+	}
+	s.appended = 0
+}
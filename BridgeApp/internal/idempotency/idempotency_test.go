@@ -0,0 +1,111 @@
+package idempotency
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaysSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency-keys.json")
+	s := Open(Config{Path: path})
+	if _, replay := s.Claim("K1"); replay {
+		t.Fatal("a new key must not replay")
+	}
+	s.Complete("K1", "T1", Response{Status: "success", Metadata: map[string]string{"trade_id": "T1"}})
+
+	reopened := Open(Config{Path: path})
+	r, replay := reopened.Claim("K1")
+	if !replay || r.TradeID != "T1" || r.Response.Status != "success" || r.Response.Metadata["trade_id"] != "T1" {
+		t.Fatalf("unexpected replay after reopen: %+v (replay=%v)", r, replay)
+	}
+
+	// A released claim is processed afresh
+	if _, replay := reopened.Claim("K2"); replay {
+		t.Fatal("a new key must not replay")
+	}
+	reopened.Release("K2")
+	if _, replay := reopened.Claim("K2"); replay {
+		t.Fatal("a released key must not replay")
+	}
+}
+
+func TestConcurrentRetryWaitsForTheOriginal(t *testing.T) {
+	s := Open(Config{Path: "off"})
+	s.Claim("K")
+	got := make(chan Record)
+	go func() {
+		r, _ := s.Claim("K")
+		got <- r
+	}()
+	time.Sleep(20 * time.Millisecond)
+	s.Complete("K", "T", Response{Status: "held"})
+	if r := <-got; r.Response.Status != "held" {
+		t.Fatalf("the retry must receive the original response, got %+v", r)
+	}
+}
+
+func TestExpiredKeysAreForgotten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency-keys.json")
+	s := Open(Config{Path: path, Window: "1h"})
+	s.Claim("OLD")
+	s.Complete("OLD", "T", Response{Status: "success"})
+	s.mu.Lock()
+	r := s.records["OLD"]
+	r.At = time.Now().Add(-2 * time.Hour)
+	s.records["OLD"] = r
+	s.mu.Unlock()
+
+	if _, replay := s.Claim("OLD"); replay {
+		t.Fatal("an expired key must not replay")
+	}
+	s.Complete("OLD", "T2", Response{Status: "success"})
+	if reopened := Open(Config{Path: path, Window: "1h"}); reopened.Len() != 1 {
+		t.Fatalf("expected one live key after reopen, got %d", reopened.Len())
+	}
+	if err := (Config{Window: "-1h"}).Validate(); err == nil {
+		t.Fatal("a negative window must be rejected")
+	}
+}
+
+func TestKeysAreAppendedAndCompacted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency-keys.json")
+	legacy := `[{"key":"OLD","trade_id":"T0","response":{"status":"success"},"at":"` + time.Now().Format(time.RFC3339Nano) + `"}]`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := Open(Config{Path: path, Window: "1h"})
+	if r, replay := s.Claim("OLD"); !replay || r.TradeID != "T0" {
+		t.Fatalf("a key saved as a JSON array must replay, got %+v (replay=%v)", r, replay)
+	}
+
+	for _, k := range []string{"K1", "K2"} {
+		s.Claim(k)
+		s.Complete(k, "T", Response{Status: "success"})
+	}
+	lines := func() []string {
+		b, _ := os.ReadFile(path)
+		return strings.Split(strings.TrimSpace(string(b)), "\n")
+	}
+	if got := lines(); len(got) != 3 {
+		t.Fatalf("expected each key on its own line, got %q", got)
+	}
+
+	// Once enough records were appended, the next save rewrites the file without expired keys
+	s.mu.Lock()
+	r := s.records["OLD"]
+	r.At = time.Now().Add(-2 * time.Hour)
+	s.records["OLD"] = r
+	s.mu.Unlock()
+	s.appended = compactAfter
+	s.Claim("K3")
+	s.Complete("K3", "T", Response{Status: "success"})
+	if got := lines(); len(got) != 3 || s.appended != 0 {
+		t.Fatalf("expected K1, K2 and K3 after the rewrite, got %q", got)
+	}
+	if reopened := Open(Config{Path: path, Window: "1h"}); reopened.Len() != 3 {
+		t.Fatalf("expected three live keys after reopen, got %d", reopened.Len())
+	}
+}
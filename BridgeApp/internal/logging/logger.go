@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -34,7 +35,7 @@ type Event struct {
 
 type stateSnapshot func() (queueSize int, netPosition int, hedgeSize float64)
 
-// Logger is a simple JSONL writer with daily rotation.
+// Logger is a simple JSONL writer with daily rotation (calendar days unless SetDayFunc says otherwise).
 type Logger struct {
 	mu       sync.Mutex
 	started  bool
@@ -46,6 +47,7 @@ type Logger struct {
 	ch       chan Event
 	quit     chan struct{}
 	state    stateSnapshot
+	dayOf    atomic.Value // func(time.Time) string naming the file of an instant
 }
 
 var defaultLogger = &Logger{}
@@ -88,6 +90,20 @@ func (l *Logger) EnsureStarted(source string) {
 // SetStateProvider attaches a snapshotter to enrich WARN/ERROR.
 func (l *Logger) SetStateProvider(s stateSnapshot) { l.state = s }
 
+// SetDayFunc makes files rotate on the trading day dayOf returns (YYYY-MM-DD) instead of the
+// local calendar date.
+func (l *Logger) SetDayFunc(dayOf func(time.Time) string) { l.dayOf.Store(dayOf) }
+
+// fileDay returns the YYYYMMDD suffix of the file now is written to.
+func (l *Logger) fileDay(now time.Time) string {
+	if dayOf, ok := l.dayOf.Load().(func(time.Time) string); ok && dayOf != nil {
+		if t, err := time.Parse("2006-01-02", dayOf(now)); err == nil {
+			return t.Format("20060102")
+		}
+	}
+	return now.Format("20060102")
+}
+
 func (l *Logger) loop() {
 	ticker := time.NewTicker(250 * time.Millisecond)
 	defer ticker.Stop()
@@ -108,7 +124,7 @@ func (l *Logger) loop() {
 }
 
 func (l *Logger) rotateIfNeeded(now time.Time) error {
-	date := now.Format("20060102")
+	date := l.fileDay(now)
 	if l.f != nil && date == l.fileDate {
 		return nil
 	}
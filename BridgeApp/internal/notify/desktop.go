@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// desktopTimeout bounds how long the OS notifier may run.
+const desktopTimeout = 10 * time.Second
+
+// Environment variables carrying the text to the notifier scripts, so it is never parsed as code.
+const (
+	titleEnv   = "BRIDGE_NOTIFY_TITLE"
+	messageEnv = "BRIDGE_NOTIFY_MESSAGE"
+)
+
+// windowsToast shows a toast through the WinRT notification API of Windows PowerShell.
+const windowsToast = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:` + titleEnv + `)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:` + messageEnv + `)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('Hedgebot Bridge').Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+
+// macNotification shows a Notification Center banner through AppleScript.
+const macNotification = `display notification (system attribute "` + messageEnv + `") with title (system attribute "` + titleEnv + `")`
+
+// desktopCommand returns the command showing a native notification on goos, or nil when the
+// platform has no notifier.
+func desktopCommand(ctx context.Context, goos, title, message string) *exec.Cmd {
+	var cmd *exec.Cmd
+	switch goos {
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToast)
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "osascript", "-e", macNotification)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=Hedgebot Bridge", "--", title, message)
+	default:
+		return nil
+	}
+	cmd.Env = append(os.Environ(), titleEnv+"="+title, messageEnv+"="+message)
+	hideWindow(cmd)
+	return cmd
+}
+
+// ShowDesktop shows n as a native desktop notification of the operating system and waits for
+// the notifier to finish.
+func ShowDesktop(n Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), desktopTimeout)
+	defer cancel()
+	cmd := desktopCommand(ctx, runtime.GOOS, n.Title, n.Message)
+	if cmd == nil {
+		return fmt.Errorf("no desktop notifier on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v %s", cmd.Path, err, out)
+	}
+	return nil
+}
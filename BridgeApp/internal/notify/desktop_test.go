@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDesktopCommandPassesTextOutsideTheScript(t *testing.T) {
+	title, message := `Hedge "failed"`, "ticket 7; $(rm -rf /)"
+	for _, goos := range []string{"windows", "darwin"} {
+		cmd := desktopCommand(context.Background(), goos, title, message)
+		if cmd == nil {
+			t.Fatalf("%s: expected a notifier", goos)
+		}
+		if script := strings.Join(cmd.Args, " "); strings.Contains(script, title) || strings.Contains(script, message) {
+			t.Fatalf("%s: text must not be part of the script: %v", goos, cmd.Args)
+		}
+		env := strings.Join(cmd.Env, "\n")
+		if !strings.Contains(env, titleEnv+"="+title) || !strings.Contains(env, messageEnv+"="+message) {
+			t.Fatalf("%s: text missing from the environment", goos)
+		}
+	}
+
+	cmd := desktopCommand(context.Background(), "linux", title, message)
+	if filepath.Base(cmd.Args[0]) != "notify-send" || cmd.Args[len(cmd.Args)-2] != title || cmd.Args[len(cmd.Args)-1] != message {
+		t.Fatalf("unexpected linux notifier %v", cmd.Args)
+	}
+	if desktopCommand(context.Background(), "plan9", title, message) != nil {
+		t.Fatal("platforms without a notifier must return nil")
+	}
+}
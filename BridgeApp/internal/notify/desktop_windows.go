@@ -0,0 +1,11 @@
+package notify
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideWindow keeps the notifier's console window from flashing up.
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
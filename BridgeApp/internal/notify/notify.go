@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of critical bridge events the desktop app is notified of.
+const (
+	HedgeFailed    = "hedge_failed"    // MT5 permanently rejected a hedge or its close
+	OrphanDetected = "orphan_detected" // MT5 reported a position no BaseID tracks
+	MT5StreamLost  = "mt5_stream_lost" // the last MT5 stream dropped while hedges were open
+)
+
+// Kinds lists the kinds that can be muted in the configuration.
+var Kinds = []string{HedgeFailed, OrphanDetected, MT5StreamLost}
+
+// Severities of a notification; each plays its own sound.
+const (
+	Info     = "info"
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// Sounds the UI can play ("none" is silent).
+var Sounds = []string{"alarm", "chime", "beep", "none"}
+
+// defaultSounds is the sound of each severity when the configuration leaves it unset.
+var defaultSounds = map[string]string{Critical: "alarm", Warning: "chime", Info: "none"}
+
+// DefaultHistorySize bounds the notification history when the configuration leaves it unset.
+const DefaultHistorySize = 200
+
+// Config is the "notifications" section of the bridge configuration file.
+type Config struct {
+	DisableDesktop bool              `json:"disable_desktop,omitempty"` // show events in the history only
+	Sounds         map[string]string `json:"sounds,omitempty"`          // severity -> alarm, chime, beep or none
+	Muted          []string          `json:"muted,omitempty"`           // kinds that are neither shown nor played
+	HistorySize    int               `json:"history_size,omitempty"`    // notifications kept (default 200)
+}
+
+// Validate rejects unknown severities, sounds and kinds and a negative history size.
+func (c Config) Validate() error {
+	for severity, sound := range c.Sounds {
+		if severity != Info && severity != Warning && severity != Critical {
+			return fmt.Errorf("notifications: unknown severity %q in sounds (use info, warning or critical)", severity)
+		}
+		if !contains(Sounds, sound) {
+			return fmt.Errorf("notifications: unknown sound %q for %s (use one of %s)", sound, severity, strings.Join(Sounds, ", "))
+		}
+	}
+	for _, k := range c.Muted {
+		if !contains(Kinds, k) {
+			return fmt.Errorf("notifications: unknown kind %q in muted (use one of %s)", k, strings.Join(Kinds, ", "))
+		}
+	}
+	if c.HistorySize < 0 {
+		return fmt.Errorf("notifications: history_size must not be negative, got %d", c.HistorySize)
+	}
+	return nil
+}
+
+// Sound returns the sound of severity.
+func (c Config) Sound(severity string) string {
+	if s, ok := c.Sounds[severity]; ok {
+		return s
+	}
+	return defaultSounds[severity]
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is one critical event shown by the desktop app.
+type Notification struct {
+	ID       uint64    `json:"id"`
+	Kind     string    `json:"kind"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+	BaseID   string    `json:"baseId,omitempty"`
+	Ticket   uint64    `json:"ticket,omitempty"`
+	Time     time.Time `json:"time"`
+	Sound    string    `json:"sound"`   // what the UI plays
+	Desktop  bool      `json:"desktop"` // whether a native desktop notification is shown
+	Read     bool      `json:"read"`
+}
+
+// Center keeps the notification history.
+type Center struct {
+	mu      sync.Mutex
+	cfg     Config
+	seq     uint64
+	history []Notification
+}
+
+// NewCenter returns an empty history with the default settings.
+func NewCenter() *Center {
+	return &Center{}
+}
+
+// Configure installs cfg, trimming the history to the new size.
+func (c *Center) Configure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+	c.trimLocked()
+	return nil
+}
+
+// Config returns the settings in use.
+func (c *Center) Config() Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg
+}
+
+// Add records n with its id, time, sound and desktop flag filled in. Muted kinds are dropped
+// (ok=false).
+func (c *Center) Add(n Notification) (Notification, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if contains(c.cfg.Muted, n.Kind) {
+		return Notification{}, false
+	}
+	c.seq++
+	n.ID = c.seq
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	if n.Severity == "" {
+		n.Severity = Critical
+	}
+	n.Sound = c.cfg.Sound(n.Severity)
+	n.Desktop = !c.cfg.DisableDesktop
+	n.Read = false
+	c.history = append(c.history, n)
+	c.trimLocked()
+	return n, true
+}
+
+func (c *Center) trimLocked() {
+	size := c.cfg.HistorySize
+	if size == 0 {
+		size = DefaultHistorySize
+	}
+	if len(c.history) > size {
+		c.history = append([]Notification(nil), c.history[len(c.history)-size:]...)
+	}
+}
+
+// History returns the notifications, newest first.
+func (c *Center) History() []Notification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Notification, 0, len(c.history))
+	for i := len(c.history) - 1; i >= 0; i-- {
+		out = append(out, c.history[i])
+	}
+	return out
+}
+
+// Unread counts the notifications not yet marked read.
+func (c *Center) Unread() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, h := range c.history {
+		if !h.Read {
+			n++
+		}
+	}
+	return n
+}
+
+// MarkRead marks notification id read, or all of them when id is 0. It returns how many changed.
+func (c *Center) MarkRead(id uint64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := 0
+	for i := range c.history {
+		if (id == 0 || c.history[i].ID == id) && !c.history[i].Read {
+			c.history[i].Read = true
+			changed++
+		}
+	}
+	return changed
+}
+
+// Clear empties the history.
+func (c *Center) Clear() {
+	c.mu.Lock()
+	c.history = nil
+	c.mu.Unlock()
+}
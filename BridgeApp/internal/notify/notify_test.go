@@ -0,0 +1,50 @@
+package notify
+
+import "testing"
+
+func TestCenterHistorySoundsAndMuting(t *testing.T) {
+	c := NewCenter()
+	if err := c.Configure(Config{Sounds: map[string]string{Warning: "beep"}, Muted: []string{OrphanDetected}, HistorySize: 2}); err != nil {
+		t.Fatal(err)
+	}
+	n, ok := c.Add(Notification{Kind: HedgeFailed, Title: "Hedge failed", BaseID: "B1"})
+	if !ok || n.ID != 1 || n.Severity != Critical || n.Sound != "alarm" || !n.Desktop || n.Time.IsZero() {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+	if _, ok := c.Add(Notification{Kind: OrphanDetected}); ok {
+		t.Fatal("muted kinds must be dropped")
+	}
+	n, _ = c.Add(Notification{Kind: MT5StreamLost, Severity: Warning})
+	if n.Sound != "beep" {
+		t.Fatalf("configured sound not applied: %+v", n)
+	}
+	c.Add(Notification{Kind: HedgeFailed, BaseID: "B3"})
+
+	h := c.History()
+	if len(h) != 2 || h[0].BaseID != "B3" || h[1].Kind != MT5StreamLost {
+		t.Fatalf("history must keep the newest 2, newest first: %+v", h)
+	}
+	if c.Unread() != 2 || c.MarkRead(h[1].ID) != 1 || c.Unread() != 1 || c.MarkRead(0) != 1 || c.Unread() != 0 {
+		t.Fatal("mark read bookkeeping is off")
+	}
+	c.Clear()
+	if len(c.History()) != 0 {
+		t.Fatal("Clear must empty the history")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, bad := range []Config{
+		{Sounds: map[string]string{"loud": "alarm"}},
+		{Sounds: map[string]string{Critical: "siren"}},
+		{Muted: []string{"everything"}},
+		{HistorySize: -1},
+	} {
+		if bad.Validate() == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+	if (Config{DisableDesktop: true, Sounds: map[string]string{Info: "chime"}}).Validate() != nil {
+		t.Fatal("valid config rejected")
+	}
+}
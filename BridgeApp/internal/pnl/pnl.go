@@ -0,0 +1,315 @@
+package pnl
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"BridgeApp/internal/hedgepnl"
+)
+
+// DefaultKeepDays is the number of trading days kept for queries.
+const DefaultKeepDays = 7
+
+// Position is the combined result of one BaseID: the Quantower position and its MT5 hedges.
+type Position struct {
+	BaseID     string `json:"base_id"`
+	Account    string `json:"account,omitempty"`
+	Instrument string `json:"instrument,omitempty"`
+	Day        string `json:"day"` // trading day of the latest activity (the close, once closed)
+
+	QTProfit      float64   `json:"qt_profit"` // latest elastic_current_profit reported by the addon
+	HasQTProfit   bool      `json:"has_qt_profit"`
+	HedgeRealized float64   `json:"hedge_realized"`
+	HedgeFloating float64   `json:"hedge_floating"`
+	HedgeCosts    float64   `json:"hedge_costs"` // commission + swap
+	HedgeNet      float64   `json:"hedge_net"`
+	Combined      float64   `json:"combined"` // QTProfit + HedgeNet
+	HedgeOpen     bool      `json:"hedge_open"`
+	Updated       time.Time `json:"updated"`
+}
+
+// Account is the combined result of a Quantower account over one trading day.
+type Account struct {
+	Account         string    `json:"account"`
+	Day             string    `json:"day"`
+	QTBalance       float64   `json:"qt_balance"`        // latest nt_balance reported with a trade
+	QTDailyPnL      float64   `json:"qt_daily_pnl"`      // latest nt_daily_pnl reported with a trade
+	QTSessionTrades int       `json:"qt_session_trades"` // latest nt_session_trades reported with a trade
+	HasQT           bool      `json:"has_qt"`
+	Entries         int       `json:"entries"`   // entries the bridge received for the account that day
+	HedgeNet        float64   `json:"hedge_net"` // positions of the account on that day
+	Combined        float64   `json:"combined"`  // QTDailyPnL + HedgeNet
+	Positions       int       `json:"positions"`
+	LossLimitHit    bool      `json:"loss_limit_hit"`
+	QTUpdated       time.Time `json:"qt_updated,omitempty"`
+}
+
+// Day totals every account of one trading day.
+type Day struct {
+	Day        string  `json:"day"`
+	QTDailyPnL float64 `json:"qt_daily_pnl"`
+	HedgeNet   float64 `json:"hedge_net"`
+	Combined   float64 `json:"combined"`
+	Positions  int     `json:"positions"`
+}
+
+// Report is the combined view of one trading day.
+type Report struct {
+	Day       Day        `json:"day"`
+	Accounts  []Account  `json:"accounts"`
+	Positions []Position `json:"positions"`
+	Days      []string   `json:"days"` // trading days with data, newest first
+}
+
+// dayAccount holds the counters of an account that restart with every trading day.
+type dayAccount struct {
+	balance, daily float64
+	sessionTrades  int
+	hasQT          bool
+	updated        time.Time
+	entries        int
+	limitHit       bool
+}
+
+// Aggregator merges what Quantower reports (balance, daily PnL, position profit) with the MT5
+// hedge results per BaseID, account and trading day.
+type Aggregator struct {
+	mu        sync.Mutex
+	positions map[string]*Position
+	accounts  map[string]map[string]*dayAccount // day -> account -> counters
+	dayOf     func(time.Time) string
+	keepDays  int
+}
+
+// NewAggregator returns an empty aggregator using local calendar days until SetDayFunc.
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		positions: make(map[string]*Position),
+		accounts:  make(map[string]map[string]*dayAccount),
+		dayOf:     func(t time.Time) string { return t.Format("2006-01-02") },
+		keepDays:  DefaultKeepDays,
+	}
+}
+
+// SetDayFunc sets the trading day (YYYY-MM-DD) of an instant.
+func (g *Aggregator) SetDayFunc(dayOf func(time.Time) string) {
+	g.mu.Lock()
+	g.dayOf = dayOf
+	g.mu.Unlock()
+}
+
+// Today returns the current trading day.
+func (g *Aggregator) Today() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.dayOf(time.Now())
+}
+
+func (g *Aggregator) positionLocked(baseID string, now time.Time) *Position {
+	p := g.positions[baseID]
+	if p == nil {
+		p = &Position{BaseID: baseID}
+		g.positions[baseID] = p
+	}
+	p.Day, p.Updated = g.dayOf(now), now
+	return p
+}
+
+func (g *Aggregator) accountLocked(account string, now time.Time) *dayAccount {
+	day := g.dayOf(now)
+	if g.accounts[day] == nil {
+		g.accounts[day] = make(map[string]*dayAccount)
+		g.pruneLocked()
+	}
+	a := g.accounts[day][account]
+	if a == nil {
+		a = &dayAccount{}
+		g.accounts[day][account] = a
+	}
+	return a
+}
+
+// RecordQTAccount records the balance, daily PnL and session trade count the addon sent with a
+// trade of account.
+func (g *Aggregator) RecordQTAccount(account string, balance, dailyPnL float64, sessionTrades int) {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	a := g.accountLocked(account, now)
+	a.balance, a.daily, a.sessionTrades, a.hasQT, a.updated = balance, dailyPnL, sessionTrades, true, now
+}
+
+// RecordEntry counts an entry the bridge received for account in the current trading day.
+func (g *Aggregator) RecordEntry(account string) {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.accountLocked(account, time.Now()).entries++
+}
+
+// MarkLossLimit flags account as having reached its loss limit in the current trading day and
+// reports whether this is the first time that day.
+func (g *Aggregator) MarkLossLimit(account string) bool {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a := g.accountLocked(account, time.Now())
+	first := !a.limitHit
+	a.limitHit = true
+	return first
+}
+
+// RecordQTProfit records the Quantower position profit (elastic_current_profit) of baseID.
+func (g *Aggregator) RecordQTProfit(baseID, account, instrument string, profit float64) {
+	if baseID == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p := g.positionLocked(baseID, time.Now())
+	p.QTProfit, p.HasQTProfit = profit, true
+	p.setIdentity(account, instrument)
+	p.combine()
+}
+
+// RecordHedge replaces the hedge side of baseID with the latest MT5 summary and returns the
+// combined position.
+func (g *Aggregator) RecordHedge(s hedgepnl.Summary, account, instrument string) Position {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p := g.positionLocked(s.BaseID, time.Now())
+	p.setIdentity(account, instrument)
+	p.HedgeRealized, p.HedgeFloating, p.HedgeCosts = s.RealizedProfit, s.FloatingProfit, s.Commission+s.Swap
+	p.HedgeNet, p.HedgeOpen = s.Net, s.OpenTickets > 0
+	p.combine()
+	return *p
+}
+
+func (p *Position) setIdentity(account, instrument string) {
+	if account = strings.TrimSpace(account); account != "" {
+		p.Account = account
+	}
+	if instrument = strings.TrimSpace(instrument); instrument != "" {
+		p.Instrument = instrument
+	}
+}
+
+func (p *Position) combine() {
+	p.Combined = p.QTProfit + p.HedgeNet
+}
+
+// Position returns the combined result of baseID.
+func (g *Aggregator) Position(baseID string) (Position, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p, ok := g.positions[baseID]
+	if !ok {
+		return Position{}, false
+	}
+	return *p, true
+}
+
+// Report returns the combined view of day ("" = today).
+func (g *Aggregator) Report(day string) Report {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if day == "" {
+		day = g.dayOf(time.Now())
+	}
+	r := Report{Day: Day{Day: day}}
+	accounts := make(map[string]*Account)
+	account := func(name string) *Account {
+		a := accounts[name]
+		if a == nil {
+			a = &Account{Account: name, Day: day}
+			accounts[name] = a
+		}
+		return a
+	}
+	for name, c := range g.accounts[day] {
+		a := account(name)
+		a.Entries, a.LossLimitHit = c.entries, c.limitHit
+		if c.hasQT {
+			a.QTBalance, a.QTDailyPnL, a.QTSessionTrades, a.HasQT, a.QTUpdated = c.balance, c.daily, c.sessionTrades, true, c.updated
+		}
+	}
+	for _, p := range g.positions {
+		if p.Day != day {
+			continue
+		}
+		r.Positions = append(r.Positions, *p)
+		a := account(p.Account)
+		a.HedgeNet += p.HedgeNet
+		a.Positions++
+	}
+	for _, a := range accounts {
+		a.Combined = a.QTDailyPnL + a.HedgeNet
+		r.Accounts = append(r.Accounts, *a)
+		r.Day.QTDailyPnL += a.QTDailyPnL
+		r.Day.HedgeNet += a.HedgeNet
+		r.Day.Positions += a.Positions
+	}
+	r.Day.Combined = r.Day.QTDailyPnL + r.Day.HedgeNet
+	sort.Slice(r.Accounts, func(i, j int) bool { return r.Accounts[i].Account < r.Accounts[j].Account })
+	sort.Slice(r.Positions, func(i, j int) bool { return r.Positions[i].Updated.After(r.Positions[j].Updated) })
+	r.Days = g.daysLocked()
+	return r
+}
+
+// Days lists the trading days with data, newest first.
+func (g *Aggregator) Days() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.daysLocked()
+}
+
+func (g *Aggregator) daysLocked() []string {
+	seen := make(map[string]bool)
+	for day := range g.accounts {
+		seen[day] = true
+	}
+	for _, p := range g.positions {
+		seen[p.Day] = true
+	}
+	days := make([]string, 0, len(seen))
+	for day := range seen {
+		days = append(days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+	return days
+}
+
+// pruneLocked drops days beyond keepDays, oldest first.
+func (g *Aggregator) pruneLocked() {
+	days := make([]string, 0, len(g.accounts))
+	for day := range g.accounts {
+		days = append(days, day)
+	}
+	if len(days) <= g.keepDays {
+		return
+	}
+	sort.Strings(days)
+	cutoff := days[len(days)-g.keepDays]
+	for _, day := range days {
+		if day < cutoff {
+			delete(g.accounts, day)
+		}
+	}
+	for id, p := range g.positions {
+		if p.Day < cutoff && !p.HedgeOpen {
+			delete(g.positions, id)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package pnl
+
+import (
+	"testing"
+	"time"
+
+	"BridgeApp/internal/hedgepnl"
+)
+
+func TestReportCombinesQuantowerAndHedgesPerAccountAndDay(t *testing.T) {
+	g := NewAggregator()
+	g.dayOf = func(time.Time) string { return "2026-03-02" }
+
+	g.RecordQTAccount("Sim101", 50250, 250, 2)
+	g.RecordQTAccount("Sim101", 50300, 300, 3) // the latest report wins
+	g.RecordQTAccount("Sim102", 25000, -50, 1)
+	g.RecordQTProfit("B1", "Sim101", "NQ", 120)
+	p := g.RecordHedge(hedgepnl.Summary{BaseID: "B1", RealizedProfit: -100, Commission: -2, Swap: -0.5, Net: -102.5}, "", "")
+	if p.Account != "Sim101" || p.Instrument != "NQ" || p.HedgeCosts != -2.5 || p.Combined != 17.5 || p.HedgeOpen {
+		t.Fatalf("unexpected position: %+v", p)
+	}
+	g.RecordHedge(hedgepnl.Summary{BaseID: "B2", FloatingProfit: 40, Net: 40, OpenTickets: 1}, "Sim102", "ES")
+
+	r := g.Report("")
+	if r.Day.Day != "2026-03-02" || len(r.Positions) != 2 || len(r.Accounts) != 2 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	a := r.Accounts[0]
+	if a.Account != "Sim101" || a.QTBalance != 50300 || a.QTDailyPnL != 300 || a.HedgeNet != -102.5 || a.Combined != 197.5 {
+		t.Fatalf("unexpected Sim101: %+v", a)
+	}
+	if r.Day.QTDailyPnL != 250 || r.Day.HedgeNet != -62.5 || r.Day.Combined != 187.5 || r.Day.Positions != 2 {
+		t.Fatalf("unexpected day totals: %+v", r.Day)
+	}
+	if other := g.Report("2026-03-01"); len(other.Accounts) != 0 || len(other.Positions) != 0 {
+		t.Fatalf("another day must be empty: %+v", other)
+	}
+}
+
+func TestOldDaysArePruned(t *testing.T) {
+	g := NewAggregator()
+	g.keepDays = 2
+	day := "2026-03-01"
+	g.dayOf = func(time.Time) string { return day }
+	g.RecordHedge(hedgepnl.Summary{BaseID: "CLOSED"}, "A", "")
+	g.RecordHedge(hedgepnl.Summary{BaseID: "OPEN", OpenTickets: 1}, "A", "")
+	g.RecordQTAccount("A", 1, 1, 1)
+	for _, d := range []string{"2026-03-02", "2026-03-03"} {
+		day = d
+		g.RecordQTAccount("A", 1, 1, 1)
+	}
+
+	if days := g.Days(); len(days) != 3 || days[0] != "2026-03-03" {
+		t.Fatalf("unexpected days: %v", days) // the open position keeps its day listed
+	}
+	if _, ok := g.Position("CLOSED"); ok {
+		t.Fatal("a closed position of a pruned day must be dropped")
+	}
+	if _, ok := g.Position("OPEN"); !ok {
+		t.Fatal("a position with open hedges must be kept")
+	}
+}
+
+func TestAccountCountersRestartWithTheTradingDay(t *testing.T) {
+	g := NewAggregator()
+	day := "2026-03-02"
+	g.SetDayFunc(func(time.Time) string { return day })
+	g.RecordEntry("Sim101")
+	g.RecordEntry("Sim101")
+	g.RecordQTAccount("Sim101", 50000, -900, 4)
+	if !g.MarkLossLimit("Sim101") || g.MarkLossLimit("Sim101") {
+		t.Fatal("the loss limit must be reported once per day")
+	}
+	if a := g.Report("").Accounts[0]; a.Entries != 2 || a.QTSessionTrades != 4 || !a.LossLimitHit {
+		t.Fatalf("unexpected counters: %+v", a)
+	}
+
+	day = "2026-03-03"
+	g.RecordEntry("Sim101")
+	if a := g.Report("").Accounts[0]; a.Entries != 1 || a.HasQT || a.LossLimitHit {
+		t.Fatalf("counters must restart on the new day: %+v", a)
+	}
+	if !g.MarkLossLimit("Sim101") {
+		t.Fatal("the loss limit must be reported again on the new day")
+	}
+}
@@ -0,0 +1,107 @@
+package protocol
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Version is the bridge protocol version. It changes only when older clients can no longer be
+// served; fields added to existing messages are announced as features instead.
+const Version = 2
+
+// MinVersion is the oldest client protocol the bridge still serves. Clients that never say Hello
+// are treated as version 1.
+const MinVersion = 1
+
+// Features a client or the bridge may support.
+const (
+	EventTrades      = "event_trades"       // Trade action "EVENT" with event_type/elastic_* (elastic hedge updates)
+	QTPositionID     = "qt_position_id"     // Trade.qt_position_id and qt_trade_id
+	MT5Symbol        = "mt5_symbol"         // bridge-resolved Trade.mt5_symbol
+	HedgeLot         = "hedge_lot"          // bridge-computed Trade.hedge_lot
+	PartialClose     = "partial_close"      // CLOSE_HEDGE with close_volume
+	IdempotencyKey   = "idempotency_key"    // Trade.idempotency_key / "idempotency-key" metadata
+	TerminalRouting  = "terminal_routing"   // GetTrades terminal_id/account_id
+	AddonEventStream = "addon_event_stream" // StreamingService.AddonEventStream
+	CloseTracking    = "close_tracking"     // GetCloseRequestStatus and CLOSE_REQUEST_COMPLETED notices
+)
+
+// Features is what this bridge supports end to end: each one is either read from clients that send
+// it or adapted away for clients that did not negotiate it (see the grpc package). Add a feature here
+// only once both halves exist.
+var Features = []string{
+	AddonEventStream,
+	CloseTracking,
+	EventTrades,
+	HedgeLot,
+	IdempotencyKey,
+	MT5Symbol,
+	PartialClose,
+	QTPositionID,
+	TerminalRouting,
+}
+
+// Hello is what a client declares about itself.
+type Hello struct {
+	Protocol    int      // 0 = not stated (version 1)
+	MinProtocol int      // oldest bridge protocol the client works with (0 = any)
+	Features    []string // features the client supports
+	Required    []string // features the client cannot work without
+}
+
+// Supported reports whether the bridge supports feature.
+func Supported(feature string) bool {
+	for _, f := range Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate returns the features both sides support, or an error explaining why the client is
+// incompatible with this bridge.
+func Negotiate(h Hello) ([]string, error) {
+	protocol := h.Protocol
+	if protocol == 0 {
+		protocol = 1
+	}
+	if protocol < MinVersion {
+		return nil, fmt.Errorf("client protocol %d is older than the oldest supported protocol %d; upgrade the client", protocol, MinVersion)
+	}
+	if h.MinProtocol > Version {
+		return nil, fmt.Errorf("client needs bridge protocol %d or newer, this bridge speaks %d; upgrade the bridge", h.MinProtocol, Version)
+	}
+	var missing []string
+	for _, f := range normalize(h.Required) {
+		if !Supported(f) {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("bridge does not support required feature(s) %s; upgrade the bridge", strings.Join(missing, ", "))
+	}
+	var common []string
+	for _, f := range normalize(h.Features) {
+		if Supported(f) {
+			common = append(common, f)
+		}
+	}
+	return common, nil
+}
+
+// normalize lower-cases, trims, de-duplicates and sorts feature names.
+func normalize(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	var out []string
+	for _, f := range list {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f != "" && !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
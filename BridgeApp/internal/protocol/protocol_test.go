@@ -0,0 +1,26 @@
+package protocol
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	got, err := Negotiate(Hello{Protocol: Version, Features: []string{" QT_Position_ID", "event_trades", "telepathy", "event_trades"}})
+	if err != nil || !reflect.DeepEqual(got, []string{EventTrades, QTPositionID}) {
+		t.Fatalf("Negotiate = %v, %v", got, err)
+	}
+
+	// Clients that do not state a protocol are version 1
+	if _, err := Negotiate(Hello{}); err != nil {
+		t.Fatalf("a legacy client must be accepted: %v", err)
+	}
+
+	if _, err := Negotiate(Hello{Protocol: 3, MinProtocol: Version + 1}); err == nil || !strings.Contains(err.Error(), "upgrade the bridge") {
+		t.Fatalf("a client needing a newer bridge must be rejected, got %v", err)
+	}
+	if _, err := Negotiate(Hello{Protocol: Version, Required: []string{EventTrades, "telepathy"}}); err == nil || !strings.Contains(err.Error(), "telepathy") {
+		t.Fatalf("a missing required feature must be named, got %v", err)
+	}
+}
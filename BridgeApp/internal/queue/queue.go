@@ -0,0 +1,483 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Class is the delivery priority of a queued trade. Lower values are delivered first.
+type Class int
+
+const (
+	// ClassClose covers CLOSE_HEDGE requests; a delayed close leaves an unhedged MT5 position.
+	ClassClose Class = iota
+	// ClassEvent covers elastic and trailing events (Action=EVENT).
+	ClassEvent
+	// ClassEntry covers new hedges (buy/sell) and anything unrecognised.
+	ClassEntry
+
+	numClasses = 3
+)
+
+// String returns the class name used in metrics and logs.
+func (c Class) String() string {
+	switch c {
+	case ClassClose:
+		return "close"
+	case ClassEvent:
+		return "event"
+	default:
+		return "entry"
+	}
+}
+
+// Classify maps a trade action onto its priority class.
+func Classify(action string) Class {
+	switch strings.ToUpper(strings.TrimSpace(action)) {
+	case "CLOSE_HEDGE":
+		return ClassClose
+	case "EVENT":
+		return ClassEvent
+	default:
+		return ClassEntry
+	}
+}
+
+// ErrFull is returned by Push when the item's class is at capacity.
+var ErrFull = errors.New("trade queue is full")
+
+// Config is the "queue" section of the bridge configuration file. Zero capacities use the default.
+type Config struct {
+	CloseCapacity int `json:"close_capacity,omitempty"`
+	EventCapacity int `json:"event_capacity,omitempty"`
+	EntryCapacity int `json:"entry_capacity,omitempty"`
+
+	// SpillDir receives overflow segment files once a class is at capacity ("" or "off" disables spilling).
+	// SpillMaxAge (Go duration, default 15m) expires spilled trades instead of delivering them late.
+	SpillDir    string `json:"spill_dir,omitempty"`
+	SpillMaxAge string `json:"spill_max_age,omitempty"`
+
+	// EntryMaxAge (Go duration, default 5m, "off" disables) is the longest an entry may wait in the
+	// queue before it is treated as stale; StaleEntryPolicy ("drop" or "hold") decides what happens then.
+	EntryMaxAge      string `json:"entry_max_age,omitempty"`
+	StaleEntryPolicy string `json:"stale_entry_policy,omitempty"`
+}
+
+// Stale entry policies.
+const (
+	StaleDrop = "drop" // discard the entry and report it
+	StaleHold = "hold" // park the entry with the held trades until it is released
+)
+
+// DefaultCapacity is the per-class capacity used when the configuration leaves it unset.
+const DefaultCapacity = 100
+
+// DefaultSpillMaxAge bounds how long a spilled trade may wait before it is expired.
+const DefaultSpillMaxAge = 15 * time.Minute
+
+// DefaultEntryMaxAge is the queue wait after which an entry is considered stale.
+const DefaultEntryMaxAge = 5 * time.Minute
+
+// Validate rejects negative capacities and malformed durations.
+func (c Config) Validate() error {
+	for name, v := range map[string]int{"close_capacity": c.CloseCapacity, "event_capacity": c.EventCapacity, "entry_capacity": c.EntryCapacity} {
+		if v < 0 {
+			return fmt.Errorf("queue: %s must not be negative, got %d", name, v)
+		}
+	}
+	if _, err := c.MaxAge(); err != nil {
+		return err
+	}
+	if _, err := c.EntryTTL(); err != nil {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(c.StaleEntryPolicy)) {
+	case "", StaleDrop, StaleHold:
+	default:
+		return fmt.Errorf("queue: stale_entry_policy %q must be \"drop\" or \"hold\"", c.StaleEntryPolicy)
+	}
+	return nil
+}
+
+// EntryTTL returns the parsed entry_max_age; 0 means entries never go stale by age.
+func (c Config) EntryTTL() (time.Duration, error) {
+	v := strings.TrimSpace(c.EntryMaxAge)
+	switch {
+	case v == "":
+		return DefaultEntryMaxAge, nil
+	case strings.EqualFold(v, "off"):
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("queue: entry_max_age %q must be a positive duration such as \"5m\" or \"off\"", c.EntryMaxAge)
+	}
+	return d, nil
+}
+
+// StalePolicy returns the normalised stale_entry_policy (default "drop").
+func (c Config) StalePolicy() string {
+	if strings.EqualFold(strings.TrimSpace(c.StaleEntryPolicy), StaleHold) {
+		return StaleHold
+	}
+	return StaleDrop
+}
+
+// Spilling reports whether overflow goes to disk.
+func (c Config) Spilling() bool {
+	dir := strings.TrimSpace(c.SpillDir)
+	return dir != "" && !strings.EqualFold(dir, "off")
+}
+
+// MaxAge returns the parsed spill_max_age.
+func (c Config) MaxAge() (time.Duration, error) {
+	if strings.TrimSpace(c.SpillMaxAge) == "" {
+		return DefaultSpillMaxAge, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(c.SpillMaxAge))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("queue: spill_max_age %q must be a positive duration such as \"15m\"", c.SpillMaxAge)
+	}
+	return d, nil
+}
+
+func (c Config) capacities() [numClasses]int {
+	caps := [numClasses]int{c.CloseCapacity, c.EventCapacity, c.EntryCapacity}
+	for i := range caps {
+		if caps[i] == 0 {
+			caps[i] = DefaultCapacity
+		}
+	}
+	return caps
+}
+
+// ClassStats reports depth and wait time for one class.
+type ClassStats struct {
+	Class     string        `json:"class"`
+	Depth     int           `json:"depth"`
+	Capacity  int           `json:"capacity"`
+	Spilled   int           `json:"spilled"` // currently on disk (included in Depth)
+	Delivered uint64        `json:"delivered"`
+	Rejected  uint64        `json:"rejected"`
+	Expired   uint64        `json:"expired"`
+	AvgWait   time.Duration `json:"avg_wait"`
+	MaxWait   time.Duration `json:"max_wait"`
+	LastWait  time.Duration `json:"last_wait"`
+}
+
+type entry[T any] struct {
+	item     T
+	key      string
+	seq      uint64
+	enqueued time.Time
+}
+
+type classMetrics struct {
+	delivered uint64
+	rejected  uint64
+	expired   uint64
+	totalWait time.Duration
+	maxWait   time.Duration
+	lastWait  time.Duration
+}
+
+// ExpireFunc is told about spilled items dropped for exceeding the maximum age.
+type ExpireFunc[T any] func(item T, class Class, age time.Duration)
+
+// Scheduler is a bounded priority queue: closes before events before entries, while items that
+// share a key (the BaseID) are always delivered in the order they were pushed, whatever their class.
+// When a class is at capacity and a spill directory is configured, further items of that class go
+// to segment files on disk and are drained back, in order, as memory frees up.
+type Scheduler[T any] struct {
+	mu       sync.Mutex
+	name     string
+	lanes    [numClasses][]entry[T]
+	spills   [numClasses]*spillLane // nil when spilling is disabled
+	caps     [numClasses]int
+	maxAge   time.Duration
+	onExpire ExpireFunc[T]
+	metrics  [numClasses]classMetrics
+	pending  map[string][]uint64 // key -> seqs still queued (memory or disk), oldest first
+	seq      uint64
+}
+
+// New creates a scheduler for the queue called name. Segment files a previous run left in the
+// spill directory for name are restored in their original order.
+func New[T any](name string, cfg Config) *Scheduler[T] {
+	s := &Scheduler[T]{name: name, caps: cfg.capacities(), pending: make(map[string][]uint64)}
+	s.maxAge, _ = cfg.MaxAge()
+	if !cfg.Spilling() {
+		return s
+	}
+	dir := spillDir(cfg.SpillDir, name)
+	recovered, old, lastSeg, err := loadSpill(dir)
+	if err != nil {
+		log.Printf("WARN: queue %s: could not restore spilled trades from %s: %v", name, dir, err)
+	}
+	for c := range s.spills {
+		s.spills[c] = &spillLane{dir: dir, class: Class(c), remaining: make(map[int]int), writeSeg: lastSeg}
+	}
+	if err := s.restore(recovered); err != nil {
+		// The old segments stay on disk and are restored by the next start
+		log.Printf("WARN: queue %s: could not restore %d spilled trade(s), left in %s: %v", name, len(recovered), dir, err)
+		return s
+	}
+	for _, path := range old {
+		os.Remove(path)
+	}
+	if len(recovered) > 0 {
+		log.Printf("Queue %s: restored %d spilled trade(s) from %s", name, len(recovered), dir)
+	}
+	return s
+}
+
+// restore appends the records of a previous run to new segments and syncs them. On failure the
+// segments it wrote are deleted, so the records are only on disk once, in the old segments.
+func (s *Scheduler[T]) restore(recs []spillRecord) error {
+	err := func() error {
+		for _, rec := range recs {
+			if rec.Class < 0 || rec.Class >= numClasses {
+				continue
+			}
+			s.seq++
+			rec.Seq = s.seq
+			if err := s.spills[rec.Class].append(rec); err != nil {
+				return err
+			}
+			if rec.Key != "" {
+				s.pending[rec.Key] = append(s.pending[rec.Key], rec.Seq)
+			}
+		}
+		for _, l := range s.spills {
+			if err := l.sync(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		for _, l := range s.spills {
+			l.discard()
+		}
+		s.pending, s.seq = make(map[string][]uint64), 0
+	}
+	return err
+}
+
+// OnExpire installs the handler for expired spilled items.
+func (s *Scheduler[T]) OnExpire(fn ExpireFunc[T]) {
+	s.mu.Lock()
+	s.onExpire = fn
+	s.mu.Unlock()
+}
+
+// SetConfig applies new capacities and spill age. Items already queued beyond a reduced capacity
+// are kept; the spill directory is fixed when the scheduler is created.
+func (s *Scheduler[T]) SetConfig(cfg Config) {
+	s.mu.Lock()
+	s.caps = cfg.capacities()
+	s.maxAge, _ = cfg.MaxAge()
+	s.mu.Unlock()
+}
+
+// Push queues item in class. key orders items of the same BaseID ("" = unordered).
+func (s *Scheduler[T]) Push(item T, class Class, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	spill := s.spills[class]
+	// Once a class has spilled, later items follow it to disk so the class drains in order
+	if len(s.lanes[class]) >= s.caps[class] || (spill != nil && len(spill.refs) > 0) {
+		if spill == nil {
+			s.metrics[class].rejected++
+			return ErrFull
+		}
+		payload, err := json.Marshal(item)
+		if err == nil {
+			err = spill.append(spillRecord{Seq: s.seq + 1, Class: class, Key: key, Enqueued: now, Item: payload})
+		}
+		if err != nil {
+			s.metrics[class].rejected++
+			log.Printf("ERROR: queue %s: spill to disk failed: %v", s.name, err)
+			return fmt.Errorf("%w (spill failed: %v)", ErrFull, err)
+		}
+		s.seq++
+	} else {
+		s.seq++
+		s.lanes[class] = append(s.lanes[class], entry[T]{item: item, key: key, seq: s.seq, enqueued: now})
+	}
+	if key != "" {
+		s.pending[key] = append(s.pending[key], s.seq)
+	}
+	return nil
+}
+
+// refill moves spilled items back into memory while there is room, expiring those older than
+// maxAge. The caller holds s.mu; expired items are returned for reporting outside the lock.
+func (s *Scheduler[T]) refill() []expired[T] {
+	var out []expired[T]
+	for c, spill := range s.spills {
+		for spill != nil && len(spill.refs) > 0 && len(s.lanes[c]) < s.caps[c] {
+			ref := spill.refs[0]
+			rec, err := spill.read(ref)
+			spill.shift()
+			var item T
+			if err == nil {
+				err = json.Unmarshal(rec.Item, &item)
+			}
+			age := time.Since(ref.enqueued)
+			if err != nil || age > s.maxAge {
+				s.release(ref.key, ref.seq)
+				s.metrics[c].expired++
+				if err != nil {
+					log.Printf("ERROR: queue %s: dropping unreadable spilled trade (seq=%d): %v", s.name, ref.seq, err)
+					continue
+				}
+				out = append(out, expired[T]{item: item, class: Class(c), age: age})
+				continue
+			}
+			s.lanes[c] = append(s.lanes[c], entry[T]{item: item, key: ref.key, seq: ref.seq, enqueued: ref.enqueued})
+		}
+	}
+	return out
+}
+
+type expired[T any] struct {
+	item  T
+	class Class
+	age   time.Duration
+}
+
+// release forgets seq in key's ordering list.
+func (s *Scheduler[T]) release(key string, seq uint64) {
+	if key == "" {
+		return
+	}
+	list := s.pending[key]
+	for i, v := range list {
+		if v == seq {
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(list) > 0 {
+		s.pending[key] = list
+	} else {
+		delete(s.pending, key)
+	}
+}
+
+// Pop returns the next deliverable item: the oldest item of the most urgent class whose key has
+// no older item still queued.
+func (s *Scheduler[T]) Pop() (T, bool) {
+	item, _, ok := s.PopWait()
+	return item, ok
+}
+
+// PopWait is Pop that also returns how long the item waited in the queue.
+func (s *Scheduler[T]) PopWait() (T, time.Duration, bool) {
+	s.mu.Lock()
+	dropped := s.refill()
+	fn := s.onExpire
+	item, wait, ok := s.pop()
+	s.mu.Unlock()
+
+	for _, e := range dropped {
+		if fn != nil {
+			fn(e.item, e.class, e.age)
+		}
+	}
+	return item, wait, ok
+}
+
+func (s *Scheduler[T]) pop() (T, time.Duration, bool) {
+	for c := range s.lanes {
+		for i, e := range s.lanes[c] {
+			if e.key != "" && s.pending[e.key][0] != e.seq {
+				continue // an older item for this BaseID must go first
+			}
+			s.lanes[c] = append(s.lanes[c][:i], s.lanes[c][i+1:]...)
+			s.release(e.key, e.seq)
+			wait := time.Since(e.enqueued)
+			m := &s.metrics[c]
+			m.delivered++
+			m.totalWait += wait
+			m.lastWait = wait
+			if wait > m.maxWait {
+				m.maxWait = wait
+			}
+			return e.item, wait, true
+		}
+	}
+	var zero T
+	return zero, 0, false
+}
+
+// CountKey returns how many items of class are queued (in memory or on disk) under key.
+func (s *Scheduler[T]) CountKey(key string, class Class) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, e := range s.lanes[class] {
+		if e.key == key {
+			n++
+		}
+	}
+	if spill := s.spills[class]; spill != nil {
+		for _, ref := range spill.refs {
+			if ref.key == key {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Len returns the number of queued items across all classes.
+func (s *Scheduler[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for c, lane := range s.lanes {
+		n += len(lane)
+		if s.spills[c] != nil {
+			n += len(s.spills[c].refs)
+		}
+	}
+	return n
+}
+
+// Stats returns per-class depth and wait-time metrics, most urgent class first.
+func (s *Scheduler[T]) Stats() []ClassStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]ClassStats, numClasses)
+	for c := range s.lanes {
+		m := s.metrics[c]
+		st := ClassStats{
+			Class:     Class(c).String(),
+			Depth:     len(s.lanes[c]),
+			Capacity:  s.caps[c],
+			Delivered: m.delivered,
+			Rejected:  m.rejected,
+			Expired:   m.expired,
+			MaxWait:   m.maxWait,
+			LastWait:  m.lastWait,
+		}
+		if s.spills[c] != nil {
+			st.Spilled = len(s.spills[c].refs)
+			st.Depth += st.Spilled
+		}
+		if m.delivered > 0 {
+			st.AvgWait = m.totalWait / time.Duration(m.delivered)
+		}
+		out[c] = st
+	}
+	return out
+}
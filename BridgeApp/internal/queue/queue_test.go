@@ -0,0 +1,121 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func popAll(t *testing.T, s *Scheduler[string]) []string {
+	t.Helper()
+	var out []string
+	for {
+		v, ok := s.Pop()
+		if !ok {
+			return out
+		}
+		out = append(out, v)
+	}
+}
+
+func TestPopPrefersClosesButKeepsBaseOrder(t *testing.T) {
+	s := New[string]("test", Config{})
+	pushes := []struct {
+		item  string
+		class Class
+		key   string
+	}{
+		{"entry-A", ClassEntry, "A"},
+		{"entry-B", ClassEntry, "B"},
+		{"event-B", ClassEvent, "B"},
+		{"close-C", ClassClose, "C"},
+		{"close-A", ClassClose, "A"}, // must wait for entry-A
+		{"entry-D", ClassEntry, ""},
+	}
+	for _, p := range pushes {
+		if err := s.Push(p.item, p.class, p.key); err != nil {
+			t.Fatalf("Push(%s): %v", p.item, err)
+		}
+	}
+
+	got := popAll(t, s)
+	want := []string{"close-C", "entry-A", "close-A", "entry-B", "event-B", "entry-D"}
+	if len(got) != len(want) {
+		t.Fatalf("Pop order = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Pop order = %v; want %v", got, want)
+		}
+	}
+	if s.Len() != 0 || len(s.pending) != 0 {
+		t.Fatalf("expected empty scheduler, len=%d pending=%v", s.Len(), s.pending)
+	}
+}
+
+func TestCapacityAndStatsPerClass(t *testing.T) {
+	s := New[string]("test", Config{EntryCapacity: 1})
+	if err := s.Push("e1", ClassEntry, "A"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if err := s.Push("e2", ClassEntry, "B"); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected ErrFull once entries are at capacity, got %v", err)
+	}
+	if err := s.Push("c1", ClassClose, "B"); err != nil {
+		t.Fatalf("closes have their own capacity: %v", err)
+	}
+	popAll(t, s)
+
+	stats := s.Stats()
+	if stats[ClassClose].Delivered != 1 || stats[ClassEntry].Delivered != 1 || stats[ClassEntry].Rejected != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats[ClassEntry].Capacity != 1 || stats[ClassEvent].Capacity != DefaultCapacity {
+		t.Fatalf("unexpected capacities: %+v", stats)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := map[string]Class{"CLOSE_HEDGE": ClassClose, "close_hedge": ClassClose, "EVENT": ClassEvent, "buy": ClassEntry, "SELL": ClassEntry}
+	for action, want := range cases {
+		if got := Classify(action); got != want {
+			t.Fatalf("Classify(%q) = %v; want %v", action, got, want)
+		}
+	}
+}
+
+func TestCountKeyAndPopWait(t *testing.T) {
+	s := New[string]("test", Config{})
+	for _, p := range []struct{ item, key string }{{"e1", "A"}, {"e2", "A"}, {"e3", "B"}} {
+		if err := s.Push(p.item, ClassEntry, p.key); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+	if n := s.CountKey("A", ClassEntry); n != 2 {
+		t.Fatalf("CountKey(A) = %d; want 2", n)
+	}
+	if n := s.CountKey("A", ClassClose); n != 0 {
+		t.Fatalf("CountKey(A, close) = %d; want 0", n)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if v, wait, ok := s.PopWait(); !ok || v != "e1" || wait < 5*time.Millisecond {
+		t.Fatalf("PopWait = %q, %v, %v", v, wait, ok)
+	}
+}
+
+func TestEntryTTLAndStalePolicy(t *testing.T) {
+	if d, err := (Config{}).EntryTTL(); err != nil || d != DefaultEntryMaxAge {
+		t.Fatalf("default EntryTTL = %v, %v", d, err)
+	}
+	if d, err := (Config{EntryMaxAge: "off"}).EntryTTL(); err != nil || d != 0 {
+		t.Fatalf("off EntryTTL = %v, %v", d, err)
+	}
+	if (Config{StaleEntryPolicy: "HOLD"}).StalePolicy() != StaleHold || (Config{}).StalePolicy() != StaleDrop {
+		t.Fatal("unexpected stale policy normalisation")
+	}
+	for _, bad := range []Config{{EntryMaxAge: "soon"}, {EntryMaxAge: "-1m"}, {StaleEntryPolicy: "convert"}} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// segmentRecords is the number of records written to a segment file before rotating.
+const segmentRecords = 500
+
+// spillRecord is one line of a segment file.
+type spillRecord struct {
+	Seq      uint64          `json:"seq"`
+	Class    Class           `json:"class"`
+	Key      string          `json:"key,omitempty"`
+	Enqueued time.Time       `json:"enqueued"`
+	Item     json.RawMessage `json:"item"`
+}
+
+// spillRef locates a spilled record; the payload stays on disk until it is drained.
+type spillRef struct {
+	seg      int
+	off      int64
+	size     int
+	seq      uint64
+	key      string
+	enqueued time.Time
+}
+
+// spillLane is the on-disk overflow of one class: append-only segment files drained in order.
+type spillLane struct {
+	dir       string
+	class     Class
+	refs      []spillRef
+	remaining map[int]int // segment -> undrained records
+	writeSeg  int
+	writeN    int
+	writeOff  int64
+	w         *os.File
+}
+
+func spillDir(root, name string) string {
+	return filepath.Join(root, url.QueryEscape(name))
+}
+
+func segmentPath(dir string, class Class, seg int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%06d.seg", class, seg))
+}
+
+// append writes rec to the active segment and remembers where it lives.
+func (l *spillLane) append(rec spillRecord) error {
+	if l.w == nil || l.writeN >= segmentRecords {
+		if l.w != nil {
+			l.w.Sync()
+			l.w.Close()
+		}
+		if err := os.MkdirAll(l.dir, 0o755); err != nil {
+			return err
+		}
+		l.writeSeg++
+		f, err := os.OpenFile(segmentPath(l.dir, l.class, l.writeSeg), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		l.w, l.writeN, l.writeOff = f, 0, 0
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if _, err := l.w.Write(b); err != nil {
+		return err
+	}
+	l.refs = append(l.refs, spillRef{seg: l.writeSeg, off: l.writeOff, size: len(b), seq: rec.Seq, key: rec.Key, enqueued: rec.Enqueued})
+	l.remaining[l.writeSeg]++
+	l.writeN++
+	l.writeOff += int64(len(b))
+	return nil
+}
+
+// read loads the payload of ref.
+func (l *spillLane) read(ref spillRef) (spillRecord, error) {
+	var rec spillRecord
+	f, err := os.Open(segmentPath(l.dir, l.class, ref.seg))
+	if err != nil {
+		return rec, err
+	}
+	defer f.Close()
+	buf := make([]byte, ref.size)
+	if _, err := f.ReadAt(buf, ref.off); err != nil {
+		return rec, err
+	}
+	return rec, json.Unmarshal(buf, &rec)
+}
+
+// sync flushes the active segment to disk.
+func (l *spillLane) sync() error {
+	if l.w == nil {
+		return nil
+	}
+	return l.w.Sync()
+}
+
+// discard closes the lane and deletes the segments it wrote, keeping its segment numbering.
+func (l *spillLane) discard() {
+	if l.w != nil {
+		l.w.Close()
+		l.w = nil
+	}
+	for seg := range l.remaining {
+		os.Remove(segmentPath(l.dir, l.class, seg))
+	}
+	l.refs, l.remaining, l.writeN, l.writeOff = nil, make(map[int]int), 0, 0
+}
+
+// shift drops the head ref and deletes its segment once fully drained.
+func (l *spillLane) shift() {
+	ref := l.refs[0]
+	l.refs = l.refs[1:]
+	l.remaining[ref.seg]--
+	if l.remaining[ref.seg] > 0 {
+		return
+	}
+	delete(l.remaining, ref.seg)
+	if ref.seg == l.writeSeg && l.w != nil {
+		l.w.Close()
+		l.w = nil
+	}
+	os.Remove(segmentPath(l.dir, l.class, ref.seg))
+}
+
+// loadSpill reads the segments a previous run left behind for one queue, oldest first. The files
+// are left in place: the caller deletes them once their records are persisted again.
+func loadSpill(dir string) ([]spillRecord, []string, int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, 0, nil
+		}
+		return nil, nil, 0, err
+	}
+	var recs []spillRecord
+	var paths []string
+	maxSeg := 0
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".seg")
+		dash := strings.LastIndex(name, "-")
+		if e.IsDir() || dash < 0 || !strings.HasSuffix(e.Name(), ".seg") {
+			continue
+		}
+		seg, err := strconv.Atoi(name[dash+1:])
+		if err != nil {
+			continue
+		}
+		if seg > maxSeg {
+			maxSeg = seg
+		}
+		path := filepath.Join(dir, e.Name())
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for sc.Scan() {
+			var rec spillRecord
+			if err := json.Unmarshal(sc.Bytes(), &rec); err == nil {
+				recs = append(recs, rec)
+			}
+		}
+		f.Close()
+		paths = append(paths, path)
+	}
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].Seq < recs[j].Seq })
+	return recs, paths, maxSeg, nil
+}
+
+// SpilledQueues lists the queue names with segment files under root, e.g. to restore them at startup.
+func SpilledQueues(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if name, err := url.QueryUnescape(e.Name()); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpillOverflowDrainsInOrder(t *testing.T) {
+	dir := t.TempDir()
+	s := New[string]("T1", Config{EntryCapacity: 2, SpillDir: dir})
+	for i := 1; i <= 5; i++ {
+		if err := s.Push(fmt.Sprintf("e%d", i), ClassEntry, fmt.Sprintf("B%d", i)); err != nil {
+			t.Fatalf("Push e%d: %v", i, err)
+		}
+	}
+	if s.Len() != 5 || s.Stats()[ClassEntry].Spilled != 3 {
+		t.Fatalf("expected 3 of 5 entries spilled, len=%d stats=%+v", s.Len(), s.Stats()[ClassEntry])
+	}
+	if segs, _ := filepath.Glob(filepath.Join(dir, "T1", "*.seg")); len(segs) == 0 {
+		t.Fatalf("expected segment files on disk")
+	}
+
+	got := popAll(t, s)
+	want := []string{"e1", "e2", "e3", "e4", "e5"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("drain order = %v; want %v", got, want)
+	}
+	if segs, _ := filepath.Glob(filepath.Join(dir, "T1", "*.seg")); len(segs) != 0 {
+		t.Fatalf("drained segments should be removed, found %v", segs)
+	}
+}
+
+func TestSpillSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	s := New[string]("T1", Config{EntryCapacity: 1, SpillDir: dir})
+	for _, v := range []string{"e1", "e2", "e3"} {
+		if err := s.Push(v, ClassEntry, ""); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+	if names := SpilledQueues(dir); len(names) != 1 || names[0] != "T1" {
+		t.Fatalf("SpilledQueues = %v", names)
+	}
+
+	restored := New[string]("T1", Config{EntryCapacity: 1, SpillDir: dir})
+	if got := popAll(t, restored); fmt.Sprint(got) != "[e2 e3]" {
+		t.Fatalf("restored drain = %v; want only the spilled items [e2 e3]", got)
+	}
+}
+
+func TestFailedRestoreKeepsSpilledSegments(t *testing.T) {
+	dir := t.TempDir()
+	s := New[string]("T1", Config{EntryCapacity: 1, SpillDir: dir})
+	for _, v := range []string{"e1", "e2", "e3"} {
+		if err := s.Push(v, ClassEntry, "B1"); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+
+	// A directory where the restore writes its first segment makes the re-append fail
+	blocker := segmentPath(spillDir(dir, "T1"), ClassEntry, 2)
+	if err := os.Mkdir(blocker, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	failed := New[string]("T1", Config{EntryCapacity: 1, SpillDir: dir})
+	if failed.Len() != 0 || len(failed.pending) != 0 {
+		t.Fatalf("a failed restore must not queue anything, len=%d pending=%v", failed.Len(), failed.pending)
+	}
+	if _, err := os.Stat(segmentPath(spillDir(dir, "T1"), ClassEntry, 1)); err != nil {
+		t.Fatalf("the old segment must be kept after a failed restore: %v", err)
+	}
+
+	os.Remove(blocker)
+	restored := New[string]("T1", Config{EntryCapacity: 1, SpillDir: dir})
+	if got := popAll(t, restored); fmt.Sprint(got) != "[e2 e3]" {
+		t.Fatalf("restored drain = %v; want [e2 e3]", got)
+	}
+	if segs, _ := filepath.Glob(filepath.Join(dir, "T1", "*.seg")); len(segs) != 0 {
+		t.Fatalf("drained and replaced segments should be removed, found %v", segs)
+	}
+}
+
+func TestSpilledItemsExpireAfterMaxAge(t *testing.T) {
+	s := New[string]("T1", Config{EntryCapacity: 1, SpillDir: t.TempDir(), SpillMaxAge: "20ms"})
+	var expired []string
+	s.OnExpire(func(item string, class Class, age time.Duration) { expired = append(expired, item) })
+
+	s.Push("fresh", ClassEntry, "A")
+	s.Push("stale", ClassEntry, "A")
+	time.Sleep(40 * time.Millisecond)
+
+	if got := popAll(t, s); fmt.Sprint(got) != "[fresh]" {
+		t.Fatalf("expected only the in-memory item, got %v", got)
+	}
+	if fmt.Sprint(expired) != "[stale]" || s.Stats()[ClassEntry].Expired != 1 || len(s.pending) != 0 {
+		t.Fatalf("expected stale spilled item reported, expired=%v stats=%+v", expired, s.Stats()[ClassEntry])
+	}
+}
+
+func TestSpillDisabledRejects(t *testing.T) {
+	s := New[string]("T1", Config{EntryCapacity: 1, SpillDir: "off"})
+	s.Push("e1", ClassEntry, "")
+	if err := s.Push("e2", ClassEntry, ""); err != ErrFull {
+		t.Fatalf("expected ErrFull with spilling disabled, got %v", err)
+	}
+	if _, err := os.Stat("off"); err == nil {
+		t.Fatalf("spill_dir=off must not create a directory")
+	}
+}
@@ -0,0 +1,130 @@
+package routing
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"BridgeApp/internal/symbols"
+)
+
+// DefaultTerminal is the destination used when no routing is configured. EAs that do not
+// identify themselves (or identify as an unknown terminal) serve the default destination.
+const DefaultTerminal = "default"
+
+// Rule routes Quantower trades to an MT5 terminal. Account and StrategyTag are exact names or
+// * / ? wildcards; an empty field matches anything, but at least one must be set.
+type Rule struct {
+	Account     string `json:"account,omitempty"`
+	StrategyTag string `json:"strategy_tag,omitempty"`
+	Terminal    string `json:"terminal"`
+}
+
+// Config is the "routing" section of the bridge configuration file.
+type Config struct {
+	DefaultTerminal string `json:"default_terminal,omitempty"` // destination for unmatched trades and anonymous EAs
+	Rules           []Rule `json:"rules,omitempty"`
+}
+
+type compiledRule struct {
+	label    string
+	account  *regexp.Regexp
+	tag      *regexp.Regexp
+	terminal string
+}
+
+// Router resolves trade destinations. A nil *Router sends everything to DefaultTerminal.
+type Router struct {
+	def       string
+	rules     []compiledRule
+	terminals map[string]string // lower-case id -> configured spelling
+}
+
+// New validates cfg and compiles its rules.
+func New(cfg Config) (*Router, error) {
+	r := &Router{def: strings.TrimSpace(cfg.DefaultTerminal), terminals: make(map[string]string)}
+	if r.def == "" {
+		r.def = DefaultTerminal
+	}
+	r.terminals[strings.ToLower(r.def)] = r.def
+	for i, rule := range cfg.Rules {
+		terminal := strings.TrimSpace(rule.Terminal)
+		account := strings.TrimSpace(rule.Account)
+		tag := strings.TrimSpace(rule.StrategyTag)
+		if terminal == "" {
+			return nil, fmt.Errorf("routing: rule %d has no terminal", i)
+		}
+		if account == "" && tag == "" {
+			return nil, fmt.Errorf("routing: rule %d must set account and/or strategy_tag", i)
+		}
+		if known, ok := r.terminals[strings.ToLower(terminal)]; ok {
+			terminal = known
+		} else {
+			r.terminals[strings.ToLower(terminal)] = terminal
+		}
+		cr := compiledRule{terminal: terminal, label: fmt.Sprintf("account=%s strategy_tag=%s", account, tag)}
+		if account != "" {
+			cr.account = symbols.Pattern(account)
+		}
+		if tag != "" {
+			cr.tag = symbols.Pattern(tag)
+		}
+		r.rules = append(r.rules, cr)
+	}
+	return r, nil
+}
+
+// Default returns the destination for unmatched trades.
+func (r *Router) Default() string {
+	if r == nil {
+		return DefaultTerminal
+	}
+	return r.def
+}
+
+// Route returns the terminal for a trade and the rule that matched ("" for the default).
+// Rules are tried in configuration order.
+func (r *Router) Route(account, strategyTag string) (terminal, rule string) {
+	if r == nil {
+		return DefaultTerminal, ""
+	}
+	account, strategyTag = strings.TrimSpace(account), strings.TrimSpace(strategyTag)
+	for _, cr := range r.rules {
+		if cr.account != nil && !cr.account.MatchString(account) {
+			continue
+		}
+		if cr.tag != nil && !cr.tag.MatchString(strategyTag) {
+			continue
+		}
+		return cr.terminal, cr.label
+	}
+	return r.def, ""
+}
+
+// Destination maps the identities an EA presents (terminal id, account id, ...) to the
+// destination it serves: the first configured terminal, otherwise the default.
+func (r *Router) Destination(identities ...string) string {
+	for _, id := range identities {
+		if r == nil {
+			break
+		}
+		if known, ok := r.terminals[strings.ToLower(strings.TrimSpace(id))]; ok && id != "" {
+			return known
+		}
+	}
+	return r.Default()
+}
+
+// Terminals lists every configured destination, sorted.
+func (r *Router) Terminals() []string {
+	if r == nil {
+		return []string{DefaultTerminal}
+	}
+	out := make([]string, 0, len(r.terminals))
+	for _, t := range r.terminals {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,58 @@
+package routing
+
+import "testing"
+
+func TestRouteRulesAndDefault(t *testing.T) {
+	r, err := New(Config{
+		DefaultTerminal: "FTMO-1",
+		Rules: []Rule{
+			{Account: "Apex-*", StrategyTag: "scalp", Terminal: "Apex-Scalp"},
+			{Account: "Apex-*", Terminal: "Apex-Main"},
+			{StrategyTag: "swing*", Terminal: "apex-main"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	cases := []struct {
+		account, tag, want string
+	}{
+		{"Apex-123", "SCALP", "Apex-Scalp"}, // both fields must match, case-insensitive
+		{"Apex-123", "trend", "Apex-Main"},  // first matching rule wins
+		{"Sim101", "swing-long", "Apex-Main"},
+		{"Sim101", "", "FTMO-1"}, // unmatched -> default terminal
+	}
+	for _, c := range cases {
+		if got, _ := r.Route(c.account, c.tag); got != c.want {
+			t.Fatalf("Route(%q, %q) = %q; want %q", c.account, c.tag, got, c.want)
+		}
+	}
+
+	if got := r.Destination("", "APEX-SCALP"); got != "Apex-Scalp" {
+		t.Fatalf("Destination should match configured terminals case-insensitively, got %q", got)
+	}
+	if got := r.Destination("unknown-terminal", ""); got != "FTMO-1" {
+		t.Fatalf("unknown EAs serve the default terminal, got %q", got)
+	}
+	if got := r.Terminals(); len(got) != 3 {
+		t.Fatalf("expected 3 terminals, got %v", got)
+	}
+}
+
+func TestNilRouterAndInvalidConfig(t *testing.T) {
+	var r *Router
+	if got, _ := r.Route("Apex-1", ""); got != DefaultTerminal || r.Destination("T1") != DefaultTerminal {
+		t.Fatalf("nil router must route everything to %s", DefaultTerminal)
+	}
+
+	bad := []Config{
+		{Rules: []Rule{{Account: "Apex-*"}}},
+		{Rules: []Rule{{Terminal: "T1"}}},
+	}
+	for i, cfg := range bad {
+		if _, err := New(cfg); err == nil {
+			t.Fatalf("case %d: expected validation error for %+v", i, cfg)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"BridgeApp/internal/symbols"
+)
+
+// DefaultWindow is how long a close_only window extends before and after each of its times.
+const DefaultWindow = 2 * time.Minute
+
+// Action is what a rule does when its time comes.
+type Action string
+
+const (
+	Flatten   Action = "flatten"    // close every open hedge of the matching accounts
+	CloseOnly Action = "close_only" // refuse new entries of the matching accounts around each time; closes pass
+)
+
+// Rule is one scheduled action. Accounts are exact names or * / ? wildcards (empty = every
+// account). A rule fires daily at At (on Days, every day when empty) and once at each of Times.
+type Rule struct {
+	Name     string   `json:"name,omitempty"`
+	Action   string   `json:"action"`
+	Accounts []string `json:"accounts,omitempty"`
+	Days     []string `json:"days,omitempty"`   // mon ... sun
+	At       string   `json:"at,omitempty"`     // "HH:MM"
+	Times    []string `json:"times,omitempty"`  // "YYYY-MM-DD HH:MM", e.g. news releases
+	Before   string   `json:"before,omitempty"` // close_only: window start before each time (Go duration, default 2m)
+	After    string   `json:"after,omitempty"`  // close_only: window end after each time (Go duration, default 2m)
+}
+
+// Config is the "schedule" section of the bridge configuration file.
+type Config struct {
+	Timezone string `json:"timezone,omitempty"` // IANA name; the session timezone when empty
+	Rules    []Rule `json:"rules,omitempty"`
+}
+
+// Validate rejects rules that could never fire or cannot be parsed.
+func (c Config) Validate() error {
+	_, err := New(c, time.Local)
+	return err
+}
+
+// Occurrence is one firing of a rule. For close_only, From and Until bound the window around At;
+// for flatten they equal At.
+type Occurrence struct {
+	Rule     string    `json:"rule"`
+	Action   Action    `json:"action"`
+	At       time.Time `json:"at"`
+	From     time.Time `json:"from"`
+	Until    time.Time `json:"until"`
+	Accounts []string  `json:"accounts,omitempty"`
+
+	accounts []*regexp.Regexp
+}
+
+// Matches reports whether the occurrence applies to account.
+func (o Occurrence) Matches(account string) bool {
+	if len(o.accounts) == 0 {
+		return true
+	}
+	for _, re := range o.accounts {
+		if re.MatchString(strings.TrimSpace(account)) {
+			return true
+		}
+	}
+	return false
+}
+
+type compiledRule struct {
+	name          string
+	action        Action
+	accountNames  []string
+	accounts      []*regexp.Regexp
+	days          map[time.Weekday]bool
+	daily         bool
+	hour, minute  int
+	times         []time.Time
+	before, after time.Duration
+}
+
+// Schedule is a compiled, immutable set of rules. A nil *Schedule has no rules.
+type Schedule struct {
+	loc   *time.Location
+	rules []compiledRule
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// New validates cfg and compiles its rules; times are read in cfg.Timezone, or in loc when empty.
+func New(cfg Config, loc *time.Location) (*Schedule, error) {
+	s := &Schedule{loc: loc}
+	if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("schedule: unknown timezone %q: %w", cfg.Timezone, err)
+		}
+		s.loc = l
+	}
+	for i, rule := range cfg.Rules {
+		cr, err := s.compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("schedule: rule %d: %w", i, err)
+		}
+		if cr.name == "" {
+			cr.name = fmt.Sprintf("%s #%d", cr.action, i+1)
+		}
+		s.rules = append(s.rules, cr)
+	}
+	return s, nil
+}
+
+func (s *Schedule) compile(rule Rule) (compiledRule, error) {
+	cr := compiledRule{name: strings.TrimSpace(rule.Name), action: Action(strings.ToLower(strings.TrimSpace(rule.Action))),
+		before: DefaultWindow, after: DefaultWindow}
+	switch cr.action {
+	case Flatten, CloseOnly:
+	default:
+		return cr, fmt.Errorf("unknown action %q (want flatten or close_only)", rule.Action)
+	}
+	for _, acct := range rule.Accounts {
+		if acct = strings.TrimSpace(acct); acct != "" {
+			cr.accountNames = append(cr.accountNames, acct)
+			cr.accounts = append(cr.accounts, symbols.Pattern(acct))
+		}
+	}
+	if at := strings.TrimSpace(rule.At); at != "" {
+		t, err := time.Parse("15:04", at)
+		if err != nil {
+			return cr, fmt.Errorf("at %q is not HH:MM", rule.At)
+		}
+		cr.daily, cr.hour, cr.minute = true, t.Hour(), t.Minute()
+	}
+	if len(rule.Days) > 0 {
+		cr.days = make(map[time.Weekday]bool)
+		for _, d := range rule.Days {
+			key := strings.ToLower(strings.TrimSpace(d))
+			if len(key) > 3 {
+				key = key[:3]
+			}
+			wd, ok := weekdays[key]
+			if !ok {
+				return cr, fmt.Errorf("unknown day %q", d)
+			}
+			cr.days[wd] = true
+		}
+	}
+	for _, v := range rule.Times {
+		t, err := time.ParseInLocation("2006-01-02 15:04", strings.TrimSpace(v), s.loc)
+		if err != nil {
+			return cr, fmt.Errorf("time %q is not YYYY-MM-DD HH:MM", v)
+		}
+		cr.times = append(cr.times, t)
+	}
+	if !cr.daily && len(cr.times) == 0 {
+		return cr, fmt.Errorf("needs at and/or times")
+	}
+	for _, d := range []struct {
+		v   string
+		dst *time.Duration
+	}{{rule.Before, &cr.before}, {rule.After, &cr.after}} {
+		if v := strings.TrimSpace(d.v); v != "" {
+			dur, err := time.ParseDuration(v)
+			if err != nil || dur < 0 {
+				return cr, fmt.Errorf("invalid window %q", d.v)
+			}
+			*d.dst = dur
+		}
+	}
+	return cr, nil
+}
+
+// instants returns the times of r in [from, to].
+func (s *Schedule) instants(r compiledRule, from, to time.Time) []time.Time {
+	var out []time.Time
+	if r.daily {
+		f := from.In(s.loc)
+		for day := time.Date(f.Year(), f.Month(), f.Day()-1, 0, 0, 0, 0, s.loc); !day.After(to); day = day.AddDate(0, 0, 1) {
+			t := time.Date(day.Year(), day.Month(), day.Day(), r.hour, r.minute, 0, 0, s.loc)
+			if (r.days == nil || r.days[t.Weekday()]) && !t.Before(from) && !t.After(to) {
+				out = append(out, t)
+			}
+		}
+	}
+	for _, t := range r.times {
+		if !t.Before(from) && !t.After(to) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (r compiledRule) occurrence(at time.Time) Occurrence {
+	o := Occurrence{Rule: r.name, Action: r.action, At: at, From: at, Until: at, Accounts: r.accountNames, accounts: r.accounts}
+	if r.action == CloseOnly {
+		o.From, o.Until = at.Add(-r.before), at.Add(r.after)
+	}
+	return o
+}
+
+// Due returns the flatten occurrences in (after, upTo], oldest first.
+func (s *Schedule) Due(after, upTo time.Time) []Occurrence {
+	if s == nil {
+		return nil
+	}
+	var out []Occurrence
+	for _, r := range s.rules {
+		if r.action != Flatten {
+			continue
+		}
+		for _, t := range s.instants(r, after, upTo) {
+			if t.After(after) {
+				out = append(out, r.occurrence(t))
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+// Active returns the close_only windows containing now.
+func (s *Schedule) Active(now time.Time) []Occurrence {
+	if s == nil {
+		return nil
+	}
+	var out []Occurrence
+	for _, r := range s.rules {
+		if r.action != CloseOnly {
+			continue
+		}
+		for _, t := range s.instants(r, now.Add(-r.after), now.Add(r.before)) {
+			if o := r.occurrence(t); !now.Before(o.From) && now.Before(o.Until) {
+				out = append(out, o)
+			}
+		}
+	}
+	return out
+}
+
+// Blocked returns the close_only window refusing entries of account at now, if any.
+func (s *Schedule) Blocked(account string, now time.Time) (Occurrence, bool) {
+	var found Occurrence
+	ok := false
+	for _, o := range s.Active(now) {
+		if o.Matches(account) && (!ok || o.Until.After(found.Until)) {
+			found, ok = o, true
+		}
+	}
+	return found, ok
+}
+
+// Upcoming returns the flatten times and close_only windows that start or are still running
+// between now and now+horizon, in start order.
+func (s *Schedule) Upcoming(now time.Time, horizon time.Duration) []Occurrence {
+	if s == nil {
+		return nil
+	}
+	end := now.Add(horizon)
+	var out []Occurrence
+	for _, r := range s.rules {
+		from, to := now, end
+		if r.action == CloseOnly {
+			from, to = now.Add(-r.after), end.Add(r.before)
+		}
+		for _, t := range s.instants(r, from, to) {
+			o := r.occurrence(t)
+			if o.Until.After(now) && !o.From.After(end) {
+				out = append(out, o)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].From.Before(out[j].From) })
+	return out
+}
+
+// Location returns the timezone rule times are read in.
+func (s *Schedule) Location() *time.Location {
+	if s == nil {
+		return time.Local
+	}
+	return s.loc
+}
+
+// Len returns the number of rules.
+func (s *Schedule) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, cfg Config) *Schedule {
+	t.Helper()
+	s, err := New(cfg, time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return s
+}
+
+func TestFlattenRulesFireOnTheirDays(t *testing.T) {
+	s := mustNew(t, Config{Timezone: "America/Chicago", Rules: []Rule{
+		{Name: "weekend", Action: "flatten", Days: []string{"Friday"}, At: "15:55"},
+		{Action: "flatten", Accounts: []string{"Apex-*"}, At: "15:58"},
+	}})
+	ct := s.Location()
+
+	// Thursday 15:00 CT to Friday 16:00 CT
+	due := s.Due(time.Date(2026, 3, 5, 15, 0, 0, 0, ct), time.Date(2026, 3, 6, 16, 0, 0, 0, ct))
+	if len(due) != 3 || due[0].Rule != "flatten #2" || due[1].Rule != "weekend" || due[2].Rule != "flatten #2" {
+		t.Fatalf("unexpected due occurrences: %+v", due)
+	}
+	if !due[1].At.Equal(time.Date(2026, 3, 6, 15, 55, 0, 0, ct)) {
+		t.Fatalf("unexpected weekend flatten time: %s", due[1].At)
+	}
+	if !due[0].Matches("Apex-123") || due[0].Matches("FTMO-1") || !due[1].Matches("FTMO-1") {
+		t.Fatal("unexpected account matching")
+	}
+	// The lower bound is exclusive so a check at the exact time does not fire twice
+	if again := s.Due(due[2].At, due[2].At.Add(time.Minute)); len(again) != 0 {
+		t.Fatalf("occurrence fired twice: %+v", again)
+	}
+}
+
+func TestCloseOnlyWindowsBlockMatchingAccounts(t *testing.T) {
+	s := mustNew(t, Config{Rules: []Rule{
+		{Name: "NFP", Action: "close_only", Times: []string{"2026-03-06 13:30"}},
+		{Name: "late", Action: "close_only", Accounts: []string{"Sim101"}, At: "20:00", Before: "0s", After: "1h"},
+	}})
+	nfp := time.Date(2026, 3, 6, 13, 30, 0, 0, time.UTC)
+
+	if _, ok := s.Blocked("Sim101", nfp.Add(-3*time.Minute)); ok {
+		t.Fatal("entries must pass before the window")
+	}
+	o, ok := s.Blocked("Sim101", nfp.Add(-time.Minute))
+	if !ok || o.Rule != "NFP" || !o.Until.Equal(nfp.Add(2*time.Minute)) {
+		t.Fatalf("unexpected block: %+v (ok=%v)", o, ok)
+	}
+	if _, ok := s.Blocked("Sim101", nfp.Add(2*time.Minute)); ok {
+		t.Fatal("the window end is exclusive")
+	}
+
+	late := time.Date(2026, 3, 6, 20, 30, 0, 0, time.UTC)
+	if _, ok := s.Blocked("Sim101", late); !ok {
+		t.Fatal("Sim101 must be blocked in the evening window")
+	}
+	if _, ok := s.Blocked("Sim102", late); ok {
+		t.Fatal("other accounts must pass")
+	}
+
+	up := s.Upcoming(nfp.Add(-time.Hour), 8*time.Hour)
+	if len(up) != 2 || up[0].Rule != "NFP" || up[1].Rule != "late" {
+		t.Fatalf("unexpected upcoming: %+v", up)
+	}
+}
+
+func TestInvalidRulesAreRejected(t *testing.T) {
+	for _, cfg := range []Config{
+		{Timezone: "Nowhere/Land"},
+		{Rules: []Rule{{Action: "pause", At: "10:00"}}},
+		{Rules: []Rule{{Action: "flatten"}}},
+		{Rules: []Rule{{Action: "flatten", At: "3pm"}}},
+		{Rules: []Rule{{Action: "flatten", At: "15:00", Days: []string{"funday"}}}},
+		{Rules: []Rule{{Action: "close_only", Times: []string{"06/03/2026 13:30"}}}},
+		{Rules: []Rule{{Action: "close_only", At: "13:30", Before: "-1m"}}},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+}
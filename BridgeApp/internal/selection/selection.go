@@ -0,0 +1,103 @@
+package selection
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Policy decides which hedge ticket of a BaseID a pooled close takes first.
+type Policy string
+
+const (
+	FIFO            Policy = "fifo"             // oldest open time first (default)
+	LIFO            Policy = "lifo"             // newest open time first
+	MostProfitable  Policy = "most_profitable"  // highest MT5-reported PnL first
+	LeastProfitable Policy = "least_profitable" // lowest MT5-reported PnL first
+
+	// Explicit marks a close that named its ticket (mt5_ticket); it is reported, never configured.
+	Explicit Policy = "explicit"
+)
+
+// Config is the "close_selection" section of the bridge configuration file.
+type Config struct {
+	Policy          string            `json:"policy,omitempty"`           // default policy (fifo when empty)
+	AccountPolicies map[string]string `json:"account_policies,omitempty"` // per Quantower account override
+}
+
+func parse(v string) (Policy, error) {
+	switch p := Policy(strings.ToLower(strings.TrimSpace(v))); p {
+	case "":
+		return FIFO, nil
+	case FIFO, LIFO, MostProfitable, LeastProfitable:
+		return p, nil
+	default:
+		return "", fmt.Errorf("close_selection: unknown policy %q (want fifo, lifo, most_profitable or least_profitable)", v)
+	}
+}
+
+// Validate rejects unknown policy names.
+func (c Config) Validate() error {
+	if _, err := parse(c.Policy); err != nil {
+		return err
+	}
+	for acct, v := range c.AccountPolicies {
+		if _, err := parse(v); err != nil {
+			return fmt.Errorf("close_selection: account_policies[%s]: %w", acct, err)
+		}
+	}
+	return nil
+}
+
+// For returns the policy applied to closes of account.
+func (c Config) For(account string) Policy {
+	if v, ok := c.AccountPolicies[strings.TrimSpace(account)]; ok {
+		if p, err := parse(v); err == nil {
+			return p
+		}
+	}
+	p, err := parse(c.Policy)
+	if err != nil {
+		return FIFO
+	}
+	return p
+}
+
+// Candidate is a pooled ticket with what the bridge knows about it.
+type Candidate struct {
+	Ticket    uint64
+	Opened    time.Time // zero when the open result was never seen
+	Profit    float64
+	HasProfit bool
+}
+
+// Order sorts candidates in the order policy closes them. Tickets without an open time count as
+// the oldest; with a PnL policy, tickets MT5 has not reported PnL for go last in FIFO order.
+// Ties keep the pool order.
+func Order(policy Policy, candidates []Candidate) []Candidate {
+	out := append([]Candidate(nil), candidates...)
+	older := func(i, j int) bool { return out[i].Opened.Before(out[j].Opened) }
+	var less func(i, j int) bool
+	switch policy {
+	case LIFO:
+		less = func(i, j int) bool { return out[j].Opened.Before(out[i].Opened) }
+	case MostProfitable, LeastProfitable:
+		less = func(i, j int) bool {
+			if out[i].HasProfit != out[j].HasProfit {
+				return out[i].HasProfit
+			}
+			if !out[i].HasProfit || out[i].Profit == out[j].Profit {
+				return older(i, j)
+			}
+			if policy == MostProfitable {
+				return out[i].Profit > out[j].Profit
+			}
+			return out[i].Profit < out[j].Profit
+		}
+	default:
+		less = older
+	}
+	sort.SliceStable(out, less)
+	return out
+}
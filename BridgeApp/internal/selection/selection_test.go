@@ -0,0 +1,70 @@
+package selection
+
+import (
+	"testing"
+	"time"
+)
+
+func tickets(cs []Candidate) []uint64 {
+	out := make([]uint64, len(cs))
+	for i, c := range cs {
+		out[i] = c.Ticket
+	}
+	return out
+}
+
+func TestOrderByPolicy(t *testing.T) {
+	t0 := time.Date(2026, 1, 5, 14, 30, 0, 0, time.UTC)
+	pool := []Candidate{
+		{Ticket: 3, Opened: t0.Add(2 * time.Minute), Profit: 5, HasProfit: true},
+		{Ticket: 1, Opened: t0, Profit: -12, HasProfit: true},
+		{Ticket: 4, Opened: t0.Add(3 * time.Minute)}, // no PnL reported yet
+		{Ticket: 2, Opened: t0.Add(time.Minute), Profit: 30, HasProfit: true},
+	}
+	cases := []struct {
+		policy Policy
+		want   []uint64
+	}{
+		{FIFO, []uint64{1, 2, 3, 4}},
+		{LIFO, []uint64{4, 3, 2, 1}},
+		{MostProfitable, []uint64{2, 3, 1, 4}},
+		{LeastProfitable, []uint64{1, 3, 2, 4}},
+	}
+	for _, c := range cases {
+		got := tickets(Order(c.policy, pool))
+		for i := range c.want {
+			if got[i] != c.want[i] {
+				t.Fatalf("%s: got %v; want %v", c.policy, got, c.want)
+			}
+		}
+	}
+	if pool[0].Ticket != 3 {
+		t.Fatal("Order must not reorder its input")
+	}
+}
+
+func TestConfigPerAccount(t *testing.T) {
+	cfg := Config{Policy: "LIFO", AccountPolicies: map[string]string{"Apex-1": "most_profitable"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got := cfg.For("Apex-1"); got != MostProfitable {
+		t.Fatalf("account override: got %s", got)
+	}
+	if got := cfg.For("Sim101"); got != LIFO {
+		t.Fatalf("default policy: got %s", got)
+	}
+	if got := (Config{}).For("Sim101"); got != FIFO {
+		t.Fatalf("empty config must select fifo, got %s", got)
+	}
+
+	bad := []Config{
+		{Policy: "random"},
+		{AccountPolicies: map[string]string{"Apex-1": "newest"}},
+	}
+	for i, c := range bad {
+		if err := c.Validate(); err == nil {
+			t.Fatalf("case %d: expected validation error for %+v", i, c)
+		}
+	}
+}
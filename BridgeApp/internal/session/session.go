@@ -0,0 +1,86 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // Windows hosts have no zoneinfo database
+)
+
+// DefaultDailyLossLimit is the per-account loss limit reported when none is configured.
+const DefaultDailyLossLimit = 5000
+
+// Config is the "session" section of the bridge configuration file.
+type Config struct {
+	Timezone       string  `json:"timezone,omitempty"`         // IANA name, e.g. "America/Chicago" (local time when empty)
+	Start          string  `json:"start,omitempty"`            // session start "HH:MM" in Timezone (midnight when empty)
+	DailyLossLimit float64 `json:"daily_loss_limit,omitempty"` // per account and trading day (DefaultDailyLossLimit when 0)
+}
+
+// Validate rejects unknown timezones and malformed start times.
+func (c Config) Validate() error {
+	_, err := New(c)
+	return err
+}
+
+// LossLimit returns the configured daily loss limit.
+func (c Config) LossLimit() float64 {
+	if c.DailyLossLimit > 0 {
+		return c.DailyLossLimit
+	}
+	return DefaultDailyLossLimit
+}
+
+// Calendar maps instants onto trading days. A session runs from Start to Start of the next
+// calendar day; it is named after the date it ends on when it starts in the afternoon or
+// evening (CME style: 17:00 CT Sunday opens Monday's session), otherwise after the date it
+// starts on.
+type Calendar struct {
+	loc    *time.Location
+	hour   int
+	minute int
+}
+
+// New builds the calendar of cfg.
+func New(cfg Config) (*Calendar, error) {
+	c := &Calendar{loc: time.Local}
+	if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("session: unknown timezone %q: %w", cfg.Timezone, err)
+		}
+		c.loc = loc
+	}
+	if start := strings.TrimSpace(cfg.Start); start != "" {
+		t, err := time.Parse("15:04", start)
+		if err != nil {
+			return nil, fmt.Errorf("session: start %q is not HH:MM", cfg.Start)
+		}
+		c.hour, c.minute = t.Hour(), t.Minute()
+	}
+	return c, nil
+}
+
+// Location returns the calendar's timezone.
+func (c *Calendar) Location() *time.Location { return c.loc }
+
+// Bounds returns the start and end of the session containing t.
+func (c *Calendar) Bounds(t time.Time) (time.Time, time.Time) {
+	local := t.In(c.loc)
+	y, m, d := local.Date()
+	start := time.Date(y, m, d, c.hour, c.minute, 0, 0, c.loc)
+	if local.Before(start) {
+		start = time.Date(y, m, d-1, c.hour, c.minute, 0, 0, c.loc)
+	}
+	sy, sm, sd := start.Date()
+	return start, time.Date(sy, sm, sd+1, c.hour, c.minute, 0, 0, c.loc)
+}
+
+// Day returns the trading day of t as YYYY-MM-DD.
+func (c *Calendar) Day(t time.Time) string {
+	start, end := c.Bounds(t)
+	if c.hour >= 12 {
+		return end.Format("2006-01-02")
+	}
+	return start.Format("2006-01-02")
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCMESessionStartsTheEveningBefore(t *testing.T) {
+	c, err := New(Config{Timezone: "America/Chicago", Start: "17:00"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ct := c.Location()
+	for _, tc := range []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 1, 16, 59, 0, 0, ct), "2026-03-01"}, // Sunday afternoon: the weekend session opened Saturday 17:00
+		{time.Date(2026, 3, 1, 17, 0, 0, 0, ct), "2026-03-02"},  // Sunday open belongs to Monday
+		{time.Date(2026, 3, 2, 9, 30, 0, 0, ct), "2026-03-02"},
+		{time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC), "2026-03-03"}, // 17:00 CT (UTC-6) in UTC
+	} {
+		if got := c.Day(tc.at); got != tc.want {
+			t.Errorf("Day(%s) = %s, want %s", tc.at, got, tc.want)
+		}
+	}
+
+	// The session spanning the DST change is 23 hours long but still ends at 17:00 CT
+	start, end := c.Bounds(time.Date(2026, 3, 8, 12, 0, 0, 0, ct))
+	if start.Hour() != 17 || end.Hour() != 17 || end.Sub(start) != 23*time.Hour {
+		t.Fatalf("unexpected DST session bounds: %s - %s", start, end)
+	}
+}
+
+func TestMorningStartAndDefaults(t *testing.T) {
+	c, _ := New(Config{Timezone: "Europe/London", Start: "08:00"})
+	if got := c.Day(time.Date(2026, 3, 3, 7, 0, 0, 0, c.Location())); got != "2026-03-02" {
+		t.Fatalf("before the morning start the previous session runs, got %s", got)
+	}
+
+	c, _ = New(Config{})
+	if got := c.Day(time.Date(2026, 3, 3, 0, 0, 1, 0, time.Local)); got != "2026-03-03" {
+		t.Fatalf("the default calendar follows local midnight, got %s", got)
+	}
+	if (Config{}).LossLimit() != DefaultDailyLossLimit {
+		t.Fatal("unexpected default loss limit")
+	}
+
+	for _, bad := range []Config{{Timezone: "Mars/Olympus"}, {Start: "5pm"}, {Start: "25:00"}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package sizing
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"BridgeApp/internal/symbols"
+)
+
+// Spec describes one instrument on both sides of the hedge: the Quantower futures contract and
+// the MT5 symbol it is hedged with.
+type Spec struct {
+	Match string `json:"match"` // Quantower instrument, exact or * / ? wildcard (same syntax as symbols)
+
+	// Quantower contract
+	TickSize           float64 `json:"tick_size"`
+	TickValue          float64 `json:"tick_value"`                    // account currency per tick per contract
+	ContractMultiplier float64 `json:"contract_multiplier,omitempty"` // scales tick value (e.g. quantity units); default 1
+
+	// MT5 symbol (per 1.0 lot)
+	MT5TickSize  float64 `json:"mt5_tick_size"`
+	MT5TickValue float64 `json:"mt5_tick_value"`
+	LotStep      float64 `json:"lot_step"`
+	LotMin       float64 `json:"lot_min"`
+	LotMax       float64 `json:"lot_max"`
+}
+
+// Config is the "sizing" section of the bridge configuration file.
+type Config struct {
+	HedgeRatio    float64            `json:"hedge_ratio,omitempty"`          // default 1.0
+	AccountRatios map[string]float64 `json:"account_hedge_ratios,omitempty"` // per Quantower account override
+	Specs         []Spec             `json:"specs,omitempty"`
+}
+
+// Result is the auditable outcome of sizing one trade.
+type Result struct {
+	Lot          float64 // final lot after step rounding and min/max clamp
+	RawLot       float64 // lot before rounding
+	Ratio        float64 // hedge ratio applied
+	QTPointValue float64 // currency per 1.0 price move for one Quantower contract
+	MT5PointVal  float64 // currency per 1.0 price move for one MT5 lot
+	Spec         string  // spec that matched
+	Clamped      string  // "", "min" or "max"
+}
+
+type compiledSpec struct {
+	Spec
+	re *regexp.Regexp
+}
+
+// Sizer computes hedge lots. A nil *Sizer sizes nothing, leaving the EA fallback in place.
+type Sizer struct {
+	ratio    float64
+	accounts map[string]float64
+	specs    []compiledSpec
+}
+
+// New validates cfg and builds a Sizer.
+func New(cfg Config) (*Sizer, error) {
+	s := &Sizer{ratio: cfg.HedgeRatio, accounts: make(map[string]float64)}
+	if s.ratio == 0 {
+		s.ratio = 1.0
+	}
+	if s.ratio < 0 {
+		return nil, fmt.Errorf("sizing: hedge_ratio must be positive, got %v", cfg.HedgeRatio)
+	}
+	for acct, r := range cfg.AccountRatios {
+		if r <= 0 {
+			return nil, fmt.Errorf("sizing: account_hedge_ratios[%s] must be positive, got %v", acct, r)
+		}
+		s.accounts[strings.TrimSpace(acct)] = r
+	}
+	for i, sp := range cfg.Specs {
+		if strings.TrimSpace(sp.Match) == "" {
+			return nil, fmt.Errorf("sizing: spec %d has no match", i)
+		}
+		if sp.TickSize <= 0 || sp.TickValue <= 0 || sp.MT5TickSize <= 0 || sp.MT5TickValue <= 0 {
+			return nil, fmt.Errorf("sizing: spec %d (%s) needs positive tick_size, tick_value, mt5_tick_size and mt5_tick_value", i, sp.Match)
+		}
+		if sp.ContractMultiplier < 0 {
+			return nil, fmt.Errorf("sizing: spec %d (%s) contract_multiplier must not be negative", i, sp.Match)
+		}
+		if sp.LotStep <= 0 || sp.LotMin <= 0 || sp.LotMax < sp.LotMin {
+			return nil, fmt.Errorf("sizing: spec %d (%s) needs lot_step > 0 and 0 < lot_min <= lot_max", i, sp.Match)
+		}
+		s.specs = append(s.specs, compiledSpec{Spec: sp, re: symbols.Pattern(sp.Match)})
+	}
+	return s, nil
+}
+
+// HedgeRatio returns the ratio applied to trades for account.
+func (s *Sizer) HedgeRatio(account string) float64 {
+	if s == nil {
+		return 1.0
+	}
+	if r, ok := s.accounts[strings.TrimSpace(account)]; ok {
+		return r
+	}
+	return s.ratio
+}
+
+// Size returns the MT5 lot that hedges quantity contracts of instrument for account.
+// ok is false when no spec matches the instrument.
+func (s *Sizer) Size(instrument, account string, quantity float64) (Result, bool) {
+	if s == nil || quantity <= 0 {
+		return Result{}, false
+	}
+	inst := strings.TrimSpace(instrument)
+	for _, sp := range s.specs {
+		if !sp.re.MatchString(inst) {
+			continue
+		}
+		mult := sp.ContractMultiplier
+		if mult == 0 {
+			mult = 1
+		}
+		res := Result{
+			Ratio:        s.HedgeRatio(account),
+			QTPointValue: sp.TickValue / sp.TickSize * mult,
+			MT5PointVal:  sp.MT5TickValue / sp.MT5TickSize,
+			Spec:         sp.Match,
+		}
+		res.RawLot = res.Ratio * quantity * res.QTPointValue / res.MT5PointVal
+		res.Lot, res.Clamped = roundLot(res.RawLot, sp.LotStep, sp.LotMin, sp.LotMax)
+		return res, true
+	}
+	return Result{}, false
+}
+
+// roundLot rounds to the nearest lot step and clamps to [min, max].
+func roundLot(raw, step, min, max float64) (float64, string) {
+	lot := math.Round(raw/step) * step
+	clamped := ""
+	if lot < min {
+		lot, clamped = min, "min"
+	}
+	if lot > max {
+		lot, clamped = max, "max"
+	}
+	// Strip binary noise so 0.1+0.2 style artefacts never reach MT5
+	return math.Round(lot*1e8) / 1e8, clamped
+}
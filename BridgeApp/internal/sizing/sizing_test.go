@@ -0,0 +1,69 @@
+package sizing
+
+import "testing"
+
+// nqSpec hedges NQ ($20/point) with a CFD paying $1/point per lot.
+var nqSpec = Spec{
+	Match: "NQ*", TickSize: 0.25, TickValue: 5,
+	MT5TickSize: 0.01, MT5TickValue: 0.01, LotStep: 0.01, LotMin: 0.01, LotMax: 50,
+}
+
+func TestSizeUsesSpecAndRatio(t *testing.T) {
+	s, err := New(Config{HedgeRatio: 0.5, AccountRatios: map[string]float64{"Apex-1": 0.25}, Specs: []Spec{nqSpec}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, ok := s.Size("nqz5", "Sim101", 1)
+	if !ok || res.Lot != 10 || res.Ratio != 0.5 || res.QTPointValue != 20 || res.MT5PointVal != 1 {
+		t.Fatalf("Size = %+v (ok=%v); want 10 lots at ratio 0.5", res, ok)
+	}
+	if res, _ := s.Size("NQZ5", "Apex-1", 1); res.Lot != 5 {
+		t.Fatalf("account override: want 5 lots, got %+v", res)
+	}
+	if _, ok := s.Size("ESZ5", "Sim101", 1); ok {
+		t.Fatalf("instrument without spec must not be sized")
+	}
+}
+
+func TestSizeRoundsAndClamps(t *testing.T) {
+	spec := nqSpec
+	spec.LotStep, spec.LotMin, spec.LotMax = 0.1, 0.5, 15
+	s, err := New(Config{HedgeRatio: 0.333, Specs: []Spec{spec}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if res, _ := s.Size("NQZ5", "", 1); res.Lot != 6.7 || res.Clamped != "" {
+		t.Fatalf("want 6.66 rounded to 6.7, got %+v", res)
+	}
+	if res, _ := s.Size("NQZ5", "", 3); res.Lot != 15 || res.Clamped != "max" {
+		t.Fatalf("want clamp to lot_max 15, got %+v", res)
+	}
+
+	small, _ := New(Config{HedgeRatio: 0.001, Specs: []Spec{spec}})
+	if res, _ := small.Size("NQZ5", "", 1); res.Lot != 0.5 || res.Clamped != "min" {
+		t.Fatalf("want clamp to lot_min 0.5, got %+v", res)
+	}
+}
+
+func TestNilSizerAndInvalidConfig(t *testing.T) {
+	var s *Sizer
+	if _, ok := s.Size("NQZ5", "", 1); ok || s.HedgeRatio("") != 1.0 {
+		t.Fatalf("nil sizer must size nothing with ratio 1.0")
+	}
+
+	noLots := nqSpec
+	noLots.LotStep = 0
+	bad := []Config{
+		{HedgeRatio: -1},
+		{AccountRatios: map[string]float64{"A": 0}},
+		{Specs: []Spec{{TickSize: 1, TickValue: 1, MT5TickSize: 1, MT5TickValue: 1, LotStep: 1, LotMin: 1, LotMax: 1}}},
+		{Specs: []Spec{{Match: "NQ*", LotStep: 1, LotMin: 1, LotMax: 1}}},
+		{Specs: []Spec{noLots}},
+	}
+	for i, cfg := range bad {
+		if _, err := New(cfg); err == nil {
+			t.Fatalf("case %d: expected validation error for %+v", i, cfg)
+		}
+	}
+}
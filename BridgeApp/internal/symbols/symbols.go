@@ -0,0 +1,177 @@
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Policy decides what happens to entries whose instrument has no mapping.
+type Policy string
+
+const (
+	// PolicyPassthrough forwards the instrument unchanged and leaves translation to the EA (legacy behaviour).
+	PolicyPassthrough Policy = "passthrough"
+	// PolicyReject refuses the trade and reports the unknown instrument to the caller.
+	PolicyReject Policy = "reject"
+	// PolicyHold parks the trade on the bridge until a mapping is configured.
+	PolicyHold Policy = "hold"
+)
+
+// ErrUnknownSymbol is returned by Resolve when no rule matches and the policy is not passthrough.
+var ErrUnknownSymbol = errors.New("unknown instrument")
+
+// Rule maps Quantower instruments onto an MT5 symbol.
+// Exactly one of Match (exact name or wildcard using * and ?) or Regex must be set.
+type Rule struct {
+	Match  string  `json:"match,omitempty"`
+	Regex  string  `json:"regex,omitempty"`
+	Symbol string  `json:"symbol"`
+	Suffix *string `json:"suffix,omitempty"` // overrides Config.Suffix for this rule when set (may be "")
+}
+
+// Config is the "symbols" section of the bridge configuration file.
+type Config struct {
+	UnknownPolicy Policy `json:"unknown_policy,omitempty"`
+	Suffix        string `json:"suffix,omitempty"` // broker suffix appended to every mapped symbol, e.g. ".cash"
+	Rules         []Rule `json:"rules,omitempty"`
+}
+
+// Resolution is the outcome of mapping a single instrument.
+type Resolution struct {
+	Instrument string // instrument as received from the add-on
+	Symbol     string // MT5 symbol including broker suffix; empty when unmapped
+	Rule       string // rule that matched (for audit/metadata)
+	Mapped     bool
+	Policy     Policy // policy applied when Mapped is false
+}
+
+type compiledRule struct {
+	label  string
+	exact  string
+	re     *regexp.Regexp
+	symbol string
+}
+
+// Map is an immutable, validated instrument map. A nil *Map resolves everything as passthrough.
+type Map struct {
+	policy Policy
+	exact  map[string]compiledRule
+	rules  []compiledRule
+}
+
+// New validates cfg and compiles its rules.
+func New(cfg Config) (*Map, error) {
+	policy := Policy(strings.ToLower(strings.TrimSpace(string(cfg.UnknownPolicy))))
+	switch policy {
+	case "":
+		policy = PolicyPassthrough
+	case PolicyPassthrough, PolicyReject, PolicyHold:
+	default:
+		return nil, fmt.Errorf("symbols: unknown_policy %q must be one of passthrough|reject|hold", cfg.UnknownPolicy)
+	}
+
+	m := &Map{policy: policy, exact: make(map[string]compiledRule)}
+	for i, r := range cfg.Rules {
+		match := strings.TrimSpace(r.Match)
+		expr := strings.TrimSpace(r.Regex)
+		symbol := strings.TrimSpace(r.Symbol)
+		if symbol == "" {
+			return nil, fmt.Errorf("symbols: rule %d has no symbol", i)
+		}
+		if (match == "") == (expr == "") {
+			return nil, fmt.Errorf("symbols: rule %d must set exactly one of match or regex", i)
+		}
+		suffix := cfg.Suffix
+		if r.Suffix != nil {
+			suffix = *r.Suffix
+		}
+		cr := compiledRule{symbol: symbol + strings.TrimSpace(suffix)}
+
+		switch {
+		case expr != "":
+			// Anchored like wildcards, so a rule for NQ does not also take MNQ
+			re, err := regexp.Compile("(?i)^(?:" + expr + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("symbols: rule %d regex %q: %w", i, expr, err)
+			}
+			cr.label, cr.re = "regex:"+expr, re
+			m.rules = append(m.rules, cr)
+		case strings.ContainsAny(match, "*?"):
+			cr.label, cr.re = "match:"+match, Pattern(match)
+			m.rules = append(m.rules, cr)
+		default:
+			key := strings.ToUpper(match)
+			if _, dup := m.exact[key]; dup {
+				return nil, fmt.Errorf("symbols: rule %d duplicates exact match %q", i, match)
+			}
+			cr.label, cr.exact = "match:"+match, key
+			m.exact[key] = cr
+		}
+	}
+	return m, nil
+}
+
+// Pattern compiles an instrument name or * / ? wildcard into a case-insensitive, anchored matcher.
+// Other packages keyed by instrument (sizing, trading hours) use it so matching stays consistent.
+func Pattern(match string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)^" + globToRegex(strings.TrimSpace(match)) + "$")
+}
+
+// globToRegex converts a * / ? wildcard into an anchored-body regular expression.
+func globToRegex(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// Policy returns the configured unknown-instrument policy.
+func (m *Map) Policy() Policy {
+	if m == nil {
+		return PolicyPassthrough
+	}
+	return m.policy
+}
+
+// Len returns the number of configured rules.
+func (m *Map) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.exact) + len(m.rules)
+}
+
+// Resolve maps an instrument to its MT5 symbol. Exact matches win over patterns; patterns are
+// tried in configuration order. When nothing matches, the error is ErrUnknownSymbol unless the
+// policy is passthrough.
+func (m *Map) Resolve(instrument string) (Resolution, error) {
+	inst := strings.TrimSpace(instrument)
+	res := Resolution{Instrument: inst, Policy: m.Policy()}
+	if m == nil || inst == "" {
+		return res, nil
+	}
+	if r, ok := m.exact[strings.ToUpper(inst)]; ok {
+		res.Symbol, res.Rule, res.Mapped = r.symbol, r.label, true
+		return res, nil
+	}
+	for _, r := range m.rules {
+		if r.re.MatchString(inst) {
+			res.Symbol, res.Rule, res.Mapped = r.symbol, r.label, true
+			return res, nil
+		}
+	}
+	if m.policy == PolicyPassthrough {
+		return res, nil
+	}
+	return res, fmt.Errorf("%w %q (policy=%s)", ErrUnknownSymbol, inst, m.policy)
+}
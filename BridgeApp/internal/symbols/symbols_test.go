@@ -0,0 +1,98 @@
+package symbols
+
+import (
+	"errors"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestResolveRules(t *testing.T) {
+	m, err := New(Config{
+		UnknownPolicy: PolicyReject,
+		Suffix:        ".cash",
+		Rules: []Rule{
+			{Match: "NQ*", Symbol: "NAS100"},
+			{Match: "NQZ5", Symbol: "USTEC", Suffix: strPtr("")},
+			{Regex: `^ES[FGHJKMNQUVXZ]\d{1,2}$`, Symbol: "US500"},
+			{Regex: `YM[HMUZ]\d`, Symbol: "US30"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	cases := []struct {
+		in, want string
+	}{
+		{"NQZ5", "USTEC"},       // exact wins over earlier wildcard
+		{"nqh6", "NAS100.cash"}, // wildcard, case-insensitive, default suffix
+		{"ESM25", "US500.cash"}, // regex
+		{"ymz5", "US30.cash"},   // regex without ^ and $ still matches the whole name
+	}
+	for _, c := range cases {
+		res, err := m.Resolve(c.in)
+		if err != nil || !res.Mapped || res.Symbol != c.want {
+			t.Fatalf("Resolve(%q) = %+v, %v; want %q", c.in, res, err, c.want)
+		}
+	}
+
+	if _, err := m.Resolve("CLZ5"); !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("expected ErrUnknownSymbol for unmapped instrument, got %v", err)
+	}
+	if _, err := m.Resolve("MYMZ5"); !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("a regex rule must match the whole instrument, not the micro contract, got %v", err)
+	}
+}
+
+func TestRegexRuleDoesNotMatchMicroContract(t *testing.T) {
+	m, err := New(Config{
+		UnknownPolicy: PolicyReject,
+		Rules: []Rule{
+			{Regex: `NQ`, Symbol: "NAS100"},
+			{Match: "MNQ*", Symbol: "NAS100.micro"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if res, err := m.Resolve("MNQZ5"); err != nil || res.Symbol != "NAS100.micro" {
+		t.Fatalf("Resolve(MNQZ5) = %+v, %v; want the micro contract rule", res, err)
+	}
+	if res, err := m.Resolve("nq"); err != nil || res.Symbol != "NAS100" {
+		t.Fatalf("Resolve(nq) = %+v, %v; want NAS100", res, err)
+	}
+	if _, err := m.Resolve("NQZ5"); !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("regex NQ must not match NQZ5, got %v", err)
+	}
+}
+
+func TestResolvePassthroughAndNilMap(t *testing.T) {
+	var nilMap *Map
+	if res, err := nilMap.Resolve("NQZ5"); err != nil || res.Mapped {
+		t.Fatalf("nil map should pass through, got %+v, %v", res, err)
+	}
+
+	m, err := New(Config{Rules: []Rule{{Match: "ES*", Symbol: "US500"}}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if res, err := m.Resolve("NQZ5"); err != nil || res.Mapped || res.Policy != PolicyPassthrough {
+		t.Fatalf("default policy should pass through, got %+v, %v", res, err)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	bad := []Config{
+		{UnknownPolicy: "drop"},
+		{Rules: []Rule{{Match: "NQ*"}}},
+		{Rules: []Rule{{Match: "NQ*", Regex: "^NQ", Symbol: "NAS100"}}},
+		{Rules: []Rule{{Regex: "([", Symbol: "NAS100"}}},
+		{Rules: []Rule{{Match: "NQZ5", Symbol: "A"}, {Match: "nqz5", Symbol: "B"}}},
+	}
+	for i, cfg := range bad {
+		if _, err := New(cfg); err == nil {
+			t.Fatalf("case %d: expected validation error for %+v", i, cfg)
+		}
+	}
+}
@@ -27,7 +27,10 @@ using trading::StreamingService;
 using trading::LoggingService;
 using trading::Trade;
 using trading::HealthRequest;
+using trading::GetTradesRequest;
 using trading::HealthResponse;
+using trading::HelloRequest;
+using trading::HelloResponse;
 using trading::GenericResponse;
 using trading::MT5TradeResult;
 using trading::HedgeCloseNotification;
@@ -69,6 +72,11 @@ public:
     
     std::chrono::steady_clock::time_point last_health_check_;
     
+    // Terminal identity sent with every call so the bridge can route per terminal
+    std::string terminal_id_;
+    std::string account_id_;
+    std::mutex identity_mutex_;
+    
     ~GrpcClientState() {
         Cleanup();
     }
@@ -147,6 +155,30 @@ public:
         std::lock_guard<std::mutex> lock(trade_queue_mutex_);
         return trade_queue_.size();
     }
+    
+    void SetIdentity(const std::string& terminal_id, const std::string& account_id) {
+        std::lock_guard<std::mutex> lock(identity_mutex_);
+        terminal_id_ = terminal_id;
+        account_id_ = account_id;
+    }
+    
+    void GetIdentity(std::string& terminal_id, std::string& account_id) {
+        std::lock_guard<std::mutex> lock(identity_mutex_);
+        terminal_id = terminal_id_;
+        account_id = account_id_;
+    }
+    
+    // Attach "terminal-id"/"account-id" metadata (skipped when unset)
+    void AddIdentityMetadata(ClientContext& context) {
+        std::string terminal_id, account_id;
+        GetIdentity(terminal_id, account_id);
+        if (!terminal_id.empty()) {
+            context.AddMetadata("terminal-id", terminal_id);
+        }
+        if (!account_id.empty()) {
+            context.AddMetadata("account-id", account_id);
+        }
+    }
 };
 
 static GrpcClientState g_client_state;
@@ -191,6 +223,7 @@ void StreamingThreadFunction() {
                               std::chrono::milliseconds(g_client_state.streaming_timeout_ms_);
                 context.set_deadline(deadline);
             }
+            g_client_state.AddIdentityMetadata(context);
             
             auto stream = g_client_state.trading_stub_->GetTrades(&context);
             
@@ -198,9 +231,13 @@ void StreamingThreadFunction() {
             std::thread heartbeat_thread([&stream, &context]() {
                 try {
                     while (!g_client_state.stop_streaming_) {
-                        HealthRequest request;
+                        std::string terminal_id, account_id;
+                        g_client_state.GetIdentity(terminal_id, account_id);
+                        GetTradesRequest request;
                         request.set_source("MT5_EA");
                         request.set_open_positions(0);
+                        request.set_terminal_id(terminal_id);
+                        request.set_account_id(account_id);
                         
                         if (!stream->Write(request)) {
                             break;
@@ -232,6 +269,8 @@ void StreamingThreadFunction() {
                     {"measurement_pips", trade.measurement_pips()},
                     {"raw_measurement", trade.raw_measurement()},
                     {"instrument", trade.instrument()},
+                    // Bridge-resolved MT5 symbol; empty when the instrument map has no rule
+                    {"mt5_symbol", trade.mt5_symbol()},
                     {"account_name", trade.account_name()},
                     {"nt_balance", trade.nt_balance()},
                     {"nt_daily_pnl", trade.nt_daily_pnl()},
@@ -239,11 +278,15 @@ void StreamingThreadFunction() {
                     {"nt_session_trades", trade.nt_session_trades()},
                     // Elastic sizing hint propagated from NT via Bridge
                     {"nt_points_per_1k_loss", trade.nt_points_per_1k_loss()},
+                    // Bridge-computed lot for this (split) trade; 0 = EA sizes the hedge itself
+                    {"hedge_lot", trade.hedge_lot()},
                     // Forward elastic metrics used by EA for partial-close gating
                     {"elastic_current_profit", trade.elastic_current_profit()},
                     {"elastic_profit_level", trade.elastic_profit_level()},
                     // Critical for deterministic CLOSE_HEDGE when multiple hedges exist
-                    {"mt5_ticket", trade.mt5_ticket()}
+                    {"mt5_ticket", trade.mt5_ticket()},
+                    // Lots to close on mt5_ticket (CLOSE_HEDGE only); 0 closes the whole position
+                    {"close_volume", trade.close_volume()}
                 };
 
                 // SAFEGUARD: Omit nt_daily_pnl from JSON for non-entry actions when the value is zero (proto default)
@@ -293,6 +336,37 @@ void StreamingThreadFunction() {
     }
 }
 
+// Declare the protocol features this client and the EA handle, so the bridge only sends what
+// they understand. A bridge without the handshake answers UNIMPLEMENTED and serves legacy output.
+static void SayHello() {
+    ClientContext context;
+    context.set_deadline(std::chrono::system_clock::now() +
+                         std::chrono::milliseconds(g_client_state.connection_timeout_ms_));
+    g_client_state.AddIdentityMetadata(context);
+
+    std::string terminal_id, account_id;
+    g_client_state.GetIdentity(terminal_id, account_id);
+
+    HelloRequest request;
+    request.set_kind("ea");
+    request.set_client_id(terminal_id);
+    request.set_client_version("mt5-dll-2");
+    request.set_protocol_version(2);
+    for (const char* feature : {"event_trades", "mt5_symbol", "hedge_lot", "partial_close", "terminal_routing"}) {
+        request.add_features(feature);
+    }
+
+    HelloResponse response;
+    Status status = g_client_state.trading_stub_->Hello(&context, request, &response);
+    if (!status.ok()) {
+        if (status.error_code() != grpc::StatusCode::UNIMPLEMENTED) {
+            g_client_state.SetLastError("Hello failed: " + status.error_message());
+        }
+    } else if (!response.accepted()) {
+        g_client_state.SetLastError("Hello rejected: " + response.message());
+    }
+}
+
 // DLL Entry Point
 BOOL APIENTRY DllMain(HMODULE hModule, DWORD ul_reason_for_call, LPVOID lpReserved) {
     switch (ul_reason_for_call) {
@@ -347,6 +421,7 @@ MT5_GRPC_API int __stdcall GrpcInitialize(const wchar_t* server_address, int por
         auto deadline = std::chrono::system_clock::now() + 
                        std::chrono::milliseconds(g_client_state.connection_timeout_ms_);
         context.set_deadline(deadline);
+        g_client_state.AddIdentityMetadata(context);
         
         HealthRequest request;
         request.set_source("MT5_EA");
@@ -359,6 +434,7 @@ MT5_GRPC_API int __stdcall GrpcInitialize(const wchar_t* server_address, int por
             g_client_state.is_initialized_ = true;
             g_client_state.is_connected_ = true;
             g_client_state.last_health_check_ = std::chrono::steady_clock::now();
+            SayHello();
             return ERROR_SUCCESS;
         } else {
             g_client_state.SetLastError("Health check failed: " + status.error_message());
@@ -371,6 +447,16 @@ MT5_GRPC_API int __stdcall GrpcInitialize(const wchar_t* server_address, int por
     }
 }
 
+MT5_GRPC_API int __stdcall GrpcSetTerminalIdentity(const wchar_t* terminal_id, const wchar_t* account_id) {
+    try {
+        g_client_state.SetIdentity(WideStringToUTF8(terminal_id), WideStringToUTF8(account_id));
+        return ERROR_SUCCESS;
+    } catch (const std::exception& e) {
+        g_client_state.SetLastError("Set terminal identity exception: " + std::string(e.what()));
+        return ERROR_INVALID_PARAMS;
+    }
+}
+
 MT5_GRPC_API int __stdcall GrpcShutdown() {
     try {
         g_client_state.Cleanup();
@@ -486,6 +572,7 @@ MT5_GRPC_API int __stdcall GrpcSubmitTradeResult(const wchar_t* result_json) {
         auto deadline = std::chrono::system_clock::now() + 
                        std::chrono::milliseconds(g_client_state.connection_timeout_ms_);
         context.set_deadline(deadline);
+        g_client_state.AddIdentityMetadata(context);
         
         MT5TradeResult trade_result;
         trade_result.set_status(result_data.value("status", ""));
@@ -493,6 +580,13 @@ MT5_GRPC_API int __stdcall GrpcSubmitTradeResult(const wchar_t* result_json) {
         trade_result.set_volume(result_data.value("volume", 0.0));
         trade_result.set_is_close(result_data.value("is_close", false));
         trade_result.set_id(result_data.value("id", ""));
+        trade_result.set_profit(result_data.value("profit", 0.0));
+        trade_result.set_price(result_data.value("price", 0.0));
+        trade_result.set_open_price(result_data.value("open_price", 0.0));
+        trade_result.set_commission(result_data.value("commission", 0.0));
+        trade_result.set_swap(result_data.value("swap", 0.0));
+        trade_result.set_server_time(result_data.value("server_time", 0LL));
+        trade_result.set_deal_ticket(result_data.value("deal_ticket", 0ULL));
         
         GenericResponse response;
         Status status = g_client_state.trading_stub_->SubmitTradeResult(&context, trade_result, &response);
@@ -527,6 +621,7 @@ MT5_GRPC_API int __stdcall GrpcHealthCheck(const wchar_t* request_json, wchar_t*
         auto deadline = std::chrono::system_clock::now() + 
                        std::chrono::milliseconds(g_client_state.connection_timeout_ms_);
         context.set_deadline(deadline);
+        g_client_state.AddIdentityMetadata(context);
         
         HealthRequest request;
         request.set_source("MT5_EA");
@@ -574,6 +669,7 @@ MT5_GRPC_API int __stdcall GrpcNotifyHedgeClose(const wchar_t* notification_json
         auto deadline = std::chrono::system_clock::now() + 
                        std::chrono::milliseconds(g_client_state.connection_timeout_ms_);
         context.set_deadline(deadline);
+        g_client_state.AddIdentityMetadata(context);
         
         HedgeCloseNotification notification;
         notification.set_event_type(notification_data.value("event_type", ""));
@@ -584,6 +680,14 @@ MT5_GRPC_API int __stdcall GrpcNotifyHedgeClose(const wchar_t* notification_json
         notification.set_closed_hedge_action(notification_data.value("closed_hedge_action", ""));
         notification.set_timestamp(notification_data.value("timestamp", ""));
         notification.set_closure_reason(notification_data.value("closure_reason", ""));
+        notification.set_mt5_ticket(notification_data.value("mt5_ticket", 0ULL));
+        notification.set_close_price(notification_data.value("close_price", 0.0));
+        notification.set_open_price(notification_data.value("open_price", 0.0));
+        notification.set_commission(notification_data.value("commission", 0.0));
+        notification.set_swap(notification_data.value("swap", 0.0));
+        notification.set_profit(notification_data.value("profit", 0.0));
+        notification.set_server_time(notification_data.value("server_time", 0LL));
+        notification.set_deal_ticket(notification_data.value("deal_ticket", 0ULL));
         
         GenericResponse response;
         Status status = g_client_state.trading_stub_->NotifyHedgeClose(&context, notification, &response);
@@ -609,6 +713,7 @@ MT5_GRPC_API int __stdcall GrpcSubmitElasticUpdate(const wchar_t* update_json) {
         auto deadline = std::chrono::system_clock::now() + 
                        std::chrono::milliseconds(g_client_state.connection_timeout_ms_);
         context.set_deadline(deadline);
+        g_client_state.AddIdentityMetadata(context);
         
         ElasticHedgeUpdate update;
         update.set_event_type(update_data.value("event_type", "elastic_update"));
@@ -642,6 +747,7 @@ MT5_GRPC_API int __stdcall GrpcSubmitTrailingUpdate(const wchar_t* update_json)
         auto deadline = std::chrono::system_clock::now() + 
                        std::chrono::milliseconds(g_client_state.connection_timeout_ms_);
         context.set_deadline(deadline);
+        g_client_state.AddIdentityMetadata(context);
         
         TrailingStopUpdate update;
         update.set_event_type(update_data.value("event_type", "trailing_update"));
@@ -762,6 +868,7 @@ MT5_GRPC_API int __stdcall GrpcLog(const wchar_t* log_json) {
         auto deadline = std::chrono::system_clock::now() + 
                        std::chrono::milliseconds(g_client_state.connection_timeout_ms_);
         context.set_deadline(deadline);
+        g_client_state.AddIdentityMetadata(context);
 
         LogEvent evt;
         // Minimal required fields with safe defaults
@@ -24,6 +24,7 @@ extern "C" {
     // Core initialization and connection
     MT5_GRPC_API int __stdcall TestFunction();
     MT5_GRPC_API int __stdcall GrpcInitialize(const wchar_t* server_address, int port);
+    MT5_GRPC_API int __stdcall GrpcSetTerminalIdentity(const wchar_t* terminal_id, const wchar_t* account_id);
     MT5_GRPC_API int __stdcall GrpcShutdown();
     MT5_GRPC_API int __stdcall GrpcIsConnected();
     MT5_GRPC_API int __stdcall GrpcReconnect();
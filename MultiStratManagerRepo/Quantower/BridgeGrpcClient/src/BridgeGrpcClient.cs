@@ -105,6 +105,15 @@ namespace Quantower.Bridge.Client
                     return false;
                 }
 
+                var hello = await newClient.HelloAsync().ConfigureAwait(false);
+                if (!hello.Success)
+                {
+                    LastError = string.IsNullOrWhiteSpace(hello.ErrorMessage)
+                        ? "Hello failed"
+                        : hello.ErrorMessage;
+                    return false;
+                }
+
                 lock (InitLock)
                 {
                     ShutdownInternal();
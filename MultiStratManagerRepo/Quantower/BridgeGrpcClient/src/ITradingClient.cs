@@ -9,6 +9,7 @@ namespace Quantower.Bridge.Client
         bool IsConnected { get; }
         Task<OperationResult> SubmitTradeAsync(string tradeJson, CancellationToken cancellationToken = default);
         Task<OperationResult> HealthCheckAsync(string source);
+        Task<OperationResult> HelloAsync();
         Task<OperationResult> SubmitElasticUpdateAsync(string updateJson);
         Task<OperationResult> SubmitTrailingUpdateAsync(string updateJson);
         Task<OperationResult> NotifyHedgeCloseAsync(string notificationJson);
@@ -160,6 +160,49 @@ namespace Quantower.Bridge.Client
             }
         }
 
+        // Bridge protocol this client speaks and the Trade fields it fills (see BridgeApp/config.md)
+        private const uint ProtocolVersion = 2;
+        private static readonly string[] ClientFeatures = { "qt_position_id", "idempotency_key" };
+
+        public async Task<OperationResult> HelloAsync()
+        {
+            try
+            {
+                var request = new HelloRequest
+                {
+                    Kind = "addon",
+                    ClientId = _component,
+                    ClientVersion = typeof(TradingClient).Assembly.GetName().Version?.ToString() ?? string.Empty,
+                    ProtocolVersion = ProtocolVersion
+                };
+                request.Features.AddRange(ClientFeatures);
+                var deadline = DateTime.UtcNow.AddSeconds(3);
+                var response = await _client.HelloAsync(request, deadline: deadline).ConfigureAwait(false);
+                var responseJson = JsonSerializer.Serialize(new
+                {
+                    accepted = response.Accepted,
+                    message = response.Message,
+                    protocol_version = response.ProtocolVersion,
+                    negotiated = response.Negotiated
+                });
+                if (!response.Accepted)
+                {
+                    LastError = $"Bridge rejected this add-on (bridge protocol {response.ProtocolVersion}): {response.Message}";
+                    return OperationResult.Failure(LastError);
+                }
+                return OperationResult.Ok(responseJson);
+            }
+            catch (RpcException ex) when (ex.StatusCode == StatusCode.Unimplemented)
+            {
+                // Bridges without the handshake serve every client
+                return OperationResult.Ok();
+            }
+            catch (Exception ex)
+            {
+                return OperationResult.Failure(ex.Message);
+            }
+        }
+
         public async Task<OperationResult> SubmitElasticUpdateAsync(string updateJson)
         {
             try
@@ -453,7 +496,8 @@ namespace Quantower.Bridge.Client
                     QtTradeId = GetStringValue(root, "qt_trade_id", "quantower_trade_id"),
                     QtPositionId = GetStringValue(root, "qt_position_id", "quantower_position_id", "position_id"),
                     StrategyTag = GetStringValue(root, "strategy_tag", "strategy"),
-                    OriginPlatform = GetStringValue(root, "origin_platform", "source_platform", "platform", "origin")
+                    OriginPlatform = GetStringValue(root, "origin_platform", "source_platform", "platform", "origin"),
+                    IdempotencyKey = GetStringValue(root, "idempotency_key")
                 };
             }
             catch (JsonException ex)
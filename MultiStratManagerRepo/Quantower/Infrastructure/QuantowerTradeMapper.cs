@@ -91,6 +91,8 @@ namespace Quantower.MultiStrat.Infrastructure
                 if (!string.IsNullOrWhiteSpace(qtTradeId))
                 {
                     payload["qt_trade_id"] = qtTradeId;
+                    // A fill is hedged once: the bridge answers re-sends of the same fill with the original response
+                    payload["idempotency_key"] = "qt-fill:" + qtTradeId;
                 }
 
                 payload["qt_position_id"] = positionId;  // Always include for audit trail